@@ -0,0 +1,55 @@
+package tolog
+
+import "sync"
+
+var fieldAliases map[string]string
+var fieldNameNormalizer func(string) string
+var fieldAliasesMu sync.RWMutex
+
+// SetFieldAliases sets the renaming applied to field names when entries are encoded,
+// e.g. {"ts": "timestamp", "err": "error"}. Nil removes all aliases.
+func SetFieldAliases(aliases map[string]string) {
+	copied := make(map[string]string, len(aliases))
+	for k, v := range aliases {
+		copied[k] = v
+	}
+	fieldAliasesMu.Lock()
+	defer fieldAliasesMu.Unlock()
+	fieldAliases = copied
+}
+
+// SetFieldNameNormalizer sets a function applied to every field name when entries are
+// encoded, after the aliases, e.g. to enforce snake_case. Nil disables it.
+func SetFieldNameNormalizer(normalizer func(string) string) {
+	fieldAliasesMu.Lock()
+	defer fieldAliasesMu.Unlock()
+	fieldNameNormalizer = normalizer
+}
+
+// normalizeFields returns the fields with aliases and normalization applied to their names.
+// When two names end up the same, the field which was already named so wins.
+func normalizeFields(f Fields) Fields {
+	fieldAliasesMu.RLock()
+	aliases, normalizer := fieldAliases, fieldNameNormalizer
+	fieldAliasesMu.RUnlock()
+	if len(f) == 0 || (len(aliases) == 0 && normalizer == nil) {
+		return f
+	}
+	normalized := make(Fields, len(f))
+	renamed := make(map[string]bool, len(f))
+	for _, k := range f.sortedKeys() {
+		name := k
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if normalizer != nil {
+			name = normalizer(name)
+		}
+		if _, exists := normalized[name]; exists && (name != k || !renamed[name]) {
+			continue
+		}
+		normalized[name] = f[k]
+		renamed[name] = name != k
+	}
+	return normalized
+}
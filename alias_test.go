@@ -0,0 +1,19 @@
+package tolog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldAliases(t *testing.T) {
+	SetFieldAliases(map[string]string{"ts": "timestamp", "err": "error"})
+	SetFieldNameNormalizer(strings.ToLower)
+	defer SetFieldAliases(nil)
+	defer SetFieldNameNormalizer(nil)
+
+	assert.Equal(t, Fields{"timestamp": 1, "error": "boom", "userid": 7}, normalizeFields(Fields{"ts": 1, "err": "boom", "UserID": 7}))
+	assert.Equal(t, Fields{"error": "explicit"}, normalizeFields(Fields{"err": "aliased", "error": "explicit"}))
+	assert.Equal(t, "error=boom timestamp=1", renderFields(Fields{"ts": 1, "err": "boom"}))
+}
@@ -0,0 +1,46 @@
+package tolog
+
+// DefaultPublicMessage is the message of an APIError when none is given.
+const DefaultPublicMessage = "internal error"
+
+// APIError is the error payload of an API response linked to the entry the
+// error was logged with, without the internals of the entry.
+type APIError struct {
+	// Message is the message for the client, never the one of the entry.
+	Message string `json:"message"`
+	// CorrelationID finds the entry in the logs.
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// Error returns the public message.
+func (e APIError) Error() string {
+	return e.Message
+}
+
+// PublicError returns the payload of the entry for the clients of an API, with
+// the public message, DefaultPublicMessage if empty, and the request_id of the
+// entry, or its trace_id, as correlation ID. The message, fields and errors of
+// the entry stay in the logs.
+func (l *ToLog) PublicError(message string) APIError {
+	return publicError(l.fields, message)
+}
+
+// PublicError returns the payload of the entry for the clients of an API, see ToLog.PublicError.
+func (e *Entry) PublicError(message string) APIError {
+	return publicError(e.Fields, message)
+}
+
+// publicError returns the payload of the message and the correlation ID in the fields.
+func publicError(fields Fields, message string) APIError {
+	if message == "" {
+		message = DefaultPublicMessage
+	}
+	e := APIError{Message: message}
+	for _, key := range []string{RequestIDField, TraceIDField} {
+		if id, ok := fields[key]; ok {
+			e.CorrelationID = toString(id)
+			break
+		}
+	}
+	return e
+}
@@ -0,0 +1,17 @@
+package tolog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicError(t *testing.T) {
+	l := Error("query failed").Err(errors.New("pq: relation \"users\" does not exist")).WithField(TraceIDField, "4bf92f35")
+	assert.Equal(t, APIError{Message: "could not load the user", CorrelationID: "4bf92f35"}, l.PublicError("could not load the user"))
+
+	l = l.WithField(RequestIDField, "req-1")
+	assert.Equal(t, APIError{Message: DefaultPublicMessage, CorrelationID: "req-1"}, l.entry().PublicError(""))
+	assert.Equal(t, APIError{Message: DefaultPublicMessage}, Error("no IDs").PublicError(""))
+}
@@ -0,0 +1,97 @@
+package tolog
+
+import (
+	"io"
+	"reflect"
+	"sync/atomic"
+)
+
+var asyncConsole int32
+
+// SetAsyncConsole queues the lines printed to the console with those of the
+// log file, printed by the goroutine writing the file in the same batches, so
+// goroutines logging heavily don't wait on the console. The console lines keep
+// their order relative to the file. Entries printed while the file of their
+// logger is not open, like those of loggers only printing, are printed at once.
+// The queued lines are printed by Flush and Close, and lost with the entries
+// dropped by the overflow policy.
+func SetAsyncConsole(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&asyncConsole, v)
+}
+
+// printedLine returns the line of the entry printed to the console.
+func (lg *Logger) printedLine(l *ToLog) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	lg.appendPrinted(buf, l, lg.consoleStream(l.logType))
+	return buf.String()
+}
+
+// printQueued queues the console line of the item to the file writer of the
+// logger, or prints the entry at once when the file is not open.
+func (lg *Logger) printQueued(l *ToLog, item writeItem) {
+	if item.console != "" && !lg.fileWriter().enqueueConsole(item) {
+		lg.print(l)
+	}
+}
+
+// enqueueConsole queues the console line of the item if the writer is open,
+// reporting whether it did.
+func (w *fileWriter) enqueueConsole(item writeItem) bool {
+	if w == nil {
+		return false
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return false
+	}
+	w.enqueue(item)
+	return true
+}
+
+// printConsole prints the console lines of the items in order, consecutive
+// lines to the same console in one write, and clears them so a batch kept
+// after a failed write doesn't print them again.
+func printConsole(items []writeItem) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	var printer *Logger
+	var console io.Writer
+	write := func() {
+		if buf.Len() == 0 {
+			return
+		}
+		printer.outMu.Lock()
+		printer.writeConsole(console, buf.Bytes())
+		printer.outMu.Unlock()
+		buf.Reset()
+	}
+	for i := range items {
+		item := &items[i]
+		if item.console == "" {
+			continue
+		}
+		stream := item.printer.consoleStream(item.level)
+		if item.printer != printer || !sameWriter(stream, console) {
+			write()
+			printer, console = item.printer, stream
+		}
+		buf.WriteString(item.console)
+		item.console = ""
+	}
+	write()
+}
+
+// sameWriter reports whether the writers are the same, false for writers which
+// can't be compared.
+func sameWriter(a, b io.Writer) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b
+}
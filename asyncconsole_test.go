@@ -0,0 +1,40 @@
+package tolog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncConsole(t *testing.T) {
+	SetAsyncConsole(true)
+	defer SetAsyncConsole(false)
+	lg := NewLogger("TestAsyncConsole")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var console bytes.Buffer
+	lg.SetConsole(&lockedWriter{w: &console})
+	lg.SetColor(false)
+
+	lg.Info("before the file is open").PrintLog()
+	assert.Contains(t, console.String(), "before the file is open")
+
+	lg.Info("first").PrintAndWriteSafe()
+	lg.Warning("printed only").PrintLog()
+	lg.Error("third").PrintAndWriteSafe()
+	require.NoError(t, lg.Flush())
+
+	lines := strings.Split(strings.TrimSpace(console.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[1], "[info]  first")
+	assert.Contains(t, lines[2], "[warning]  printed only")
+	assert.Contains(t, lines[3], "[error]  third")
+	content, err := os.ReadFile(lg.FilePath())
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "printed only")
+	assert.Contains(t, string(content), "third")
+}
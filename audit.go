@@ -0,0 +1,152 @@
+package tolog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// auditMACKey is the key of the HMAC closing every line of an audit log.
+const auditMACKey = `,"hmac":"`
+
+// AuditSink writes the entries to an audit log, a file of JSON lines each with
+// a sequence number and an HMAC-SHA256 over the line and the HMAC of the line
+// before, so modified, removed or reordered lines are detected by
+// VerifyAuditLog. Lines are written synchronously, in the order of the entries.
+type AuditSink struct {
+	mu   sync.Mutex
+	file *os.File
+	key  []byte
+	seq  uint64
+	prev []byte
+}
+
+// NewAuditSink opens the audit log at path, continuing its chain if it exists,
+// with the secret key of the HMACs. It fails if the existing file does not
+// verify. Add it with AddSink.
+func NewAuditSink(path string, key []byte) (*AuditSink, error) {
+	s := &AuditSink{key: key}
+	if _, err := os.Stat(path); err == nil {
+		report, err := VerifyAuditLog(path, key)
+		if err != nil {
+			return nil, err
+		}
+		s.seq = report.LastSeq
+		s.prev, _ = hex.DecodeString(report.LastHMAC)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s.file = file
+	return s, nil
+}
+
+// WriteEntry appends the entry to the audit log.
+func (s *AuditSink) WriteEntry(e *Entry) error {
+	obj := entryObject(e, "")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return ErrClosed
+	}
+	obj["seq"] = s.seq + 1
+	payload := []byte(marshalLine(obj))
+	mac := auditMAC(s.key, s.prev, payload)
+	line := make([]byte, 0, len(payload)+len(auditMACKey)+2*len(mac)+3)
+	line = append(line, payload[:len(payload)-1]...)
+	line = append(line, auditMACKey...)
+	line = append(line, hex.EncodeToString(mac)...)
+	line = append(line, '"', '}', '\n')
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	s.seq++
+	s.prev = mac
+	return nil
+}
+
+// Close syncs and closes the audit log.
+func (s *AuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Sync()
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	s.file = nil
+	return err
+}
+
+// auditMAC returns the HMAC of the line chained to the HMAC of the line before.
+func auditMAC(key, prev, payload []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(prev)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// AuditReport describes a verified audit log. Keep LastSeq and LastHMAC
+// elsewhere to detect the truncation of the end of the log, which leaves a
+// valid chain.
+type AuditReport struct {
+	Lines    int
+	LastSeq  uint64
+	LastHMAC string
+}
+
+// VerifyAuditLog checks the chain of the audit log at path with the key,
+// returning an error naming the first line modified, removed, inserted or
+// reordered, with the report of the lines verified before it.
+func VerifyAuditLog(path string, key []byte) (AuditReport, error) {
+	var report AuditReport
+	f, err := os.Open(path)
+	if err != nil {
+		return report, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var prev []byte
+	for scanner.Scan() {
+		n := report.Lines + 1
+		line := scanner.Bytes()
+		i := bytes.LastIndex(line, []byte(auditMACKey))
+		if i < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+			return report, fmt.Errorf("tolog: audit log %s line %d: no hmac", path, n)
+		}
+		mac, err := hex.DecodeString(string(line[i+len(auditMACKey) : len(line)-2]))
+		if err != nil {
+			return report, fmt.Errorf("tolog: audit log %s line %d: invalid hmac", path, n)
+		}
+		payload := append(line[:i:i], '}')
+		if !hmac.Equal(mac, auditMAC(key, prev, payload)) {
+			return report, fmt.Errorf("tolog: audit log %s line %d: hmac mismatch, the line or one before it was modified or removed", path, n)
+		}
+		var obj struct {
+			Seq json.Number `json:"seq"`
+		}
+		if err := json.Unmarshal(payload, &obj); err != nil {
+			return report, fmt.Errorf("tolog: audit log %s line %d: %w", path, n, err)
+		}
+		seq, err := strconv.ParseUint(string(obj.Seq), 10, 64)
+		if err != nil || seq != report.LastSeq+1 {
+			return report, fmt.Errorf("tolog: audit log %s line %d: sequence %s, want %d", path, n, obj.Seq, report.LastSeq+1)
+		}
+		report.Lines = n
+		report.LastSeq = seq
+		report.LastHMAC = hex.EncodeToString(mac)
+		prev = mac
+	}
+	return report, scanner.Err()
+}
@@ -0,0 +1,56 @@
+package tolog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAudit(t *testing.T, path string, key []byte, messages ...string) {
+	t.Helper()
+	sink, err := NewAuditSink(path, key)
+	require.NoError(t, err)
+	for _, msg := range messages {
+		require.NoError(t, sink.WriteEntry(&Entry{Time: time.Now(), Level: StatusInfo, Message: msg, Fields: Fields{"user": "alice"}}))
+	}
+	require.NoError(t, sink.Close())
+	assert.ErrorIs(t, sink.WriteEntry(&Entry{Time: time.Now(), Level: StatusInfo, Message: "closed"}), ErrClosed)
+}
+
+func TestAuditSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	key := []byte("secret")
+	writeAudit(t, path, key, "login", "grant admin")
+	writeAudit(t, path, key, "logout")
+
+	report, err := VerifyAuditLog(path, key)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Lines)
+	assert.Equal(t, uint64(3), report.LastSeq)
+	assert.Len(t, report.LastHMAC, 64)
+
+	_, err = VerifyAuditLog(path, []byte("other key"))
+	assert.ErrorContains(t, err, "line 1: hmac mismatch")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.SplitAfter(string(content), "\n")
+	assert.Contains(t, lines[0], `"seq":1`)
+
+	modified := strings.Replace(string(content), "grant admin", "grant guest", 1)
+	require.NoError(t, os.WriteFile(path, []byte(modified), 0600))
+	report, err = VerifyAuditLog(path, key)
+	assert.ErrorContains(t, err, "line 2: hmac mismatch")
+	assert.Equal(t, 1, report.Lines)
+
+	require.NoError(t, os.WriteFile(path, []byte(lines[0]+lines[2]), 0600))
+	_, err = VerifyAuditLog(path, key)
+	assert.ErrorContains(t, err, "line 2: hmac mismatch")
+	_, err = NewAuditSink(path, key)
+	assert.Error(t, err, "a tampered log is not continued")
+}
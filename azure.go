@@ -0,0 +1,146 @@
+package tolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAzureIngestionEndpoint is used when the connection string has no IngestionEndpoint.
+const DefaultAzureIngestionEndpoint = "https://dc.services.visualstudio.com/"
+
+// AzureMonitorOptions configures an AzureMonitorSink.
+type AzureMonitorOptions struct {
+	// ConnectionString is the Application Insights connection string,
+	// "InstrumentationKey=...;IngestionEndpoint=https://...".
+	ConnectionString string
+	// InstrumentationKey is used when no connection string is given.
+	InstrumentationKey string
+	// RoleName is sent as the cloud role of the entries.
+	RoleName string
+	// BatchSize and FlushInterval bound the entries per request, default 100 and 1s.
+	BatchSize     int
+	FlushInterval time.Duration
+	// Client sends the requests, default http.DefaultClient.
+	Client *http.Client
+}
+
+// AzureMonitorSink posts entries as Application Insights traces to the Azure Monitor ingestion API.
+type AzureMonitorSink struct {
+	endpoint string
+	iKey     string
+	roleName string
+	client   *http.Client
+	batcher  *batcher
+}
+
+var azureSeverities = map[LogStatus]string{
+	StatusDebug:   "Verbose",
+	StatusInfo:    "Information",
+	StatusNotice:  "Information",
+	StatusWarning: "Warning",
+	StatusError:   "Error",
+}
+
+// NewAzureMonitorSink creates a sink posting to Azure Monitor. Add it with AddSink.
+func NewAzureMonitorSink(opts AzureMonitorOptions) (*AzureMonitorSink, error) {
+	s := &AzureMonitorSink{
+		endpoint: DefaultAzureIngestionEndpoint,
+		iKey:     opts.InstrumentationKey,
+		roleName: opts.RoleName,
+		client:   opts.Client,
+	}
+	for _, part := range strings.Split(opts.ConnectionString, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "instrumentationkey":
+			s.iKey = value
+		case "ingestionendpoint":
+			s.endpoint = value
+		}
+	}
+	if s.iKey == "" {
+		return nil, fmt.Errorf("tolog: azure monitor: missing instrumentation key")
+	}
+	if !strings.HasSuffix(s.endpoint, "/") {
+		s.endpoint += "/"
+	}
+	s.endpoint += "v2/track"
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+	s.batcher = newBatcher(opts.BatchSize, opts.FlushInterval, s.send)
+	return s, nil
+}
+
+// WriteEntry queues the entry for the next batch, dropping it with
+// ErrChannelFull if the queue is full.
+func (s *AzureMonitorSink) WriteEntry(e *Entry) error {
+	return s.batcher.add(e)
+}
+
+// Close sends the queued entries and stops the sink.
+func (s *AzureMonitorSink) Close() error {
+	s.batcher.close()
+	return nil
+}
+
+// envelope builds the Application Insights telemetry envelope of the entry.
+func (s *AzureMonitorSink) envelope(e *Entry) map[string]any {
+	severity, ok := azureSeverities[e.Level]
+	if !ok {
+		severity = "Information"
+	}
+	properties := make(map[string]string, len(e.Fields)+1)
+	for k, v := range normalizeFields(e.Fields).flatten() {
+		properties[k] = toString(jsonValue(v))
+	}
+	properties["level"] = string(e.Level)
+	env := map[string]any{
+		"name": "Microsoft.ApplicationInsights.Message",
+		"time": e.Time.UTC().Format(time.RFC3339Nano),
+		"iKey": s.iKey,
+		"data": map[string]any{
+			"baseType": "MessageData",
+			"baseData": map[string]any{
+				"ver":           2,
+				"message":       e.Message,
+				"severityLevel": severity,
+				"properties":    properties,
+			},
+		},
+	}
+	if s.roleName != "" {
+		env["tags"] = map[string]string{"ai.cloud.role": s.roleName}
+	}
+	return env
+}
+
+// send posts a batch of entries.
+func (s *AzureMonitorSink) send(batch []*Entry) error {
+	envelopes := make([]map[string]any, 0, len(batch))
+	for _, e := range batch {
+		envelopes = append(envelopes, s.envelope(e))
+	}
+	body, err := json.Marshal(envelopes)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("tolog: azure monitor: unexpected status %s", resp.Status)
+	}
+	return nil
+}
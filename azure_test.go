@@ -0,0 +1,44 @@
+package tolog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureMonitorSink(t *testing.T) {
+	received := make(chan []map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/track", r.URL.Path)
+		var envelopes []map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&envelopes))
+		received <- envelopes
+	}))
+	defer server.Close()
+
+	_, err := NewAzureMonitorSink(AzureMonitorOptions{})
+	assert.Error(t, err)
+
+	sink, err := NewAzureMonitorSink(AzureMonitorOptions{
+		ConnectionString: "InstrumentationKey=key-1;IngestionEndpoint=" + server.URL,
+		RoleName:         "api",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, sink.WriteEntry(&Entry{Time: time.Now(), Level: StatusWarning, Message: "disk almost full", Fields: Fields{"free": 3}}))
+	require.NoError(t, sink.Close())
+
+	envelopes := <-received
+	require.Len(t, envelopes, 1)
+	assert.Equal(t, "key-1", envelopes[0]["iKey"])
+	assert.Equal(t, map[string]any{"ai.cloud.role": "api"}, envelopes[0]["tags"])
+	baseData := envelopes[0]["data"].(map[string]any)["baseData"].(map[string]any)
+	assert.Equal(t, "disk almost full", baseData["message"])
+	assert.Equal(t, "Warning", baseData["severityLevel"])
+	assert.Equal(t, map[string]any{"free": "3", "level": "warning"}, baseData["properties"])
+}
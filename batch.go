@@ -0,0 +1,134 @@
+package tolog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// defaultBatchSize is the number of queued lines written at once unless set with SetBatchSize.
+const defaultBatchSize = 100
+
+// The adaptive mode grows the batch and the flush interval up to these factors.
+const (
+	maxBatchGrowth    = 16
+	maxIntervalGrowth = 4
+)
+
+var (
+	batchSize      int64 = defaultBatchSize
+	adaptiveFlush  int32
+	largeEntrySize int64
+)
+
+// SetBatchSize sets after how many queued lines the background writer writes
+// them, without waiting for the ticker of SetLogTickerTime. Zero or less
+// restores the default of 100.
+func SetBatchSize(n int) {
+	if n <= 0 {
+		n = defaultBatchSize
+	}
+	atomic.StoreInt64(&batchSize, int64(n))
+}
+
+// SetAdaptiveFlush sets whether the background writers grow their batch and
+// flush interval under sustained load, up to 16 times the batch size and 4
+// times the interval, for fewer larger writes, and shrink them back when idle
+// so quiet periods keep a low latency. Writers started before keep the mode
+// they started with.
+func SetAdaptiveFlush(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&adaptiveFlush, v)
+}
+
+// SetLargeEntrySize sets the size in bytes from which the lines of entries are
+// written to the file by the writing goroutine instead of queued, so a huge
+// entry, like a dump of a request, neither waits behind nor delays the batches
+// of small ones. A large line may land before lines queued just before it.
+// Zero or less disables it, the default.
+func SetLargeEntrySize(n int) {
+	atomic.StoreInt64(&largeEntrySize, int64(n))
+}
+
+// large reports whether the line of the item goes around the queue, see SetLargeEntrySize.
+func (w *fileWriter) large(item writeItem) bool {
+	max := atomic.LoadInt64(&largeEntrySize)
+	return max > 0 && !w.manual && int64(len(item.line)) >= max
+}
+
+// flushPacer holds the batch size and the flush interval of a writer, adapted
+// to the load in the adaptive mode. It is used by the goroutine of the writer only.
+type flushPacer struct {
+	adaptive bool
+	size     int
+	interval time.Duration
+}
+
+// newFlushPacer returns a pacer starting at the set batch size and flush interval.
+func newFlushPacer() *flushPacer {
+	return &flushPacer{
+		adaptive: atomic.LoadInt32(&adaptiveFlush) == 1,
+		size:     int(atomic.LoadInt64(&batchSize)),
+		interval: time.Duration(atomic.LoadInt64(&flushInterval)),
+	}
+}
+
+// batch returns the number of queued lines to write at once.
+func (p *flushPacer) batch() int {
+	if !p.adaptive {
+		return int(atomic.LoadInt64(&batchSize))
+	}
+	return p.size
+}
+
+// full grows the batch and the interval after a batch filled up before the
+// ticker, reporting whether the interval changed.
+func (p *flushPacer) full() bool {
+	if !p.adaptive {
+		return false
+	}
+	base := int(atomic.LoadInt64(&batchSize))
+	if p.size < base*maxBatchGrowth {
+		p.size *= 2
+		if p.size > base*maxBatchGrowth {
+			p.size = base * maxBatchGrowth
+		}
+	}
+	return p.scaleInterval(2)
+}
+
+// tick shrinks the batch and the interval back when the ticker found less
+// than half a batch queued, reporting whether the interval changed.
+func (p *flushPacer) tick(queued int) bool {
+	if !p.adaptive || queued >= p.size/2 {
+		return false
+	}
+	base := int(atomic.LoadInt64(&batchSize))
+	if p.size > base {
+		p.size /= 2
+		if p.size < base {
+			p.size = base
+		}
+	}
+	return p.scaleInterval(0.5)
+}
+
+// scaleInterval multiplies the interval by factor, between the set flush
+// interval and maxIntervalGrowth times it, reporting whether it changed.
+func (p *flushPacer) scaleInterval(factor float64) bool {
+	base := time.Duration(atomic.LoadInt64(&flushInterval))
+	interval := time.Duration(float64(p.interval) * factor)
+	if interval > base*maxIntervalGrowth {
+		interval = base * maxIntervalGrowth
+	}
+	if interval < base {
+		interval = base
+	}
+	if interval == p.interval {
+		return false
+	}
+	p.interval = interval
+	return true
+}
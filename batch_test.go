@@ -0,0 +1,186 @@
+package tolog
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlushPacer(t *testing.T) {
+	SetBatchSize(10)
+	defer SetBatchSize(0)
+	SetLogTickerTime(100 * time.Millisecond)
+	defer SetLogTickerTime(500 * time.Millisecond)
+
+	p := newFlushPacer()
+	assert.False(t, p.full(), "not adaptive")
+	assert.Equal(t, 10, p.batch())
+
+	SetAdaptiveFlush(true)
+	defer SetAdaptiveFlush(false)
+	p = newFlushPacer()
+	for i := 0; i < 10; i++ {
+		p.full()
+	}
+	assert.Equal(t, 160, p.batch(), "grows up to 16 times")
+	assert.Equal(t, 400*time.Millisecond, p.interval, "up to 4 times")
+
+	assert.False(t, p.tick(100), "still busy")
+	assert.True(t, p.tick(0))
+	assert.Equal(t, 80, p.batch())
+	assert.Equal(t, 200*time.Millisecond, p.interval)
+	for i := 0; i < 10; i++ {
+		p.tick(0)
+	}
+	assert.Equal(t, 10, p.batch(), "shrinks back to the batch size")
+	assert.Equal(t, 100*time.Millisecond, p.interval)
+}
+
+func TestSetBatchSize(t *testing.T) {
+	SetBatchSize(3)
+	defer SetBatchSize(0)
+	SetLogTickerTime(time.Hour)
+	defer SetLogTickerTime(500 * time.Millisecond)
+	lg := NewLogger("TestSetBatchSize")
+	defer lg.Close()
+	path := lg.FilePath()
+	defer os.Remove(path)
+
+	for _, msg := range []string{"one", "two", "three"} {
+		lg.Info(msg).WriteSafe()
+	}
+	assert.Eventually(t, func() bool {
+		content, _ := os.ReadFile(path)
+		return strings.Contains(string(content), "three")
+	}, time.Second, 10*time.Millisecond, "written once the batch is full, without the ticker")
+}
+
+func TestSetLargeEntrySize(t *testing.T) {
+	SetLargeEntrySize(1024)
+	defer SetLargeEntrySize(0)
+	SetLogTickerTime(time.Hour)
+	defer SetLogTickerTime(500 * time.Millisecond)
+	lg := NewLogger("TestSetLargeEntrySize")
+	defer lg.Close()
+	path := lg.FilePath()
+	os.Remove(path)
+	defer os.Remove(path)
+
+	lg.Info("small").WriteSafe()
+	lg.Info("dump").WithField("body", strings.Repeat("x", 2048)).WriteSafe()
+	content, _ := os.ReadFile(path)
+	assert.Contains(t, string(content), "dump", "written before WriteSafe returns")
+	assert.NotContains(t, string(content), "small", "still queued for the ticker")
+
+	assert.NoError(t, lg.Flush())
+	content, _ = os.ReadFile(path)
+	assert.Contains(t, string(content), "small")
+}
+
+func TestSetPriorityLevel(t *testing.T) {
+	SetPriorityLevel(StatusError)
+	defer SetPriorityLevel("")
+	SetBatchSize(1000)
+	defer SetBatchSize(0)
+	SetLogTickerTime(time.Hour)
+	defer SetLogTickerTime(500 * time.Millisecond)
+	lg := NewLogger("TestSetPriorityLevel")
+	defer lg.Close()
+	path := lg.FilePath()
+	os.Remove(path)
+	defer os.Remove(path)
+
+	for i := 0; i < 10; i++ {
+		lg.Info("noise").WriteSafe()
+	}
+	lg.Error("failure").WriteSafe()
+	assert.Eventually(t, func() bool {
+		content, _ := os.ReadFile(path)
+		return strings.Contains(string(content), "failure")
+	}, time.Second, 5*time.Millisecond, "written without waiting for the batch")
+	content, _ := os.ReadFile(path)
+	assert.NotContains(t, string(content), "noise")
+
+	assert.NoError(t, lg.Flush())
+	content, _ = os.ReadFile(path)
+	assert.Equal(t, 10, strings.Count(string(content), "noise"))
+	assert.Less(t, strings.Index(string(content), "failure"), strings.Index(string(content), "noise"), "ahead of the queued lines")
+}
+
+func TestPriorityShortWrite(t *testing.T) {
+	var armed int32
+	SetFS(shortWriteFS{armed: &armed})
+	defer SetFS(nil)
+	var mu sync.Mutex
+	var errs []error
+	SetErrorHandler(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+	defer SetErrorHandler(nil)
+	SetPriorityLevel(StatusError)
+	defer SetPriorityLevel("")
+	lg := NewLogger("TestPriorityShortWrite")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	require.NoError(t, lg.WriteRaw([]byte("opened")))
+	require.NoError(t, lg.Flush())
+
+	atomic.StoreInt32(&armed, 1)
+	lg.Error("failure").WriteSafe()
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) == 1
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, lg.Flush())
+
+	// the rest of the line was written once, the line was not queued again
+	content, err := os.ReadFile(lg.FilePath())
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.Regexp(t, `^\[\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\] \[error\]  failure$`, lines[1])
+}
+
+func TestSetFlushOnLevel(t *testing.T) {
+	SetFlushOnLevel(StatusError)
+	defer SetFlushOnLevel("")
+	SetBatchSize(1000)
+	defer SetBatchSize(0)
+	SetLogTickerTime(time.Hour)
+	defer SetLogTickerTime(500 * time.Millisecond)
+	lg := NewLogger("TestSetFlushOnLevel")
+	defer lg.Close()
+	path := lg.FilePath()
+	os.Remove(path)
+	defer os.Remove(path)
+
+	lg.Info("context").WriteSafe()
+	lg.Warning("still queued").WriteSafe()
+	time.Sleep(20 * time.Millisecond)
+	content, _ := os.ReadFile(path)
+	assert.NotContains(t, string(content), "context")
+
+	lg.Error("failure").WriteSafe()
+	assert.Eventually(t, func() bool {
+		content, _ := os.ReadFile(path)
+		return strings.Contains(string(content), "context") && strings.Contains(string(content), "failure")
+	}, time.Second, 5*time.Millisecond, "the batch is written with the error")
+
+	flush, sync := flushOn(writeItem{level: StatusWarning})
+	assert.False(t, flush || sync)
+	SetSyncOnLevel(StatusWarning)
+	defer SetSyncOnLevel("")
+	flush, sync = flushOn(writeItem{level: StatusWarning})
+	assert.True(t, flush && sync)
+	flush, _ = flushOn(writeItem{line: "raw"})
+	assert.False(t, flush)
+}
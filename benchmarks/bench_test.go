@@ -0,0 +1,230 @@
+// Package benchmarks compares tolog with the standard library log package, zap
+// and zerolog on common scenarios. It is a separate module so the libraries it
+// compares against are not dependencies of tolog.
+package benchmarks
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+	"github.com/rs/zerolog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const message = "request handled"
+
+// discard drops the output like io.Discard, which the log package detects to
+// skip formatting altogether.
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestMain(m *testing.M) {
+	tolog.SetLogWithColor(false)
+	tolog.SetConsole(discard{})
+	code := m.Run()
+	tolog.CloseLogFile()
+	os.RemoveAll("logs")
+	os.Exit(code)
+}
+
+func newZap(json bool) *zap.Logger {
+	cfg := zap.NewProductionEncoderConfig()
+	enc := zapcore.NewConsoleEncoder(cfg)
+	if json {
+		enc = zapcore.NewJSONEncoder(cfg)
+	}
+	return zap.New(zapcore.NewCore(enc, zapcore.AddSync(discard{}), zapcore.DebugLevel))
+}
+
+// BenchmarkMessage logs a plain message as text to the console, discarded.
+func BenchmarkMessage(b *testing.B) {
+	b.Run("tolog", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tolog.Info(message).PrintLog()
+		}
+	})
+	b.Run("stdlog", func(b *testing.B) {
+		lg := log.New(discard{}, "", log.LstdFlags)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Print(message)
+		}
+	})
+	b.Run("zap", func(b *testing.B) {
+		lg := newZap(false)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Info(message)
+		}
+	})
+	b.Run("zerolog", func(b *testing.B) {
+		lg := zerolog.New(zerolog.ConsoleWriter{Out: discard{}, NoColor: true}).With().Timestamp().Logger()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Info().Msg(message)
+		}
+	})
+}
+
+// BenchmarkFields logs a message with a few fields as text, discarded.
+func BenchmarkFields(b *testing.B) {
+	b.Run("tolog", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tolog.Info(message).Fields(tolog.Fields{"method": "GET", "status": 200, "latency": 0.042}).PrintLog()
+		}
+	})
+	b.Run("stdlog", func(b *testing.B) {
+		lg := log.New(discard{}, "", log.LstdFlags)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Printf("%s method=%s status=%d latency=%g", message, "GET", 200, 0.042)
+		}
+	})
+	b.Run("zap", func(b *testing.B) {
+		lg := newZap(false)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Info(message, zap.String("method", "GET"), zap.Int("status", 200), zap.Float64("latency", 0.042))
+		}
+	})
+	b.Run("zerolog", func(b *testing.B) {
+		lg := zerolog.New(zerolog.ConsoleWriter{Out: discard{}, NoColor: true}).With().Timestamp().Logger()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Info().Str("method", "GET").Int("status", 200).Float64("latency", 0.042).Msg(message)
+		}
+	})
+}
+
+// BenchmarkJSON logs a message with a few fields as JSON, discarded.
+func BenchmarkJSON(b *testing.B) {
+	b.Run("tolog", func(b *testing.B) {
+		tolog.SetLogFormat(tolog.FormatJSON)
+		defer tolog.SetLogFormat(tolog.FormatText)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tolog.Info(message).Fields(tolog.Fields{"method": "GET", "status": 200, "latency": 0.042}).PrintLog()
+		}
+	})
+	b.Run("zap", func(b *testing.B) {
+		lg := newZap(true)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Info(message, zap.String("method", "GET"), zap.Int("status", 200), zap.Float64("latency", 0.042))
+		}
+	})
+	b.Run("zerolog", func(b *testing.B) {
+		lg := zerolog.New(discard{}).With().Timestamp().Logger()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Info().Str("method", "GET").Int("status", 200).Float64("latency", 0.042).Msg(message)
+		}
+	})
+}
+
+// BenchmarkFile logs to a file, through the queue of tolog and directly for
+// the others, which write synchronously.
+func BenchmarkFile(b *testing.B) {
+	open := func(b *testing.B) *os.File {
+		f, err := os.CreateTemp(b.TempDir(), "bench-*.log")
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Cleanup(func() { f.Close() })
+		return f
+	}
+	b.Run("tolog", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tolog.Info(message).WithField("status", 200).WriteSafe()
+		}
+	})
+	b.Run("stdlog", func(b *testing.B) {
+		lg := log.New(open(b), "", log.LstdFlags)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Printf("%s status=%d", message, 200)
+		}
+	})
+	b.Run("zap", func(b *testing.B) {
+		lg := zap.New(zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(open(b)), zapcore.DebugLevel))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Info(message, zap.Int("status", 200))
+		}
+	})
+	b.Run("zerolog", func(b *testing.B) {
+		lg := zerolog.New(open(b)).With().Timestamp().Logger()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			lg.Info().Int("status", 200).Msg(message)
+		}
+	})
+}
+
+// BenchmarkParallel logs from all processors at once.
+func BenchmarkParallel(b *testing.B) {
+	b.Run("tolog", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				tolog.Info(message).WithField("status", 200).PrintLog()
+			}
+		})
+	})
+	b.Run("stdlog", func(b *testing.B) {
+		lg := log.New(discard{}, "", log.LstdFlags)
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				lg.Printf("%s status=%d", message, 200)
+			}
+		})
+	})
+	b.Run("zap", func(b *testing.B) {
+		lg := newZap(false)
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				lg.Info(message, zap.Int("status", 200))
+			}
+		})
+	})
+	b.Run("zerolog", func(b *testing.B) {
+		lg := zerolog.New(zerolog.ConsoleWriter{Out: discard{}, NoColor: true}).With().Timestamp().Logger()
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				lg.Info().Int("status", 200).Msg(message)
+			}
+		})
+	})
+}
+
+// BenchmarkQueue writes to a file from all processors at once through the
+// queues of tolog, to compare the channel with the ring buffers.
+func BenchmarkQueue(b *testing.B) {
+	for _, q := range []struct {
+		name string
+		mode tolog.QueueMode
+	}{{"channel", tolog.QueueChannel}, {"ring", tolog.QueueRing}, {"sharded", tolog.QueueSharded}} {
+		b.Run(q.name, func(b *testing.B) {
+			tolog.SetQueueMode(q.mode)
+			defer tolog.SetQueueMode(tolog.QueueChannel)
+			lg := tolog.NewLogger("BenchmarkQueue-" + q.name)
+			defer lg.Close()
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					lg.Info(message).WithField("status", 200).WriteSafe()
+				}
+			})
+		})
+	}
+}
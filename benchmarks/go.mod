@@ -0,0 +1,23 @@
+module github.com/callme-taota/tolog/benchmarks
+
+go 1.22.0
+
+require (
+	github.com/callme-taota/tolog v0.0.0
+	github.com/rs/zerolog v1.34.0
+	go.uber.org/zap v1.27.1
+)
+
+require (
+	github.com/Microsoft/go-winio v0.6.1 // indirect
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/mod v0.8.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/term v0.10.0 // indirect
+	golang.org/x/tools v0.6.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/callme-taota/tolog => ../
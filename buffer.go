@@ -0,0 +1,32 @@
+package tolog
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which a buffer is dropped instead of
+// returned to the pool, so a burst of large entries doesn't stay in memory.
+const maxPooledBuffer = 64 << 10
+
+// newline ends every line of the log files.
+var newline = []byte{'\n'}
+
+// buffers holds the buffers lines and batches are encoded into, reused
+// across entries to keep the hot path free of allocations.
+var buffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf := buffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns the buffer to the pool, its bytes must not be used after.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buffers.Put(buf)
+}
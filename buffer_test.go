@@ -0,0 +1,31 @@
+package tolog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLineWithoutColor(t *testing.T) {
+	lg := NewLogger("TestFileLineWithoutColor")
+	defer lg.Close()
+	lg.SetColor(true)
+
+	l := lg.Warning("disk \033[1m almost full").Fields(Fields{"free": 5, "mount": "/var"})
+	assert.Contains(t, l.String(), colorReset)
+	assert.Equal(t, "["+l.logTime+"] [warning]  disk \033[1m almost full free=5 mount=/var\n", fileLine(l))
+}
+
+func TestAppendTextAllocs(t *testing.T) {
+	l := Info("request handled").WithField("status", 200)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		appendText(buf, l, true)
+	})
+	// the sorted keys of the fields
+	assert.LessOrEqual(t, allocs, 1.0)
+	assert.True(t, strings.HasSuffix(buf.String(), " request handled status=200"), buf.String())
+}
@@ -0,0 +1,218 @@
+package tolog
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bundleMagic starts the encrypted bundles of ExportBundle, followed by the
+// nonce and the sealed bundle.
+const bundleMagic = "TLB1"
+
+// ErrBundleKey is returned by DecryptBundle when the bundle was encrypted with
+// another key or was altered.
+var ErrBundleKey = errors.New("tolog: bundle not encrypted with this key or altered")
+
+// BundleOptions configures ExportBundle.
+type BundleOptions struct {
+	// Dir is the directory of the log files, the log directory if empty.
+	Dir string
+	// Since and Until bound the times of the entries bundled, unbounded if zero.
+	Since, Until time.Time
+	// Key encrypts the bundle with AES-GCM when set, a key of 16, 24 or 32
+	// bytes, see DecryptBundle.
+	Key []byte
+}
+
+// ExportBundle writes the entries of a time range of the log files, with the
+// stats of the session and the self-test of the files, to w as a single
+// gzip compressed tar file, to send logs to support from machines without
+// network access. It returns the number of entries bundled.
+//
+// The bundle holds logs/NAME, the lines of each log file in the range, gzip
+// compressed files decompressed, stats.json, the GetStats of the process, and
+// selftest.json, the VerifyReport of each bundled file by name. Lines without
+// a time, like file markers and stack traces, go with the entry before them.
+// The queued entries are flushed first. An encrypted bundle is held in memory
+// until written.
+func ExportBundle(w io.Writer, opts BundleOptions) (int, error) {
+	if len(opts.Key) > 0 {
+		gcm, err := bundleCipher(opts.Key)
+		if err != nil {
+			return 0, err
+		}
+		var buf bytes.Buffer
+		n, err := ExportBundle(&buf, BundleOptions{Dir: opts.Dir, Since: opts.Since, Until: opts.Until})
+		if err != nil {
+			return n, err
+		}
+		sealed, err := sealBundle(gcm, buf.Bytes())
+		if err != nil {
+			return n, err
+		}
+		_, err = w.Write(sealed)
+		return n, err
+	}
+	if opts.Dir == "" {
+		opts.Dir = logDirectory()
+	}
+	Flush()
+	names, err := bundleFiles(opts.Dir, opts.Since)
+	if err != nil {
+		return 0, err
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	add := func(name string, data []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: now}); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	total := 0
+	reports := map[string]VerifyReport{}
+	for _, name := range names {
+		path := filepath.Join(opts.Dir, name)
+		data, n, err := bundleLines(path, opts.Since, opts.Until)
+		if err != nil {
+			return total, err
+		}
+		if n == 0 {
+			continue
+		}
+		total += n
+		name = strings.TrimSuffix(name, ".gz")
+		if err := add("logs/"+name, data); err != nil {
+			return total, err
+		}
+		if reports[name], err = VerifyFile(path, VerifyOptions{}); err != nil {
+			return total, err
+		}
+	}
+	for name, v := range map[string]any{"stats.json": GetStats(), "selftest.json": reports} {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return total, err
+		}
+		if err := add(name, append(data, '\n')); err != nil {
+			return total, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return total, err
+	}
+	return total, gz.Close()
+}
+
+// bundleFiles returns the names of the log files of the directory, plain or
+// gzip compressed, modified since the time, sorted.
+func bundleFiles(dir string, since time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !(strings.HasSuffix(name, ".log") || strings.HasSuffix(name, ".log.gz")) {
+			continue
+		}
+		if info, err := e.Info(); err != nil || info.ModTime().Before(since) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// bundleLines returns the lines of the log file in the time range and the
+// number of entries among them.
+func bundleLines(path string, since, until time.Time) ([]byte, int, error) {
+	r, err := readLogFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer r.Close()
+	var out bytes.Buffer
+	n := 0
+	keep := false
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			text := strings.TrimRight(line, "\r\n")
+			if e, perr := ParseLine(text); perr == nil && e.Level != "" && !e.Time.IsZero() {
+				keep = !e.Time.Before(since) && (until.IsZero() || e.Time.Before(until))
+				if keep {
+					n++
+				}
+			}
+			if keep {
+				out.WriteString(line)
+			}
+		}
+		if err == io.EOF {
+			return out.Bytes(), n, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+// sealBundle encrypts the bundle with AES-GCM under a random nonce.
+func sealBundle(gcm cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte(bundleMagic), nonce...)
+	return gcm.Seal(out, nonce, data, []byte(bundleMagic)), nil
+}
+
+// DecryptBundle reads a bundle of ExportBundle encrypted with the key,
+// returning the gzip compressed tar file. It returns ErrBundleKey when the key
+// is not the one of the bundle.
+func DecryptBundle(r io.Reader, key []byte) (io.Reader, error) {
+	gcm, err := bundleCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte(bundleMagic)) || len(data) < len(bundleMagic)+gcm.NonceSize() {
+		return nil, errors.New("tolog: not an encrypted bundle")
+	}
+	data = data[len(bundleMagic):]
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(bundleMagic))
+	if err != nil {
+		return nil, ErrBundleKey
+	}
+	return bytes.NewReader(plain), nil
+}
+
+func bundleCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,80 @@
+package tolog
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportBundle(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app-log-2024-01-02.log"), []byte(
+		`{"time":"2024-01-02T09:00:00Z","level":"info","msg":"early"}`+"\n"+
+			`{"time":"2024-01-02T10:00:00Z","level":"error","msg":"failed"}`+"\n"+
+			"\tat main.go:12\n"+
+			`{"time":"2024-01-02T11:00:00Z","level":"info","msg":"late"}`+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app-log-2024-01-01.log"), []byte(
+		`{"time":"2024-01-01T10:00:00Z","level":"info","msg":"yesterday"}`+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a log\n"), 0644))
+
+	opts := BundleOptions{
+		Dir:   dir,
+		Since: time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC),
+		Until: time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC),
+	}
+	var out bytes.Buffer
+	n, err := ExportBundle(&out, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	files := readBundle(t, &out)
+	assert.Equal(t, `{"time":"2024-01-02T10:00:00Z","level":"error","msg":"failed"}`+"\n\tat main.go:12\n", files["logs/app-log-2024-01-02.log"])
+	assert.NotContains(t, files, "logs/app-log-2024-01-01.log")
+	var stats Stats
+	require.NoError(t, json.Unmarshal([]byte(files["stats.json"]), &stats))
+	var reports map[string]VerifyReport
+	require.NoError(t, json.Unmarshal([]byte(files["selftest.json"]), &reports))
+	assert.Equal(t, 4, reports["app-log-2024-01-02.log"].Lines)
+	assert.Len(t, reports, 1)
+
+	opts.Key = bytes.Repeat([]byte{7}, 32)
+	out.Reset()
+	n, err = ExportBundle(&out, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = DecryptBundle(bytes.NewReader(out.Bytes()), bytes.Repeat([]byte{8}, 32))
+	assert.ErrorIs(t, err, ErrBundleKey)
+	r, err := DecryptBundle(bytes.NewReader(out.Bytes()), opts.Key)
+	require.NoError(t, err)
+	assert.Contains(t, readBundle(t, r)["logs/app-log-2024-01-02.log"], "failed")
+
+	_, err = ExportBundle(io.Discard, BundleOptions{Dir: dir, Key: []byte("short")})
+	assert.Error(t, err)
+}
+
+// readBundle returns the files of a bundle by name.
+func readBundle(t *testing.T, r io.Reader) map[string]string {
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[h.Name] = string(data)
+	}
+}
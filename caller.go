@@ -0,0 +1,117 @@
+package tolog
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// The fields the source location of an entry is written in.
+const (
+	CallerField   = "caller"
+	FunctionField = "function"
+)
+
+var reportCaller int32
+
+// trimPrefixes are removed from the caller files, set with SetTrimPath.
+var trimPrefixes atomic.Value // []string
+
+// SetReportCaller enables capturing the file, line and function each entry is
+// emitted from, written in the caller and function fields. Capturing the
+// caller costs a stack walk per entry, it is disabled by default.
+func SetReportCaller(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&reportCaller, v)
+}
+
+// Caller captures the source location skip frames above the caller of Caller,
+// for entries emitted from helpers. 0 is the function calling Caller.
+func (l *ToLog) Caller(skip int) *ToLog {
+	l = l.mutable()
+	l.setCaller(captureCaller(skip + 1))
+	CreateFullLog(l)
+	return l
+}
+
+// WithCaller sets the source location of the entry to the file and line, for
+// records imported or replayed from elsewhere to keep their origin. The caller
+// is reported whether or not SetReportCaller is enabled, without a function.
+func WithCaller(file string, line int) Options {
+	return func(l *ToLog) {
+		l.setCaller(&callerInfo{file: file, line: line})
+	}
+}
+
+// captureSite captures the source location skip frames above the caller of
+// captureSite, when reported or needed by the format and not captured yet.
+func (l *ToLog) captureSite(skip int) {
+	if l.caller != nil {
+		return
+	}
+	if atomic.LoadInt32(&reportCaller) == 1 {
+		l.setCaller(captureCaller(skip + 1))
+	} else if l.log().format() == FormatGCP || templateShowsCaller() {
+		l.caller = captureCaller(skip + 1)
+	}
+}
+
+// setCaller sets the source location of the entry and its fields.
+func (l *ToLog) setCaller(c *callerInfo) {
+	l.caller = c
+	if c == nil {
+		return
+	}
+	fields := Fields{CallerField: c.short()}
+	if c.function != "" {
+		fields[FunctionField] = c.function
+	}
+	l.fields = l.fields.merge(fields)
+}
+
+// SetTrimPath reports the callers with their file path relative to the
+// first matching prefix, like internal/billing/invoice.go:42 for the prefix
+// of the module root /home/ci/src/app/, so paths are stable across build
+// machines. Files in the module cache are reported from the module path, like
+// github.com/org/lib@v1.2.0/client.go. Without prefixes the caller is the
+// file with its directory, like billing/invoice.go:42, the default.
+func SetTrimPath(prefixes ...string) {
+	trimPrefixes.Store(append([]string(nil), prefixes...))
+}
+
+// short returns the file of the location and the line, trimmed with SetTrimPath
+// or with its directory, like tolog/tolog.go:42.
+func (c *callerInfo) short() string {
+	if prefixes, _ := trimPrefixes.Load().([]string); len(prefixes) > 0 {
+		return trimPath(c.file, prefixes) + ":" + strconv.Itoa(c.line)
+	}
+	dir, file := filepath.Split(c.file)
+	return filepath.Join(filepath.Base(dir), file) + ":" + strconv.Itoa(c.line)
+}
+
+// path returns the file of the location, trimmed with SetTrimPath.
+func (c *callerInfo) path() string {
+	if prefixes, _ := trimPrefixes.Load().([]string); len(prefixes) > 0 {
+		return trimPath(c.file, prefixes)
+	}
+	return c.file
+}
+
+// trimPath removes the first matching prefix or the module cache directory from the file.
+func trimPath(file string, prefixes []string) string {
+	file = filepath.ToSlash(file)
+	for _, p := range prefixes {
+		p = strings.TrimSuffix(filepath.ToSlash(p), "/") + "/"
+		if rest, ok := strings.CutPrefix(file, p); ok {
+			return rest
+		}
+	}
+	if _, rest, ok := strings.Cut(file, "/pkg/mod/"); ok {
+		return rest
+	}
+	return file
+}
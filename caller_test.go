@@ -0,0 +1,67 @@
+package tolog
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func logFromHelper(msg string) *ToLog {
+	return Info(msg).Caller(1)
+}
+
+func TestReportCaller(t *testing.T) {
+	lg := NewLogger("TestReportCaller")
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+
+	l := lg.Info("without caller").PrintLog()
+	assert.NotContains(t, l.fields, CallerField)
+
+	SetReportCaller(true)
+	defer SetReportCaller(false)
+	l = lg.Info("with caller").PrintLog()
+	assert.Regexp(t, `^\w+/caller_test\.go:\d+$`, l.fields[CallerField])
+	assert.Equal(t, "github.com/callme-taota/tolog.TestReportCaller", l.fields[FunctionField])
+	assert.Contains(t, l.FullLog, "/caller_test.go:")
+}
+
+func TestCaller(t *testing.T) {
+	l := logFromHelper("from helper")
+	assert.Equal(t, "github.com/callme-taota/tolog.TestCaller", l.fields[FunctionField])
+}
+
+func TestWithCaller(t *testing.T) {
+	l := Log(WithCaller("/src/importer/events.go", 17), WithContext("imported"))
+	l.PrintLog()
+	assert.Equal(t, "importer/events.go:17", l.fields[CallerField])
+	assert.NotContains(t, l.fields, FunctionField)
+
+	SetReportCaller(true)
+	defer SetReportCaller(false)
+	l = Log(WithCaller("/src/importer/events.go", 17)).PrintLog()
+	assert.Equal(t, "importer/events.go:17", l.fields[CallerField])
+}
+
+func TestTrimPath(t *testing.T) {
+	defer SetTrimPath()
+	c := &callerInfo{file: "/home/ci/src/app/internal/billing/invoice.go", line: 42}
+	assert.Equal(t, "billing/invoice.go:42", c.short())
+
+	SetTrimPath("/opt/other", "/home/ci/src/ap", "/home/ci/src/app/")
+	assert.Equal(t, "internal/billing/invoice.go:42", c.short())
+	assert.Equal(t, "internal/billing/invoice.go", c.path())
+	lib := &callerInfo{file: "/root/go/pkg/mod/github.com/org/lib@v1.2.0/client.go", line: 7}
+	assert.Equal(t, "github.com/org/lib@v1.2.0/client.go:7", lib.short())
+	other := &callerInfo{file: "/usr/local/go/src/net/http/server.go", line: 1}
+	assert.Equal(t, "/usr/local/go/src/net/http/server.go:1", other.short())
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	SetTrimPath(wd)
+	l := std.Info("x").Caller(0)
+	assert.Regexp(t, `^caller_test.go:\d+$`, l.fields[CallerField])
+}
@@ -0,0 +1,104 @@
+package tolog
+
+import (
+	"context"
+	"sync"
+)
+
+// CanonicalLine accumulates the fields of a request while it is handled, to be
+// emitted as exactly one rich summary entry at its end, a canonical log line.
+// It is safe for concurrent use, and its methods do nothing on a nil line.
+type CanonicalLine struct {
+	mu      sync.Mutex
+	level   LogStatus
+	fields  Fields
+	emitted bool
+}
+
+type canonicalKey struct{}
+
+// WithCanonicalLine returns a copy of the context carrying a new canonical line.
+func WithCanonicalLine(ctx context.Context) (context.Context, *CanonicalLine) {
+	c := &CanonicalLine{level: StatusInfo}
+	return context.WithValue(ctx, canonicalKey{}, c), c
+}
+
+// CanonicalFromContext returns the canonical line of the context, nil if none.
+func CanonicalFromContext(ctx context.Context) *CanonicalLine {
+	c, _ := ctx.Value(canonicalKey{}).(*CanonicalLine)
+	return c
+}
+
+// AddCanonicalField adds a field to the canonical line of the context, if any.
+func AddCanonicalField(ctx context.Context, key string, value any) {
+	CanonicalFromContext(ctx).Add(key, value)
+}
+
+// Add adds a field to the line, overriding an existing key.
+func (c *CanonicalLine) Add(key string, value any) {
+	c.AddFields(Fields{key: value})
+}
+
+// AddFields adds the fields to the line, overriding existing keys.
+func (c *CanonicalLine) AddFields(fields Fields) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fields = c.fields.merge(fields)
+}
+
+// Escalate raises the level of the line to the level if it is more severe.
+func (c *CanonicalLine) Escalate(level LogStatus) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if severity(level) > severity(c.level) {
+		c.level = level
+	}
+}
+
+// Fields returns a copy of the fields accumulated so far.
+func (c *CanonicalLine) Fields() Fields {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Fields(nil).merge(c.fields)
+}
+
+// Emit writes the line once with the logger, or the default logger if nil,
+// with the fields of the context. Later calls do nothing and return false.
+func (c *CanonicalLine) Emit(ctx context.Context, lg *Logger, msg string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	if c.emitted {
+		c.mu.Unlock()
+		return false
+	}
+	c.emitted = true
+	fields := Fields(nil).merge(c.fields)
+	level := c.level
+	c.mu.Unlock()
+	if lg == nil {
+		lg = std
+	}
+	lg.Log(WithType(level), WithFields(fields)).Ctx(ctx).Msg(msg)
+	return true
+}
+
+// Level returns the level the line is written with.
+func (c *CanonicalLine) Level() LogStatus {
+	if c == nil {
+		return StatusInfo
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.level
+}
@@ -0,0 +1,41 @@
+package tolog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalLine(t *testing.T) {
+	lg := NewLogger("TestCanonicalLine")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	ctx, line := WithCanonicalLine(context.Background())
+	assert.Same(t, line, CanonicalFromContext(ctx))
+	AddCanonicalField(ctx, "user", "alice")
+	line.AddFields(Fields{"items": 3, "user": "bob"})
+	line.Escalate(StatusError)
+	line.Escalate(StatusNotice)
+	assert.Equal(t, StatusError, line.Level())
+	assert.Equal(t, Fields{"items": 3, "user": "bob"}, line.Fields())
+
+	assert.True(t, line.Emit(ctx, lg, "checkout"))
+	assert.False(t, line.Emit(ctx, lg, "checkout"))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], " [error] ")
+	assert.Contains(t, lines[0], "items=3")
+	assert.Contains(t, lines[0], "user=bob")
+
+	// Without a line in the context everything is a no-op.
+	AddCanonicalField(context.Background(), "user", "alice")
+	assert.Nil(t, CanonicalFromContext(context.Background()))
+	assert.False(t, CanonicalFromContext(context.Background()).Emit(ctx, lg, "none"))
+}
@@ -0,0 +1,144 @@
+package tolog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrCaptured is returned when capturing a stream already captured.
+var ErrCaptured = errors.New("tolog: stream already captured")
+
+// The original stdout and stderr while they are captured, the console and the
+// diagnostics write to them so that entries are not captured again.
+var (
+	savedStdout atomic.Value // *os.File
+	savedStderr atomic.Value // *os.File
+)
+
+// stdout returns the stdout of the process, the original one while captured.
+func stdout() *os.File {
+	if f, _ := savedStdout.Load().(*os.File); f != nil {
+		return f
+	}
+	return os.Stdout
+}
+
+// stderr returns the stderr of the process, the original one while captured.
+func stderr() *os.File {
+	if f, _ := savedStderr.Load().(*os.File); f != nil {
+		return f
+	}
+	return os.Stderr
+}
+
+// realStream returns the console writer, stdout if nil, with the standard
+// streams replaced by the original ones while captured.
+func realStream(w io.Writer) io.Writer {
+	switch w {
+	case nil, os.Stdout:
+		return stdout()
+	case os.Stderr:
+		return stderr()
+	}
+	return w
+}
+
+// Capture is a standard stream of the process redirected into the log.
+type Capture struct {
+	stream string
+	fd     int
+	saved  *atomic.Value
+	orig   *os.File
+	pipe   *os.File
+	done   chan struct{}
+	once   sync.Once
+	err    error
+}
+
+// CaptureStdout redirects the stdout of the process into the default logger,
+// see Logger.CaptureStdout.
+func CaptureStdout() (*Capture, error) {
+	return std.CaptureStdout()
+}
+
+// CaptureStderr redirects the stderr of the process into the default logger,
+// see Logger.CaptureStderr.
+func CaptureStderr() (*Capture, error) {
+	return std.CaptureStderr()
+}
+
+// CaptureStdout redirects the file descriptor of stdout to the logger, so the
+// prints of fmt, of other packages and of C libraries become entries of level
+// unknown with the stream=stdout field, printed and written to the log file.
+// The console keeps printing to the original stdout. Only supported on Unix.
+func (lg *Logger) CaptureStdout() (*Capture, error) {
+	return lg.capture("stdout", 1, &savedStdout)
+}
+
+// CaptureStderr redirects the file descriptor of stderr to the logger like
+// CaptureStdout, e.g. for the warnings of C libraries and runtime messages.
+func (lg *Logger) CaptureStderr() (*Capture, error) {
+	return lg.capture("stderr", 2, &savedStderr)
+}
+
+func (lg *Logger) capture(stream string, fd int, saved *atomic.Value) (*Capture, error) {
+	if f, _ := saved.Load().(*os.File); f != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCaptured, stream)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	orig, err := dupFD(fd, "/dev/"+stream)
+	if err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	saved.Store(orig)
+	if err := redirectFD(w, fd); err != nil {
+		saved.Store((*os.File)(nil))
+		orig.Close()
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	// the descriptor now refers to the pipe, the copy is not needed
+	w.Close()
+	c := &Capture{stream: stream, fd: fd, saved: saved, orig: orig, pipe: r, done: make(chan struct{})}
+	go c.read(lg)
+	diag("capture", "capturing %s", stream)
+	return c, nil
+}
+
+// read logs the lines of the stream until it is restored.
+func (c *Capture) read(lg *Logger) {
+	defer close(c.done)
+	w := &entryWriter{logger: lg, level: StatusUnknown, fields: Fields{"stream": c.stream}}
+	scanner := bufio.NewScanner(c.pipe)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		w.Write(scanner.Bytes())
+	}
+	if err := scanner.Err(); err != nil {
+		handleError(fmt.Errorf("tolog: capturing %s: %w", c.stream, err))
+	}
+}
+
+// Stop restores the stream and waits until the lines written before are logged.
+func (c *Capture) Stop() error {
+	c.once.Do(func() {
+		c.err = redirectFD(c.orig, c.fd)
+		c.saved.Store((*os.File)(nil))
+		<-c.done
+		c.pipe.Close()
+		c.orig.Close()
+		diag("capture", "stopped capturing %s", c.stream)
+	})
+	return c.err
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package tolog
+
+import (
+	"errors"
+	"os"
+)
+
+var errNoCapture = errors.New("tolog: capturing standard streams is only supported on Unix")
+
+func dupFD(fd int, name string) (*os.File, error) {
+	return nil, errNoCapture
+}
+
+func redirectFD(f *os.File, fd int) error {
+	return errNoCapture
+}
@@ -0,0 +1,44 @@
+//go:build unix
+
+package tolog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureStderr(t *testing.T) {
+	lg := NewLogger("TestCaptureStderr")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+	var console bytes.Buffer
+	lg.SetConsole(&console)
+
+	c, err := lg.CaptureStderr()
+	require.NoError(t, err)
+	_, err = lg.CaptureStderr()
+	assert.ErrorIs(t, err, ErrCaptured)
+	fmt.Fprintln(os.Stderr, "legacy print")
+	syscall.Write(2, []byte("from C\n"))
+	require.NoError(t, c.Stop())
+	require.NoError(t, c.Stop())
+	require.NoError(t, lg.Flush())
+
+	assert.Contains(t, out.String(), "legacy print")
+	assert.Contains(t, out.String(), "from C")
+	assert.Contains(t, out.String(), "unknown")
+	assert.Contains(t, out.String(), "stream=stderr")
+	assert.Contains(t, console.String(), "from C")
+	content, err := os.ReadFile(lg.FilePath())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "legacy print")
+	assert.Equal(t, os.Stderr, stderr())
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package tolog
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// dupFD returns a file of a copy of the descriptor.
+func dupFD(fd int, name string) (*os.File, error) {
+	dup, err := unix.Dup(fd)
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(dup), name), nil
+}
+
+// redirectFD makes the descriptor refer to the file.
+func redirectFD(f *os.File, fd int) error {
+	return unix.Dup2(int(f.Fd()), fd)
+}
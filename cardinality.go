@@ -0,0 +1,131 @@
+package tolog
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// CardinalityAction is what a cardinality guard does with the values of a
+// field beyond its limit.
+type CardinalityAction int
+
+const (
+	// CardinalityHash replaces the new values with one of HashBuckets hashes,
+	// like #1f, keeping the entries of a value together.
+	CardinalityHash CardinalityAction = iota
+	// CardinalityDrop removes the field from the entries of the new values.
+	CardinalityDrop
+)
+
+// CardinalityOptions configures CardinalityGuard.
+type CardinalityOptions struct {
+	// Fields are the fields guarded, all of them if empty. Fields of groups
+	// are left as they are.
+	Fields []string
+	// MaxValues is the number of distinct values of a field passed as they
+	// are, 100 by default.
+	MaxValues int
+	// Action is what is done with the further values.
+	Action CardinalityAction
+	// HashBuckets is the number of hashes of CardinalityHash, 64 by default.
+	HashBuckets int
+	// Per forgets the values seen every period, never if zero.
+	Per time.Duration
+}
+
+// CardinalityGuard returns the sink receiving the entries with the values of
+// their fields limited, for sinks turning fields into labels, like Loki or
+// Prometheus, where every distinct value is a new stream: once a field had
+// MaxValues distinct values, the new ones are hashed or dropped. The entries
+// are copied, other sinks see the values as they are.
+func CardinalityGuard(sink Sink, opts CardinalityOptions) Sink {
+	if opts.MaxValues <= 0 {
+		opts.MaxValues = 100
+	}
+	if opts.HashBuckets <= 0 {
+		opts.HashBuckets = 64
+	}
+	g := &cardinalitySink{Sink: sink, opts: opts, values: map[string]map[string]bool{}, capped: map[string]bool{}, since: time.Now()}
+	if len(opts.Fields) > 0 {
+		g.guarded = make(map[string]bool, len(opts.Fields))
+		for _, f := range opts.Fields {
+			g.guarded[f] = true
+		}
+	}
+	return g
+}
+
+// cardinalitySink is a sink whose fields have a bounded number of values.
+type cardinalitySink struct {
+	Sink
+	opts    CardinalityOptions
+	guarded map[string]bool // nil guards all the fields
+
+	mu     sync.Mutex
+	values map[string]map[string]bool // the values passed per field
+	capped map[string]bool            // the fields beyond their limit
+	since  time.Time
+}
+
+func (s *cardinalitySink) WriteEntry(e *Entry) error {
+	var fields Fields
+	s.mu.Lock()
+	if s.opts.Per > 0 && time.Since(s.since) >= s.opts.Per {
+		s.values = map[string]map[string]bool{}
+		s.capped = map[string]bool{}
+		s.since = time.Now()
+	}
+	for k, v := range e.Fields {
+		if _, group := v.(Fields); group || (s.guarded != nil && !s.guarded[k]) {
+			continue
+		}
+		value := fmt.Sprint(v)
+		seen := s.values[k]
+		if seen == nil {
+			seen = map[string]bool{}
+			s.values[k] = seen
+		}
+		if seen[value] {
+			continue
+		}
+		if len(seen) < s.opts.MaxValues {
+			seen[value] = true
+			continue
+		}
+		if !s.capped[k] {
+			s.capped[k] = true
+			diag("cardinality", "field %s has more than %d values", k, s.opts.MaxValues)
+		}
+		if fields == nil {
+			fields = make(Fields, len(e.Fields))
+			for k, v := range e.Fields {
+				fields[k] = v
+			}
+		}
+		if s.opts.Action == CardinalityDrop {
+			delete(fields, k)
+		} else {
+			fields[k] = s.hash(value)
+		}
+	}
+	s.mu.Unlock()
+	if fields == nil {
+		return s.Sink.WriteEntry(e)
+	}
+	guarded := *e
+	guarded.Fields = fields
+	return s.Sink.WriteEntry(&guarded)
+}
+
+// hash returns the bucket of the value.
+func (s *cardinalitySink) hash(value string) string {
+	h := fnv.New32a()
+	h.Write([]byte(value))
+	return fmt.Sprintf("#%x", h.Sum32()%uint32(s.opts.HashBuckets))
+}
+
+func (s *cardinalitySink) Enabled(level LogStatus) bool {
+	return sinkEnabled(s.Sink, level)
+}
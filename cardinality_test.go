@@ -0,0 +1,36 @@
+package tolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardinalityGuard(t *testing.T) {
+	sink := &memorySink{}
+	g := CardinalityGuard(sink, CardinalityOptions{Fields: []string{"user_id"}, MaxValues: 2, HashBuckets: 4})
+	for _, id := range []int{1, 2, 1, 3, 4} {
+		e := &Entry{Time: time.Now(), Level: StatusInfo, Message: "login", Fields: Fields{"user_id": id, "path": id}}
+		assert.NoError(t, g.WriteEntry(e))
+		assert.Equal(t, id, e.Fields["user_id"], "the entry of the other sinks")
+	}
+	var ids, paths []any
+	for _, e := range sink.entries {
+		ids = append(ids, e.Fields["user_id"])
+		paths = append(paths, e.Fields["path"])
+	}
+	assert.Equal(t, []any{1, 2, 1}, ids[:3])
+	assert.Regexp(t, `^#[0-3]$`, ids[3])
+	assert.Regexp(t, `^#[0-3]$`, ids[4])
+	assert.Equal(t, []any{1, 2, 1, 3, 4}, paths, "not guarded")
+
+	sink = &memorySink{}
+	g = CardinalityGuard(sink, CardinalityOptions{MaxValues: 1, Action: CardinalityDrop, Per: time.Hour})
+	g.WriteEntry(&Entry{Level: StatusInfo, Fields: Fields{"a": "x", "b": "y"}})
+	g.WriteEntry(&Entry{Level: StatusInfo, Fields: Fields{"a": "x", "b": "z"}})
+	assert.Equal(t, Fields{"a": "x"}, sink.entries[1].Fields)
+
+	g = CardinalityGuard(SinkLevel(sink, StatusError), CardinalityOptions{})
+	assert.False(t, sinkEnabled(g, StatusInfo))
+}
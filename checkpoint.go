@@ -0,0 +1,79 @@
+package tolog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Checkpoint is the position of a Follower, persisted so a restarted follower
+// resumes where it left off. The file is identified by device and inode where
+// the platform has them, so a rotated or renamed file is still found.
+type Checkpoint struct {
+	Path   string `json:"path"`
+	Device uint64 `json:"device,omitempty"`
+	Inode  uint64 `json:"inode,omitempty"`
+	Offset int64  `json:"offset"`
+}
+
+// Checkpoint returns the current position of the follower.
+func (f *Follower) Checkpoint() Checkpoint {
+	dev, ino := fileID(f.info)
+	return Checkpoint{Path: f.path, Device: dev, Inode: ino, Offset: f.offset}
+}
+
+// SaveCheckpoint writes the checkpoint to the file atomically.
+func SaveCheckpoint(file string, cp Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// LoadCheckpoint reads a checkpoint written by SaveCheckpoint.
+func LoadCheckpoint(file string) (Checkpoint, error) {
+	var cp Checkpoint
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return cp, err
+	}
+	err = json.Unmarshal(data, &cp)
+	return cp, err
+}
+
+// Resume creates a follower continuing from the checkpoint. If the file was
+// renamed within its directory it is followed under its new name, if it was
+// replaced by another file, the new file is followed from its start.
+func Resume(cp Checkpoint) (*Follower, error) {
+	if cp.Inode == 0 {
+		return Follow(cp.Path, cp.Offset)
+	}
+	if info, err := os.Stat(cp.Path); err == nil && sameFileID(info, cp) {
+		return Follow(cp.Path, cp.Offset)
+	}
+	entries, err := os.ReadDir(filepath.Dir(cp.Path))
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && sameFileID(info, cp) {
+			return Follow(filepath.Join(filepath.Dir(cp.Path), entry.Name()), cp.Offset)
+		}
+	}
+	if _, err := os.Stat(cp.Path); err != nil {
+		return nil, fmt.Errorf("resume checkpoint: %w", err)
+	}
+	return Follow(cp.Path, 0)
+}
+
+func sameFileID(info os.FileInfo, cp Checkpoint) bool {
+	dev, ino := fileID(info)
+	return ino != 0 && dev == cp.Device && ino == cp.Inode
+}
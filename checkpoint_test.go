@@ -0,0 +1,47 @@
+package tolog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app-log-2024-01-01.log")
+	cpFile := filepath.Join(dir, "follower.checkpoint")
+	require.NoError(t, os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	f, err := Follow(path, 0)
+	require.NoError(t, err)
+	_, err = f.NextLine(ctx)
+	require.NoError(t, err)
+	require.NoError(t, SaveCheckpoint(cpFile, f.Checkpoint()))
+	require.NoError(t, f.Close())
+
+	cp, err := LoadCheckpoint(cpFile)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), cp.Offset)
+
+	renamed := filepath.Join(dir, "app-log-2024-01-01.log.1")
+	require.NoError(t, os.Rename(path, renamed))
+	require.NoError(t, os.WriteFile(path, []byte("replacement\n"), 0644))
+
+	f, err = Resume(cp)
+	require.NoError(t, err)
+	defer f.Close()
+	if cp.Inode != 0 {
+		assert.Equal(t, renamed, f.Path())
+		line, err := f.NextLine(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "two", line)
+	}
+}
@@ -0,0 +1,43 @@
+package tolog
+
+import "context"
+
+// LoggerField is the field the name of a named logger is written in.
+const LoggerField = "logger"
+
+// Named returns a child logger whose entries have the name in the logger
+// field, appended to the name of lg with a dot, like payments.stripe. It
+// shares the file, outputs, routes and settings of lg and needs no Close.
+func (lg *Logger) Named(name string) *Logger {
+	child := lg.derive(lg.ctx)
+	if child.name != "" && name != "" {
+		name = child.name + "." + name
+	}
+	if name != "" {
+		child.name = name
+	}
+	return child
+}
+
+// With returns a child logger adding the fields to all of its entries, in
+// addition to those of lg. Fields of the entries themselves take precedence.
+// It shares the file, outputs, routes and settings of lg and needs no Close.
+func (lg *Logger) With(fields Fields) *Logger {
+	child := lg.derive(lg.ctx)
+	child.fields = Fields(nil).merge(lg.fields).merge(fields)
+	return child
+}
+
+// derive returns a logger writing like lg with its name and fields, bound to the context.
+func (lg *Logger) derive(ctx context.Context) *Logger {
+	root := lg.root()
+	return &Logger{prefix: root.Prefix(), parent: root, ctx: ctx, name: lg.name, fields: lg.fields}
+}
+
+// childFields returns the fields a child logger adds to its entries.
+func (lg *Logger) childFields() Fields {
+	if lg.name == "" {
+		return lg.fields
+	}
+	return Fields(nil).merge(lg.fields).merge(Fields{LoggerField: lg.name})
+}
@@ -0,0 +1,36 @@
+package tolog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChildLoggers(t *testing.T) {
+	lg := NewLogger("TestChildLoggers")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	payments := lg.Named("payments").With(Fields{"region": "eu", "tenant": "a"})
+	stripe := payments.Named("stripe").With(Fields{"tenant": "b"})
+	assert.Equal(t, lg.FilePath(), stripe.FilePath())
+	assert.Same(t, lg, stripe.root())
+
+	payments.Info("charged").WriteSafe()
+	stripe.Info("called").WithField("region", "us").WriteSafe()
+	lg.Info("plain").WriteSafe()
+	FromContext(NewContext(context.Background(), stripe)).Info("from context").WriteSafe()
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 4)
+	assert.Contains(t, string(lines[0]), "logger=payments region=eu tenant=a")
+	assert.Contains(t, string(lines[1]), "logger=payments.stripe region=us tenant=b")
+	assert.NotContains(t, string(lines[2]), "logger=")
+	assert.Contains(t, string(lines[3]), "logger=payments.stripe region=eu tenant=b")
+	assert.Equal(t, Fields{"region": "eu", "tenant": "a"}, payments.fields)
+}
@@ -0,0 +1,104 @@
+package tolog
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// cleanupInterval is how often the janitor of EnableCleanup scans the log directory.
+var cleanupInterval = time.Hour
+
+var (
+	cleanupMu   sync.Mutex
+	stopCleanup func()
+)
+
+// EnableCleanup starts a janitor scanning the log directory now and every hour,
+// removing the log files of every prefix older than maxAge, then the oldest
+// ones while all of them take more than maxTotalSize bytes, so the logs never
+// fill the disk. Zero disables a limit. The open log files are kept, and every
+// removal is logged. It replaces the janitor started before, and returns a
+// function to stop it.
+func EnableCleanup(maxTotalSize int64, maxAge time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var once sync.Once
+	stop = func() { once.Do(func() { close(done) }) }
+	cleanupMu.Lock()
+	if stopCleanup != nil {
+		stopCleanup()
+	}
+	stopCleanup = stop
+	cleanupMu.Unlock()
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+		for {
+			cleanLogDir(maxTotalSize, maxAge, time.Now())
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return stop
+}
+
+type logFileInfo struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// cleanLogDir removes the log files older than maxAge, then the oldest while
+// they take more than maxTotalSize, returning the paths removed.
+func cleanLogDir(maxTotalSize int64, maxAge time.Duration, now time.Time) []string {
+	fsys := logFS()
+	dir := logDirectory()
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		diag("cleanup", "reading %s failed: %v", dir, err)
+		return nil
+	}
+	open := make(map[string]bool)
+	for _, w := range openWriters() {
+		open[filepath.Clean(w.path())] = true
+	}
+	var files []logFileInfo
+	var total int64
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if _, ok := parseLegacyName(e.Name()); !ok || e.IsDir() || open[path] {
+			continue
+		}
+		info, err := fsys.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, logFileInfo{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	var removed []string
+	for _, f := range files {
+		reason := ""
+		switch {
+		case maxAge > 0 && now.Sub(f.modTime) > maxAge:
+			reason = "age"
+		case maxTotalSize > 0 && total > maxTotalSize:
+			reason = "size"
+		default:
+			continue
+		}
+		if err := fsys.Remove(f.path); err != nil {
+			diag("cleanup", "removing %s failed: %v", f.path, err)
+			continue
+		}
+		total -= f.size
+		removed = append(removed, f.path)
+		Notice("removed old log file").Fields(Fields{"path": f.path, "size": f.size, "reason": reason}).WriteSafe()
+	}
+	return removed
+}
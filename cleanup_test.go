@@ -0,0 +1,63 @@
+package tolog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanLogDir(t *testing.T) {
+	dir := t.TempDir()
+	SetLogDir(dir)
+	defer SetLogDir(testLogDir)
+	defer CloseLogFile()
+	now := time.Now()
+	files := []struct {
+		name string
+		age  time.Duration
+	}{
+		{"api-log-2024-01-01.log", 40 * 24 * time.Hour},
+		{"api-log-2024-01-20.log.gz", 20 * 24 * time.Hour},
+		{"log-2024-01-25.log", 15 * 24 * time.Hour},
+		{"log-2024-02-01.log", 8 * 24 * time.Hour},
+		{"notes.txt", 100 * 24 * time.Hour},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		require.NoError(t, os.WriteFile(path, make([]byte, 100), 0644))
+		require.NoError(t, os.Chtimes(path, now.Add(-f.age), now.Add(-f.age)))
+	}
+
+	removed := cleanLogDir(250, 30*24*time.Hour, now)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "api-log-2024-01-01.log"),
+		filepath.Join(dir, "api-log-2024-01-20.log.gz"),
+	}, removed, "the oldest by age, then by size until 200 bytes are left")
+	assert.FileExists(t, filepath.Join(dir, "log-2024-01-25.log"))
+	assert.FileExists(t, filepath.Join(dir, "log-2024-02-01.log"))
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+
+	assert.Empty(t, cleanLogDir(0, 0, now))
+}
+
+func TestEnableCleanup(t *testing.T) {
+	dir := t.TempDir()
+	SetLogDir(dir)
+	defer SetLogDir(testLogDir)
+	defer CloseLogFile()
+	path := filepath.Join(dir, "old-log-2024-01-01.log")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	stop := EnableCleanup(0, 24*time.Hour)
+	defer stop()
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return os.IsNotExist(err)
+	}, time.Second, 10*time.Millisecond)
+}
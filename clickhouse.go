@@ -0,0 +1,151 @@
+package tolog
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ClickHouseColumn maps a column of the ClickHouse table to a value of the entry.
+type ClickHouseColumn struct {
+	Name  string
+	Value func(e *Entry) any
+}
+
+// TimeColumn stores the entry time, for DateTime64 columns.
+func TimeColumn(name string) ClickHouseColumn {
+	return ClickHouseColumn{Name: name, Value: func(e *Entry) any { return e.Time }}
+}
+
+// LevelColumn stores the entry level.
+func LevelColumn(name string) ClickHouseColumn {
+	return ClickHouseColumn{Name: name, Value: func(e *Entry) any { return string(e.Level) }}
+}
+
+// MessageColumn stores the entry message.
+func MessageColumn(name string) ClickHouseColumn {
+	return ClickHouseColumn{Name: name, Value: func(e *Entry) any { return e.Message }}
+}
+
+// FieldsColumn stores all fields as a JSON object string.
+func FieldsColumn(name string) ClickHouseColumn {
+	return ClickHouseColumn{Name: name, Value: func(e *Entry) any { return fieldsJSON(e.Fields) }}
+}
+
+// FieldColumn stores a single field as a string, empty when the entry does not carry it.
+func FieldColumn(name, field string) ClickHouseColumn {
+	return ClickHouseColumn{Name: name, Value: func(e *Entry) any {
+		v, ok := e.Fields[field]
+		if !ok {
+			return ""
+		}
+		return toString(jsonValue(v))
+	}}
+}
+
+// ClickHouseOptions configures a ClickHouseSink.
+type ClickHouseOptions struct {
+	// Table is the table entries are inserted into, default "logs".
+	Table string
+	// Columns maps the table columns to entry values, default
+	// timestamp, level, message and fields.
+	Columns []ClickHouseColumn
+	// BatchSize and FlushInterval bound the rows per insert, default 1000 and 5s.
+	// ClickHouse prefers few large inserts over many small ones.
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// ClickHouseSink bulk inserts entries into ClickHouse through its database/sql driver.
+type ClickHouseSink struct {
+	db      *sql.DB
+	columns []ClickHouseColumn
+	insert  string
+	batcher *batcher
+}
+
+// NewClickHouseSink creates a sink inserting into ClickHouse. Add it with AddSink.
+// It pings the database and checks the table and column names, which are
+// written into the insert statement, are identifiers like db.logs.
+func NewClickHouseSink(db *sql.DB, opts ClickHouseOptions) (*ClickHouseSink, error) {
+	if db == nil {
+		return nil, fmt.Errorf("tolog: clickhouse sink: missing database")
+	}
+	if opts.Table == "" {
+		opts.Table = "logs"
+	}
+	if len(opts.Columns) == 0 {
+		opts.Columns = []ClickHouseColumn{TimeColumn("timestamp"), LevelColumn("level"), MessageColumn("message"), FieldsColumn("fields")}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if !isIdentifier(opts.Table, true) {
+		return nil, fmt.Errorf("tolog: clickhouse sink: invalid table name %q", opts.Table)
+	}
+	names := make([]string, len(opts.Columns))
+	for i, c := range opts.Columns {
+		if !isIdentifier(c.Name, false) || c.Value == nil {
+			return nil, fmt.Errorf("tolog: clickhouse sink: invalid column %q", c.Name)
+		}
+		names[i] = c.Name
+	}
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("tolog: clickhouse sink: %w", err)
+	}
+	s := &ClickHouseSink{
+		db:      db,
+		columns: opts.Columns,
+		insert:  "INSERT INTO " + opts.Table + " (" + strings.Join(names, ", ") + ")",
+	}
+	s.batcher = newBatcher(opts.BatchSize, opts.FlushInterval, s.write)
+	return s, nil
+}
+
+// isIdentifier reports whether the name is a plain SQL identifier, or two
+// joined by a dot if qualified is set.
+func isIdentifier(name string, qualified bool) bool {
+	parts := []string{name}
+	if qualified {
+		parts = strings.SplitN(name, ".", 2)
+	}
+	for _, part := range parts {
+		if part == "" || part[0] >= '0' && part[0] <= '9' {
+			return false
+		}
+		for _, r := range part {
+			if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// WriteEntry queues the entry for the next insert, dropping it with
+// ErrChannelFull if the queue is full.
+func (s *ClickHouseSink) WriteEntry(e *Entry) error {
+	return s.batcher.add(e)
+}
+
+// Close inserts the queued entries and stops the sink, the database is left open.
+func (s *ClickHouseSink) Close() error {
+	s.batcher.close()
+	return nil
+}
+
+// write inserts the batch as a single block, the driver sends the rows
+// executed within one transaction as one insert.
+func (s *ClickHouseSink) write(batch []*Entry) error {
+	return insertBatch(s.db, s.insert, batch, func(e *Entry) []any {
+		values := make([]any, len(s.columns))
+		for i, c := range s.columns {
+			values[i] = c.Value(e)
+		}
+		return values
+	})
+}
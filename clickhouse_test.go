@@ -0,0 +1,53 @@
+package tolog
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickHouseSink(t *testing.T) {
+	db, err := sql.Open("tolog-fake", t.Name())
+	require.NoError(t, err)
+	defer db.Close()
+	fake := fakeSQL.db(t.Name())
+
+	sink, err := NewClickHouseSink(db, ClickHouseOptions{
+		Table:   "events",
+		Columns: []ClickHouseColumn{TimeColumn("ts"), LevelColumn("severity"), MessageColumn("body"), FieldColumn("user", "user_id")},
+	})
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, sink.WriteEntry(&Entry{Time: now, Level: StatusInfo, Message: "login", Fields: Fields{"user_id": 7}}))
+	require.NoError(t, sink.WriteEntry(&Entry{Time: now, Level: StatusInfo, Message: "anonymous"}))
+	require.NoError(t, sink.Close())
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, []string{"INSERT INTO events (ts, severity, body, user)", "INSERT INTO events (ts, severity, body, user)"}, fake.execs)
+	assert.Equal(t, [][]driver.Value{{now, "info", "login", "7"}, {now, "info", "anonymous", ""}}, fake.rows)
+}
+
+func TestClickHouseSinkOptions(t *testing.T) {
+	db, err := sql.Open("tolog-fake", t.Name())
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = NewClickHouseSink(nil, ClickHouseOptions{})
+	assert.EqualError(t, err, "tolog: clickhouse sink: missing database")
+	_, err = NewClickHouseSink(db, ClickHouseOptions{Table: "logs; DROP TABLE users"})
+	assert.EqualError(t, err, `tolog: clickhouse sink: invalid table name "logs; DROP TABLE users"`)
+	_, err = NewClickHouseSink(db, ClickHouseOptions{Columns: []ClickHouseColumn{{Name: "ts"}}})
+	assert.EqualError(t, err, `tolog: clickhouse sink: invalid column "ts"`)
+	_, err = NewClickHouseSink(db, ClickHouseOptions{Columns: []ClickHouseColumn{LevelColumn("1level")}})
+	assert.Error(t, err)
+
+	sink, err := NewClickHouseSink(db, ClickHouseOptions{Table: "analytics.logs_2024"})
+	require.NoError(t, err)
+	assert.Equal(t, "INSERT INTO analytics.logs_2024 (timestamp, level, message, fields)", sink.insert)
+	require.NoError(t, sink.Close())
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/callme-taota/tolog"
+)
+
+// bundle packs a time range of the log files of a directory into a bundle for
+// support, or decrypts a bundle with -d.
+func bundle(args []string) error {
+	fs := newFlagSet("bundle")
+	dir := fs.String("dir", "./logs", "directory of the log files")
+	since := fs.String("since", "", "first time to bundle, RFC 3339 or a duration before now like 24h")
+	until := fs.String("until", "", "time to bundle up to, RFC 3339 or a duration before now")
+	keyFile := fs.String("key", "", "file holding the hex AES key encrypting the bundle, of 16, 24 or 32 bytes")
+	out := fs.String("o", "bundle.tar.gz", "bundle file to write")
+	decrypt := fs.Bool("d", false, "decrypt the bundle given as argument with -key into -o")
+	fs.Parse(args)
+
+	opts := tolog.BundleOptions{Dir: *dir}
+	var err error
+	if *keyFile != "" {
+		if opts.Key, err = readKey(*keyFile); err != nil {
+			return err
+		}
+	}
+	if *decrypt {
+		if fs.NArg() != 1 || opts.Key == nil {
+			return fmt.Errorf("give -key and one bundle to decrypt")
+		}
+		return writeFile(*out, func(w io.Writer) error {
+			in, err := os.Open(fs.Arg(0))
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+			r, err := tolog.DecryptBundle(in, opts.Key)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(w, r)
+			return err
+		})
+	}
+	if opts.Since, err = parseWhen(*since); err != nil {
+		return err
+	}
+	if opts.Until, err = parseWhen(*until); err != nil {
+		return err
+	}
+	var entries int
+	err = writeFile(*out, func(w io.Writer) error {
+		entries, err = tolog.ExportBundle(w, opts)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d entries\n", *out, entries)
+	return nil
+}
+
+// writeFile creates the file and writes it with write, removing it on failure.
+func writeFile(name string, write func(w io.Writer) error) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(name)
+	}
+	return err
+}
+
+// readKey reads a hex encoded key from the file.
+func readKey(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("key file %s: %v", name, err)
+	}
+	return key, nil
+}
+
+// parseWhen parses an RFC 3339 time or a duration before now, zero if empty.
+func parseWhen(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a duration", s)
+	}
+	return t, nil
+}
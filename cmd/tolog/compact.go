@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/callme-taota/tolog"
+)
+
+// compact turns a log file of pretty-printed JSON back into an entry per line.
+func compact(args []string) error {
+	fs := newFlagSet("compact")
+	out := fs.String("o", "", "file to write, stdout if empty")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		return fmt.Errorf("give at most one log file")
+	}
+	in, err := openInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if *out == "" {
+		_, err = tolog.CompactJSON(os.Stdout, in)
+		return err
+	}
+	return writeFile(*out, func(w io.Writer) error {
+		_, err := tolog.CompactJSON(w, in)
+		return err
+	})
+}
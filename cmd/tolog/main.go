@@ -0,0 +1,76 @@
+// Command tolog works with the log files written by the tolog package.
+//
+// Usage:
+//
+//	tolog relay [-in file [-follow [-offset n]]] [-azure connection-string [-role name]] [-remote addr [-remote-net tcp] [-remote-format json]] [-syslog addr|local [-syslog-net udp] [-tag name]]
+//	tolog verify [-tolerance 1s] file...
+//	tolog parquet [-o file.parquet] file
+//	tolog bundle [-dir ./logs] [-since 24h] [-until time] [-key file] [-o bundle.tar.gz]
+//	tolog bundle -d -key file [-o bundle.tar.gz] bundle
+//	tolog compact [-o file] [file]
+//	tolog search [-B n] [-A n] [-C n] pattern file...
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "relay":
+		err = relay(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	case "parquet":
+		err = parquet(os.Args[2:])
+	case "bundle":
+		err = bundle(os.Args[2:])
+	case "compact":
+		err = compact(os.Args[2:])
+	case "search":
+		err = search(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tolog: %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: tolog relay [-in file [-follow [-offset n]]] [-azure connection-string [-role name]] [-remote addr [-remote-net tcp] [-remote-format json]] [-syslog addr|local [-syslog-net udp] [-tag name]]")
+	fmt.Fprintln(os.Stderr, "       tolog verify [-tolerance 1s] file...")
+	fmt.Fprintln(os.Stderr, "       tolog parquet [-o file.parquet] file")
+	fmt.Fprintln(os.Stderr, "       tolog bundle [-dir ./logs] [-since 24h] [-until time] [-key file] [-o bundle.tar.gz]")
+	fmt.Fprintln(os.Stderr, "       tolog bundle -d -key file [-o bundle.tar.gz] bundle")
+	fmt.Fprintln(os.Stderr, "       tolog compact [-o file] [file]")
+	fmt.Fprintln(os.Stderr, "       tolog search [-B n] [-A n] [-C n] pattern file...")
+	os.Exit(2)
+}
+
+// openInput opens the named file, or returns stdin for an empty name or "-".
+func openInput(name string) (io.ReadCloser, error) {
+	if name == "" || name == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// signalContext returns a context cancelled on interrupt.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("tolog "+name, flag.ExitOnError)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/callme-taota/tolog"
+)
+
+// parquet converts a log file into a Parquet file.
+func parquet(args []string) error {
+	fs := newFlagSet("parquet")
+	out := fs.String("o", "", "Parquet file to write, the log file with a .parquet extension if empty")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("give one log file")
+	}
+	path := fs.Arg(0)
+	if *out == "" {
+		*out = strings.TrimSuffix(strings.TrimSuffix(path, ".gz"), ".log") + ".parquet"
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	rows, err := tolog.ExportParquetFile(w, path)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(*out)
+		return err
+	}
+	fmt.Printf("%s: %d rows\n", *out, rows)
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/callme-taota/tolog"
+)
+
+// relay forwards the entries of a log file to remote sinks: Azure Monitor, a
+// collector over TCP or UDP and syslog. The publish, SQL and ClickHouse sinks
+// need the client of the broker or the driver of the database, so they are
+// relayed by embedding NewRelay in a program which links them.
+func relay(args []string) error {
+	fs := newFlagSet("relay")
+	in := fs.String("in", "", "log file to read, stdin if empty")
+	follow := fs.Bool("follow", false, "follow the log file for new lines and its next dated files, needs -in")
+	offset := fs.Int64("offset", 0, "offset in the log file to follow from")
+	azure := fs.String("azure", "", "Azure Monitor connection string")
+	role := fs.String("role", "", "cloud role name sent to Azure Monitor")
+	remote := fs.String("remote", "", "address of a collector to stream the entries to")
+	remoteNet := fs.String("remote-net", "tcp", "network of the collector, tcp or udp")
+	remoteFormat := fs.String("remote-format", string(tolog.FormatJSON), "format sent to the collector, text, json or ecs")
+	syslogAddr := fs.String("syslog", "", "address of a syslog daemon, \"local\" for the local one")
+	syslogNet := fs.String("syslog-net", "udp", "network of the syslog daemon, udp or tcp")
+	tag := fs.String("tag", "tolog", "syslog tag")
+	fs.Parse(args)
+	if *follow && (*in == "" || *in == "-") {
+		return fmt.Errorf("give the log file to follow with -in")
+	}
+
+	var sinks []tolog.Sink
+	closeSinks := func() {
+		for _, s := range sinks {
+			s.Close()
+		}
+	}
+	if *azure != "" {
+		sink, err := tolog.NewAzureMonitorSink(tolog.AzureMonitorOptions{ConnectionString: *azure, RoleName: *role})
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, sink)
+	}
+	if *remote != "" {
+		sinks = append(sinks, tolog.NewRemoteSink(*remoteNet, *remote, tolog.LogFormat(*remoteFormat)))
+	}
+	if *syslogAddr != "" {
+		network, addr := *syslogNet, *syslogAddr
+		if addr == "local" {
+			network, addr = "", ""
+		}
+		sink, err := tolog.NewSyslogSink(network, addr, *tag)
+		if err != nil {
+			closeSinks()
+			return err
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 0 {
+		return fmt.Errorf("no sink configured, give -azure, -remote or -syslog")
+	}
+	defer closeSinks()
+
+	var source tolog.Source
+	if *follow {
+		f, err := tolog.Follow(*in, *offset)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		source = f
+	} else {
+		r, err := openInput(*in)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		source = tolog.NewLineSource(r)
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	err := tolog.NewRelay(source, sinks...).Run(ctx)
+	if errors.Is(err, context.Canceled) {
+		return nil // interrupted
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/callme-taota/tolog"
+)
+
+// search prints the lines of log files matching a regular expression with the
+// lines around them, like grep: matching lines as file:N:line, the others as
+// file-N-line, and -- between groups which are not adjacent.
+func search(args []string) error {
+	fs := newFlagSet("search")
+	before := fs.Int("B", 0, "lines to print before each match")
+	after := fs.Int("A", 0, "lines to print after each match")
+	context := fs.Int("C", 0, "lines to print before and after each match")
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		return fmt.Errorf("give a pattern and log files")
+	}
+	if *context > 0 {
+		*before, *after = *context, *context
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	found := false
+	for _, path := range fs.Args()[1:] {
+		matches, err := tolog.SearchWithContext(path, fs.Arg(0), *before, *after)
+		if err != nil {
+			return err
+		}
+		matched := map[int]bool{}
+		for _, m := range matches {
+			matched[m.Line.Number] = true
+		}
+		last := 0 // the number of the last line printed
+		for _, m := range matches {
+			found = true
+			lines := append(append(m.Before, m.Line), m.After...)
+			if last > 0 && lines[0].Number > last+1 {
+				fmt.Fprintln(w, "--")
+			}
+			for _, l := range lines {
+				if l.Number <= last {
+					continue
+				}
+				sep := "-"
+				if matched[l.Number] {
+					sep = ":"
+				}
+				fmt.Fprintf(w, "%s%s%d%s%s\n", path, sep, l.Number, sep, l.Text)
+				last = l.Number
+			}
+		}
+	}
+	if !found {
+		w.Flush()
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/callme-taota/tolog"
+)
+
+// verify checks that log files are well formed, failing if one is not.
+func verify(args []string) error {
+	fs := newFlagSet("verify")
+	tolerance := fs.Duration("tolerance", 0, "how far a time may go back, 1s if zero")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("no file given")
+	}
+
+	failed := 0
+	for _, path := range fs.Args() {
+		report, err := tolog.VerifyFile(path, tolog.VerifyOptions{Tolerance: *tolerance})
+		if err != nil {
+			return err
+		}
+		for _, issue := range report.Issues {
+			fmt.Printf("%s: %s\n", path, issue)
+		}
+		if report.Incomplete {
+			fmt.Printf("%s: too many issues, stopped\n", path)
+		}
+		if !report.OK() {
+			failed++
+		}
+		fmt.Printf("%s: %d lines, %d entries, %d issues\n", path, report.Lines, report.Entries, len(report.Issues))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files have issues", failed, fs.NArg())
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package tolog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Collector listens on a Unix socket, or a named pipe on Windows, and writes the
+// lines forwarded by the loggers of sibling processes to the log file of its
+// logger, producing a single merged file per host instead of one file per process.
+type Collector struct {
+	address  string
+	logger   *Logger
+	listener net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+}
+
+// ListenCollector starts a collector writing to the log file of the logger, or
+// of the default logger if nil. The address is the path of a Unix socket, or of
+// a named pipe like \\.\pipe\app-logs on Windows. A stale socket file left by a
+// previous collector is replaced.
+func ListenCollector(address string, lg *Logger) (*Collector, error) {
+	if lg == nil {
+		lg = std
+	}
+	if info, err := os.Lstat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if conn, err := dialCollector(address); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("tolog: a collector already listens on %s", address)
+		}
+		os.Remove(address)
+	}
+	listener, err := listenCollector(address)
+	if err != nil {
+		return nil, err
+	}
+	c := &Collector{address: address, logger: lg, listener: listener, conns: map[net.Conn]struct{}{}}
+	c.wg.Add(1)
+	go c.accept()
+	diag("open", "collector listening on %s", collectorName(address))
+	return c, nil
+}
+
+// Addr returns the address the collector listens on.
+func (c *Collector) Addr() string {
+	return c.address
+}
+
+// isPipe reports whether the collector address is a Windows named pipe.
+func isPipe(address string) bool {
+	return strings.HasPrefix(address, `\\.\pipe\`) || strings.HasPrefix(address, `//./pipe/`)
+}
+
+// collectorName returns the collector address with its transport, as shown by FilePath.
+func collectorName(address string) string {
+	if isPipe(address) {
+		return "pipe:" + address
+	}
+	return "unix:" + address
+}
+
+// Close stops the collector and disconnects the forwarding processes, which
+// reconnect on their next flush to whichever collector then listens.
+func (c *Collector) Close() error {
+	err := c.listener.Close()
+	c.mu.Lock()
+	for conn := range c.conns {
+		conn.Close()
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+	diag("close", "collector on %s stopped", collectorName(c.address))
+	return err
+}
+
+func (c *Collector) accept() {
+	defer c.wg.Done()
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				handleError(err)
+			}
+			return
+		}
+		c.mu.Lock()
+		c.conns[conn] = struct{}{}
+		c.mu.Unlock()
+		c.wg.Add(1)
+		go c.serve(conn)
+	}
+}
+
+// serve writes the lines of the connection to the log file, whole lines only so
+// the lines of different processes never interleave.
+func (c *Collector) serve(conn net.Conn) {
+	defer c.wg.Done()
+	defer func() {
+		c.mu.Lock()
+		delete(c.conns, conn)
+		c.mu.Unlock()
+		conn.Close()
+	}()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	scanner.Split(wholeLines)
+	for scanner.Scan() {
+		item := writeItem{line: scanner.Text() + "\n"}
+		if e, err := ParseLine(scanner.Text()); err == nil {
+			item.level = e.Level
+		}
+		if err := c.logger.fileWriter().write(item); err != nil {
+			return
+		}
+	}
+}
+
+// wholeLines splits the lines like bufio.ScanLines, but drops the last one if
+// the connection ended before its newline: the logger resends it whole.
+func wholeLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && bytes.IndexByte(data, '\n') < 0 {
+		return len(data), nil, nil
+	}
+	return bufio.ScanLines(data, false)
+}
+
+// ForwardTo makes the logger send its lines to the collector listening on the
+// address instead of writing its own log file. Loggers forwarding to the same
+// collector share a connection. The sinks of the logger are not affected.
+func (lg *Logger) ForwardTo(address string) {
+	lg = lg.root()
+	lg.mu.Lock()
+	old := lg.writer
+	lg.writer = acquireForwarder(address)
+	lg.mu.Unlock()
+	if err := old.release(); err != nil {
+		handleError(err)
+	}
+}
+
+// dial connects to the collector, fileMu is taken.
+func (w *fileWriter) dial() error {
+	conn, err := dialCollector(w.socket)
+	if err != nil {
+		return err
+	}
+	w.fileMu.Lock()
+	w.conn = conn
+	w.fileMu.Unlock()
+	diag("open", "connected to %s", w.name())
+	return nil
+}
+
+// send writes the lines to the collector, reconnecting once if the connection
+// was lost, fileMu must be held. A line cut by the lost connection is dropped
+// by the collector and resent whole, the lines before it are not resent.
+func (w *fileWriter) send(data []byte) (int, error) {
+	sent := 0
+	if w.conn != nil {
+		n, err := w.conn.Write(data)
+		if err == nil {
+			return n, nil
+		}
+		w.conn.Close()
+		w.conn = nil
+		diag("reopen", "connection to %s lost: %v", w.name(), err)
+		sent = bytes.LastIndexByte(data[:n], '\n') + 1
+	}
+	conn, err := dialCollector(w.socket)
+	if err != nil {
+		return sent, err
+	}
+	w.conn = conn
+	n, err := conn.Write(data[sent:])
+	return sent + n, err
+}
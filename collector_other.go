@@ -0,0 +1,24 @@
+//go:build !windows
+
+package tolog
+
+import (
+	"errors"
+	"net"
+)
+
+var errNoPipes = errors.New("tolog: named pipes are only supported on Windows")
+
+func listenCollector(address string) (net.Listener, error) {
+	if isPipe(address) {
+		return nil, errNoPipes
+	}
+	return net.Listen("unix", address)
+}
+
+func dialCollector(address string) (net.Conn, error) {
+	if isPipe(address) {
+		return nil, errNoPipes
+	}
+	return net.Dial("unix", address)
+}
@@ -0,0 +1,99 @@
+package tolog
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "tolog.sock")
+	lg := NewLogger("TestCollector")
+	defer lg.Close()
+	os.Remove(lg.FilePath())
+
+	c, err := ListenCollector(socket, lg)
+	require.NoError(t, err)
+	_, err = ListenCollector(socket, lg)
+	assert.Error(t, err)
+
+	first := NewLogger("first")
+	first.ForwardTo(socket)
+	second := NewLogger("second")
+	second.ForwardTo(socket)
+	assert.Equal(t, "unix:"+socket, first.FilePath())
+
+	first.Info("from the first process").WriteSafe()
+	second.Error("from the second process").WriteSafe()
+	first.Close()
+	second.Close()
+
+	assert.Eventually(t, func() bool {
+		lg.CloseFile()
+		content, _ := os.ReadFile(lg.FilePath())
+		return strings.Contains(string(content), "from the first process") &&
+			strings.Contains(string(content), "from the second process")
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, c.Close())
+}
+
+// cutConn writes the first limit bytes, then fails like a lost connection.
+type cutConn struct {
+	net.Conn
+	limit int
+}
+
+func (c *cutConn) Write(p []byte) (int, error) {
+	if len(p) <= c.limit {
+		return c.Conn.Write(p)
+	}
+	n, _ := c.Conn.Write(p[:c.limit])
+	c.Conn.Close()
+	return n, errors.New("connection reset")
+}
+
+func TestCollectorShortWrite(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "tolog.sock")
+	lg := NewLogger("TestCollectorShortWrite")
+	defer lg.Close()
+	os.Remove(lg.FilePath())
+	c, err := ListenCollector(socket, lg)
+	require.NoError(t, err)
+	defer c.Close()
+
+	conn, err := dialCollector(socket)
+	require.NoError(t, err)
+	w := &fileWriter{socket: socket, conn: &cutConn{Conn: conn, limit: len("first\nsec")}}
+	data := []byte("first\nsecond\nthird\n")
+	n, err := w.send(data)
+	require.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	w.conn.Close()
+
+	// the connections are served concurrently, the lines of each one in order
+	var lines []string
+	assert.Eventually(t, func() bool {
+		lg.CloseFile()
+		content, _ := os.ReadFile(lg.FilePath())
+		lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		return len(lines) >= 3
+	}, time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{"first", "second", "third"}, lines)
+}
+
+func TestCollectorName(t *testing.T) {
+	assert.Equal(t, `pipe:\\.\pipe\app-logs`, collectorName(`\\.\pipe\app-logs`))
+	assert.Equal(t, "unix:/run/app.sock", collectorName("/run/app.sock"))
+	if runtime.GOOS != "windows" {
+		_, err := ListenCollector(`\\.\pipe\app-logs`, nil)
+		assert.Error(t, err)
+	}
+}
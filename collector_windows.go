@@ -0,0 +1,28 @@
+//go:build windows
+
+package tolog
+
+import (
+	"net"
+	"time"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// The time a forwarder waits for a busy named pipe.
+const pipeDialTimeout = 5 * time.Second
+
+func listenCollector(address string) (net.Listener, error) {
+	if isPipe(address) {
+		return winio.ListenPipe(address, nil)
+	}
+	return net.Listen("unix", address)
+}
+
+func dialCollector(address string) (net.Conn, error) {
+	if isPipe(address) {
+		timeout := pipeDialTimeout
+		return winio.DialPipe(address, &timeout)
+	}
+	return net.Dial("unix", address)
+}
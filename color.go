@@ -0,0 +1,137 @@
+package tolog
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/term"
+)
+
+// ColorMode is when the console entries are colored.
+type ColorMode int32
+
+const (
+	// ColorAuto colors the entries when the console is a terminal and the
+	// NO_COLOR environment variable is not set, the default.
+	ColorAuto ColorMode = iota
+	// ColorAlways colors the entries, also when redirected to a file or a pipe.
+	ColorAlways
+	// ColorNever prints the entries without colors.
+	ColorNever
+)
+
+// SetColorMode sets when the console entries are colored. Loggers with
+// SetColor keep their own setting, the log files are never colored and the
+// outputs only when wrapped with ColorOutput.
+func SetColorMode(mode ColorMode) {
+	updateSettings(func(s *settings) {
+		s.colorMode = mode
+		LogWithColor = mode != ColorNever
+		knownColor = LogWithColor
+	})
+}
+
+// terminals caches whether the console files are terminals supporting colors.
+var terminals sync.Map // *os.File -> bool
+
+// colorTerminal reports whether the console writer is a terminal supporting
+// colors, turning on their support on Windows.
+func colorTerminal(w io.Writer) bool {
+	f, ok := realStream(w).(*os.File)
+	if !ok {
+		return false
+	}
+	if v, ok := terminals.Load(f); ok {
+		return v.(bool)
+	}
+	colored := term.IsTerminal(int(f.Fd())) && enableVirtualTerminal(f)
+	terminals.Store(f, colored)
+	return colored
+}
+
+// autoColor reports whether ColorAuto colors the entries printed to the console writer.
+func autoColor(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return colorTerminal(w)
+}
+
+// Color is a color of the 256 color palette of terminals.
+type Color struct {
+	n   uint8
+	set bool
+}
+
+// NoColor leaves the color of the terminal, for levels only styled.
+var NoColor = Color{}
+
+// Color256 returns the color of the 256 color palette, 0-15 are the standard
+// colors of the theme of the terminal, 16-231 a color cube and 232-255 grays.
+func Color256(n uint8) Color {
+	return Color{n: n, set: true}
+}
+
+// Style is how the level of colored entries is rendered.
+type Style int
+
+const (
+	// StyleBold renders the level bold.
+	StyleBold Style = 1 << iota
+	// StyleUnderline underlines the level.
+	StyleUnderline
+	// StyleForeground colors the level itself instead of its background,
+	// readable on light and dark themes alike.
+	StyleForeground
+)
+
+// levelColors holds the escape codes set with SetLevelColor, replaced as a whole on change.
+var levelColors atomic.Value // map[LogStatus]string
+var levelColorsMu sync.Mutex
+
+// SetLevelColor sets how the level of colored entries is rendered, replacing
+// its default background color, e.g. SetLevelColor(StatusWarning, Color256(208), StyleBold).
+func SetLevelColor(level LogStatus, color Color, styles ...Style) {
+	var style Style
+	for _, s := range styles {
+		style |= s
+	}
+	var params []string
+	if style&StyleBold != 0 {
+		params = append(params, "1")
+	}
+	if style&StyleUnderline != 0 {
+		params = append(params, "4")
+	}
+	if color.set {
+		layer := "48"
+		if style&StyleForeground != 0 {
+			layer = "38"
+		}
+		params = append(params, layer, "5", strconv.Itoa(int(color.n)))
+	}
+	code := ""
+	if len(params) > 0 {
+		code = "\033[" + strings.Join(params, ";") + "m"
+	}
+	levelColorsMu.Lock()
+	defer levelColorsMu.Unlock()
+	current, _ := levelColors.Load().(map[LogStatus]string)
+	colors := make(map[LogStatus]string, len(current)+1)
+	for k, v := range current {
+		colors[k] = v
+	}
+	colors[level] = code
+	levelColors.Store(colors)
+}
+
+// ResetLevelColors restores the default colors of the levels.
+func ResetLevelColors() {
+	levelColorsMu.Lock()
+	defer levelColorsMu.Unlock()
+	levelColors.Store(map[LogStatus]string(nil))
+}
@@ -0,0 +1,60 @@
+package tolog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorMode(t *testing.T) {
+	lg := NewLogger("TestColorMode")
+	defer lg.Close()
+	var console bytes.Buffer
+	lg.SetConsole(&console)
+	defer SetColorMode(ColorAuto)
+
+	printed := func() string {
+		console.Reset()
+		lg.Info("hello").PrintLog()
+		return console.String()
+	}
+	assert.NotContains(t, printed(), "\033[", "a buffer is not a terminal")
+	SetColorMode(ColorAlways)
+	assert.Contains(t, printed(), colorInfoBg)
+	assert.True(t, LogWithColor)
+	SetColorMode(ColorNever)
+	assert.NotContains(t, printed(), "\033[")
+	assert.False(t, LogWithColor)
+	lg.SetColor(true)
+	assert.Contains(t, printed(), colorInfoBg, "the logger setting wins")
+
+	f, err := os.CreateTemp(t.TempDir(), "console")
+	require.NoError(t, err)
+	defer f.Close()
+	assert.False(t, colorTerminal(f))
+	assert.False(t, autoColor(&console))
+	t.Setenv("NO_COLOR", "")
+	assert.False(t, autoColor(os.Stdout))
+}
+
+func TestSetLevelColor(t *testing.T) {
+	defer ResetLevelColors()
+	SetLevelColor(StatusWarning, Color256(208), StyleBold)
+	SetLevelColor(StatusError, Color256(196), StyleForeground, StyleUnderline)
+	SetLevelColor(StatusDebug, NoColor, StyleBold)
+	SetLevelColor(StatusNotice, NoColor)
+	assert.Equal(t, "\033[1;48;5;208m", levelColor(StatusWarning))
+	assert.Equal(t, "\033[4;38;5;196m", levelColor(StatusError))
+	assert.Equal(t, "\033[1m", levelColor(StatusDebug))
+	assert.Equal(t, "", levelColor(StatusNotice))
+	assert.Equal(t, colorInfoBg, levelColor(StatusInfo))
+
+	l := std.Log(WithType(StatusWarning)).Context("careful")
+	assert.Contains(t, encodeLine(l, FormatText, true), "\033[1;48;5;208m warning "+colorReset)
+
+	ResetLevelColors()
+	assert.Equal(t, colorWarningBg, levelColor(StatusWarning))
+}
@@ -0,0 +1,68 @@
+package tolog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+var compressRotated int32
+
+var compressions sync.WaitGroup
+
+// SetCompression enables or disables compressing log files to .gz once they are
+// rotated by date or by size. The files are compressed in the background and the
+// originals removed. Compression is disabled by default.
+func SetCompression(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&compressRotated, v)
+}
+
+// compressLater compresses the rotated file in the background when compression is enabled.
+func compressLater(path string) {
+	if atomic.LoadInt32(&compressRotated) == 0 {
+		return
+	}
+	compressions.Add(1)
+	go func() {
+		defer compressions.Done()
+		if err := compressFile(path); err != nil {
+			diag("rotate", "compressing %s failed: %v", path, err)
+			return
+		}
+		diag("rotate", "compressed %s", path)
+	}()
+}
+
+// compressFile writes the file gzipped next to it and removes the original.
+func compressFile(path string) error {
+	fsys := logFS()
+	src, err := openRead(fsys, path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := fsys.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fsys.Remove(path + ".gz")
+		return err
+	}
+	src.Close()
+	return fsys.Remove(path)
+}
@@ -0,0 +1,48 @@
+package tolog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressRotated(t *testing.T) {
+	lg := NewLogger("TestCompressRotated")
+	defer lg.Close()
+	base := strings.TrimSuffix(lg.FilePath(), ".log")
+	old, _ := filepath.Glob(base + "*")
+	for _, f := range old {
+		os.Remove(f)
+	}
+
+	SetCompression(true)
+	SetMaxFileSize(50)
+	defer SetCompression(false)
+	defer SetMaxFileSize(0)
+
+	line := strings.Repeat("x", 39)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, lg.WriteRaw([]byte(line)))
+		lg.CloseFile()
+	}
+
+	plain, _ := filepath.Glob(base + "-*.log")
+	assert.Empty(t, plain)
+	zipped, _ := filepath.Glob(base + "-*.log.gz")
+	require.Len(t, zipped, 1)
+
+	f, err := os.Open(zipped[0])
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	content, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, line+"\n", string(content))
+}
@@ -0,0 +1,18 @@
+package tolog
+
+// ConcurrencySafe reports whether the logger can be used from several
+// goroutines at once, which is always the case. It documents the guarantees
+// held by every logger, checked by the race tests of the package:
+//
+//   - An entry belongs to the goroutine building it until its terminator. Once
+//     emitted it is frozen, and builders called on it change a copy.
+//   - Lines are written whole to the file, the console and the outputs, lines
+//     of concurrent entries never interleave, and none is lost while the file
+//     is rotated.
+//   - Setters of the package and of loggers can be called while entries are
+//     written. An entry uses the settings read when it is encoded.
+//   - Close and CloseFile can be called while entries are written. Entries
+//     written after Close report ErrClosed to the error handler.
+func (lg *Logger) ConcurrencySafe() bool {
+	return true
+}
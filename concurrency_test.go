@@ -0,0 +1,219 @@
+package tolog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests of this file check the concurrency guarantees of Logger.ConcurrencySafe,
+// they are meant to be run with -race.
+
+func TestConcurrentWritesDuringRotation(t *testing.T) {
+	lg := NewLogger("TestConcurrentWritesDuringRotation")
+	defer lg.Close()
+	base := strings.TrimSuffix(lg.FilePath(), ".log")
+	removeFiles := func() {
+		files, _ := filepath.Glob(base + "*")
+		for _, f := range files {
+			os.Remove(f)
+		}
+	}
+	removeFiles()
+	defer removeFiles()
+	lg.SetConsole(io.Discard)
+	SetMaxFileSize(2 << 10)
+	defer SetMaxFileSize(0)
+
+	const goroutines, lines = 8, 200
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < lines; j++ {
+				lg.Infof("rotated line %d-%d", i, j).WriteSafe()
+			}
+		}(i)
+	}
+	wg.Wait()
+	require.NoError(t, lg.CloseFile())
+
+	files, err := filepath.Glob(base + "*")
+	require.NoError(t, err)
+	assert.Greater(t, len(files), 1)
+	seen := map[string]int{}
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		require.NoError(t, err)
+		for _, line := range strings.Split(string(content), "\n") {
+			if _, id, ok := strings.Cut(line, "rotated line "); ok {
+				seen[id]++
+			}
+		}
+	}
+	assert.Len(t, seen, goroutines*lines)
+	for id, n := range seen {
+		assert.Equal(t, 1, n, "line %s", id)
+	}
+}
+
+func TestCloseDuringWrites(t *testing.T) {
+	var mu sync.Mutex
+	var errs []error
+	SetErrorHandler(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+	defer SetErrorHandler(nil)
+
+	lg := NewLogger("TestCloseDuringWrites")
+	defer os.Remove(lg.FilePath())
+	lg.SetConsole(io.Discard)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				lg.Info("closing").PrintAndWriteSafe()
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, lg.Close())
+	wg.Wait()
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, ErrClosed), err)
+	}
+}
+
+func TestPrefixChangeDuringWrites(t *testing.T) {
+	var mu sync.Mutex
+	var errs []error
+	SetErrorHandler(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+	defer SetErrorHandler(nil)
+
+	prefixes := []string{"TestPrefixChangeDuringWritesA", "TestPrefixChangeDuringWritesB"}
+	lg := NewLogger(prefixes[0])
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+	paths := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		paths[i] = logFilePath(p, currentDay())
+		defer os.Remove(paths[i])
+	}
+
+	const goroutines, lines = 8, 200
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < lines; j++ {
+				lg.Infof("prefixed line %d-%d", i, j).WriteSafe()
+			}
+		}(i)
+	}
+	for i := 0; i < 20; i++ {
+		lg.SetPrefix(prefixes[i%2])
+	}
+	wg.Wait()
+	require.NoError(t, lg.CloseFile())
+
+	seen := map[string]int{}
+	for _, path := range paths {
+		content, _ := os.ReadFile(path)
+		for _, line := range strings.Split(string(content), "\n") {
+			if _, id, ok := strings.Cut(line, "prefixed line "); ok {
+				seen[id]++
+			}
+		}
+	}
+	assert.Len(t, seen, goroutines*lines)
+	assert.Empty(t, errs)
+}
+
+func TestSettersDuringLogging(t *testing.T) {
+	lg := NewLogger("TestSettersDuringLogging")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.SetConsole(io.Discard)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				lg.Warning("setting").WithField("goroutine", i).PrintAndWriteSafe()
+				lg.Named("child").Debugf("child %d", i).PrintLog()
+			}
+		}(i)
+	}
+
+	for i := 0; i < 50; i++ {
+		SetLogFormat([]LogFormat{FormatText, FormatJSON, FormatECS}[i%3])
+		SetColorMode([]ColorMode{ColorAlways, ColorNever, ColorAuto}[i%3])
+		SetLevelColor(StatusWarning, Color256(uint8(i)), StyleBold)
+		SetGlobalFields(Fields{"round": i})
+		SetConsoleLevel([]LogStatus{StatusDebug, StatusWarning}[i%2])
+		SetLogTimeFormat([]DateFormat{DateTime, StampNano}[i%2])
+		lg.SetColor(i%2 == 0)
+		lg.SetSplitStdStreams(i%2 == 0)
+		if i == 25 {
+			lg.AddOutput(&lockedWriter{w: &out})
+		}
+		SetTrimPath(fmt.Sprint("/src/", i))
+	}
+	close(done)
+	wg.Wait()
+
+	SetLogFormat(FormatText)
+	SetColorMode(ColorAuto)
+	ResetLevelColors()
+	SetGlobalFields(nil)
+	SetConsoleLevel("")
+	SetLogTimeFormat(DateTime)
+	SetTrimPath()
+}
+
+// lockedWriter is a writer safe to read while written.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+func TestConcurrencySafe(t *testing.T) {
+	assert.True(t, std.ConcurrencySafe())
+	assert.True(t, NewLogger("TestConcurrencySafe").Named("child").ConcurrencySafe())
+}
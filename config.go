@@ -0,0 +1,249 @@
+package tolog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// settings are how entries are formatted. The package settings are complete,
+// those of a logger only hold what was set on it, the rest follows the package.
+// They are never modified once stored, setters store a modified copy, so they
+// can be changed at runtime while entries are written.
+type settings struct {
+	color      *bool // of a logger, the package has colorMode
+	format     LogFormat
+	timeFormat DateFormat
+	timeZone   *time.Location
+
+	// The console and the file use the format unless set, package only.
+	consoleFormat LogFormat
+	fileFormat    LogFormat
+	// The console and the file use the time format unless set, package only.
+	consoleTimeFormat DateFormat
+	fileTimeFormat    DateFormat
+	// When the console entries are colored, package only.
+	colorMode ColorMode
+	// The minimum levels of the console and the file, all levels if empty, package only.
+	consoleLevel LogStatus
+	fileLevel    LogStatus
+
+	// The lines of this level and above skip the queue, package only.
+	priorityLevel LogStatus
+	// The lines of these levels and above are flushed, and synced, at once, package only.
+	flushLevel LogStatus
+	syncLevel  LogStatus
+}
+
+// The directory of the log files unless set with SetLogDir.
+const defaultLogDir = "./logs"
+
+var configMu sync.Mutex // serializes the setters
+
+var config atomic.Value // *settings
+
+// Settings of the log files, shared by the loggers writing them.
+var (
+	fileDateFormat atomic.Value // DateFormat
+	logDir         atomic.Value // string
+	queueSize      int64        = 300
+	flushInterval  int64        = int64(500 * time.Millisecond)
+)
+
+func init() {
+	config.Store(&settings{format: FormatText, timeFormat: DateTime, timeZone: time.Local})
+	fileDateFormat.Store(DateOnly)
+}
+
+// globalSettings returns the package settings.
+func globalSettings() *settings {
+	return config.Load().(*settings)
+}
+
+// updateSettings stores a copy of the package settings modified by update.
+func updateSettings(update func(s *settings)) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	s := *globalSettings()
+	update(&s)
+	config.Store(&s)
+}
+
+// globalTimeZone returns the time zone of the package settings, the log files are named in.
+func globalTimeZone() *time.Location {
+	return globalSettings().timeZone
+}
+
+// globalTimeFormat returns the time format of the package settings.
+func globalTimeFormat() DateFormat {
+	return globalSettings().timeFormat
+}
+
+// currentDay returns the date in the name of the log files of today.
+func currentDay() string {
+	return time.Now().In(globalTimeZone()).Format(string(fileDateFormat.Load().(DateFormat)))
+}
+
+// overrides returns the settings set on the logger, nil if none.
+func (lg *Logger) overrides() *settings {
+	s, _ := lg.root().settings.Load().(*settings)
+	return s
+}
+
+// updateOverrides stores a copy of the settings of the logger modified by update.
+func (lg *Logger) updateOverrides(update func(s *settings)) {
+	lg = lg.root()
+	configMu.Lock()
+	defer configMu.Unlock()
+	var s settings
+	if o := lg.overrides(); o != nil {
+		s = *o
+	}
+	update(&s)
+	lg.settings.Store(&s)
+}
+
+// SetColor sets whether the entries of the logger are colored on the console,
+// overriding SetColorMode.
+func (lg *Logger) SetColor(enabled bool) {
+	lg.updateOverrides(func(s *settings) { s.color = &enabled })
+}
+
+// SetFormat sets the format the entries of the logger are encoded with,
+// overriding SetLogFormat.
+func (lg *Logger) SetFormat(format LogFormat) {
+	lg.updateOverrides(func(s *settings) { s.format = format })
+}
+
+// SetTimeFormat sets the format of the time of the entries of the logger,
+// overriding SetLogTimeFormat.
+func (lg *Logger) SetTimeFormat(format DateFormat) {
+	lg.updateOverrides(func(s *settings) { s.timeFormat = format })
+}
+
+// SetTimeZone sets the time zone of the time of the entries of the logger,
+// overriding SetLogTimeZone. The log files are still named in the package time zone.
+func (lg *Logger) SetTimeZone(zone *time.Location) {
+	lg.updateOverrides(func(s *settings) { s.timeZone = zone })
+}
+
+// withColor reports whether the entries of the logger are colored on its console.
+func (lg *Logger) withColor() bool {
+	root := lg.root()
+	root.mu.RLock()
+	console := root.console
+	root.mu.RUnlock()
+	return lg.colorOn(console)
+}
+
+// colorOn reports whether the lines of the logger printed to w are colored.
+func (lg *Logger) colorOn(w io.Writer) bool {
+	if o := lg.overrides(); o != nil && o.color != nil {
+		return *o.color
+	}
+	switch globalSettings().colorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return autoColor(w)
+}
+
+// format returns the format of the entries of the logger.
+func (lg *Logger) format() LogFormat {
+	if o := lg.overrides(); o != nil && o.format != "" {
+		return o.format
+	}
+	return globalSettings().format
+}
+
+// consoleFormat returns the format of the entries of the logger on the console.
+func (lg *Logger) consoleFormat() LogFormat {
+	if o := lg.overrides(); o != nil && o.format != "" {
+		return o.format
+	}
+	if s := globalSettings(); s.consoleFormat != "" {
+		return s.consoleFormat
+	}
+	return globalSettings().format
+}
+
+// fileFormat returns the format of the entries of the logger in the log file.
+func (lg *Logger) fileFormat() LogFormat {
+	if o := lg.overrides(); o != nil && o.format != "" {
+		return o.format
+	}
+	if s := globalSettings(); s.fileFormat != "" {
+		return s.fileFormat
+	}
+	return globalSettings().format
+}
+
+// timeFormat returns the time format of the entries of the logger.
+func (lg *Logger) timeFormat() DateFormat {
+	if o := lg.overrides(); o != nil && o.timeFormat != "" {
+		return o.timeFormat
+	}
+	return globalSettings().timeFormat
+}
+
+// consoleTimeFormat returns the time format of the entries on the console
+// overriding that of the logger, empty if none.
+func (lg *Logger) consoleTimeFormat() DateFormat {
+	return globalSettings().consoleTimeFormat
+}
+
+// fileTimeFormat returns the time format of the entries in the log file
+// overriding that of the logger, empty if none.
+func (lg *Logger) fileTimeFormat() DateFormat {
+	return globalSettings().fileTimeFormat
+}
+
+// timeZone returns the time zone of the entries of the logger.
+func (lg *Logger) timeZone() *time.Location {
+	if o := lg.overrides(); o != nil && o.timeZone != nil {
+		return o.timeZone
+	}
+	return globalSettings().timeZone
+}
+
+// SetConsoleFormat sets the format of the entries printed on the console,
+// overriding SetLogFormat, e.g. colored text on the console and JSON in the
+// file. Empty follows SetLogFormat again.
+func SetConsoleFormat(format LogFormat) {
+	updateSettings(func(s *settings) { s.consoleFormat = format })
+}
+
+// SetFileFormat sets the format of the entries written to the log files and
+// the outputs, overriding SetLogFormat. Empty follows SetLogFormat again.
+func SetFileFormat(format LogFormat) {
+	updateSettings(func(s *settings) { s.fileFormat = format })
+}
+
+// SetConsoleLevel sets the minimum level of the entries printed on the
+// console, from debug to error. Empty prints all entries.
+func SetConsoleLevel(level LogStatus) {
+	updateSettings(func(s *settings) { s.consoleLevel = level })
+}
+
+// SetFileLevel sets the minimum level of the entries written to the log files
+// and the outputs, from debug to error. Empty writes all entries. Sinks get
+// all entries regardless.
+func SetFileLevel(level LogStatus) {
+	updateSettings(func(s *settings) { s.fileLevel = level })
+}
+
+func consoleLevel() LogStatus {
+	return globalSettings().consoleLevel
+}
+
+func fileLevel() LogStatus {
+	return globalSettings().fileLevel
+}
+
+// levelEnabled reports whether entries of the level pass the minimum level.
+func levelEnabled(level LogStatus, min LogStatus) bool {
+	return min == "" || severity(level) >= severity(min)
+}
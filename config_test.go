@@ -0,0 +1,133 @@
+package tolog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerSettings(t *testing.T) {
+	lg := NewLogger("TestLoggerSettings")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	utc := time.FixedZone("UTC-2", -2*3600)
+	lg.SetColor(false)
+	lg.SetFormat(FormatJSON)
+	lg.SetTimeFormat(RFC3339)
+	lg.SetTimeZone(utc)
+
+	l := lg.Info("json")
+	assert.True(t, strings.HasPrefix(l.FullLog, "{"), l.FullLog)
+	assert.True(t, strings.HasSuffix(l.logTime, "-02:00"), l.logTime)
+	// the package settings and the other loggers are not affected
+	assert.True(t, strings.HasPrefix(Info("text").FullLog, "["))
+
+	// derived loggers use the settings of their parent
+	assert.True(t, strings.HasPrefix(FromContext(NewContext(context.Background(), lg)).Info("json").FullLog, "{"))
+}
+
+func TestSettingsRace(t *testing.T) {
+	lg := NewLogger("TestSettingsRace")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	lg.SetConsole(discardWriter{})
+	defer SetLogWithColor(true)
+	defer SetLogTimeFormat(DateTime)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				lg.Info("entry").PrintAndWriteSafe()
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				SetLogWithColor(j%2 == 0)
+				SetLogTimeFormat(DateTime)
+				SetMaxFileSize(0)
+				lg.SetFormat(FormatText)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestConsoleAndFileSettings(t *testing.T) {
+	lg := NewLogger("TestConsoleAndFileSettings")
+	defer lg.Close()
+	path := lg.FilePath()
+	defer os.Remove(path)
+	var console bytes.Buffer
+	lg.SetConsole(&console)
+
+	SetConsoleFormat(FormatText)
+	SetFileFormat(FormatJSON)
+	SetConsoleLevel(StatusWarning)
+	defer SetConsoleFormat("")
+	defer SetFileFormat("")
+	defer SetConsoleLevel("")
+
+	lg.Info("detail").PrintAndWriteSafe()
+	lg.Warning("slow").PrintAndWriteSafe()
+	assert.NoError(t, lg.CloseFile())
+
+	assert.NotContains(t, console.String(), "detail")
+	assert.Contains(t, console.String(), "slow")
+	assert.False(t, strings.HasPrefix(console.String(), "{"))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], `{`), lines[0])
+	assert.Contains(t, lines[0], `"msg":"detail"`)
+
+	SetFileLevel(StatusError)
+	defer SetFileLevel("")
+	lg.Warning("dropped from the file").WriteSafe()
+	lg.Error("kept").WriteSafe()
+	assert.NoError(t, lg.CloseFile())
+	content, _ = os.ReadFile(path)
+	assert.NotContains(t, string(content), "dropped from the file")
+	assert.Contains(t, string(content), `"msg":"kept"`)
+}
+
+func TestDeprecatedVariables(t *testing.T) {
+	prefix := Default().Prefix()
+	defer SetLogPrefix(prefix)
+	defer SetLogTimeZone(time.Local)
+	defer SetColorMode(ColorAuto)
+
+	utc := time.FixedZone("UTC-2", -2*3600)
+	LogWithColor = false
+	LogTimeZone = utc
+	LogfilePrefix = "TestDeprecatedVariables"
+	require.NoError(t, Init())
+	defer os.Remove(Default().FilePath())
+	assert.Equal(t, ColorNever, globalSettings().colorMode)
+	assert.Equal(t, utc, globalTimeZone())
+	assert.Equal(t, "TestDeprecatedVariables", Default().Prefix())
+
+	// the setters win unless the variable is assigned again
+	SetLogWithColor(true)
+	require.NoError(t, Init())
+	assert.Equal(t, ColorAlways, globalSettings().colorMode)
+}
@@ -0,0 +1,403 @@
+package tolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes the logging of a deployment, loaded with LoadConfig or
+// ConfigureFromEnv. Empty settings are left as they are.
+type Config struct {
+	// Level is the minimum level of the console and the file.
+	Level LogStatus `json:"level" yaml:"level"`
+	// ConsoleLevel and FileLevel override Level for the console and the file.
+	ConsoleLevel LogStatus `json:"console_level" yaml:"console_level"`
+	FileLevel    LogStatus `json:"file_level" yaml:"file_level"`
+	// Format is the format of the console and the file: text, json, ecs, gcp, logfmt
+	// or a registered format.
+	Format LogFormat `json:"format" yaml:"format"`
+	// ConsoleFormat and FileFormat override Format for the console and the file.
+	ConsoleFormat LogFormat `json:"console_format" yaml:"console_format"`
+	FileFormat    LogFormat `json:"file_format" yaml:"file_format"`
+	// Color is when the console is colored: auto, always or never.
+	Color string `json:"color" yaml:"color"`
+	// TimeFormat is the Go layout of the time of the entries, or unix,
+	// unixmilli or unixnano for the time since the epoch.
+	TimeFormat string `json:"time_format" yaml:"time_format"`
+	// ConsoleTimeFormat and FileTimeFormat override TimeFormat for the console
+	// and the file, and set the time of the JSON format there.
+	ConsoleTimeFormat string `json:"console_time_format" yaml:"console_time_format"`
+	FileTimeFormat    string `json:"file_time_format" yaml:"file_time_format"`
+	// TimeZone is the IANA name of the time zone of the entries, like UTC.
+	TimeZone string `json:"time_zone" yaml:"time_zone"`
+	// Dir is the directory of the log files.
+	Dir string `json:"dir" yaml:"dir"`
+	// Prefix is the prefix of the log files of the default logger.
+	Prefix *string `json:"prefix" yaml:"prefix"`
+	// Console is where the default logger prints: stdout, stderr, split for
+	// warnings and errors to stderr and the rest to stdout, or none.
+	Console string `json:"console" yaml:"console"`
+	// Rotation configures the rotation of the log files.
+	Rotation RotationConfig `json:"rotation" yaml:"rotation"`
+	// Remote are the collectors the entries are streamed to.
+	Remote []RemoteConfig `json:"remote" yaml:"remote"`
+	// Include are configuration files read first, the settings of the file
+	// including them overriding theirs, relative to its directory.
+	Include []string `json:"include" yaml:"include"`
+	// Profiles are named variants of the configuration, like dev, staging and
+	// prod, the one of the TOLOG_PROFILE environment variable overriding the
+	// settings it sets when the file is read.
+	Profiles map[string]Config `json:"profiles" yaml:"profiles"`
+}
+
+// ProfileEnv is the environment variable selecting the profile of the
+// configuration files.
+const ProfileEnv = "TOLOG_PROFILE"
+
+// RotationConfig configures the rotation of the log files, see SetMaxFileSize.
+type RotationConfig struct {
+	MaxSize    int64 `json:"max_size" yaml:"max_size"` // bytes
+	MaxLines   int64 `json:"max_lines" yaml:"max_lines"`
+	MaxBackups int   `json:"max_backups" yaml:"max_backups"`
+	MaxAge     int   `json:"max_age" yaml:"max_age"` // days
+	Compress   *bool `json:"compress" yaml:"compress"`
+}
+
+// RemoteConfig is a collector the entries are streamed to, see AddRemoteSink.
+type RemoteConfig struct {
+	Network string    `json:"network" yaml:"network"`
+	Address string    `json:"address" yaml:"address"`
+	Format  LogFormat `json:"format" yaml:"format"`
+}
+
+// LoadConfig reads the configuration file, YAML or JSON by its extension,
+// after the files it includes, with ${NAME} replaced by the environment
+// variable of the name, ${NAME:-default} for a default, and applies it with
+// the profile of TOLOG_PROFILE, if set. Nothing is applied when a file is
+// invalid or has profiles but not the one selected.
+func LoadConfig(path string) error {
+	var cfg Config
+	if err := cfg.readFile(path); err != nil {
+		return err
+	}
+	return cfg.Apply()
+}
+
+// readFile reads the configuration file into c, overriding the settings it sets.
+func (c *Config) readFile(path string) error {
+	return c.readFileIncluded(path, nil)
+}
+
+// readFileIncluded reads the configuration file into c after the files it
+// includes, the files including it listed in parents.
+func (c *Config) readFileIncluded(path string, parents []string) error {
+	for _, p := range parents {
+		if p == path {
+			return fmt.Errorf("tolog: %s includes itself", path)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data = []byte(expandEnv(string(data)))
+	var includes struct {
+		Include []string `json:"include" yaml:"include"`
+	}
+	if err := decodeConfig(path, data, &includes, false); err != nil {
+		return err
+	}
+	for _, include := range includes.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		if err := c.readFileIncluded(filepath.Clean(include), append(parents, path)); err != nil {
+			return err
+		}
+	}
+	if err := decodeConfig(path, data, c, true); err != nil {
+		return err
+	}
+	c.Include = nil
+	if err := c.selectProfile(os.Getenv(ProfileEnv)); err != nil {
+		return fmt.Errorf("tolog: %s: %w", path, err)
+	}
+	return nil
+}
+
+// decodeConfig decodes the configuration file into v, YAML or JSON by its
+// extension, failing on unknown fields if strict.
+func decodeConfig(path string, data []byte, v any, strict bool) error {
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if strict {
+			dec.DisallowUnknownFields()
+		}
+		err = dec.Decode(v)
+	default:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(strict)
+		if err = dec.Decode(v); errors.Is(err, io.EOF) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("tolog: parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+// expandEnv replaces ${NAME} with the environment variable of the name, and
+// ${NAME:-default} with the default when it is unset or empty. Other dollar
+// signs are left as they are.
+func expandEnv(s string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			break
+		}
+		sb.WriteString(s[:start])
+		name, def, _ := strings.Cut(s[start+2:start+end], ":-")
+		value := os.Getenv(name)
+		if value == "" {
+			value = def
+		}
+		sb.WriteString(value)
+		s = s[start+end+1:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
+// selectProfile overrides the settings of c with those the profile sets and
+// drops the profiles. A file without profiles has no profile to select.
+func (c *Config) selectProfile(name string) error {
+	profiles := c.Profiles
+	c.Profiles = nil
+	if name == "" || len(profiles) == 0 {
+		return nil
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q", name)
+	}
+	profile.Profiles = nil
+	overlay(reflect.ValueOf(c).Elem(), reflect.ValueOf(profile))
+	return nil
+}
+
+// overlay sets the fields of dst to those of src which are set, field by
+// field in nested structs.
+func overlay(dst, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		f := src.Field(i)
+		switch {
+		case f.Kind() == reflect.Struct:
+			overlay(dst.Field(i), f)
+		case !f.IsZero():
+			dst.Field(i).Set(f)
+		}
+	}
+}
+
+// ConfigureFromEnv applies the TOLOG_LEVEL, TOLOG_DIR, TOLOG_FORMAT and
+// TOLOG_COLOR environment variables, those not set are left as they are.
+func ConfigureFromEnv() error {
+	var cfg Config
+	cfg.readEnv()
+	return cfg.Apply()
+}
+
+// readEnv overrides the settings of c with the environment variables which are set.
+func (c *Config) readEnv() {
+	if v := os.Getenv("TOLOG_LEVEL"); v != "" {
+		c.Level = LogStatus(v)
+	}
+	if v := os.Getenv("TOLOG_DIR"); v != "" {
+		c.Dir = v
+	}
+	if v := os.Getenv("TOLOG_FORMAT"); v != "" {
+		c.Format = LogFormat(v)
+	}
+	if v := os.Getenv("TOLOG_COLOR"); v != "" {
+		c.Color = v
+	}
+}
+
+// Apply validates the configuration and applies it, nothing is applied when
+// it is invalid.
+func (c Config) Apply() error {
+	var errs []error
+	levels := []*LogStatus{&c.Level, &c.ConsoleLevel, &c.FileLevel}
+	for _, level := range levels {
+		if *level == "" {
+			continue
+		}
+		parsed, err := ParseLevel(string(*level))
+		if err != nil {
+			errs = append(errs, err)
+		}
+		*level = parsed
+	}
+	for _, format := range []LogFormat{c.Format, c.ConsoleFormat, c.FileFormat} {
+		if format != "" && !knownFormat(format) {
+			errs = append(errs, fmt.Errorf("tolog: unknown format %q", format))
+		}
+	}
+	for _, r := range c.Remote {
+		if r.Format != "" && !knownFormat(r.Format) {
+			errs = append(errs, fmt.Errorf("tolog: unknown format %q", r.Format))
+		}
+		if r.Address == "" {
+			errs = append(errs, errors.New("tolog: remote without address"))
+		}
+	}
+	color, err := parseColorMode(c.Color)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	var zone *time.Location
+	if c.TimeZone != "" {
+		if zone, err = time.LoadLocation(c.TimeZone); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	var console io.Writer
+	switch strings.ToLower(c.Console) {
+	case "", "stdout", "split":
+		console = os.Stdout
+	case "stderr":
+		console = os.Stderr
+	case "none":
+		console = io.Discard
+	default:
+		errs = append(errs, fmt.Errorf("tolog: unknown console %q", c.Console))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if c.Level != "" {
+		SetConsoleLevel(c.Level)
+		SetFileLevel(c.Level)
+	}
+	if c.ConsoleLevel != "" {
+		SetConsoleLevel(c.ConsoleLevel)
+	}
+	if c.FileLevel != "" {
+		SetFileLevel(c.FileLevel)
+	}
+	if c.Format != "" {
+		SetLogFormat(c.Format)
+	}
+	if c.ConsoleFormat != "" {
+		SetConsoleFormat(c.ConsoleFormat)
+	}
+	if c.FileFormat != "" {
+		SetFileFormat(c.FileFormat)
+	}
+	if c.Color != "" {
+		SetColorMode(color)
+	}
+	if c.TimeFormat != "" {
+		SetLogTimeFormat(DateFormat(c.TimeFormat))
+	}
+	if c.ConsoleTimeFormat != "" {
+		SetConsoleTimeFormat(DateFormat(c.ConsoleTimeFormat))
+	}
+	if c.FileTimeFormat != "" {
+		SetFileTimeFormat(DateFormat(c.FileTimeFormat))
+	}
+	if zone != nil {
+		SetLogTimeZone(zone)
+	}
+	if c.Dir != "" {
+		SetLogDir(c.Dir)
+	}
+	if c.Prefix != nil {
+		SetLogPrefix(*c.Prefix)
+	}
+	if c.Console != "" {
+		SetConsole(console)
+		SetSplitStdStreams(strings.EqualFold(c.Console, "split"))
+	}
+	c.Rotation.apply()
+	for _, r := range c.Remote {
+		format := r.Format
+		if format == "" {
+			format = FormatJSON
+		}
+		network := r.Network
+		if network == "" {
+			network = "tcp"
+		}
+		AddRemoteSink(network, r.Address, format)
+	}
+	return nil
+}
+
+// apply applies the rotation settings which are set.
+func (r RotationConfig) apply() {
+	if r.MaxSize != 0 {
+		SetMaxFileSize(r.MaxSize)
+	}
+	if r.MaxLines != 0 {
+		SetMaxFileLines(r.MaxLines)
+	}
+	if r.MaxBackups != 0 {
+		SetMaxBackups(r.MaxBackups)
+	}
+	if r.MaxAge != 0 {
+		SetMaxAge(r.MaxAge)
+	}
+	if r.Compress != nil {
+		SetCompression(*r.Compress)
+	}
+}
+
+// knownFormat reports whether the format is one of the package or registered.
+func knownFormat(format LogFormat) bool {
+	switch format {
+	case FormatText, FormatJSON, FormatECS, FormatGCP:
+		return true
+	}
+	return encoderFor(format) != nil
+}
+
+// parseColorMode parses auto, always or never, or a boolean, empty is auto.
+func parseColorMode(s string) (ColorMode, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return ColorAuto, nil
+	case "always":
+		return ColorAlways, nil
+	case "never":
+		return ColorNever, nil
+	}
+	enabled, err := strconv.ParseBool(s)
+	if err != nil {
+		return ColorAuto, fmt.Errorf("tolog: unknown color mode %q", s)
+	}
+	if enabled {
+		return ColorAlways, nil
+	}
+	return ColorNever, nil
+}
@@ -0,0 +1,166 @@
+package tolog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetConfig restores the settings LoadConfig and ConfigureFromEnv change in the tests.
+func resetConfig() {
+	SetConsoleLevel("")
+	SetFileLevel("")
+	SetLogFormat(FormatText)
+	SetConsoleFormat("")
+	SetFileFormat("")
+	SetConsoleTimeFormat("")
+	SetFileTimeFormat("")
+	SetColorMode(ColorAuto)
+	SetLogTimeZone(time.Local)
+	SetLogDir(testLogDir)
+	SetMaxFileSize(0)
+	SetMaxBackups(0)
+	SetCompression(false)
+}
+
+func TestLoadConfig(t *testing.T) {
+	defer resetConfig()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logging.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+level: warning
+file_level: debug
+format: json
+console_format: text
+color: never
+time_zone: UTC
+dir: `+filepath.Join(dir, "logs")+`
+rotation:
+  max_size: 1048576
+  max_backups: 3
+  compress: true
+`), 0644))
+	require.NoError(t, LoadConfig(path))
+	assert.Equal(t, StatusWarning, consoleLevel())
+	assert.Equal(t, StatusDebug, fileLevel())
+	assert.Equal(t, FormatJSON, globalSettings().format)
+	assert.Equal(t, FormatText, globalSettings().consoleFormat)
+	assert.Equal(t, ColorNever, globalSettings().colorMode)
+	assert.Equal(t, time.UTC, globalTimeZone())
+	assert.Equal(t, filepath.Join(dir, "logs"), logDirectory())
+	assert.Equal(t, int64(1048576), maxFileSize)
+	assert.Equal(t, int64(3), maxBackups)
+
+	lg := NewLogger("TestLoadConfig")
+	lg.Error("in the configured dir").WriteSafe()
+	require.NoError(t, lg.Close())
+	assert.FileExists(t, filepath.Join(dir, "logs", "TestLoadConfig-log-"+currentDay()+".log"))
+
+	jsonPath := filepath.Join(dir, "logging.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"level": "nope", "format": "xml", "colour": "always"}`), 0644))
+	assert.ErrorContains(t, LoadConfig(jsonPath), "unknown field")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"level": "nope", "format": "xml", "console": "printer"}`), 0644))
+	err := LoadConfig(jsonPath)
+	assert.ErrorIs(t, err, ErrInvalidLevel)
+	assert.ErrorContains(t, err, `unknown format "xml"`)
+	assert.ErrorContains(t, err, `unknown console "printer"`)
+	assert.Equal(t, StatusWarning, consoleLevel(), "nothing applied")
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	defer resetConfig()
+	t.Setenv("TOLOG_LEVEL", "ERROR")
+	t.Setenv("TOLOG_FORMAT", "ecs")
+	t.Setenv("TOLOG_COLOR", "false")
+	require.NoError(t, ConfigureFromEnv())
+	assert.Equal(t, StatusError, consoleLevel())
+	assert.Equal(t, StatusError, fileLevel())
+	assert.Equal(t, FormatECS, globalSettings().format)
+	assert.Equal(t, ColorNever, globalSettings().colorMode)
+	assert.Equal(t, testLogDir, logDirectory(), "left as is")
+
+	t.Setenv("TOLOG_COLOR", "sometimes")
+	assert.Error(t, ConfigureFromEnv())
+}
+
+func TestConfigProfiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logging.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+level: info
+format: text
+rotation:
+  max_size: 1024
+  max_backups: 3
+profiles:
+  dev:
+    level: debug
+  prod:
+    format: json
+    rotation:
+      max_size: 4096
+    remote:
+      - address: collector:5140
+`), 0644))
+
+	var base Config
+	require.NoError(t, base.readFile(path))
+	assert.Equal(t, StatusInfo, base.Level)
+	assert.Nil(t, base.Profiles)
+
+	t.Setenv(ProfileEnv, "prod")
+	var prod Config
+	require.NoError(t, prod.readFile(path))
+	assert.Equal(t, StatusInfo, prod.Level)
+	assert.Equal(t, FormatJSON, prod.Format)
+	assert.Equal(t, RotationConfig{MaxSize: 4096, MaxBackups: 3}, prod.Rotation)
+	assert.Equal(t, []RemoteConfig{{Address: "collector:5140"}}, prod.Remote)
+
+	t.Setenv(ProfileEnv, "dev")
+	jsonPath := filepath.Join(dir, "logging.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"level": "warning", "profiles": {"dev": {"level": "debug"}}}`), 0644))
+	var dev Config
+	require.NoError(t, dev.readFile(jsonPath))
+	assert.Equal(t, StatusDebug, dev.Level)
+
+	t.Setenv(ProfileEnv, "staging")
+	assert.ErrorContains(t, LoadConfig(path), `unknown profile "staging"`)
+}
+
+func TestConfigInclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "base"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base", "logging.yaml"), []byte(`
+level: info
+format: json
+dir: ${LOG_DIR:-/var/log/app}
+rotation:
+  max_size: 1024
+`), 0644))
+	path := filepath.Join(dir, "service.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"include": ["base/logging.yaml"], "level": "${LEVEL}", "rotation": {"max_backups": 2}}`), 0644))
+
+	t.Setenv("LEVEL", "debug")
+	var c Config
+	require.NoError(t, c.readFile(path))
+	assert.Equal(t, StatusDebug, c.Level)
+	assert.Equal(t, FormatJSON, c.Format)
+	assert.Equal(t, "/var/log/app", c.Dir)
+	assert.Equal(t, RotationConfig{MaxSize: 1024, MaxBackups: 2}, c.Rotation)
+	assert.Nil(t, c.Include)
+
+	t.Setenv("LOG_DIR", "/srv/logs")
+	c = Config{}
+	require.NoError(t, c.readFile(path))
+	assert.Equal(t, "/srv/logs", c.Dir)
+
+	assert.Equal(t, "a $HOME ${ b", expandEnv("a $HOME ${ b"))
+
+	loop := filepath.Join(dir, "loop.yaml")
+	require.NoError(t, os.WriteFile(loop, []byte("include: [loop.yaml]\n"), 0644))
+	assert.ErrorContains(t, LoadConfig(loop), "includes itself")
+}
@@ -0,0 +1,129 @@
+package tolog
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestIDField is the field of the correlation ID of a request, set by the
+// httplog middleware from the request header.
+const RequestIDField = "request_id"
+
+// The fields of the trace and span an entry belongs to, attached by the
+// extractors of tracing libraries like otellog.
+const (
+	TraceIDField      = "trace_id"
+	SpanIDField       = "span_id"
+	TraceSampledField = "trace_sampled"
+)
+
+// ContextExtractor pulls request metadata out of a context as fields.
+type ContextExtractor func(ctx context.Context) Fields
+
+var contextExtractors []ContextExtractor
+var contextExtractorsMu sync.RWMutex
+
+// RegisterContextExtractor registers an extractor which is run for every entry
+// given a context, so frameworks can attach their request metadata automatically.
+func RegisterContextExtractor(extractor ContextExtractor) {
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+	contextExtractors = append(contextExtractors, extractor)
+}
+
+// ResetContextExtractors unregisters all context extractors.
+func ResetContextExtractors() {
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+	contextExtractors = nil
+}
+
+// fieldsFromContext returns the fields carried by the context and those of
+// every registered extractor.
+func fieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	contextExtractorsMu.RLock()
+	defer contextExtractorsMu.RUnlock()
+	fields := Fields(nil).merge(ContextFields(ctx))
+	for _, extractor := range contextExtractors {
+		fields = fields.merge(extractor(ctx))
+	}
+	return fields
+}
+
+// Ctx attaches the fields extracted from the context to an existing ToLog instance,
+// and binds it to the request buffer of the context if any.
+func (l *ToLog) Ctx(ctx context.Context) *ToLog {
+	l = l.mutable()
+	l.bindContext(ctx)
+	CreateFullLog(l)
+	return l
+}
+
+// bindContext merges the fields of the context and binds the request buffer.
+func (l *ToLog) bindContext(ctx context.Context) {
+	l.fields = l.fields.merge(fieldsFromContext(ctx))
+	if rb := requestBufferFromContext(ctx); rb != nil {
+		l.buffer = rb
+	}
+}
+
+type contextFieldsKey struct{}
+type contextLoggerKey struct{}
+
+// WithContextFields returns a copy of the context carrying the fields in
+// addition to those it already carries, so middleware can stash a request or
+// trace ID once and every entry logged with the context includes it.
+func WithContextFields(ctx context.Context, fields Fields) context.Context {
+	merged := Fields(nil).merge(ContextFields(ctx)).merge(fields)
+	return context.WithValue(ctx, contextFieldsKey{}, merged)
+}
+
+// ContextFields returns the fields carried by the context.
+func ContextFields(ctx context.Context) Fields {
+	fields, _ := ctx.Value(contextFieldsKey{}).(Fields)
+	return fields
+}
+
+// NewContext returns a copy of the context carrying the logger, for FromContext.
+func NewContext(ctx context.Context, lg *Logger) context.Context {
+	return context.WithValue(ctx, contextLoggerKey{}, lg)
+}
+
+// FromContext returns a logger writing like the logger carried by the context,
+// or the default logger, whose entries include the fields of the context and
+// belong to its request buffer. It shares the file of that logger and needs no Close.
+func FromContext(ctx context.Context) *Logger {
+	lg, _ := ctx.Value(contextLoggerKey{}).(*Logger)
+	if lg == nil {
+		lg = std
+	}
+	return lg.derive(ctx)
+}
+
+// InfoCtx sets the log type to "info", the log context and the fields extracted from ctx.
+func InfoCtx(ctx context.Context, msg string) *ToLog {
+	return std.InfoCtx(ctx, msg)
+}
+
+// WarningCtx sets the log type to "warning", the log context and the fields extracted from ctx.
+func WarningCtx(ctx context.Context, msg string) *ToLog {
+	return std.WarningCtx(ctx, msg)
+}
+
+// ErrorCtx sets the log type to "error", the log context and the fields extracted from ctx.
+func ErrorCtx(ctx context.Context, msg string) *ToLog {
+	return std.ErrorCtx(ctx, msg)
+}
+
+// NoticeCtx sets the log type to "notice", the log context and the fields extracted from ctx.
+func NoticeCtx(ctx context.Context, msg string) *ToLog {
+	return std.NoticeCtx(ctx, msg)
+}
+
+// DebugCtx sets the log type to "debug", the log context and the fields extracted from ctx.
+func DebugCtx(ctx context.Context, msg string) *ToLog {
+	return std.DebugCtx(ctx, msg)
+}
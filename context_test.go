@@ -0,0 +1,67 @@
+package tolog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type routeKey struct{}
+
+func TestContextExtractor(t *testing.T) {
+	RegisterContextExtractor(func(ctx context.Context) Fields {
+		route, ok := ctx.Value(routeKey{}).(string)
+		if !ok {
+			return nil
+		}
+		return Fields{"route": route}
+	})
+	defer ResetContextExtractors()
+
+	ctx := context.WithValue(context.Background(), routeKey{}, "/users/{id}")
+
+	l := InfoCtx(ctx, "request handled")
+	assert.Equal(t, Fields{"route": "/users/{id}"}, l.fields)
+	assert.Contains(t, l.FullLog, "request handled route=/users/{id}")
+
+	l = Warning("no route").Ctx(context.Background())
+	assert.Empty(t, l.fields)
+	assert.NotContains(t, l.FullLog, "route=")
+}
+
+func TestContextFields(t *testing.T) {
+	ctx := WithContextFields(context.Background(), Fields{"request_id": "r1"})
+	ctx = WithContextFields(ctx, Fields{"trace_id": "t1"})
+	assert.Equal(t, Fields{"request_id": "r1", "trace_id": "t1"}, ContextFields(ctx))
+	assert.Nil(t, ContextFields(context.Background()))
+
+	l := InfoCtx(ctx, "handled")
+	assert.Equal(t, "r1", l.fields["request_id"])
+	assert.Equal(t, "t1", l.fields["trace_id"])
+}
+
+func TestFromContext(t *testing.T) {
+	lg := NewLogger("TestFromContext")
+	defer lg.Close()
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	assert.Equal(t, "", FromContext(context.Background()).Prefix())
+
+	ctx := NewContext(context.Background(), lg)
+	ctx = WithContextFields(ctx, Fields{"request_id": "r2"})
+	reqLog := FromContext(ctx)
+	assert.Equal(t, "TestFromContext", reqLog.Prefix())
+	assert.Equal(t, lg.FilePath(), reqLog.FilePath())
+
+	reqLog.Warning("slow query").WriteSafe()
+	assert.NoError(t, reqLog.Close())
+	lg.CloseFile()
+	defer os.Remove(lg.FilePath())
+
+	assert.Contains(t, out.String(), "slow query request_id=r2")
+	checkMessageExistInFile(t, lg.FilePath(), "slow query request_id=r2")
+}
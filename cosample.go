@@ -0,0 +1,34 @@
+package tolog
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// The highest level logged only for sampled traces, none if empty.
+var coSampleLevel atomic.Value // LogStatus
+
+// SetTraceCoSampling logs the entries of the level and below only when their
+// trace is sampled, as told by their trace_sampled field, attached by otellog
+// from the context. SetTraceCoSampling(StatusDebug) keeps the full detail of
+// the sampled requests and drops the debug entries of the others. Entries
+// without the field are logged. Empty turns it off, the default.
+func SetTraceCoSampling(level LogStatus) {
+	coSampleLevel.Store(level)
+}
+
+// traceUnsampled reports whether the entry is dropped because its trace isn't sampled.
+func (l *ToLog) traceUnsampled() bool {
+	level, _ := coSampleLevel.Load().(LogStatus)
+	if level == "" || severity(l.logType) > severity(level) {
+		return false
+	}
+	switch sampled := l.fields[TraceSampledField].(type) {
+	case bool:
+		return !sampled
+	case string:
+		b, err := strconv.ParseBool(sampled)
+		return err == nil && !b
+	}
+	return false
+}
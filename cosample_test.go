@@ -0,0 +1,30 @@
+package tolog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceCoSampling(t *testing.T) {
+	if DebugStripped {
+		t.Skip("co-samples debug entries, stripped by tolog_nodebug")
+	}
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+	SetTraceCoSampling(StatusDebug)
+	defer SetTraceCoSampling("")
+
+	Debug("sampled").WithField(TraceSampledField, true).WriteSafe()
+	Debug("not sampled").WithField(TraceSampledField, false).WriteSafe()
+	Debug("not sampled header").WithField(TraceSampledField, "0").WriteSafe()
+	Debug("no trace").WriteSafe()
+	Info("info of a trace not sampled").WithField(TraceSampledField, false).WriteSafe()
+
+	var msgs []string
+	for _, e := range sink.entries {
+		msgs = append(msgs, e.Message)
+	}
+	assert.Equal(t, []string{"sampled", "no trace", "info of a trace not sampled"}, msgs)
+}
@@ -0,0 +1,114 @@
+package tolog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CountersMessage is the message of the entries of Count and Gauge.
+const CountersMessage = "counters"
+
+var (
+	countersMu      sync.RWMutex
+	counters        = map[string]*int64{}
+	gauges          = map[string]int64{}
+	countersSince   = time.Now()
+	counterInterval = int64(time.Minute)
+	countersStarted int32
+)
+
+// Count adds one to the counter of the name, like cache.miss. The counters are
+// written every minute, see SetCounterInterval, in an info entry with the
+// counters group holding the counts since the last one and the gauges group
+// holding the last value of the gauges set since then, for rough metrics
+// without a metrics stack.
+func Count(name string) {
+	CountN(name, 1)
+}
+
+// CountN adds n to the counter of the name, see Count.
+func CountN(name string, n int64) {
+	startCounters()
+	countersMu.RLock()
+	c := counters[name]
+	countersMu.RUnlock()
+	if c == nil {
+		countersMu.Lock()
+		if c = counters[name]; c == nil {
+			c = new(int64)
+			counters[name] = c
+		}
+		countersMu.Unlock()
+	}
+	atomic.AddInt64(c, n)
+}
+
+// Gauge sets the gauge of the name, like queue.depth, written with the
+// counters, see Count.
+func Gauge(name string, value int64) {
+	startCounters()
+	countersMu.Lock()
+	gauges[name] = value
+	countersMu.Unlock()
+}
+
+// SetCounterInterval sets how often the counters are written, one minute by
+// default. Zero or less writes them only on FlushCounters and Shutdown.
+func SetCounterInterval(d time.Duration) {
+	atomic.StoreInt64(&counterInterval, int64(d))
+}
+
+// FlushCounters writes the counters and gauges now, if any changed since they
+// were last written.
+func FlushCounters() {
+	counts := Fields{}
+	values := Fields{}
+	countersMu.Lock()
+	for name, c := range counters {
+		if n := atomic.SwapInt64(c, 0); n != 0 {
+			counts[name] = n
+		}
+	}
+	for name, v := range gauges {
+		values[name] = v
+	}
+	gauges = map[string]int64{}
+	since := countersSince
+	countersSince = time.Now()
+	countersMu.Unlock()
+	if len(counts) == 0 && len(values) == 0 {
+		return
+	}
+	fields := Fields{"interval": time.Since(since).Round(time.Millisecond).String()}
+	if len(counts) > 0 {
+		fields["counters"] = counts
+	}
+	if len(values) > 0 {
+		fields["gauges"] = values
+	}
+	Info(CountersMessage).Fields(fields).WriteSafe()
+}
+
+// startCounters starts the goroutine writing the counters, once.
+func startCounters() {
+	if atomic.LoadInt32(&countersStarted) == 1 || !atomic.CompareAndSwapInt32(&countersStarted, 0, 1) {
+		return
+	}
+	go func() {
+		for {
+			step := time.Second
+			if d := time.Duration(atomic.LoadInt64(&counterInterval)); d > 0 && d < step {
+				step = d
+			}
+			time.Sleep(step)
+			d := time.Duration(atomic.LoadInt64(&counterInterval))
+			countersMu.RLock()
+			due := d > 0 && time.Since(countersSince) >= d
+			countersMu.RUnlock()
+			if due {
+				FlushCounters()
+			}
+		}
+	}()
+}
@@ -0,0 +1,40 @@
+package tolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounters(t *testing.T) {
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+	defer CloseLogFile()
+	FlushCounters()
+
+	Count("cache.miss")
+	Count("cache.miss")
+	CountN("bytes", 512)
+	Gauge("queue.depth", 7)
+	Gauge("queue.depth", 3)
+	FlushCounters()
+	FlushCounters()
+	if assert.Len(t, sink.entries, 1, "nothing changed since") {
+		e := sink.entries[0]
+		assert.Equal(t, CountersMessage, e.Message)
+		assert.Equal(t, Fields{"cache.miss": int64(2), "bytes": int64(512)}, e.Fields["counters"])
+		assert.Equal(t, Fields{"queue.depth": int64(3)}, e.Fields["gauges"])
+		assert.NotEmpty(t, e.Fields["interval"])
+	}
+
+	SetCounterInterval(20 * time.Millisecond)
+	defer SetCounterInterval(time.Minute)
+	Count("cache.hit")
+	assert.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return len(sink.entries) == 2
+	}, 2*time.Second, 5*time.Millisecond, "written periodically")
+}
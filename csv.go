@@ -0,0 +1,79 @@
+package tolog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"time"
+)
+
+// FormatCSV and FormatTSV encode entries as comma and tab separated values,
+// the time, level, message and the fields as key=value pairs, to open the log
+// files in spreadsheets. Register a CSVEncoder with columns for chosen fields.
+const (
+	FormatCSV LogFormat = "csv"
+	FormatTSV LogFormat = "tsv"
+)
+
+func init() {
+	RegisterEncoder(FormatCSV, CSVEncoder{})
+	RegisterEncoder(FormatTSV, CSVEncoder{Comma: '\t'})
+}
+
+// CSVEncoder encodes entries as separated values: the time, level and message,
+// then a column per key of Columns holding that field, empty if the entry has
+// none. Fields of groups are named with dotted keys, like http.status. Without
+// Columns the last column holds all the fields like the text format.
+type CSVEncoder struct {
+	// Columns lists the keys of the fields written in their own column.
+	Columns []string
+	// Comma separates the values, a comma if zero.
+	Comma rune
+}
+
+// Encode encodes the entry as a record of separated values.
+func (c CSVEncoder) Encode(e *Entry) ([]byte, error) {
+	record := make([]string, 0, 4+len(c.Columns))
+	record = append(record, e.Time.Format(time.RFC3339Nano), string(e.Level), e.Message)
+	if c.Columns == nil {
+		record = append(record, renderFields(e.Fields))
+	} else {
+		f := normalizeFields(e.Fields).flatten()
+		for _, k := range c.Columns {
+			v, ok := f[k]
+			if !ok {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, plainValue(v))
+		}
+	}
+	return c.write(record)
+}
+
+// Header returns the header record of the columns, to start a file or an export with.
+func (c CSVEncoder) Header() []byte {
+	header := []string{"time", "level", "msg"}
+	if c.Columns == nil {
+		header = append(header, "fields")
+	}
+	header = append(header, c.Columns...)
+	line, _ := c.write(header)
+	return line
+}
+
+// write encodes the record, without the line break.
+func (c CSVEncoder) write(record []string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if c.Comma != 0 {
+		w.Comma = c.Comma
+	}
+	if err := w.Write(record); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), newline), nil
+}
@@ -0,0 +1,38 @@
+package tolog
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVEncoder(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   StatusWarning,
+		Message: `slow "query", retrying`,
+		Fields:  Fields{"user_id": 42, "http": Fields{"status": 504}},
+	}
+	data, err := CSVEncoder{}.Encode(e)
+	assert.NoError(t, err)
+	assert.Equal(t, `2024-01-02T03:04:05Z,warning,"slow ""query"", retrying",http.status=504 user_id=42`, string(data))
+	assert.Equal(t, "time,level,msg,fields", string(CSVEncoder{}.Header()))
+
+	enc := CSVEncoder{Columns: []string{"user_id", "http.status", "missing"}, Comma: '\t'}
+	data, err = enc.Encode(e)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-02T03:04:05Z\twarning\t\"slow \"\"query\"\", retrying\"\t42\t504\t", string(data))
+	assert.Equal(t, "time\tlevel\tmsg\tuser_id\thttp.status\tmissing", string(enc.Header()))
+
+	e.Message = "two\nlines"
+	data, _ = CSVEncoder{}.Encode(e)
+	record, err := csv.NewReader(strings.NewReader(string(data))).Read()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "two\nlines", record[2])
+	}
+	assert.True(t, knownFormat(FormatCSV))
+	assert.True(t, knownFormat(FormatTSV))
+}
@@ -0,0 +1,105 @@
+package tolog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// deduper coalesces the consecutive repeats of an entry of a logger within a
+// window into one line, like the sampler does for repeats at any distance.
+type deduper struct {
+	mu     sync.Mutex
+	window time.Duration
+	runs   map[*Logger]*dedupRun
+}
+
+// dedupRun is the entry last logged by a logger and its repeats since.
+type dedupRun struct {
+	level   LogStatus
+	message string
+	start   time.Time
+	repeats int
+	last    *ToLog // the last repeat, written with the count
+	emit    func(*ToLog)
+	timer   *time.Timer // forgets the run when the window closes
+}
+
+var dedup = &deduper{runs: map[*Logger]*dedupRun{}}
+
+// SetDedupWindow coalesces the entries repeating the level and message of the
+// entry logged just before them by the same logger, within the window starting
+// with that entry. The first is written at once, the repeats are written as one
+// line with the fields of the last and the message suffixed with their count,
+// like "connection refused (x999)", when the window closes or another message
+// arrives. Zero turns it off, the default.
+func SetDedupWindow(window time.Duration) {
+	dedup.mu.Lock()
+	dedup.window = window
+	var pending []func()
+	for lg, run := range dedup.runs {
+		pending = append(pending, dedup.flush(lg, run))
+	}
+	dedup.mu.Unlock()
+	writeSummaries(pending)
+}
+
+// dedup reports whether the entry repeats the previous one of its logger and
+// is coalesced, emit writes the coalesced line like the entry would have been.
+func (l *ToLog) dedup(emit func(*ToLog)) bool {
+	if l.summary {
+		return false
+	}
+	d := dedup
+	d.mu.Lock()
+	if d.window <= 0 {
+		d.mu.Unlock()
+		return false
+	}
+	now := time.Now()
+	lg := l.log().root()
+	run := d.runs[lg]
+	if run != nil && run.level == l.logType && run.message == l.logContext && now.Sub(run.start) < d.window {
+		run.repeats++
+		l.frozen = true
+		run.last = l.clone()
+		run.emit = emit
+		d.mu.Unlock()
+		return true
+	}
+	var coalesced func()
+	if run != nil {
+		coalesced = d.flush(lg, run)
+	}
+	run = &dedupRun{level: l.logType, message: l.logContext, start: now}
+	// the run is forgotten when its window closes, writing its repeats if any
+	run.timer = time.AfterFunc(d.window, func() {
+		d.mu.Lock()
+		var coalesced func()
+		if d.runs[lg] == run {
+			coalesced = d.flush(lg, run)
+		}
+		d.mu.Unlock()
+		writeSummaries([]func(){coalesced})
+	})
+	d.runs[lg] = run
+	d.mu.Unlock()
+	// the repeats are written before the entry ending them
+	writeSummaries([]func(){coalesced})
+	return false
+}
+
+// flush forgets the run, returning the function writing its repeats, nil if
+// there were none, d.mu must be held.
+func (d *deduper) flush(lg *Logger, run *dedupRun) func() {
+	delete(d.runs, lg)
+	run.timer.Stop()
+	if run.repeats == 0 {
+		return nil
+	}
+	line := run.last
+	line.logContext = fmt.Sprintf("%s (x%d)", line.logContext, run.repeats)
+	line.summary = true
+	emit := run.emit
+	return func() { emit(line) }
+}
@@ -0,0 +1,63 @@
+package tolog
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupWindow(t *testing.T) {
+	lg := NewLogger("TestDedupWindow")
+	defer lg.Close()
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+	SetDedupWindow(time.Hour)
+	defer SetDedupWindow(0)
+
+	for i := 0; i < 5; i++ {
+		lg.Error("connection refused").WithField("attempt", i).WriteSafe()
+	}
+	lg.Warning("connection refused").WriteSafe()
+	lg.Info("connected").WriteSafe()
+	lg.Info("connected").WriteSafe()
+
+	SetDedupWindow(20 * time.Millisecond)
+	lg.Info("tick").WriteSafe()
+	lg.Info("tick").WriteSafe()
+	lg.Info("tick").WriteSafe()
+	assert.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return len(sink.entries) == 7
+	}, time.Second, 5*time.Millisecond)
+
+	var msgs []string
+	for _, e := range sink.entries {
+		msgs = append(msgs, e.Message)
+	}
+	assert.Equal(t, []string{
+		"connection refused", "connection refused (x4)", "connection refused",
+		"connected", "connected (x1)",
+		"tick", "tick (x2)",
+	}, msgs)
+	assert.Equal(t, 4, sink.entries[1].Fields["attempt"])
+}
+
+func TestDedupWindowForgetsRuns(t *testing.T) {
+	SetDedupWindow(10 * time.Millisecond)
+	defer SetDedupWindow(0)
+	for i := 0; i < 3; i++ {
+		lg := NewLogger("TestDedupWindowForgetsRuns")
+		lg.SetConsole(io.Discard)
+		lg.Info("once").PrintLog()
+		lg.Close()
+	}
+	assert.Eventually(t, func() bool {
+		dedup.mu.Lock()
+		defer dedup.mu.Unlock()
+		return len(dedup.runs) == 0
+	}, time.Second, 5*time.Millisecond, "runs without repeats kept after their window")
+}
@@ -0,0 +1,49 @@
+package tolog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Diagnostic is an action of the logger itself, like a flush or a rotation.
+type Diagnostic struct {
+	Time    time.Time
+	Event   string // open, flush, rotate, reopen, close, drop or sink
+	Message string
+}
+
+// String formats the diagnostic as a single line.
+func (d Diagnostic) String() string {
+	return "[" + d.Time.Format(string(globalTimeFormat())) + "] [tolog] " + d.Event + ": " + d.Message
+}
+
+var diagnostics atomic.Value // func(Diagnostic)
+
+// SetDiagnostics sets a callback receiving the actions of the logger itself,
+// to investigate where entries went. Nil disables diagnostics, the default.
+func SetDiagnostics(fn func(d Diagnostic)) {
+	diagnostics.Store(fn)
+}
+
+// StderrDiagnostics prints diagnostics to stderr, use it with SetDiagnostics.
+func StderrDiagnostics(d Diagnostic) {
+	fmt.Fprintln(stderr(), d.String())
+}
+
+// diag reports an action of the logger when diagnostics are enabled, and as a
+// debug message of tolog itself when enabled by SetInternalLevel.
+func diag(event string, format string, a ...any) {
+	fn, _ := diagnostics.Load().(func(Diagnostic))
+	internal := internalEnabled(StatusDebug)
+	if fn == nil && !internal {
+		return
+	}
+	d := Diagnostic{Time: time.Now().In(globalTimeZone()), Event: event, Message: fmt.Sprintf(format, a...)}
+	if fn != nil {
+		fn(d)
+	}
+	if internal {
+		logInternal(StatusDebug, d.Message, Fields{EventField: d.Event})
+	}
+}
@@ -0,0 +1,31 @@
+package tolog
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnostics(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	SetDiagnostics(func(d Diagnostic) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, d.Event)
+	})
+	defer SetDiagnostics(nil)
+
+	lg := NewLogger("TestDiagnostics")
+	lg.Info("diagnosed").WriteSafe()
+	lg.Close()
+
+	sub := Subscribe(0)
+	sub.WriteEntry(&Entry{})
+	sub.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"reopen", "open", "flush", "close", "drop"}, events)
+}
@@ -0,0 +1,114 @@
+package tolog
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// DiffField is the field Diff writes the changes in.
+const DiffField = "diff"
+
+// Diff adds the changes from before to after in the diff field, keyed by the
+// dotted path of every value which changed, with its from and to values, e.g.
+// to audit a change of configuration without writing both versions:
+//
+//	lg.Notice("config reloaded").Diff(old, cfg).PrintAndWriteSafe()
+//	// diff.limits.rps.from=100 diff.limits.rps.to=250
+//
+// The values are compared as they encode to JSON, so the paths are the JSON
+// names of struct fields and the indexes of slices. Added values have no
+// from, removed values no to. Nothing is added when they are equal.
+func (l *ToLog) Diff(before, after any) *ToLog {
+	changes, err := diffValues(before, after)
+	if err != nil {
+		changes = Fields{ErrorField: err.Error()}
+	}
+	if len(changes) == 0 {
+		return l
+	}
+	l = l.mutable()
+	l.addFields(Fields{DiffField: changes})
+	CreateFullLog(l)
+	return l
+}
+
+// diffValues returns the changes between the values encoded to JSON.
+func diffValues(before, after any) (Fields, error) {
+	a, err := jsonTree(before)
+	if err != nil {
+		return nil, err
+	}
+	b, err := jsonTree(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := Fields{}
+	diffTree(changes, "", a, b)
+	return changes, nil
+}
+
+// jsonTree returns the value decoded from its JSON encoding, made of maps,
+// slices and scalars.
+func jsonTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	err = json.Unmarshal(data, &tree)
+	return tree, err
+}
+
+// diffTree adds the changes between a and b at the path to changes.
+func diffTree(changes Fields, path string, a, b any) {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			for k, av := range a {
+				bv, ok := b[k]
+				if !ok {
+					changes[joinPath(path, k)] = Fields{"from": av}
+					continue
+				}
+				diffTree(changes, joinPath(path, k), av, bv)
+			}
+			for k, bv := range b {
+				if _, ok := a[k]; !ok {
+					changes[joinPath(path, k)] = Fields{"to": bv}
+				}
+			}
+			return
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				key := joinPath(path, strconv.Itoa(i))
+				switch {
+				case i >= len(b):
+					changes[key] = Fields{"from": a[i]}
+				case i >= len(a):
+					changes[key] = Fields{"to": b[i]}
+				default:
+					diffTree(changes, key, a[i], b[i])
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		key := path
+		if key == "" {
+			key = "value"
+		}
+		changes[key] = Fields{"from": a, "to": b}
+	}
+}
+
+// joinPath appends the key to the dotted path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,41 @@
+package tolog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	type limits struct {
+		RPS   int `json:"rps"`
+		Burst int `json:"burst"`
+	}
+	type config struct {
+		Name   string            `json:"name"`
+		Limits limits            `json:"limits"`
+		Hosts  []string          `json:"hosts"`
+		Labels map[string]string `json:"labels,omitempty"`
+	}
+	before := config{Name: "api", Limits: limits{RPS: 100, Burst: 10}, Hosts: []string{"a", "b"}, Labels: map[string]string{"team": "core"}}
+	after := config{Name: "api", Limits: limits{RPS: 250, Burst: 10}, Hosts: []string{"a", "c", "d"}}
+
+	l := Notice("config reloaded").Diff(before, after)
+	assert.Equal(t, Fields{
+		"limits.rps": Fields{"from": float64(100), "to": float64(250)},
+		"hosts.1":    Fields{"from": "b", "to": "c"},
+		"hosts.2":    Fields{"to": "d"},
+		"labels":     Fields{"from": map[string]any{"team": "core"}},
+	}, l.fields[DiffField])
+	assert.Contains(t, l.FullLog, "diff.limits.rps.from=100 diff.limits.rps.to=250")
+
+	l = Info("unchanged").Diff(before, before)
+	assert.NotContains(t, l.fields, DiffField)
+
+	l = Info("scalars").Diff(1, "one")
+	assert.Equal(t, Fields{"value": Fields{"from": float64(1), "to": "one"}}, l.fields[DiffField])
+
+	l = Info("invalid").Diff(math.Inf(1), 0)
+	assert.Contains(t, l.fields[DiffField], ErrorField)
+}
@@ -0,0 +1,40 @@
+package tolog
+
+import (
+	"reflect"
+	"time"
+)
+
+// ECSVersion is the Elastic Common Schema version written in ecs.version.
+const ECSVersion = "8.11.0"
+
+// encodeECS encodes the entry as an Elastic Common Schema JSON object. An error
+// field becomes error.message and error.type, an error_type field error.type,
+// a stack or stack_trace field error.stack_trace, other fields are kept at the
+// top level.
+func encodeECS(l *ToLog) string {
+	obj := map[string]any{
+		"@timestamp":  l.time.UTC().Format(time.RFC3339Nano),
+		"log.level":   string(l.logType),
+		"message":     l.logContext,
+		"ecs.version": ECSVersion,
+	}
+	for k, v := range normalizeFields(l.fields) {
+		switch k {
+		case "error":
+			if err, ok := v.(error); ok {
+				obj["error.message"] = err.Error()
+				obj["error.type"] = reflect.TypeOf(err).String()
+				continue
+			}
+			obj["error.message"] = jsonValue(v)
+		case ErrorTypeField:
+			obj["error.type"] = jsonValue(v)
+		case "stack", "stack_trace":
+			obj["error.stack_trace"] = jsonValue(v)
+		default:
+			obj[k] = jsonValue(v)
+		}
+	}
+	return marshalLine(obj)
+}
@@ -0,0 +1,32 @@
+package tolog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECSFormat(t *testing.T) {
+	SetLogFormat(FormatECS)
+	defer SetLogFormat(FormatText)
+	RegisterContextExtractor(func(ctx context.Context) Fields {
+		return Fields{"error": errors.New("disk full"), "stack": "main.go:12", "user": "bob"}
+	})
+	defer ResetContextExtractors()
+
+	l := ErrorCtx(context.Background(), "write failed")
+
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal([]byte(l.FullLog), &obj))
+	assert.Equal(t, "error", obj["log.level"])
+	assert.Equal(t, "write failed", obj["message"])
+	assert.Equal(t, "disk full", obj["error.message"])
+	assert.Equal(t, "*errors.errorString", obj["error.type"])
+	assert.Equal(t, "main.go:12", obj["error.stack_trace"])
+	assert.Equal(t, "bob", obj["user"])
+	assert.NotEmpty(t, obj["@timestamp"])
+}
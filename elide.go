@@ -0,0 +1,188 @@
+package tolog
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+var fieldElision int32
+
+// SetFieldElision shortens the text lines written to the log files in a
+// batch: the leading fields a line shares with the line above, like the
+// request_id of the entries of a request, are replaced by ^N, N being their
+// number. Fields are sorted by key, the shared ones elide best when their keys
+// come first. ExpandElidedFields restores the lines, taking a message ending
+// with such a marker for one. Lines of other formats, and all lines while a
+// flush hook is set, are written as they are.
+func SetFieldElision(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&fieldElision, v)
+}
+
+// fieldPairs returns the fields rendered like appendFields, one per pair, if
+// field elision is enabled.
+func fieldPairs(f Fields) []string {
+	if atomic.LoadInt32(&fieldElision) == 0 || len(f) == 0 {
+		return nil
+	}
+	f = normalizeFields(f).flatten()
+	pairs := make([]string, 0, len(f))
+	buf := getBuffer()
+	defer putBuffer(buf)
+	for _, k := range f.sortedKeys() {
+		buf.Reset()
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		appendFieldValue(buf, f[k])
+		pairs = append(pairs, buf.String())
+	}
+	return pairs
+}
+
+// elideFields returns the lines of the batch with the fields shared with the
+// line above elided, the lines as they are if elision is disabled.
+func elideFields(batch []writeItem, lines []string) []string {
+	if atomic.LoadInt32(&fieldElision) == 0 || loadFlushHook() != nil || len(batch) != len(lines) {
+		return lines
+	}
+	var prev []string // the pairs of the line above, nil if it cannot be referred to
+	for i, item := range batch {
+		body, ending := splitLineEnding(lines[i])
+		_, trailing := trailingPairs(body)
+		if len(item.pairs) == 0 || !equalStrings(trailing, item.pairs) {
+			// not a text line, or one whose message ends like a field
+			prev = nil
+			continue
+		}
+		n := 0
+		for n < len(prev) && n < len(item.pairs) && prev[n] == item.pairs[n] {
+			n++
+		}
+		prev = item.pairs
+		marker := "^" + strconv.Itoa(n)
+		if n == 0 || len(strings.Join(item.pairs[:n], " ")) <= len(marker) {
+			continue
+		}
+		head := strings.TrimSuffix(body, strings.Join(item.pairs, " "))
+		lines[i] = head + strings.Join(append([]string{marker}, item.pairs[n:]...), " ") + ending
+	}
+	return lines
+}
+
+// ExpandElidedFields returns a reader of the lines of r with the fields elided
+// by SetFieldElision restored, for tools reading the log files.
+func ExpandElidedFields(r io.Reader) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		w := bufio.NewWriter(pw)
+		var prev []string
+		for scanner.Scan() {
+			line := expandLine(scanner.Text(), prev)
+			_, prev = trailingPairs(strings.TrimSuffix(line, "\r"))
+			w.WriteString(line)
+			w.WriteByte('\n')
+		}
+		err := scanner.Err()
+		if ferr := w.Flush(); err == nil {
+			err = ferr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// expandLine replaces the ^N marker of the line with the first N pairs of the
+// line above.
+func expandLine(line string, prev []string) string {
+	body := strings.TrimSuffix(line, "\r")
+	start, pairs := trailingPairs(body)
+	head := strings.TrimSuffix(body[:start], " ")
+	i := strings.LastIndexByte(head, ' ')
+	n, err := strconv.Atoi(strings.TrimPrefix(head[i+1:], "^"))
+	if !strings.HasPrefix(head[i+1:], "^") || err != nil || n <= 0 || n > len(prev) {
+		return line
+	}
+	restored := append(append([]string(nil), prev[:n]...), pairs...)
+	return head[:i+1] + strings.Join(restored, " ") + line[len(body):]
+}
+
+// trailingPairs returns the key=value pairs ending the line and the index
+// they start at, values quoted like strconv.Quote kept whole.
+func trailingPairs(line string) (int, []string) {
+	start := len(line)
+	var pairs []string
+	for pos := 0; pos < len(line); {
+		end := tokenEnd(line, pos)
+		token := line[pos:end]
+		if isPair(token) {
+			if pairs == nil {
+				start = pos
+			}
+			pairs = append(pairs, token)
+		} else {
+			pairs = nil
+			start = len(line)
+		}
+		pos = end + 1
+	}
+	return start, pairs
+}
+
+// tokenEnd returns the index of the space ending the token at pos, or the
+// length of the line, skipping over quoted values.
+func tokenEnd(line string, pos int) int {
+	quoted := false
+	for i := pos; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"' && i > pos && line[i-1] == '=' && !quoted:
+			quoted = true
+		case c == '"' && quoted:
+			quoted = false
+		case c == ' ' && !quoted:
+			return i
+		}
+	}
+	return len(line)
+}
+
+// isPair reports whether the token is a key=value pair like appendFields writes.
+func isPair(token string) bool {
+	key, value, ok := strings.Cut(token, "=")
+	if !ok || key == "" || strings.ContainsAny(key, `"^`) {
+		return false
+	}
+	if strings.HasPrefix(value, `"`) {
+		_, err := strconv.Unquote(value)
+		return err == nil
+	}
+	return value != ""
+}
+
+// splitLineEnding splits the line ending off the line.
+func splitLineEnding(line string) (string, string) {
+	body := strings.TrimRight(line, "\r\n")
+	return body, line[len(body):]
+}
+
+// equalStrings reports whether the slices hold the same strings.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
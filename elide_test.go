@@ -0,0 +1,70 @@
+package tolog
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFieldElision(t *testing.T) {
+	SetBatchSize(1000)
+	defer SetBatchSize(0)
+	SetLogTickerTime(time.Hour)
+	defer SetLogTickerTime(500 * time.Millisecond)
+	lg := NewLogger("TestSetFieldElision")
+	defer lg.Close()
+	path := lg.FilePath()
+	os.Remove(path)
+	defer os.Remove(path)
+
+	write := func() {
+		req := lg.With(Fields{"a_request_id": "8f3a2c", "a_user": "alice smith"})
+		req.Info("start").Fields(Fields{"step": 1}).WriteSafe()
+		req.Info("query").Fields(Fields{"step": 2}).WriteSafe()
+		req.Info("done").WriteSafe()
+		req.Info("ends like a=field").WriteSafe()
+		req.Info("after").WriteSafe()
+		lg.Info("short").Fields(Fields{"a": 1}).WriteSafe()
+		lg.Info("short").Fields(Fields{"a": 1}).WriteSafe()
+		require.NoError(t, lg.Flush())
+	}
+	write()
+	plain, err := os.ReadFile(path)
+	require.NoError(t, err)
+	os.Truncate(path, 0)
+
+	SetFieldElision(true)
+	defer SetFieldElision(false)
+	write()
+	elided, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(elided), "\n"), "\n")
+	require.Len(t, lines, 7)
+	assert.True(t, strings.HasSuffix(lines[0], `start a_request_id=8f3a2c a_user="alice smith" step=1`), lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], "query ^2 step=2"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[2], "done ^2"), lines[2])
+	assert.True(t, strings.HasSuffix(lines[3], `ends like a=field a_request_id=8f3a2c a_user="alice smith"`), "message ending like a field: %s", lines[3])
+	assert.True(t, strings.HasSuffix(lines[4], `after a_request_id=8f3a2c a_user="alice smith"`), "line above not referable: %s", lines[4])
+	assert.True(t, strings.HasSuffix(lines[6], "short ^1"), lines[6])
+	assert.Less(t, len(elided), len(plain))
+
+	expanded, err := io.ReadAll(ExpandElidedFields(strings.NewReader(string(elided))))
+	require.NoError(t, err)
+	strip := func(s string) string {
+		var out []string
+		for _, line := range strings.Split(s, "\n") {
+			if _, rest, ok := strings.Cut(line, "] "); ok {
+				line = rest
+			}
+			out = append(out, line)
+		}
+		return strings.Join(out, "\n")
+	}
+	assert.Equal(t, strip(string(plain)), strip(string(expanded)))
+}
@@ -0,0 +1,65 @@
+package tolog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+var emitCheck int32
+
+// SetEmitCheck enables a development check reporting entries which were created
+// but never emitted because PrintLog, WriteSafe or Msg was forgotten at the end
+// of the chain. Such entries are reported with ErrNotEmitted and the location
+// they were created at to the error handler once garbage collected. The check
+// captures the stack of every entry, do not enable it in production.
+func SetEmitCheck(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&emitCheck, v)
+}
+
+// watchEmit reports the entry if it is garbage collected before being emitted.
+func (l *ToLog) watchEmit() {
+	if atomic.LoadInt32(&emitCheck) == 0 {
+		return
+	}
+	site := creationSite()
+	runtime.SetFinalizer(l, func(l *ToLog) {
+		if !l.emitted {
+			handleError(fmt.Errorf("%w: created at %s, add .WriteSafe(), .PrintLog() or .Msg()", ErrNotEmitted, site))
+		}
+	})
+}
+
+// creationSite returns the location of the first caller outside of the package.
+func creationSite() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		inPackage := strings.HasPrefix(frame.Function, "github.com/callme-taota/tolog.") &&
+			!strings.HasSuffix(frame.File, "_test.go")
+		if !inPackage || !more {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+	}
+}
+
+// Msg sets the message and writes the entry with WriteSafe, ending a chain
+// started with Log or a level function in one call.
+func (l *ToLog) Msg(msg string) {
+	l = l.Context(msg)
+	l.captureSite(1)
+	l.WriteSafe()
+}
+
+// Msgf sets the formatted message and writes the entry with WriteSafe.
+func (l *ToLog) Msgf(format string, a ...any) {
+	l = l.Context(fmt.Sprintf(format, a...))
+	l.captureSite(1)
+	l.WriteSafe()
+}
@@ -0,0 +1,65 @@
+package tolog
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsg(t *testing.T) {
+	lg := NewLogger("TestMsg")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	SetReportCaller(true)
+	defer SetReportCaller(false)
+
+	lg.Log(WithType(StatusWarning)).WithField("k", "v").Msg("ended with msg")
+	lg.Log().Msgf("ended with %s", "msgf")
+	lg.CloseFile()
+
+	content, err := os.ReadFile(lg.FilePath())
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "ended with msg")
+	assert.Contains(t, string(content), "ended with msgf")
+	assert.Contains(t, string(content), "function=github.com/callme-taota/tolog.TestMsg")
+}
+
+func forgetTerminator() {
+	Info("forgotten").WithField("k", "v")
+}
+
+func TestEmitCheck(t *testing.T) {
+	var mu sync.Mutex
+	var errs []error
+	SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+	defer SetErrorHandler(nil)
+	SetEmitCheck(true)
+	defer SetEmitCheck(false)
+
+	lg := NewLogger("TestEmitCheck")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	lg.Info("emitted").WriteSafe()
+	forgetTerminator()
+
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) > 0
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, errs, 1)
+	assert.True(t, errors.Is(errs[0], ErrNotEmitted))
+	assert.Contains(t, errs[0].Error(), "emit_test.go:33")
+}
@@ -0,0 +1,63 @@
+package tolog
+
+// LevelEnabler is implemented by sinks receiving only the entries of some
+// levels, which are then not handed the others.
+type LevelEnabler interface {
+	Enabled(level LogStatus) bool
+}
+
+// Enabled reports whether entries of the level of the default logger are
+// written or printed anywhere, see Logger.Enabled.
+func Enabled(level LogStatus) bool {
+	return std.Enabled(level)
+}
+
+// Enabled reports whether entries of the level of the logger are written or
+// printed anywhere, to guard expensive preparation of entries:
+//
+//	if lg.Enabled(tolog.StatusDebug) {
+//		lg.Debug(dump(state)).PrintAndWriteSafe()
+//	}
+//
+// It checks the console and file levels, the level of the logger name and the
+// sinks, not sampling or the schema, which may still drop the entry.
+func (lg *Logger) Enabled(level LogStatus) bool {
+	if DebugStripped && level == StatusDebug {
+		return false
+	}
+	if !levelEnabled(level, loggerLevel(lg.name)) {
+		return false
+	}
+	if levelEnabled(level, consoleLevel()) || levelEnabled(level, fileLevel()) {
+		return true
+	}
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, s := range sinks {
+		if sinkEnabled(s, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// SinkLevel returns the sink receiving only the entries of the level and above.
+func SinkLevel(sink Sink, min LogStatus) Sink {
+	return &levelSink{Sink: sink, min: min}
+}
+
+// levelSink is a sink with a minimum level.
+type levelSink struct {
+	Sink
+	min LogStatus
+}
+
+func (s *levelSink) Enabled(level LogStatus) bool {
+	return levelEnabled(level, s.min) && sinkEnabled(s.Sink, level)
+}
+
+// sinkEnabled reports whether the sink receives entries of the level.
+func sinkEnabled(s Sink, level LogStatus) bool {
+	e, ok := s.(LevelEnabler)
+	return !ok || e.Enabled(level)
+}
@@ -0,0 +1,36 @@
+package tolog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnabled(t *testing.T) {
+	assert.Equal(t, !DebugStripped, Enabled(StatusDebug))
+
+	SetLevel(StatusWarning)
+	defer SetLevel("")
+	assert.False(t, Enabled(StatusInfo))
+	assert.True(t, Enabled(StatusError))
+
+	sink := &memorySink{}
+	leveled := SinkLevel(sink, StatusInfo)
+	AddSink(leveled)
+	defer RemoveSink(leveled)
+	assert.False(t, Enabled(StatusDebug))
+	assert.True(t, Enabled(StatusInfo))
+
+	lg := GetLogger("TestEnabled")
+	SetLoggerLevel("TestEnabled", StatusError)
+	defer SetLoggerLevel("TestEnabled", "")
+	assert.False(t, lg.Enabled(StatusWarning))
+	assert.True(t, lg.Enabled(StatusError))
+	assert.True(t, Enabled(StatusWarning))
+
+	Debug("below the sink level").WriteSafe()
+	Info("at the sink level").WriteSafe()
+	if assert.Len(t, sink.entries, 1) {
+		assert.Equal(t, "at the sink level", sink.entries[0].Message)
+	}
+}
@@ -0,0 +1,96 @@
+package tolog
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+// Encoder encodes entries into the lines of a format registered with
+// RegisterEncoder. Lines must not end with a newline, which is added when
+// they are written.
+type Encoder interface {
+	Encode(e *Entry) ([]byte, error)
+}
+
+// EncoderFunc adapts a function to the Encoder interface.
+type EncoderFunc func(e *Entry) ([]byte, error)
+
+// Encode calls the function.
+func (f EncoderFunc) Encode(e *Entry) ([]byte, error) {
+	return f(e)
+}
+
+// TextEncoder encodes entries in the default text layout of FormatText.
+type TextEncoder struct{}
+
+// Encode encodes the entry as [time] [level]  message key=value.
+func (TextEncoder) Encode(e *Entry) ([]byte, error) {
+	l := &ToLog{
+		logType:    e.Level,
+		logContext: e.Message,
+		logTime:    formatTime(e.Time.In(globalTimeZone()), globalTimeFormat()),
+		time:       e.Time,
+		fields:     e.Fields,
+	}
+	var buf bytes.Buffer
+	appendText(&buf, l, false)
+	return buf.Bytes(), nil
+}
+
+// JSONEncoder encodes entries like FormatJSON.
+type JSONEncoder struct{}
+
+// Encode encodes the entry as {"time":...,"level":...,"msg":...,"fields":{...}}.
+func (JSONEncoder) Encode(e *Entry) ([]byte, error) {
+	return encodeEntryJSON(e), nil
+}
+
+var (
+	encoders   atomic.Value // map[LogFormat]Encoder, replaced as a whole on change
+	encodersMu sync.Mutex
+)
+
+// RegisterEncoder registers the encoder of the format, so SetLogFormat and the
+// other format setters can select it, replacing the built-in encoder of the
+// format if any. Lines the encoder fails on are written in the text format.
+func RegisterEncoder(format LogFormat, enc Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	old, _ := encoders.Load().(map[LogFormat]Encoder)
+	m := make(map[LogFormat]Encoder, len(old)+1)
+	for f, e := range old {
+		m[f] = e
+	}
+	if enc == nil {
+		delete(m, format)
+	} else {
+		m[format] = enc
+	}
+	encoders.Store(m)
+}
+
+// encoderFor returns the registered encoder of the format, nil if none is.
+func encoderFor(format LogFormat) Encoder {
+	m, _ := encoders.Load().(map[LogFormat]Encoder)
+	return m[format]
+}
+
+// appendEncoded appends the entry encoded by the registered encoder of the
+// format to the buffer, reporting whether there is one. The entry is written
+// in the text format if the encoder fails.
+func appendEncoded(buf *bytes.Buffer, l *ToLog, format LogFormat) bool {
+	enc := encoderFor(format)
+	if enc == nil {
+		return false
+	}
+	data, err := enc.Encode(l.entry())
+	if err != nil {
+		diag("encode", "%T failed on an entry, writing it as text: %v", enc, err)
+		handleError(err)
+		appendText(buf, l, false)
+		return true
+	}
+	buf.Write(data)
+	return true
+}
@@ -0,0 +1,47 @@
+package tolog
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterEncoder(t *testing.T) {
+	const formatPipe LogFormat = "pipe"
+	RegisterEncoder(formatPipe, EncoderFunc(func(e *Entry) ([]byte, error) {
+		if e.Message == "" {
+			return nil, errors.New("empty message")
+		}
+		return []byte(fmt.Sprintf("%s|%s|%v", e.Level, e.Message, e.Fields["user"])), nil
+	}))
+	defer RegisterEncoder(formatPipe, nil)
+	assert.True(t, knownFormat(formatPipe))
+
+	lg := NewLogger("TestRegisterEncoder")
+	defer lg.Close()
+	lg.SetFormat(formatPipe)
+	assert.Equal(t, "warning|slow|bob", lg.Warning("slow").WithField("user", "bob").FullLog)
+
+	var handled error
+	SetErrorHandler(func(err error) { handled = err })
+	defer SetErrorHandler(nil)
+	l := lg.Info("")
+	assert.Equal(t, "["+l.logTime+"] [info]  ", l.FullLog)
+	assert.EqualError(t, handled, "empty message")
+
+	RegisterEncoder(formatPipe, nil)
+	assert.False(t, knownFormat(formatPipe))
+}
+
+func TestBuiltinEncoders(t *testing.T) {
+	l := Info("built in").WithField("n", 1)
+	data, err := TextEncoder{}.Encode(l.entry())
+	assert.NoError(t, err)
+	assert.Equal(t, l.FullLog, string(data))
+
+	data, err = JSONEncoder{}.Encode(l.entry())
+	assert.NoError(t, err)
+	assert.Equal(t, encodeLine(l, FormatJSON, false), string(data))
+}
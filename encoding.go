@@ -0,0 +1,83 @@
+package tolog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync/atomic"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// FileEncoding is the character encoding of the log files.
+type FileEncoding int32
+
+const (
+	// EncodingUTF8 writes UTF-8 without a byte order mark, the default.
+	EncodingUTF8 FileEncoding = iota
+	// EncodingUTF8BOM writes UTF-8 starting each file with a byte order mark.
+	EncodingUTF8BOM
+	// EncodingUTF16LE writes little endian UTF-16 starting each file with a
+	// byte order mark, for legacy Windows tools.
+	EncodingUTF16LE
+)
+
+var fileEncoding int32 // FileEncoding
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+)
+
+// SetFileEncoding sets the encoding of the log files. It should be set before
+// the files are opened, a file appended to keeps its byte order mark. ParseLine,
+// Follow and the other readers of the package expect UTF-8.
+func SetFileEncoding(enc FileEncoding) {
+	atomic.StoreInt32(&fileEncoding, int32(enc))
+}
+
+// encodeFile returns the data encoded for a log file with its line ending,
+// starting with the byte order mark if the file is empty.
+func encodeFile(data []byte, empty bool) []byte {
+	if ending := fileLineEnding(); ending != "" && ending != "\n" {
+		data = bytes.ReplaceAll(data, newline, []byte(ending))
+	}
+	switch FileEncoding(atomic.LoadInt32(&fileEncoding)) {
+	case EncodingUTF8BOM:
+		if empty {
+			return append(append([]byte{}, bomUTF8...), data...)
+		}
+	case EncodingUTF16LE:
+		units := utf16.Encode([]rune(string(data)))
+		out := make([]byte, 0, 2+2*len(units))
+		if empty {
+			out = append(out, bomUTF16LE...)
+		}
+		for _, u := range units {
+			out = binary.LittleEndian.AppendUint16(out, u)
+		}
+		return out
+	}
+	return data
+}
+
+// decodedLen returns the number of bytes of the data whose encoding by
+// encodeFile lies within the first n bytes written, so a partial write is
+// retried from there. In other encodings than UTF-8 with LF line endings a
+// character written in part is written again whole.
+func decodedLen(data []byte, n int, empty bool) int {
+	pos := len(encodeFile(nil, empty)) // the byte order mark
+	if n < pos {
+		return 0
+	}
+	if len(encodeFile(data, false)) == len(data) {
+		return n - pos
+	}
+	for i := 0; i < len(data); {
+		_, size := utf8.DecodeRune(data[i:])
+		if pos += len(encodeFile(data[i:i+size], false)); pos > n {
+			return i
+		}
+		i += size
+	}
+	return len(data)
+}
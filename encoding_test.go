@@ -0,0 +1,57 @@
+package tolog
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileEncoding(t *testing.T) {
+	defer SetFileEncoding(EncodingUTF8)
+	for _, tc := range []struct {
+		name string
+		enc  FileEncoding
+		want []byte
+	}{
+		{"UTF8", EncodingUTF8, []byte("é\né\n")},
+		{"UTF8BOM", EncodingUTF8BOM, []byte("\xEF\xBB\xBFé\né\n")},
+		{"UTF16LE", EncodingUTF16LE, []byte{0xFF, 0xFE, 0xE9, 0, '\n', 0, 0xE9, 0, '\n', 0}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			SetFileEncoding(tc.enc)
+			lg := NewLogger("TestFileEncoding" + tc.name)
+			defer lg.Close()
+			path := lg.FilePath()
+			os.Remove(path)
+			defer os.Remove(path)
+
+			require.NoError(t, lg.WriteRaw([]byte("é")))
+			require.NoError(t, lg.CloseFile())
+			// appending does not repeat the byte order mark
+			require.NoError(t, lg.WriteRaw([]byte("é")))
+			require.NoError(t, lg.CloseFile())
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, content)
+		})
+	}
+}
+
+func TestDecodedLen(t *testing.T) {
+	defer SetFileEncoding(EncodingUTF8)
+	data := []byte("aé\n")
+	SetFileEncoding(EncodingUTF8BOM)
+	assert.Equal(t, 0, decodedLen(data, 2, true))
+	assert.Equal(t, 2, decodedLen(data, 5, true))
+	assert.Equal(t, 2, decodedLen(data, 2, false))
+	SetFileEncoding(EncodingUTF16LE)
+	// the byte order mark, then two bytes for each rune
+	assert.Equal(t, 0, decodedLen(data, 1, true))
+	assert.Equal(t, 0, decodedLen(data, 3, true))
+	assert.Equal(t, 1, decodedLen(data, 4, true))
+	assert.Equal(t, 3, decodedLen(data, 6, true))
+	assert.Equal(t, 3, decodedLen(data, 4, false))
+	assert.Equal(t, 4, decodedLen(data, 8, true))
+}
@@ -0,0 +1,52 @@
+package tolog
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+var lineEnding atomic.Value // string
+
+// SetLineEnding sets the line ending of the log files, "\n" by default or
+// "\r\n" for Windows tools misrendering LF only files. It applies to every
+// format, to raw lines and to the file markers.
+func SetLineEnding(ending string) error {
+	if err := checkLineEnding(ending); err != nil {
+		return err
+	}
+	lineEnding.Store(ending)
+	return nil
+}
+
+// checkLineEnding returns an error if the ending is neither "\n" nor "\r\n".
+func checkLineEnding(ending string) error {
+	if ending != "\n" && ending != "\r\n" {
+		return fmt.Errorf("tolog: unsupported line ending %q", ending)
+	}
+	return nil
+}
+
+// withLineEnding returns the lines with the ending instead of "\n".
+func withLineEnding(data string, ending string) string {
+	if ending == "" || ending == "\n" {
+		return data
+	}
+	return strings.ReplaceAll(data, "\n", ending)
+}
+
+// fileLineEnding returns the line ending of the log files.
+func fileLineEnding() string {
+	ending, _ := lineEnding.Load().(string)
+	return ending
+}
+
+// SetLineEnding sets the line ending of the lines sent, like the package
+// SetLineEnding for the log files.
+func (s *RemoteSink) SetLineEnding(ending string) error {
+	if err := checkLineEnding(ending); err != nil {
+		return err
+	}
+	s.ending.Store(ending)
+	return nil
+}
@@ -0,0 +1,39 @@
+package tolog
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineEnding(t *testing.T) {
+	require.NoError(t, SetLineEnding("\r\n"))
+	defer SetLineEnding("\n")
+	SetLogFormat(FormatJSON)
+	defer SetLogFormat(FormatText)
+
+	lg := NewLogger("TestLineEnding")
+	defer lg.Close()
+	path := lg.FilePath()
+	defer os.Remove(path)
+
+	lg.Info("entry").WriteSafe()
+	require.NoError(t, lg.WriteRaw([]byte("raw 1\nraw 2")))
+	require.NoError(t, lg.CloseFile())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(content), "\r\n"))
+	assert.Equal(t, 3, strings.Count(string(content), "\n"))
+	e, err := ParseLine(strings.SplitAfter(string(content), "\n")[0])
+	require.NoError(t, err)
+	assert.Equal(t, "entry", e.Message)
+
+	assert.Error(t, SetLineEnding("\r"))
+	remote := NewRemoteSink("udp", "127.0.0.1:1", FormatJSON)
+	defer remote.Close()
+	assert.NoError(t, remote.SetLineEnding("\r\n"))
+	assert.Error(t, remote.SetLineEnding(";"))
+}
@@ -0,0 +1,61 @@
+package tolog
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"hash/fnv"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// EntryIDField is the field of the ID of the entries added by SetEntryIDs.
+const EntryIDField = "entry_id"
+
+var (
+	entryIDs      int32
+	entrySeq      atomic.Uint64
+	hostOnce      sync.Once
+	entryHostName string
+)
+
+// SetEntryIDs sets whether every entry gets an entry_id field, a hash of its
+// time, a sequence number and the host name, or an ID of the IDGenerator if
+// set, so pipelines shipping the entries at least once can drop the repeats.
+// An entry written again keeps its ID.
+func SetEntryIDs(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&entryIDs, v)
+}
+
+// addEntryID adds the entry_id field to the entry when enabled, unless it has one.
+func (l *ToLog) addEntryID() {
+	if atomic.LoadInt32(&entryIDs) == 0 {
+		return
+	}
+	if _, ok := l.fields[EntryIDField]; ok {
+		return
+	}
+	id := newID(func() string { return entryID(l.time.UnixNano(), entrySeq.Add(1), entryHost()) })
+	l.fields = l.fields.merge(Fields{EntryIDField: id})
+}
+
+// entryID returns the 16 hex digits of the hash of the time, the sequence number and the host.
+func entryID(nanos int64, seq uint64, host string) string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(nanos))
+	binary.BigEndian.PutUint64(b[8:], seq)
+	h := fnv.New64a()
+	h.Write(b[:])
+	h.Write([]byte(host))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// entryHost returns the host name, looked up once.
+func entryHost() string {
+	hostOnce.Do(func() { entryHostName, _ = os.Hostname() })
+	return entryHostName
+}
@@ -0,0 +1,36 @@
+package tolog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryIDs(t *testing.T) {
+	SetEntryIDs(true)
+	defer SetEntryIDs(false)
+	lg := NewLogger("TestEntryIDs")
+	defer lg.Close()
+	lg.SetConsole(discardWriter{})
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+
+	entry := lg.Info("shipped")
+	entry.PrintAndWriteSafe()
+	entry.PrintAndWriteSafe()
+	lg.Info("shipped").PrintAndWriteSafe()
+
+	require.Len(t, sink.entries, 3)
+	first, _ := sink.entries[0].Fields[EntryIDField].(string)
+	assert.Len(t, first, 16)
+	assert.Equal(t, first, sink.entries[1].Fields[EntryIDField], "an entry written again keeps its ID")
+	assert.NotEqual(t, first, sink.entries[2].Fields[EntryIDField])
+}
+
+func TestEntryID(t *testing.T) {
+	assert.Equal(t, entryID(1, 2, "host"), entryID(1, 2, "host"))
+	assert.NotEqual(t, entryID(1, 2, "host"), entryID(1, 3, "host"))
+	assert.NotEqual(t, entryID(1, 2, "host"), entryID(1, 2, "other"))
+}
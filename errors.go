@@ -0,0 +1,178 @@
+package tolog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Errors returned by the package, compare them with errors.Is.
+var (
+	// ErrClosed is returned when writing to a closed logger, sink or subscription.
+	ErrClosed = errors.New("tolog: closed")
+	// ErrChannelFull is returned when an entry is dropped because a queue is full.
+	ErrChannelFull = errors.New("tolog: channel full")
+	// ErrInvalidLevel is returned when parsing an unknown level name.
+	ErrInvalidLevel = errors.New("tolog: invalid level")
+	// ErrRotateFailed is returned when switching to the next log file failed.
+	ErrRotateFailed = errors.New("tolog: rotate failed")
+	// ErrNotEmitted is reported by the emit check for an entry never emitted.
+	ErrNotEmitted = errors.New("tolog: entry never emitted")
+)
+
+var errorHandler atomic.Value // func(error)
+
+// SetErrorHandler sets the function called with the errors the logger cannot
+// return to a caller, like a full disk or a missing permission while writing
+// the log file in the background. Nil restores the default, writing them as
+// messages of tolog itself, see SetInternalSink.
+func SetErrorHandler(fn func(err error)) {
+	errorHandler.Store(fn)
+}
+
+// handleError passes the error to the error handler.
+func handleError(err error) {
+	if fn, _ := errorHandler.Load().(func(error)); fn != nil {
+		fn(err)
+		return
+	}
+	logInternal(StatusError, err.Error(), nil)
+}
+
+// multiErrors returns the errors an error joins, like those of errors.Join and
+// of the multierror packages, nil for other errors.
+func multiErrors(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	case interface{ WrappedErrors() []error }:
+		return e.WrappedErrors()
+	case interface{ Errors() []error }:
+		return e.Errors()
+	}
+	return nil
+}
+
+// joinedErrorFields returns the errors joined by an error as a group of fields
+// indexed from 0 with their count, so each can be searched for. The errors
+// printing more with %+v, like those carrying a stack, have it in the stack group.
+func joinedErrorFields(errs []error) Fields {
+	fields := Fields{"count": len(errs)}
+	var stacks Fields
+	for i, err := range errs {
+		key := strconv.Itoa(i)
+		if err == nil {
+			fields[key] = "<nil>"
+			continue
+		}
+		if inner := multiErrors(err); len(inner) > 0 {
+			fields[key] = joinedErrorFields(inner)
+			continue
+		}
+		msg := err.Error()
+		fields[key] = msg
+		if verbose := fmt.Sprintf("%+v", err); verbose != msg {
+			if stacks == nil {
+				stacks = Fields{}
+			}
+			stacks[key] = verbose
+		}
+	}
+	if stacks != nil {
+		fields["stack"] = stacks
+	}
+	return fields
+}
+
+// The fields Err writes the error in, with the stack in StackField.
+const (
+	ErrorField       = "error"
+	ErrorTypeField   = "error_type"
+	ErrorCausesField = "error_causes"
+)
+
+// ErrorFields returns the fields describing the error, keeping its structure
+// instead of a single formatted string: error is the message, error_type the
+// Go type, error_causes the messages of the errors it wraps, depth first, and
+// stack the stack trace of the first of them carrying one, from a StackTrace
+// method like those of github.com/pkg/errors. A nil error has no fields.
+func ErrorFields(err error) Fields {
+	if err == nil {
+		return nil
+	}
+	fields := Fields{
+		ErrorField:     err.Error(),
+		ErrorTypeField: fmt.Sprintf("%T", err),
+	}
+	all := unwrapAll(err)
+	if len(all) > 1 {
+		causes := make([]string, 0, len(all)-1)
+		for _, e := range all[1:] {
+			causes = append(causes, e.Error())
+		}
+		fields[ErrorCausesField] = causes
+	}
+	for _, e := range all {
+		if stack, ok := errorStack(e); ok {
+			fields[StackField] = stack
+			break
+		}
+	}
+	return fields
+}
+
+// Err adds the fields describing the error, see ErrorFields. A nil error adds nothing.
+func (l *ToLog) Err(err error) *ToLog {
+	if err == nil {
+		return l
+	}
+	l = l.mutable()
+	l.addFields(ErrorFields(err))
+	CreateFullLog(l)
+	return l
+}
+
+// ErrorE creates an "error" entry of the default logger with the message of
+// the error and the fields describing it, see ErrorFields.
+func ErrorE(err error) *ToLog {
+	return std.ErrorE(err)
+}
+
+// unwrapAll returns the error and the errors it wraps, depth first, those
+// joined included. Nil errors are left out.
+func unwrapAll(err error) []error {
+	var all []error
+	var walk func(err error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		all = append(all, err)
+		if inner := multiErrors(err); inner != nil {
+			for _, e := range inner {
+				walk(e)
+			}
+			return
+		}
+		walk(errors.Unwrap(err))
+	}
+	walk(err)
+	return all
+}
+
+// errorStack returns the stack trace of the error from its StackTrace method,
+// returning program counters or a type printing the frames with %+v.
+func errorStack(err error) (string, bool) {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return "", false
+	}
+	trace := m.Call(nil)[0].Interface()
+	if pcs, ok := trace.([]uintptr); ok {
+		return formatStack(pcs), true
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%+v", trace), "\n"), true
+}
@@ -0,0 +1,123 @@
+package tolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	level, err := ParseLevel("WARNING")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusWarning, level)
+	level, err = ParseLevel("fatal")
+	assert.ErrorIs(t, err, ErrInvalidLevel)
+	assert.Equal(t, StatusUnknown, level)
+
+	lg := NewLogger("TestSentinelErrors")
+	lg.Close()
+	assert.ErrorIs(t, lg.WriteRaw([]byte("after close")), ErrClosed)
+	lg.Info("after close").WriteSafe()
+
+	sub := Subscribe(1)
+	assert.NoError(t, sub.WriteEntry(&Entry{}))
+	assert.ErrorIs(t, sub.WriteEntry(&Entry{}), ErrChannelFull)
+	sub.Close()
+	assert.ErrorIs(t, sub.WriteEntry(&Entry{}), ErrClosed)
+	sub.Next(context.Background())
+
+	b := newBatcher(1, 0, func([]*Entry) error { return nil })
+	b.close()
+	assert.ErrorIs(t, b.add(&Entry{}), ErrClosed)
+}
+
+func TestErrorHandler(t *testing.T) {
+	var errs []error
+	SetErrorHandler(func(err error) { errs = append(errs, err) })
+	defer SetErrorHandler(nil)
+
+	lg := NewLogger("TestErrorHandler")
+	lg.Info("opened").WriteSafe()
+	assert.NoError(t, lg.CloseFile())
+	os.Remove(lg.FilePath())
+	assert.NoError(t, lg.Close())
+	assert.Empty(t, errs)
+
+	lg.Info("after close").WriteSafe()
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrClosed)
+}
+
+type stackError struct{ msg string }
+
+func (e stackError) Error() string { return e.msg }
+
+func (e stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%s\n\tmain.go:12", e.msg)
+		return
+	}
+	fmt.Fprint(s, e.msg)
+}
+
+func TestJoinedErrors(t *testing.T) {
+	lg := NewLogger("TestJoinedErrors")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	lg.SetColor(false)
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	err := errors.Join(errors.New("disk full"), stackError{"quota exceeded"}, errors.Join(errors.New("a"), errors.New("b")))
+	l := lg.Error("save failed").WithField("error", err)
+	l.WriteSafe()
+	assert.Equal(t, Fields{
+		"count": 3,
+		"0":     "disk full",
+		"1":     "quota exceeded",
+		"2":     Fields{"count": 2, "0": "a", "1": "b"},
+		"stack": Fields{"1": "quota exceeded\n\tmain.go:12"},
+	}, l.fields["error"])
+	assert.Contains(t, out.String(), `error.0="disk full" error.1="quota exceeded" error.2.0=a error.2.1=b error.2.count=2 error.count=3 error.stack.1=`)
+
+	single := lg.Error("x").WithField("error", errors.New("plain"))
+	single.WriteSafe()
+	assert.Equal(t, errors.New("plain"), single.fields["error"])
+}
+
+// tracedError carries a stack like the errors of github.com/pkg/errors.
+type tracedError struct {
+	msg string
+	pcs []uintptr
+}
+
+func (e *tracedError) Error() string         { return e.msg }
+func (e *tracedError) StackTrace() []uintptr { return e.pcs }
+
+func TestErr(t *testing.T) {
+	pcs := make([]uintptr, 8)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	root := &tracedError{msg: "connection refused", pcs: pcs}
+	err := fmt.Errorf("charge card: %w", fmt.Errorf("call gateway: %w", root))
+
+	l := Info("retrying").Err(err)
+	assert.Equal(t, "charge card: call gateway: connection refused", l.fields[ErrorField])
+	assert.Equal(t, "*fmt.wrapError", l.fields[ErrorTypeField])
+	assert.Equal(t, []string{"call gateway: connection refused", "connection refused"}, l.fields[ErrorCausesField])
+	assert.Contains(t, l.fields[StackField], "tolog.TestErr")
+	assert.Same(t, l, l.Err(nil))
+
+	l = ErrorE(errors.New("disk full"))
+	assert.Equal(t, StatusError, l.logType)
+	assert.Equal(t, "disk full", l.logContext)
+	assert.Equal(t, "*errors.errorString", l.fields[ErrorTypeField])
+	assert.NotContains(t, l.fields, ErrorCausesField)
+	assert.NotContains(t, l.fields, StackField)
+}
@@ -0,0 +1,177 @@
+package tolog
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Fields holds structured key/value metadata attached to a log entry.
+type Fields map[string]any
+
+// globalFields holds the Fields applied to every entry, replaced as a whole on change.
+var globalFields atomic.Value
+var globalFieldsMu sync.Mutex
+
+// SetGlobalFields sets the fields applied to every entry, e.g. env or region set
+// once at startup. Fields of the entry itself take precedence. Nil removes them.
+func SetGlobalFields(fields Fields) {
+	globalFieldsMu.Lock()
+	defer globalFieldsMu.Unlock()
+	globalFields.Store(Fields(nil).merge(fields))
+}
+
+// AddGlobalField adds a field applied to every entry.
+func AddGlobalField(key string, value any) {
+	globalFieldsMu.Lock()
+	defer globalFieldsMu.Unlock()
+	current, _ := globalFields.Load().(Fields)
+	globalFields.Store(Fields(nil).merge(current).merge(Fields{key: value}))
+}
+
+// GlobalFields returns a copy of the fields applied to every entry.
+func GlobalFields() Fields {
+	current, _ := globalFields.Load().(Fields)
+	return Fields(nil).merge(current)
+}
+
+// merge copies the given fields into f, overriding existing keys, and returns f.
+func (f Fields) merge(other Fields) Fields {
+	if len(other) == 0 {
+		return f
+	}
+	if f == nil {
+		f = make(Fields, len(other))
+	}
+	for k, v := range other {
+		f[k] = v
+	}
+	return f
+}
+
+// without returns the fields without the keys, f itself if it has none of them.
+func (f Fields) without(keys ...string) Fields {
+	for _, k := range keys {
+		if _, ok := f[k]; ok {
+			out := make(Fields, len(f))
+			for k, v := range f {
+				out[k] = v
+			}
+			for _, k := range keys {
+				delete(out, k)
+			}
+			return out
+		}
+	}
+	return f
+}
+
+// sortedKeys returns the field keys in lexical order.
+func (f Fields) sortedKeys() []string {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renderFields renders the fields as space separated key=value pairs, sorted by key.
+func renderFields(f Fields) string {
+	if len(f) == 0 {
+		return ""
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	appendFields(buf, f)
+	return buf.String()
+}
+
+// appendFields appends the fields rendered like renderFields to the buffer.
+func appendFields(buf *bytes.Buffer, f Fields) {
+	f = normalizeFields(f).flatten()
+	for i, k := range f.sortedKeys() {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		appendFieldValue(buf, f[k])
+	}
+}
+
+// appendFieldValue appends a field value formatted for text output, quoting it
+// when needed. Strings, integers and booleans are formatted without fmt.
+func appendFieldValue(buf *bytes.Buffer, v any) {
+	var num [20]byte
+	var s string
+	switch v := v.(type) {
+	case LogValuer:
+		buf.WriteString(lazyValue)
+		return
+	case string:
+		s = v
+	case int:
+		buf.Write(strconv.AppendInt(num[:0], int64(v), 10))
+		return
+	case int64:
+		buf.Write(strconv.AppendInt(num[:0], v, 10))
+		return
+	case uint64:
+		buf.Write(strconv.AppendUint(num[:0], v, 10))
+		return
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+		return
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		buf.WriteString(strconv.Quote(s))
+		return
+	}
+	buf.WriteString(s)
+}
+
+// KeyValues returns the fields of alternating keys and values, like the
+// arguments of klog and logr. A key without a value gets "(MISSING)".
+func KeyValues(keysAndValues ...any) Fields {
+	fields := make(Fields, len(keysAndValues)/2+1)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprint(keysAndValues[i])
+		if i+1 == len(keysAndValues) {
+			fields[key] = "(MISSING)"
+			break
+		}
+		fields[key] = keysAndValues[i+1]
+	}
+	return fields
+}
+
+// WithFields sets fields using functional options.
+func WithFields(fields Fields) Options {
+	return func(l *ToLog) {
+		l.fields = l.fields.merge(fields)
+	}
+}
+
+// Fields adds the fields to an existing ToLog instance, overriding existing keys,
+// in the current Group if any.
+func (l *ToLog) Fields(fields map[string]any) *ToLog {
+	l = l.mutable()
+	l.addFields(fields)
+	CreateFullLog(l)
+	return l
+}
+
+// WithField adds a field to an existing ToLog instance.
+func (l *ToLog) WithField(key string, value any) *ToLog {
+	l = l.mutable()
+	l.addFields(Fields{key: value})
+	CreateFullLog(l)
+	return l
+}
@@ -0,0 +1,39 @@
+package tolog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderFields(t *testing.T) {
+	assert.Equal(t, "", renderFields(nil))
+	assert.Equal(t, "a=1 b=two", renderFields(Fields{"b": "two", "a": 1}))
+	assert.Equal(t, `empty="" msg="hello world"`, renderFields(Fields{"msg": "hello world", "empty": ""}))
+}
+
+func TestGlobalFields(t *testing.T) {
+	SetGlobalFields(Fields{"env": "prod"})
+	AddGlobalField("region", "eu-west-1")
+	defer SetGlobalFields(nil)
+
+	assert.Equal(t, Fields{"env": "prod", "region": "eu-west-1"}, GlobalFields())
+	l := Info("started")
+	assert.Contains(t, l.FullLog, "started env=prod region=eu-west-1")
+
+	l.fields["env"] = "changed"
+	assert.Equal(t, "prod", GlobalFields()["env"])
+
+	SetGlobalFields(nil)
+	assert.Empty(t, Info("no globals").fields)
+}
+
+func TestEntryFields(t *testing.T) {
+	l := Info("request done").WithField("user_id", 42).Fields(map[string]any{"request_id": "r-1", "latency": "12ms"})
+	assert.Equal(t, Fields{"user_id": 42, "request_id": "r-1", "latency": "12ms"}, l.fields)
+	assert.Contains(t, l.FullLog, "request done latency=12ms request_id=r-1 user_id=42")
+	assert.Equal(t, Fields{"user_id": 42, "request_id": "r-1", "latency": "12ms"}, l.entry().Fields)
+
+	l = Log(WithType(StatusWarning), WithContext("options"), WithFields(Fields{"a": 1}))
+	assert.Equal(t, Fields{"a": 1}, l.fields)
+}
@@ -0,0 +1,135 @@
+package tolog
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+// Filter reports whether an entry is kept, dropping it from every output when
+// false. The entry must not be modified.
+type Filter func(entry *Entry) bool
+
+type redactor struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+var (
+	filtersMu sync.Mutex   // serializes the writers of filters and redactors
+	filters   atomic.Value // []Filter
+	redactors atomic.Value // []redactor
+)
+
+// AddFilter adds a filter run on every entry before it is encoded, after the
+// redactors, so entries can be dropped before reaching the console, the log
+// files and the sinks.
+func AddFilter(filter Filter) {
+	filtersMu.Lock()
+	defer filtersMu.Unlock()
+	old, _ := filters.Load().([]Filter)
+	filters.Store(append(old[:len(old):len(old)], filter))
+}
+
+// AddRedactor replaces the matches of the regular expression in the message and
+// the string, error and fmt.Stringer fields of every entry before it is
+// encoded, e.g. to mask passwords, tokens or card numbers. The replacement can
+// refer to the submatches like regexp.ReplaceAllString. It returns the error of
+// compiling the pattern.
+func AddRedactor(pattern, replacement string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	filtersMu.Lock()
+	defer filtersMu.Unlock()
+	old, _ := redactors.Load().([]redactor)
+	redactors.Store(append(old[:len(old):len(old)], redactor{re: re, replacement: replacement}))
+	return nil
+}
+
+// ClearFilters removes the filters and the redactors.
+func ClearFilters() {
+	filtersMu.Lock()
+	defer filtersMu.Unlock()
+	filters.Store([]Filter(nil))
+	redactors.Store([]redactor(nil))
+}
+
+// filter redacts the entry and reports whether a filter drops it. The lazy
+// values are resolved first, so they are redacted and filtered too.
+func (l *ToLog) filter() bool {
+	rs, _ := redactors.Load().([]redactor)
+	fs, _ := filters.Load().([]Filter)
+	if len(rs) == 0 && len(fs) == 0 {
+		return false
+	}
+	l.resolve()
+	if len(rs) > 0 {
+		l.logContext = redact(rs, l.logContext)
+		if fields, changed := redactFields(rs, l.fields); changed {
+			l.fields = fields
+		}
+	}
+	if len(fs) == 0 {
+		return false
+	}
+	e := l.entry()
+	for _, f := range fs {
+		if !f(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact replaces the matches of the redactors in s.
+func redact(rs []redactor, s string) string {
+	for _, r := range rs {
+		s = r.re.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
+
+// redactFields returns the fields redacted, a copy if any changed, so the fields
+// shared with the logger and other entries are left as they are.
+func redactFields(rs []redactor, fields Fields) (Fields, bool) {
+	var out Fields
+	for k, v := range fields {
+		value, changed := redactValue(rs, v)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = Fields(nil).merge(fields)
+		}
+		out[k] = value
+	}
+	if out == nil {
+		return fields, false
+	}
+	return out, true
+}
+
+// redactValue returns the value redacted, as a string for errors and
+// fmt.Stringers, and whether it changed.
+func redactValue(rs []redactor, v any) (any, bool) {
+	var s string
+	switch val := v.(type) {
+	case Fields:
+		return redactFields(rs, val)
+	case string:
+		s = val
+	case error:
+		s = val.Error()
+	case fmt.Stringer:
+		s = val.String()
+	default:
+		return v, false
+	}
+	if r := redact(rs, s); r != s {
+		return r, true
+	}
+	return v, false
+}
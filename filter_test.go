@@ -0,0 +1,66 @@
+package tolog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRedactor(t *testing.T) {
+	defer ClearFilters()
+	lg := NewLogger("TestAddRedactor")
+	defer lg.Close()
+	var console bytes.Buffer
+	lg.SetConsole(&console)
+	lg.SetColor(false)
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+
+	require.NoError(t, AddRedactor(`password=\S+`, "password=***"))
+	require.NoError(t, AddRedactor(`\b(\d{4})\d{8}(\d{4})\b`, "$1********$2"))
+	assert.Error(t, AddRedactor(`(`, ""))
+
+	base := Fields{"card": "4111111111111111"}
+	lg.With(base).Info("login password=hunter2").
+		WithField("err", errors.New("bad password=hunter2")).
+		Group("user").WithField("note", "password=s3cret").
+		PrintAndWriteSafe()
+
+	assert.NotContains(t, console.String(), "hunter2")
+	assert.NotContains(t, console.String(), "s3cret")
+	assert.Contains(t, console.String(), "4111********1111")
+	require.Len(t, sink.entries, 1)
+	e := sink.entries[0]
+	assert.Equal(t, "login password=***", e.Message)
+	assert.Equal(t, "bad password=***", e.Fields["err"])
+	assert.Equal(t, "password=***", e.Fields["user"].(Fields)["note"])
+	assert.Equal(t, "4111111111111111", base["card"], "the fields of the caller are not modified")
+}
+
+func TestAddFilter(t *testing.T) {
+	defer ClearFilters()
+	lg := NewLogger("TestAddFilter")
+	defer lg.Close()
+	var console bytes.Buffer
+	lg.SetConsole(&console)
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+
+	AddFilter(func(e *Entry) bool { return !strings.HasPrefix(e.Message, "healthz") })
+	AddFilter(func(e *Entry) bool { return e.Fields["secret"] == nil })
+
+	lg.Info("healthz ok").PrintAndWriteSafe()
+	lg.Info("token issued").WithField("secret", "x").PrintAndWriteSafe()
+	lg.Info("kept").PrintAndWriteSafe()
+
+	assert.NotContains(t, console.String(), "healthz")
+	assert.NotContains(t, console.String(), "token issued")
+	require.Len(t, sink.entries, 1)
+	assert.Equal(t, "kept", sink.entries[0].Message)
+}
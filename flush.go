@@ -0,0 +1,131 @@
+package tolog
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Flush writes the entries queued for the log files of all loggers and syncs
+// the files to disk, e.g. before a checkpoint, and the messages of tolog
+// itself queued for the internal sink. Entries written concurrently may or
+// may not be included.
+func Flush() error {
+	flushInternal()
+	var errs []error
+	for _, w := range openWriters() {
+		if err := w.sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flush writes the entries queued for the log file of the logger and syncs it to disk.
+func (lg *Logger) Flush() error {
+	return lg.fileWriter().sync()
+}
+
+// Shutdown writes the counters, flushes and closes the log files of all
+// loggers and the sinks and writes the messages of tolog itself still queued, returning the error of the context if it is done
+// first. The files are reopened if entries are written afterwards.
+func Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		FlushCounters()
+		writeShutdownReport()
+		var errs []error
+		for _, w := range openWriters() {
+			if err := w.close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := CloseSinks(); err != nil {
+			errs = append(errs, err)
+		}
+		flushInternal()
+		done <- errors.Join(errs...)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// openWriters returns the registered writers.
+func openWriters() []*fileWriter {
+	writersMu.Lock()
+	defer writersMu.Unlock()
+	ws := make([]*fileWriter, 0, len(writers))
+	for _, w := range writers {
+		ws = append(ws, w)
+	}
+	return ws
+}
+
+// syncRequest asks the goroutine of a writer to write the queued lines,
+// syncing the file if fsync is set.
+type syncRequest struct {
+	ack   chan error
+	fsync bool
+}
+
+// sync writes the queued lines and syncs the file, see writeOut.
+func (w *fileWriter) sync() error {
+	return w.writeOut(true)
+}
+
+// writeOut writes the queued lines, syncing the file if fsync is set: from the
+// calling goroutine in manual mode, else by asking the goroutine of the writer
+// and waiting until it did. A closed writer has nothing queued.
+func (w *fileWriter) writeOut(fsync bool) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return nil
+	}
+	if w.manual {
+		var buffer []writeItem
+		w.pumpMu.Lock()
+		defer w.pumpMu.Unlock()
+		return w.writeQueued(&buffer, fsync)
+	}
+	req := syncRequest{ack: make(chan error, 1), fsync: fsync}
+	w.syncs <- req
+	return <-req.ack
+}
+
+// writeQueued writes the buffer and the queued lines, then syncs the file if
+// fsync is set, from the goroutine of the writer or holding pumpMu in manual
+// mode.
+func (w *fileWriter) writeQueued(buffer *[]writeItem, fsync bool) error {
+	w.flushUrgent()
+	for w.queued() > 0 {
+		item, ok := w.dequeue()
+		if !ok {
+			continue
+		}
+		*buffer = append(*buffer, item)
+	}
+	if len(*buffer) > 0 || w.pending() {
+		if err := w.flush(buffer); err != nil {
+			return err
+		}
+	}
+	if !fsync {
+		return nil
+	}
+	w.fileMu.Lock()
+	defer w.fileMu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	w.unsynced = 0
+	w.syncedAt = time.Now()
+	return w.file.Sync()
+}
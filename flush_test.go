@@ -0,0 +1,129 @@
+package tolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlush(t *testing.T) {
+	SetLogTickerTime(time.Hour)
+	defer SetLogTickerTime(500 * time.Millisecond)
+	lg := NewLogger("TestFlush")
+	defer lg.Close()
+	path := lg.FilePath()
+	defer os.Remove(path)
+
+	lg.Info("first").WriteSafe()
+	require.NoError(t, lg.Flush())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "first")
+
+	lg.Info("second").WriteSafe()
+	require.NoError(t, Flush())
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "second")
+}
+
+func TestShutdown(t *testing.T) {
+	lg := NewLogger("TestShutdown")
+	defer lg.Close()
+	path := lg.FilePath()
+	defer os.Remove(path)
+
+	lg.Info("before shutdown").WriteSafe()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, Shutdown(ctx))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "before shutdown")
+
+	// writing afterwards reopens the file
+	lg.Info("after shutdown").WriteSafe()
+	require.NoError(t, lg.CloseFile())
+	content, _ = os.ReadFile(path)
+	assert.Equal(t, 2, strings.Count(string(content), "shutdown"))
+
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Shutdown(expired)
+	assert.True(t, err == nil || err == context.Canceled, err)
+}
+
+// shortWriteFS opens files whose next write, once armed, writes a few bytes
+// and fails.
+type shortWriteFS struct {
+	OSFS
+	armed *int32
+}
+
+func (f shortWriteFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	file, err := f.OSFS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return shortWriteFile{file, f.armed}, nil
+}
+
+type shortWriteFile struct {
+	File
+	armed *int32
+}
+
+func (f shortWriteFile) Write(p []byte) (int, error) {
+	if len(p) > 10 && atomic.CompareAndSwapInt32(f.armed, 1, 0) {
+		n, _ := f.File.Write(p[:10])
+		return n, errors.New("no space left on device")
+	}
+	return f.File.Write(p)
+}
+
+func TestFlushShortWrite(t *testing.T) {
+	var armed int32
+	SetFS(shortWriteFS{armed: &armed})
+	defer SetFS(nil)
+	var errs []error
+	SetErrorHandler(func(err error) { errs = append(errs, err) })
+	defer SetErrorHandler(nil)
+	SetAsyncConsole(true)
+	defer SetAsyncConsole(false)
+	lg := NewLogger("TestFlushShortWrite")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var console bytes.Buffer
+	lg.SetConsole(&lockedWriter{w: &console})
+	lg.SetColor(false)
+	require.NoError(t, lg.WriteRaw([]byte("opened")))
+	require.NoError(t, lg.Flush())
+
+	atomic.StoreInt32(&armed, 1)
+	lg.Info("first").PrintAndWriteSafe()
+	lg.Info("second").PrintAndWriteSafe()
+	assert.Error(t, lg.Flush())
+	require.Len(t, errs, 1)
+	require.NoError(t, lg.Flush())
+
+	// the lines were printed once and the retry went on after the bytes written
+	assert.Equal(t, 1, strings.Count(console.String(), "first"))
+	assert.Equal(t, 1, strings.Count(console.String(), "second"))
+	content, err := os.ReadFile(lg.FilePath())
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "opened", lines[0])
+	for i, text := range []string{"first", "second"} {
+		assert.Regexp(t, `^\[\d{4}-\d\d-\d\d \d\d:\d\d:\d\d\] \[info\]  `+text+`$`, lines[i+1])
+	}
+}
@@ -0,0 +1,46 @@
+package tolog
+
+import "time"
+
+// SetFlushOnLevel makes the writing goroutine of a log file write the lines
+// taken from the queue as soon as one is of an entry of the level or above,
+// like an error, instead of waiting for a full batch or the ticker, bounding
+// the context lost before a failure. Empty disables it, the default.
+func SetFlushOnLevel(level LogStatus) {
+	updateSettings(func(s *settings) { s.flushLevel = level })
+}
+
+// SetSyncOnLevel makes the lines of the entries of the level or above flushed
+// like SetFlushOnLevel and the file synced to disk after them, so they survive
+// a crash of the machine. Empty disables it, the default.
+func SetSyncOnLevel(level LogStatus) {
+	updateSettings(func(s *settings) { s.syncLevel = level })
+}
+
+// flushOn reports whether the item makes its writer flush the batch at once,
+// and whether to sync the file after.
+func flushOn(item writeItem) (flush, sync bool) {
+	if item.level == "" {
+		return false, false
+	}
+	s := globalSettings()
+	sync = s.syncLevel != "" && levelEnabled(item.level, s.syncLevel)
+	flush = sync || s.flushLevel != "" && levelEnabled(item.level, s.flushLevel)
+	return flush, sync
+}
+
+// syncNow syncs the file to disk.
+func (w *fileWriter) syncNow() {
+	w.fileMu.Lock()
+	defer w.fileMu.Unlock()
+	if w.file == nil {
+		return
+	}
+	if err := w.file.Sync(); err != nil {
+		diag("sync", "syncing %s failed: %v", w.file.Name(), err)
+		handleError(err)
+		return
+	}
+	w.unsynced = 0
+	w.syncedAt = time.Now()
+}
@@ -0,0 +1,179 @@
+package tolog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Follower tails a log file written by tolog, following the switch to the next
+// dated file of the same prefix as well as truncation and replacement of the file.
+// It is a Source, so a Relay can ship a live log file.
+type Follower struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+	buf     []byte
+	poll    time.Duration
+}
+
+// Follow opens the log file and positions the follower at fromOffset.
+func Follow(path string, fromOffset int64) (*Follower, error) {
+	f := &Follower{poll: 200 * time.Millisecond, buf: make([]byte, 32*1024)}
+	if err := f.open(path, fromOffset); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// SetPollInterval sets how often the file is checked for new data, default 200ms.
+func (f *Follower) SetPollInterval(d time.Duration) {
+	f.poll = d
+}
+
+// Path returns the file currently followed.
+func (f *Follower) Path() string {
+	return f.path
+}
+
+// Offset returns the offset after the last line returned.
+func (f *Follower) Offset() int64 {
+	return f.offset
+}
+
+// Close closes the followed file.
+func (f *Follower) Close() error {
+	return f.file.Close()
+}
+
+// Next returns the entry of the next parsable line, waiting for new lines.
+func (f *Follower) Next(ctx context.Context) (*Entry, error) {
+	for {
+		line, err := f.NextLine(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if e, err := ParseLine(line); err == nil {
+			return e, nil
+		}
+	}
+}
+
+// NextLine returns the next complete line without its newline, waiting for new
+// lines until the context is done.
+func (f *Follower) NextLine(ctx context.Context) (string, error) {
+	for {
+		if i := bytes.IndexByte(f.partial, '\n'); i >= 0 {
+			line := string(f.partial[:i])
+			f.partial = f.partial[i+1:]
+			f.offset += int64(i + 1)
+			return line, nil
+		}
+		n, err := f.file.Read(f.buf)
+		if n > 0 {
+			f.partial = append(f.partial, f.buf[:n]...)
+			continue
+		}
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		switched, err := f.checkRotation()
+		if err != nil {
+			return "", err
+		}
+		if switched {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(f.poll):
+		}
+	}
+}
+
+// checkRotation reopens the file when it was truncated or replaced, and moves
+// to the next dated file once the current one is drained.
+func (f *Follower) checkRotation() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err == nil && !os.SameFile(info, f.info) {
+		return true, f.reopen(f.path)
+	}
+	if err == nil && info.Size() < f.offset+int64(len(f.partial)) {
+		return true, f.reopen(f.path)
+	}
+	if len(f.partial) > 0 {
+		return false, nil
+	}
+	if next := f.successor(); next != "" {
+		return true, f.reopen(next)
+	}
+	return false, nil
+}
+
+// successor returns the oldest file of the same prefix modified after the current one.
+func (f *Follower) successor() string {
+	dir, base := filepath.Split(f.path)
+	prefix := base
+	if i := strings.LastIndex(base, "log-"); i >= 0 {
+		prefix = base[:i+len("log-")]
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, globEscape(prefix)+"*.log"))
+	current, err := f.file.Stat()
+	if err != nil {
+		return ""
+	}
+	next := ""
+	var nextMod time.Time
+	for _, m := range matches {
+		if filepath.Clean(m) == filepath.Clean(f.path) {
+			continue
+		}
+		info, err := os.Stat(m)
+		if err != nil || !info.ModTime().After(current.ModTime()) {
+			continue
+		}
+		if next == "" || info.ModTime().Before(nextMod) {
+			next, nextMod = m, info.ModTime()
+		}
+	}
+	return next
+}
+
+func (f *Follower) reopen(path string) error {
+	f.file.Close()
+	f.partial = f.partial[:0]
+	return f.open(path, 0)
+}
+
+func (f *Follower) open(path string, offset int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if offset > info.Size() {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return err
+	}
+	f.path, f.file, f.info, f.offset = path, file, info, offset
+	return nil
+}
+
+// globEscape escapes the glob meta characters of a literal path part.
+func globEscape(s string) string {
+	return strings.NewReplacer("*", "\\*", "?", "\\?", "[", "\\[").Replace(s)
+}
@@ -0,0 +1,57 @@
+package tolog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollow(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "app-log-2024-01-01.log")
+	second := filepath.Join(dir, "app-log-2024-01-02.log")
+	require.NoError(t, os.WriteFile(first, []byte("skipped\none\ntw"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other-log-2024-01-03.log"), []byte("other\n"), 0644))
+
+	f, err := Follow(first, int64(len("skipped\n")))
+	require.NoError(t, err)
+	defer f.Close()
+	f.SetPollInterval(time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	line, err := f.NextLine(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "one", line)
+	assert.Equal(t, int64(len("skipped\none\n")), f.Offset())
+
+	file, err := os.OpenFile(first, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString("o\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	line, err = f.NextLine(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "two", line)
+
+	require.NoError(t, os.WriteFile(second, []byte("next day\n"), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(second, later, later))
+
+	line, err = f.NextLine(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "next day", line)
+	assert.Equal(t, second, f.Path())
+
+	short, cancelShort := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelShort()
+	_, err = f.NextLine(short)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,99 @@
+package tolog
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LogFormat selects how entries are encoded.
+type LogFormat string
+
+const (
+	FormatText LogFormat = "text" // bracketed human readable text, default
+	FormatJSON LogFormat = "json" // {"time":...,"level":...,"msg":...,"fields":{...}} per line
+	FormatECS  LogFormat = "ecs"  // JSON using Elastic Common Schema key names
+)
+
+// SetLogFormat sets the format entries are encoded with for console and file.
+func SetLogFormat(format LogFormat) {
+	updateSettings(func(s *settings) { s.format = format })
+}
+
+// encodeEntryJSON encodes an entry as {"time":...,"level":...,"msg":...,"fields":{...}}.
+func encodeEntryJSON(e *Entry) []byte {
+	return encodeEntryJSONTime(e, "")
+}
+
+// encodeEntryJSONTime encodes an entry like encodeEntryJSON, its time in the
+// format, RFC 3339 if empty.
+func encodeEntryJSONTime(e *Entry, format DateFormat) []byte {
+	return []byte(marshalLine(entryObject(e, format)))
+}
+
+// entryObject returns the object of the entry encoded by encodeEntryJSONTime.
+func entryObject(e *Entry, format DateFormat) map[string]any {
+	obj := map[string]any{
+		"time":  jsonTime(e.Time, format),
+		"level": string(e.Level),
+		"msg":   e.Message,
+	}
+	if len(e.Fields) > 0 {
+		fields := make(map[string]any, len(e.Fields))
+		for k, v := range normalizeFields(e.Fields) {
+			fields[k] = jsonValue(v)
+		}
+		obj["fields"] = fields
+	}
+	return obj
+}
+
+// jsonValue converts values which do not marshal meaningfully, like errors, to strings.
+func jsonValue(v any) any {
+	switch val := v.(type) {
+	case LogValuer:
+		return lazyValue
+	case Fields:
+		obj := make(map[string]any, len(val))
+		for k, v := range val {
+			obj[k] = jsonValue(v)
+		}
+		return obj
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	}
+	return v
+}
+
+// toString formats a value as a string.
+func toString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// marshalLine marshals the object into a single JSON line, falling back to
+// string values for the values which cannot be marshaled.
+func marshalLine(obj map[string]any) string {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		stringifyValues(obj)
+		data, _ = json.Marshal(obj)
+	}
+	return string(data)
+}
+
+// stringifyValues replaces the values of obj which cannot be marshaled by their string form.
+func stringifyValues(obj map[string]any) {
+	for k, v := range obj {
+		if nested, ok := v.(map[string]any); ok {
+			stringifyValues(nested)
+			continue
+		}
+		if _, err := json.Marshal(v); err != nil {
+			obj[k] = fmt.Sprint(v)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package tolog
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFormat(t *testing.T) {
+	logPrefix := "TestJSONFormat"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
+	cleanLogFiles(t, logFilePath)
+	SetLogPrefix(logPrefix)
+	SetLogFormat(FormatJSON)
+	defer SetLogFormat(FormatText)
+
+	l := Error("payment failed").WithField("order", 42).PrintLog()
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal([]byte(l.FullLog), &obj))
+	assert.Equal(t, "error", obj["level"])
+	assert.Equal(t, "payment failed", obj["msg"])
+	assert.Equal(t, map[string]any{"order": float64(42)}, obj["fields"])
+	parsed, err := time.Parse(time.RFC3339Nano, obj["time"].(string))
+	require.NoError(t, err)
+	assert.True(t, l.time.Equal(parsed))
+
+	Info("no fields").WriteSafe()
+	CloseLogFile()
+	checkMessageExistInFile(t, logFilePath, `"msg":"no fields"`)
+}
+
+func TestMarshalLineFallback(t *testing.T) {
+	line := marshalLine(map[string]any{"ch": make(chan int), "nested": map[string]any{"fn": func() {}}, "ok": 1})
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal([]byte(line), &obj))
+	assert.Equal(t, float64(1), obj["ok"])
+	assert.IsType(t, "", obj["ch"])
+}
@@ -0,0 +1,75 @@
+package tolog
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"sync/atomic"
+)
+
+// File is a log file opened by an FS, an *os.File in the file system of the
+// operating system.
+type File interface {
+	io.ReadWriteCloser
+	Name() string
+	Stat() (fs.FileInfo, error)
+	Sync() error
+}
+
+// FS is the file system the log files are written, rotated, compressed and
+// removed in, so tests can write them in memory and applications to other file
+// systems through an adapter. Its methods behave like those of the os package.
+type FS interface {
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+}
+
+// OSFS is the file system of the operating system, the default.
+type OSFS struct{}
+
+func (OSFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	f, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		// a nil *os.File would be a non-nil File
+		return nil, err
+	}
+	return f, nil
+}
+
+func (OSFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (OSFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (OSFS) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+func (OSFS) Rename(oldpath, newpath string) error { return os.Rename(oldpath, newpath) }
+func (OSFS) Remove(name string) error             { return os.Remove(name) }
+
+// fsBox holds the file system in an atomic.Value, which needs one concrete type.
+type fsBox struct{ fs FS }
+
+var fileSystem atomic.Value // fsBox
+
+// SetFS sets the file system the log files are written in, nil restores the
+// file system of the operating system. Set it before logging, files already
+// open are written in the file system they were opened in until they are
+// rotated. Follow, Resume and the other readers always use the os package.
+func SetFS(fsys FS) {
+	fileSystem.Store(fsBox{fsys})
+}
+
+// logFS returns the file system the log files are written in.
+func logFS() FS {
+	if box, _ := fileSystem.Load().(fsBox); box.fs != nil {
+		return box.fs
+	}
+	return OSFS{}
+}
+
+// openRead opens the file of the file system for reading.
+func openRead(fsys FS, name string) (File, error) {
+	return fsys.OpenFile(name, os.O_RDONLY, 0)
+}
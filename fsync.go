@@ -0,0 +1,71 @@
+package tolog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// The number of lines written between syncs of the log files, default 0 leaves it to the OS.
+var syncEvery int64
+
+// The longest time written lines stay unsynced, default 0 leaves it to the OS.
+var syncInterval int64
+
+// SetSyncEvery makes the writers sync the log files to disk after every n lines
+// written, so a crash loses at most n lines from the page cache. Zero disables it.
+func SetSyncEvery(n int) {
+	atomic.StoreInt64(&syncEvery, int64(n))
+}
+
+// SetSyncInterval makes the writers sync the log files to disk when lines were
+// written and the last sync is older than d, checked on every flush and every
+// flush interval. Zero disables it.
+func SetSyncInterval(d time.Duration) {
+	atomic.StoreInt64(&syncInterval, int64(d))
+}
+
+// SyncWrite writes the entry like WriteSafe and blocks until it, and every entry
+// queued before it, is written and synced to disk, e.g. before a risky operation
+// whose post-mortem needs it. It returns the error of the write or the sync,
+// nil if the entry isn't written to the file, like below the file level.
+func (l *ToLog) SyncWrite() error {
+	if l.intercept(func(c *ToLog) { c.SyncWrite() }) {
+		return nil
+	}
+	var err error
+	l.emit(TargetFile|TargetSinks, func(w *fileWriter, item writeItem) error {
+		werr := w.write(item)
+		if werr == nil {
+			werr = w.sync()
+		}
+		if err == nil {
+			err = werr
+		}
+		return werr
+	})
+	return err
+}
+
+// syncIfDue counts the n lines written and syncs the file when SetSyncEvery or
+// SetSyncInterval asks for it, fileMu must be held.
+func (w *fileWriter) syncIfDue(n int) {
+	every := atomic.LoadInt64(&syncEvery)
+	interval := time.Duration(atomic.LoadInt64(&syncInterval))
+	if every <= 0 && interval <= 0 {
+		return
+	}
+	w.unsynced += int64(n)
+	if w.file == nil || w.unsynced == 0 {
+		return
+	}
+	if (every <= 0 || w.unsynced < every) && (interval <= 0 || time.Since(w.syncedAt) < interval) {
+		return
+	}
+	if err := w.file.Sync(); err != nil {
+		diag("sync", "syncing %s failed: %v", w.file.Name(), err)
+		handleError(err)
+		return
+	}
+	w.unsynced = 0
+	w.syncedAt = time.Now()
+}
@@ -0,0 +1,83 @@
+package tolog
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncCountFS counts the syncs of the files it opens.
+type syncCountFS struct {
+	OSFS
+	syncs *int64
+}
+
+func (s syncCountFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	f, err := s.OSFS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return syncCountFile{File: f, syncs: s.syncs}, nil
+}
+
+type syncCountFile struct {
+	File
+	syncs *int64
+}
+
+func (f syncCountFile) Sync() error {
+	atomic.AddInt64(f.syncs, 1)
+	return f.File.Sync()
+}
+
+func TestSyncEvery(t *testing.T) {
+	var syncs int64
+	SetFS(syncCountFS{syncs: &syncs})
+	defer SetFS(nil)
+	lg := NewLogger("TestSyncEvery")
+	os.Remove(lg.FilePath())
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+
+	SetSyncEvery(2)
+	for i := 0; i < 5; i++ {
+		lg.Info("direct").Write()
+	}
+	assert.Equal(t, int64(2), atomic.LoadInt64(&syncs))
+	SetSyncEvery(0)
+
+	SetSyncInterval(time.Nanosecond)
+	lg.Info("after the interval").Write()
+	assert.Equal(t, int64(3), atomic.LoadInt64(&syncs))
+	SetSyncInterval(0)
+
+	lg.Info("not synced").Write()
+	assert.Equal(t, int64(3), atomic.LoadInt64(&syncs))
+}
+
+func TestSyncWrite(t *testing.T) {
+	var syncs int64
+	SetFS(syncCountFS{syncs: &syncs})
+	defer SetFS(nil)
+	lg := NewLogger("TestSyncWrite")
+	os.Remove(lg.FilePath())
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+
+	lg.Info("queued before").WriteSafe()
+	assert.NoError(t, lg.Info("persisted").SyncWrite())
+	assert.Equal(t, int64(1), atomic.LoadInt64(&syncs))
+	data, err := os.ReadFile(lg.FilePath())
+	assert.NoError(t, err)
+	assert.Regexp(t, "(?s)queued before.*persisted", string(data))
+
+	SetFileLevel(StatusError)
+	defer SetFileLevel("")
+	assert.NoError(t, lg.Info("below the file level").SyncWrite())
+	assert.Equal(t, int64(1), atomic.LoadInt64(&syncs))
+}
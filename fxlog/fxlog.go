@@ -0,0 +1,35 @@
+// Package fxlog provides the logger of tolog to applications built with
+// go.uber.org/fx, flushing and closing the log files and the sinks when the
+// application stops, so the last entries before a shutdown are not lost.
+package fxlog
+
+import (
+	"context"
+
+	"github.com/callme-taota/tolog"
+	"go.uber.org/fx"
+)
+
+// NewLoggerForFx sets up logging with tolog.Init and returns the default
+// logger, appending a hook to the lifecycle that calls tolog.Shutdown when the
+// application stops, within the stop timeout of the application.
+func NewLoggerForFx(lc fx.Lifecycle, opts ...tolog.InitOption) (*tolog.Logger, error) {
+	if err := tolog.Init(opts...); err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return tolog.Shutdown(ctx)
+		},
+	})
+	return tolog.Default(), nil
+}
+
+// Module provides the *tolog.Logger of NewLoggerForFx set up with the options.
+func Module(opts ...tolog.InitOption) fx.Option {
+	return fx.Module("tolog",
+		fx.Provide(func(lc fx.Lifecycle) (*tolog.Logger, error) {
+			return NewLoggerForFx(lc, opts...)
+		}),
+	)
+}
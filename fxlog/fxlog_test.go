@@ -0,0 +1,43 @@
+package fxlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestModule(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	defer tolog.SetLogDir("")
+	defer tolog.SetLogPrefix("")
+	tolog.SetBatchSize(1000)
+	defer tolog.SetBatchSize(0)
+
+	app := fxtest.New(t,
+		fx.NopLogger,
+		Module(tolog.InitDir(dir), tolog.InitPrefix("TestModule")),
+		fx.Invoke(func(lg *tolog.Logger) {
+			lg.Info("started").WriteSafe()
+		}),
+	)
+	app.RequireStart()
+	app.RequireStop()
+
+	files, err := filepath.Glob(filepath.Join(dir, "TestModule-log-*.log"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "started")
+}
+
+func TestModuleInitError(t *testing.T) {
+	app := fx.New(fx.NopLogger, Module(tolog.InitConfig(tolog.Config{Level: "loud"})), fx.Invoke(func(*tolog.Logger) {}))
+	assert.Error(t, app.Err())
+}
@@ -0,0 +1,84 @@
+package tolog
+
+import (
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// FormatGCP encodes entries as the structured JSON Google Cloud Logging reads from stdout.
+const FormatGCP LogFormat = "gcp"
+
+var gcpProjectID atomic.Value // string
+
+// SetGCPProjectID sets the project used to qualify trace ids as projects/ID/traces/TRACE.
+func SetGCPProjectID(projectID string) {
+	gcpProjectID.Store(projectID)
+}
+
+// callerInfo is the source location an entry was emitted from.
+type callerInfo struct {
+	file     string
+	line     int
+	function string
+}
+
+// captureCaller returns the source location skip frames above its caller.
+func captureCaller(skip int) *callerInfo {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return nil
+	}
+	c := &callerInfo{file: file, line: line}
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		c.function = fn.Name()
+	}
+	return c
+}
+
+var gcpSeverities = map[LogStatus]string{
+	StatusDebug:   "DEBUG",
+	StatusInfo:    "INFO",
+	StatusNotice:  "NOTICE",
+	StatusWarning: "WARNING",
+	StatusError:   "ERROR",
+}
+
+// encodeGCP encodes the entry for Google Cloud Logging. The trace_id, span_id and
+// trace_sampled fields become the special trace keys, other fields end up in jsonPayload.
+func encodeGCP(l *ToLog) string {
+	severity, ok := gcpSeverities[l.logType]
+	if !ok {
+		severity = "DEFAULT"
+	}
+	obj := map[string]any{
+		"severity": severity,
+		"time":     l.time.UTC().Format(time.RFC3339Nano),
+		"message":  l.logContext,
+	}
+	if l.caller != nil {
+		obj["logging.googleapis.com/sourceLocation"] = map[string]any{
+			"file":     l.caller.path(),
+			"line":     strconv.Itoa(l.caller.line),
+			"function": l.caller.function,
+		}
+	}
+	for k, v := range normalizeFields(l.fields) {
+		switch k {
+		case TraceIDField:
+			trace := toString(v)
+			if project, _ := gcpProjectID.Load().(string); project != "" {
+				trace = "projects/" + project + "/traces/" + trace
+			}
+			obj["logging.googleapis.com/trace"] = trace
+		case SpanIDField:
+			obj["logging.googleapis.com/spanId"] = toString(v)
+		case TraceSampledField:
+			obj["logging.googleapis.com/trace_sampled"] = v
+		default:
+			obj[k] = jsonValue(v)
+		}
+	}
+	return marshalLine(obj)
+}
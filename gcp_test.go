@@ -0,0 +1,35 @@
+package tolog
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCPFormat(t *testing.T) {
+	SetLogFormat(FormatGCP)
+	SetGCPProjectID("my-project")
+	defer SetLogFormat(FormatText)
+	defer SetGCPProjectID("")
+	RegisterContextExtractor(func(ctx context.Context) Fields {
+		return Fields{"trace_id": "0679686673", "span_id": "000000000000004a", "user": "bob"}
+	})
+	defer ResetContextExtractors()
+
+	l := WarningCtx(context.Background(), "slow query").PrintLog()
+
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal([]byte(l.FullLog), &obj))
+	assert.Equal(t, "WARNING", obj["severity"])
+	assert.Equal(t, "slow query", obj["message"])
+	assert.Equal(t, "projects/my-project/traces/0679686673", obj["logging.googleapis.com/trace"])
+	assert.Equal(t, "000000000000004a", obj["logging.googleapis.com/spanId"])
+	assert.Equal(t, "bob", obj["user"])
+	source, ok := obj["logging.googleapis.com/sourceLocation"].(map[string]any)
+	require.True(t, ok)
+	assert.Contains(t, source["file"], "gcp_test.go")
+	assert.Contains(t, source["function"], "TestGCPFormat")
+}
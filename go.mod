@@ -2,10 +2,17 @@ module github.com/callme-taota/tolog
 
 go 1.20
 
-require github.com/stretchr/testify v1.9.0
+require (
+	github.com/Microsoft/go-winio v0.6.1
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/sys v0.10.0
+	golang.org/x/term v0.10.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/mod v0.8.0 // indirect
+	golang.org/x/tools v0.6.0 // indirect
 )
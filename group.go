@@ -0,0 +1,122 @@
+package tolog
+
+import "time"
+
+// Group nests the fields added after it with WithField and Fields under the
+// name, rendered as a nested object in JSON and as dotted keys in text, like
+// the groups of log/slog. Groups nest, Group("http").Group("req") puts the
+// fields under http.req. An empty name is ignored, and groups without fields
+// are left out.
+func (l *ToLog) Group(name string) *ToLog {
+	if name == "" {
+		return l
+	}
+	l = l.mutable()
+	l.group = append(l.group[:len(l.group):len(l.group)], name)
+	return l
+}
+
+// Str adds a string field in the current group, like WithField.
+func (l *ToLog) Str(key string, value string) *ToLog {
+	return l.WithField(key, value)
+}
+
+// Int adds an int field in the current group, like WithField.
+func (l *ToLog) Int(key string, value int) *ToLog {
+	return l.WithField(key, value)
+}
+
+// Int64 adds an int64 field in the current group, like WithField.
+func (l *ToLog) Int64(key string, value int64) *ToLog {
+	return l.WithField(key, value)
+}
+
+// Uint64 adds a uint64 field in the current group, like WithField.
+func (l *ToLog) Uint64(key string, value uint64) *ToLog {
+	return l.WithField(key, value)
+}
+
+// Float64 adds a float64 field in the current group, like WithField.
+func (l *ToLog) Float64(key string, value float64) *ToLog {
+	return l.WithField(key, value)
+}
+
+// Bool adds a bool field in the current group, like WithField.
+func (l *ToLog) Bool(key string, value bool) *ToLog {
+	return l.WithField(key, value)
+}
+
+// Dur adds a duration field in the current group, like WithField.
+func (l *ToLog) Dur(key string, value time.Duration) *ToLog {
+	return l.WithField(key, value)
+}
+
+// addFields adds the fields to the entry in its current group.
+func (l *ToLog) addFields(fields Fields) {
+	if len(l.group) == 0 {
+		l.fields = l.fields.merge(fields)
+		return
+	}
+	l.fields = l.fields.mergeIn(l.group, fields)
+}
+
+// mergeIn copies the given fields into the group of f at the path, overriding
+// existing keys, and returns f.
+func (f Fields) mergeIn(path []string, other Fields) Fields {
+	if len(other) == 0 {
+		return f
+	}
+	if f == nil {
+		f = make(Fields, 1)
+	}
+	group, _ := f[path[0]].(Fields)
+	if len(path) == 1 {
+		f[path[0]] = group.merge(other)
+	} else {
+		f[path[0]] = group.mergeIn(path[1:], other)
+	}
+	return f
+}
+
+// copy returns a copy of the fields and of the fields of their groups.
+func (f Fields) copy() Fields {
+	if f == nil {
+		return nil
+	}
+	out := make(Fields, len(f))
+	for k, v := range f {
+		if group, ok := v.(Fields); ok {
+			v = group.copy()
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// flatten returns the fields with those of groups under dotted keys, f itself
+// if it has no groups.
+func (f Fields) flatten() Fields {
+	grouped := false
+	for _, v := range f {
+		if _, ok := v.(Fields); ok {
+			grouped = true
+			break
+		}
+	}
+	if !grouped {
+		return f
+	}
+	out := make(Fields, len(f))
+	f.flattenInto(out, "")
+	return out
+}
+
+func (f Fields) flattenInto(out Fields, prefix string) {
+	for k, v := range f {
+		if group, ok := v.(Fields); ok {
+			group.flattenInto(out, prefix+k+".")
+			continue
+		}
+		out[prefix+k] = v
+	}
+}
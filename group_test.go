@@ -0,0 +1,65 @@
+package tolog
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+	lg := NewLogger("TestGroup")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	lg.SetColor(false)
+
+	l := lg.Info("request").WithField("user", "bob").
+		Group("http").WithField("method", "GET").WithField("status", 200).
+		Group("").Group("req").Fields(Fields{"size": 12}).
+		Group("empty")
+	assert.Equal(t, Fields{
+		"user": "bob",
+		"http": Fields{"method": "GET", "status": 200, "req": Fields{"size": 12}},
+	}, l.fields)
+	assert.Contains(t, l.FullLog, "http.method=GET http.req.size=12 http.status=200 user=bob")
+
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal(encodeEntryJSON(l.entry()), &obj))
+	assert.Equal(t, map[string]any{
+		"user": "bob",
+		"http": map[string]any{"method": "GET", "status": 200.0, "req": map[string]any{"size": 12.0}},
+	}, obj["fields"])
+
+	// a frozen entry is copied with its groups
+	l.WriteSafe()
+	c := l.WithField("size", 13)
+	assert.Equal(t, Fields{"size": 12}, l.fields["http"].(Fields)["req"])
+	assert.Equal(t, Fields{"size": 12, "empty": Fields{"size": 13}}, c.fields["http"].(Fields)["req"])
+}
+
+func TestGroupTypedFields(t *testing.T) {
+	lg := NewLogger("TestGroupTypedFields")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	lg.SetColor(false)
+
+	l := lg.Info("request").Str("user", "bob").
+		Group("http").Str("method", "GET").Int("status", 200).Dur("latency", 1500*time.Millisecond).
+		Group("req").Int64("size", 12).Uint64("id", 7).Float64("ratio", 0.5).Bool("cached", true)
+	assert.Contains(t, l.FullLog, "http.latency=1.5s http.method=GET http.req.cached=true http.req.id=7 http.req.ratio=0.5 http.req.size=12 http.status=200 user=bob")
+
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal(encodeEntryJSON(l.entry()), &obj))
+	assert.Equal(t, map[string]any{
+		"user": "bob",
+		"http": map[string]any{
+			"method":  "GET",
+			"status":  200.0,
+			"latency": "1.5s",
+			"req":     map[string]any{"size": 12.0, "id": 7.0, "ratio": 0.5, "cached": true},
+		},
+	}, obj["fields"])
+}
@@ -0,0 +1,174 @@
+package tolog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// histogramMinutes is how many minutes of counts are kept.
+const histogramMinutes = 60
+
+// minuteBucket counts the entries per level of one minute, in the order of statLevels.
+type minuteBucket struct {
+	minute atomic.Int64 // minutes since the epoch
+	counts [6]atomic.Uint64
+}
+
+var (
+	histogram   [histogramMinutes]minuteBucket
+	histogramMu sync.Mutex // serializes the start of minutes
+	firstMinute int64      // the first minute counted, guarded by histogramMu
+)
+
+// MinuteCounts are the entries emitted in a minute per level.
+type MinuteCounts struct {
+	Minute time.Time
+	Counts map[LogStatus]uint64
+}
+
+// Histogram returns the entries emitted per level in each of the last 60
+// minutes, the current one last. Minutes without entries have no counts.
+func Histogram() []MinuteCounts {
+	now := time.Now().Unix() / 60
+	out := make([]MinuteCounts, 0, histogramMinutes)
+	for m := now - histogramMinutes + 1; m <= now; m++ {
+		mc := MinuteCounts{Minute: time.Unix(m*60, 0), Counts: map[LogStatus]uint64{}}
+		b := &histogram[m%histogramMinutes]
+		if b.minute.Load() == m {
+			for i, l := range statLevels {
+				if n := b.counts[i].Load(); n > 0 {
+					mc.Counts[l] = n
+				}
+			}
+		}
+		out = append(out, mc)
+	}
+	return out
+}
+
+// Anomaly is a minute whose count of entries of a level is far above the baseline.
+type Anomaly struct {
+	Level    LogStatus
+	Minute   time.Time
+	Count    uint64
+	Baseline float64 // the average count of the minutes before
+}
+
+// AnomalyOptions configures OnAnomaly.
+type AnomalyOptions struct {
+	// Level is the level counted, StatusError if empty.
+	Level LogStatus
+	// Factor is how many times the baseline a minute must exceed, 3 if zero.
+	Factor float64
+	// Baseline is the number of minutes the baseline averages, 15 if zero.
+	Baseline int
+	// MinCount is the count below which a minute is never an anomaly, 10 if
+	// zero, so a single error after quiet minutes doesn't fire.
+	MinCount uint64
+}
+
+var anomaly atomic.Value // *anomalyWatch
+
+type anomalyWatch struct {
+	opts     AnomalyOptions
+	callback func(Anomaly)
+}
+
+// OnAnomaly calls the callback, in a goroutine of its own, when the entries of
+// a level emitted in a minute exceed the average of the minutes before by the
+// factor, e.g. to alert on error spikes without external monitoring. Minutes
+// are checked once the next one starts. A nil callback stops it.
+func OnAnomaly(opts AnomalyOptions, callback func(Anomaly)) {
+	if opts.Level == "" {
+		opts.Level = StatusError
+	}
+	if opts.Factor <= 0 {
+		opts.Factor = 3
+	}
+	if opts.Baseline <= 0 {
+		opts.Baseline = 15
+	}
+	if opts.Baseline >= histogramMinutes-1 {
+		opts.Baseline = histogramMinutes - 2
+	}
+	if opts.MinCount == 0 {
+		opts.MinCount = 10
+	}
+	anomaly.Store(&anomalyWatch{opts: opts, callback: callback})
+}
+
+// countMinute counts the entry of the level statLevels[i] in the histogram of the time.
+func countMinute(i int, t time.Time) {
+	m := t.Unix() / 60
+	b := &histogram[m%histogramMinutes]
+	if b.minute.Load() != m {
+		if startMinute(b, m); b.minute.Load() != m {
+			// an entry stamped before the minutes kept
+			return
+		}
+	}
+	b.counts[i].Add(1)
+}
+
+// startMinute resets the bucket for the minute and checks the minute before.
+func startMinute(b *minuteBucket, m int64) {
+	histogramMu.Lock()
+	if b.minute.Load() == m {
+		histogramMu.Unlock()
+		return
+	}
+	if b.minute.Load() > m {
+		histogramMu.Unlock()
+		return
+	}
+	for i := range b.counts {
+		b.counts[i].Store(0)
+	}
+	b.minute.Store(m)
+	if firstMinute == 0 {
+		firstMinute = m
+	}
+	a, callback := checkAnomaly(m - 1)
+	histogramMu.Unlock()
+	if a != nil {
+		go callback(*a)
+	}
+}
+
+// checkAnomaly returns the anomaly of the minute and the callback to report it
+// to, nil if it is none, histogramMu must be held.
+func checkAnomaly(m int64) (*Anomaly, func(Anomaly)) {
+	w, _ := anomaly.Load().(*anomalyWatch)
+	if w == nil || w.callback == nil || m-int64(w.opts.Baseline) < firstMinute {
+		return nil, nil
+	}
+	level := len(statLevels) - 1
+	for i, l := range statLevels {
+		if l == w.opts.Level {
+			level = i
+		}
+	}
+	count := minuteCount(m, level)
+	if count < w.opts.MinCount {
+		return nil, nil
+	}
+	var sum uint64
+	for past := m - int64(w.opts.Baseline); past < m; past++ {
+		sum += minuteCount(past, level)
+	}
+	baseline := float64(sum) / float64(w.opts.Baseline)
+	if float64(count) <= baseline*w.opts.Factor {
+		return nil, nil
+	}
+	return &Anomaly{Level: w.opts.Level, Minute: time.Unix(m*60, 0), Count: count, Baseline: baseline}, w.callback
+}
+
+// minuteCount returns the count of the level statLevels[i] in the minute, 0 if it isn't kept.
+func minuteCount(m int64, i int) uint64 {
+	b := &histogram[m%histogramMinutes]
+	if b.minute.Load() != m {
+		return 0
+	}
+	return b.counts[i].Load()
+}
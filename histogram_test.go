@@ -0,0 +1,70 @@
+package tolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resetHistogram forgets the counted minutes.
+func resetHistogram() {
+	histogramMu.Lock()
+	defer histogramMu.Unlock()
+	for i := range histogram {
+		histogram[i].minute.Store(0)
+		for j := range histogram[i].counts {
+			histogram[i].counts[j].Store(0)
+		}
+	}
+	firstMinute = 0
+}
+
+func TestHistogram(t *testing.T) {
+	resetHistogram()
+	defer resetHistogram()
+	countEntry(StatusError, time.Now())
+	countEntry(StatusError, time.Now())
+	countEntry(StatusInfo, time.Now())
+	countEntry(StatusInfo, time.Now().Add(-time.Minute))
+
+	h := Histogram()
+	assert.Len(t, h, 60)
+	assert.Equal(t, map[LogStatus]uint64{StatusError: 2, StatusInfo: 1}, h[59].Counts)
+	assert.Equal(t, map[LogStatus]uint64{StatusInfo: 1}, h[58].Counts)
+	assert.Empty(t, h[0].Counts)
+}
+
+func TestOnAnomaly(t *testing.T) {
+	resetHistogram()
+	defer resetHistogram()
+	anomalies := make(chan Anomaly, 1)
+	OnAnomaly(AnomalyOptions{Factor: 2, Baseline: 3, MinCount: 5}, func(a Anomaly) { anomalies <- a })
+	defer OnAnomaly(AnomalyOptions{}, nil)
+
+	start := time.Unix(1e6*60, 0)
+	minute := func(m int, errors int) {
+		for i := 0; i < errors; i++ {
+			countEntry(StatusError, start.Add(time.Duration(m)*time.Minute))
+		}
+		countEntry(StatusInfo, start.Add(time.Duration(m)*time.Minute))
+	}
+	for m := 0; m < 3; m++ {
+		minute(m, 4)
+	}
+	minute(3, 8) // twice the baseline is no anomaly
+	minute(4, 20)
+	minute(5, 0)
+
+	select {
+	case a := <-anomalies:
+		assert.Equal(t, Anomaly{Level: StatusError, Minute: start.Add(4 * time.Minute), Count: 20, Baseline: 16.0 / 3}, a)
+	case <-time.After(time.Second):
+		t.Fatal("no anomaly reported")
+	}
+	select {
+	case a := <-anomalies:
+		t.Errorf("unexpected anomaly %+v", a)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
@@ -0,0 +1,86 @@
+package tolog
+
+import "sync/atomic"
+
+// FlushHook transforms or aggregates a batch of entries right before it is
+// written to the log file, e.g. to sort it by time or merge duplicates.
+type FlushHook func(batch []Entry) []Entry
+
+var flushHook atomic.Value // FlushHook
+
+// OnFlush sets the hook applied to every batch written to the log files, nil
+// removes it. Lines written with WriteRaw are not passed to the hook and are
+// written before the entries of their batch.
+func OnFlush(hook FlushHook) {
+	flushHook.Store(hook)
+}
+
+// WriteHook is called after every write to a log file with the bytes written
+// and the error, e.g. to meter the volume against a quota.
+type WriteHook func(n int, err error)
+
+var writeHook atomic.Value // WriteHook
+
+// AfterWrite sets the hook called after every write to the log files, nil removes it.
+func AfterWrite(hook WriteHook) {
+	writeHook.Store(hook)
+}
+
+// afterWrite calls the write hook if set.
+func afterWrite(n int, err error) {
+	if hook, _ := writeHook.Load().(WriteHook); hook != nil {
+		hook(n, err)
+	}
+}
+
+func loadFlushHook() FlushHook {
+	hook, _ := flushHook.Load().(FlushHook)
+	return hook
+}
+
+// writeItem returns the item queued for the log file, carrying a snapshot of
+// the entry while a flush hook is set.
+func (l *ToLog) writeItem() writeItem {
+	item := writeItem{line: fileLine(l), level: l.logType, pairs: fieldPairs(l.fields)}
+	if loadFlushHook() != nil {
+		item.entry = l.entry()
+	}
+	return item
+}
+
+// applyFlushHook returns the lines of the batch, passing the entries through the
+// flush hook and encoding the result again.
+func applyFlushHook(batch []writeItem) []string {
+	hook := loadFlushHook()
+	lines := make([]string, 0, len(batch))
+	var entries []Entry
+	for _, item := range batch {
+		if hook == nil || item.entry == nil {
+			lines = append(lines, item.line)
+			continue
+		}
+		entries = append(entries, *item.entry)
+	}
+	if len(entries) == 0 {
+		return lines
+	}
+	for _, e := range hook(entries) {
+		e := e
+		lines = append(lines, fileLine(e.toLog()))
+	}
+	return lines
+}
+
+// toLog returns a ToLog of the entry, to encode it again.
+func (e *Entry) toLog() *ToLog {
+	l := &ToLog{
+		logType:    e.Level,
+		logContext: e.Message,
+		logTime:    formatTime(e.Time.In(globalTimeZone()), globalTimeFormat()),
+		time:       e.Time,
+		timed:      true,
+		fields:     e.Fields,
+	}
+	CreateFullLog(l)
+	return l
+}
@@ -0,0 +1,64 @@
+package tolog
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnFlush(t *testing.T) {
+	logPrefix := "TestOnFlush"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
+	cleanLogFiles(t, logFilePath)
+	SetLogPrefix(logPrefix)
+
+	OnFlush(func(batch []Entry) []Entry {
+		sort.Slice(batch, func(i, j int) bool { return batch[i].Message < batch[j].Message })
+		kept := batch[:0]
+		for _, e := range batch {
+			if e.Message != "dropped" {
+				e.Fields = e.Fields.merge(Fields{"batch": len(batch)})
+				kept = append(kept, e)
+			}
+		}
+		return kept
+	})
+	defer OnFlush(nil)
+
+	Info("b second").WriteSafe()
+	Info("dropped").WriteSafe()
+	Info("a first").WriteSafe()
+	require.NoError(t, WriteRaw([]byte("raw line")))
+	CloseLogFile()
+
+	content, err := os.ReadFile(logFilePath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "raw line", lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], "a first batch=3"), lines[1])
+	assert.True(t, strings.HasSuffix(lines[2], "b second batch=3"), lines[2])
+}
+
+func TestAfterWrite(t *testing.T) {
+	var total, calls int
+	AfterWrite(func(n int, err error) {
+		assert.NoError(t, err)
+		total += n
+		calls++
+	})
+	defer AfterWrite(nil)
+
+	lg := NewLogger("TestAfterWrite")
+	require.NoError(t, lg.WriteRaw([]byte("12345")))
+	require.NoError(t, lg.WriteRaw([]byte("6789")))
+	lg.Close()
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, len("12345\n6789\n"), total)
+}
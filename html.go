@@ -0,0 +1,65 @@
+package tolog
+
+import (
+	"bytes"
+	"html"
+	"time"
+)
+
+// FormatHTML encodes entries as HTML elements, a span per part with the CSS
+// classes of the level, to embed them in status pages and emails.
+const FormatHTML LogFormat = "html"
+
+func init() {
+	RegisterEncoder(FormatHTML, HTMLEncoder{})
+}
+
+// HTMLStyle is a style sheet for the entries of HTMLEncoder, coloring them by level.
+const HTMLStyle = `.tolog-entry{font-family:monospace;white-space:pre-wrap;margin:0}
+.tolog-time{color:#888}
+.tolog-level{font-weight:bold;padding:0 .3em;border-radius:3px}
+.tolog-level-debug{background:#e0e0e0;color:#333}
+.tolog-level-info{background:#d4f4dd;color:#11622a}
+.tolog-level-notice{background:#d7e9fb;color:#0b4f8a}
+.tolog-level-warning{background:#fff1c2;color:#7a5a00}
+.tolog-level-error{background:#fbd7d7;color:#8a0b0b}
+.tolog-level-unknown{background:#eee;color:#555}
+.tolog-fields{color:#555}
+`
+
+// HTMLEncoder encodes entries like FormatHTML, one div per entry:
+//
+//	<div class="tolog-entry tolog-entry-error"><time class="tolog-time" datetime="...">...</time>
+//	<span class="tolog-level tolog-level-error">error</span> <span class="tolog-msg">...</span>
+//	<span class="tolog-fields">key=value</span></div>
+//
+// without the line breaks. The message and the fields are escaped.
+type HTMLEncoder struct{}
+
+// Encode encodes the entry as a div of spans with the CSS classes of its level.
+func (HTMLEncoder) Encode(e *Entry) ([]byte, error) {
+	level := html.EscapeString(string(e.Level))
+	var buf bytes.Buffer
+	buf.WriteString(`<div class="tolog-entry tolog-entry-`)
+	buf.WriteString(level)
+	buf.WriteString(`"><time class="tolog-time" datetime="`)
+	buf.WriteString(e.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`">`)
+	buf.WriteString(html.EscapeString(formatTime(e.Time.In(globalTimeZone()), globalTimeFormat())))
+	buf.WriteString(`</time> <span class="tolog-level tolog-level-`)
+	buf.WriteString(level)
+	buf.WriteString(`">`)
+	buf.WriteString(level)
+	buf.WriteString(`</span> <span class="tolog-msg">`)
+	buf.WriteString(html.EscapeString(e.Message))
+	buf.WriteString(`</span>`)
+	if len(e.Fields) > 0 {
+		var fields bytes.Buffer
+		appendFields(&fields, e.Fields)
+		buf.WriteString(` <span class="tolog-fields">`)
+		buf.WriteString(html.EscapeString(fields.String()))
+		buf.WriteString(`</span>`)
+	}
+	buf.WriteString(`</div>`)
+	return buf.Bytes(), nil
+}
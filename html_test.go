@@ -0,0 +1,44 @@
+package tolog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLEncoder(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   StatusError,
+		Message: `<script>alert("x")</script>`,
+		Fields:  Fields{"path": "/a&b"},
+	}
+	data, err := HTMLEncoder{}.Encode(e)
+	assert.NoError(t, err)
+	out := string(data)
+	assert.Contains(t, out, `<div class="tolog-entry tolog-entry-error">`)
+	assert.Contains(t, out, `datetime="2024-01-02T03:04:05Z"`)
+	assert.Contains(t, out, `<span class="tolog-level tolog-level-error">error</span>`)
+	assert.Contains(t, out, `<span class="tolog-msg">&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</span>`)
+	assert.Contains(t, out, `<span class="tolog-fields">path=/a&amp;b</span>`)
+	assert.NotContains(t, out, "<script>")
+	assert.True(t, knownFormat(FormatHTML))
+	assert.Contains(t, HTMLStyle, ".tolog-level-error")
+
+	data, _ = HTMLEncoder{}.Encode(&Entry{Time: e.Time, Level: StatusInfo, Message: "ok"})
+	assert.NotContains(t, string(data), "tolog-fields")
+
+	SetRecentCache(10, time.Hour)
+	defer SetRecentCache(0, 0)
+	lg := NewLogger("TestHTMLEncoder")
+	defer lg.Close()
+	lg.Warning("disk <full>").WriteSafe()
+	rec := httptest.NewRecorder()
+	RecentHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?format=html", nil))
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "<style>")
+	assert.Contains(t, rec.Body.String(), `<span class="tolog-msg">disk &lt;full&gt;</span>`)
+}
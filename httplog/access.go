@@ -0,0 +1,269 @@
+package httplog
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/callme-taota/tolog"
+)
+
+// SLO is a latency objective of the requests of a route. Requests slower than
+// its thresholds are logged as warnings or errors instead of info.
+type SLO struct {
+	// Method of the requests, any method if empty.
+	Method string
+	// Path of the requests, or a prefix of the paths when ending with /*.
+	Path string
+	// Warning is the latency logging the request as a warning, 0 disables it.
+	Warning time.Duration
+	// Error is the latency logging the request as an error, 0 disables it.
+	Error time.Duration
+}
+
+// matches reports whether the SLO applies to the request.
+func (s SLO) matches(r *http.Request) bool {
+	return matchRequest(s.Method, s.Path, r)
+}
+
+// Noise is a kind of request left out of the access log while it succeeds,
+// like health checks, see AccessOptions.Suppress.
+type Noise struct {
+	// Method of the requests, any method if empty.
+	Method string
+	// Path of the requests, or a prefix of the paths when ending with /*, any path if empty.
+	Path string
+}
+
+// NoisePreset lists the usual noise: health and readiness checks, the gRPC
+// health service, favicons and CORS preflights.
+var NoisePreset = []Noise{
+	{Path: "/health"},
+	{Path: "/healthz"},
+	{Path: "/livez"},
+	{Path: "/readyz"},
+	{Path: "/ping"},
+	{Path: "/grpc.health.v1.Health/*"},
+	{Path: "/favicon.ico"},
+	{Method: http.MethodOptions},
+}
+
+// SuppressNoise returns NoisePreset extended with the noise of the application,
+// for AccessOptions.Suppress.
+func SuppressNoise(extra ...Noise) []Noise {
+	noise := make([]Noise, 0, len(NoisePreset)+len(extra))
+	noise = append(noise, NoisePreset...)
+	return append(noise, extra...)
+}
+
+// matchRequest reports whether the request has the method, any if empty, and
+// matches the path pattern, any path if empty.
+func matchRequest(method string, path string, r *http.Request) bool {
+	if method != "" && !strings.EqualFold(method, r.Method) {
+		return false
+	}
+	return path == "" || matchPath(path, r.URL.Path)
+}
+
+// matchPath reports whether the path is the pattern, or starts with it when
+// the pattern ends with /*.
+func matchPath(pattern string, path string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return pattern == path
+}
+
+// Sampling decides which requests the access log writes, to keep it affordable
+// on high traffic services. Failed requests, requests breaching their SLO and
+// requests of the listed routes are always written.
+type Sampling struct {
+	// Rate is the fraction of the other requests written, from 0 to 1.
+	Rate float64
+	// ErrorStatus is the lowest status of failed requests, 400 if 0.
+	ErrorStatus int
+	// Routes are always written, a path or a prefix of paths when ending with /*.
+	Routes []string
+}
+
+// keep reports whether the request is written.
+func (s *Sampling) keep(r *http.Request, status int, level tolog.LogStatus) bool {
+	errorStatus := s.ErrorStatus
+	if errorStatus == 0 {
+		errorStatus = http.StatusBadRequest
+	}
+	if status >= errorStatus || level != tolog.StatusInfo {
+		return true
+	}
+	for _, route := range s.Routes {
+		if matchPath(route, r.URL.Path) {
+			return true
+		}
+	}
+	return s.Rate >= 1 || (s.Rate > 0 && rand.Float64() < s.Rate)
+}
+
+// level returns the level of a request of the latency.
+func (s SLO) level(latency time.Duration) tolog.LogStatus {
+	switch {
+	case s.Error > 0 && latency >= s.Error:
+		return tolog.StatusError
+	case s.Warning > 0 && latency >= s.Warning:
+		return tolog.StatusWarning
+	}
+	return tolog.StatusInfo
+}
+
+// AccessOptions configures the access log middleware.
+type AccessOptions struct {
+	// Logger the requests are logged with, the default logger if nil.
+	Logger *tolog.Logger
+	// RequestIDHeader is the header of the correlation ID, X-Request-ID if empty.
+	RequestIDHeader string
+	// TrustProxyHeaders takes the client IP from the X-Forwarded-For and
+	// X-Real-IP headers, only enable it behind a proxy setting them.
+	TrustProxyHeaders bool
+	// SLOs escalate the entries of slow requests, the first matching SLO applies.
+	SLOs []SLO
+	// Sampling writes only a part of the requests, all are written if nil.
+	Sampling *Sampling
+	// Canonical puts a tolog.CanonicalLine in the request context, the fields
+	// added to it while handling the request are written with the entry.
+	Canonical bool
+	// Suppress leaves the matching requests out unless they fail with a
+	// status from 400, e.g. SuppressNoise() for health checks and preflights.
+	Suppress []Noise
+}
+
+// AccessLogger writes the access log entries, it is the part of the middleware
+// shared with the adapters of other routers.
+type AccessLogger struct {
+	opts     AccessOptions
+	lg       *tolog.Logger
+	idHeader string
+}
+
+// NewAccessLogger returns an AccessLogger with the options.
+func NewAccessLogger(opts AccessOptions) *AccessLogger {
+	lg := opts.Logger
+	if lg == nil {
+		lg = tolog.Default()
+	}
+	idHeader := opts.RequestIDHeader
+	if idHeader == "" {
+		idHeader = "X-Request-ID"
+	}
+	return &AccessLogger{opts: opts, lg: lg, idHeader: idHeader}
+}
+
+// Begin prepares the request before it is handled, it returns the request
+// carrying the canonical line when enabled.
+func (a *AccessLogger) Begin(r *http.Request) *http.Request {
+	if !a.opts.Canonical {
+		return r
+	}
+	ctx, _ := tolog.WithCanonicalLine(r.Context())
+	return r.WithContext(ctx)
+}
+
+// Log writes the entry of a handled request returned by Begin.
+func (a *AccessLogger) Log(r *http.Request, status int, bytes int, latency time.Duration) {
+	if a.suppressed(r, status) {
+		return
+	}
+	fields := tolog.Fields{
+		"method":    r.Method,
+		"path":      r.URL.Path,
+		"status":    status,
+		"bytes":     bytes,
+		"latency":   latency.String(),
+		"client_ip": ClientIP(r, a.opts.TrustProxyHeaders),
+	}
+	if id := r.Header.Get(a.idHeader); id != "" {
+		fields[tolog.RequestIDField] = id
+	}
+	level := tolog.StatusInfo
+	for _, slo := range a.opts.SLOs {
+		if !slo.matches(r) {
+			continue
+		}
+		if level = slo.level(latency); level != tolog.StatusInfo {
+			fields["slo"] = "breached"
+		}
+		break
+	}
+	var line *tolog.CanonicalLine
+	if a.opts.Canonical {
+		line = tolog.CanonicalFromContext(r.Context())
+	}
+	if line != nil {
+		line.AddFields(fields)
+		line.Escalate(level)
+		fields, level = line.Fields(), line.Level()
+	}
+	if a.opts.Sampling != nil && !a.opts.Sampling.keep(r, status, level) {
+		return
+	}
+	if line != nil {
+		line.Emit(r.Context(), a.lg, "request")
+		return
+	}
+	a.lg.Log(tolog.WithType(level), tolog.WithFields(fields)).Ctx(r.Context()).Msg("request")
+}
+
+// suppressed reports whether the request is noise left out of the access log.
+func (a *AccessLogger) suppressed(r *http.Request, status int) bool {
+	if status >= http.StatusBadRequest {
+		return false
+	}
+	for _, n := range a.opts.Suppress {
+		if matchRequest(n.Method, n.Path, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client of the request. With trustProxy the
+// first address of X-Forwarded-For or X-Real-IP is preferred to the peer.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// AccessLog returns middleware writing an entry per request with the default options.
+func AccessLog(next http.Handler) http.Handler {
+	return AccessLogWith(AccessOptions{})(next)
+}
+
+// AccessLogWith returns middleware writing an entry per request to the log file
+// with its method, path, status, size, latency, client IP and request ID.
+// Requests breaching their SLO are logged as warnings or errors with the slo field.
+func AccessLogWith(opts AccessOptions) func(http.Handler) http.Handler {
+	a := NewAccessLogger(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r = a.Begin(r)
+			rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rw, r)
+			a.Log(r, rw.status, rw.bytes, time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package httplog
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/callme-taota/tolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain writes the log files of the tests to a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "httplog-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	tolog.SetLogDir(dir)
+	code := m.Run()
+	tolog.CloseLogFile()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestAccessLogSLO(t *testing.T) {
+	lg := tolog.NewLogger("TestAccessLogSLO")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	handler := AccessLogWith(AccessOptions{
+		Logger: lg,
+		SLOs: []SLO{
+			{Method: http.MethodGet, Path: "/reports/*", Warning: time.Millisecond, Error: time.Hour},
+			{Path: "/health", Error: time.Millisecond},
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fast" {
+			time.Sleep(2 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("ok"))
+	}))
+
+	for _, path := range []string{"/fast", "/reports/daily", "/health"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Request-ID", "id"+path)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], " [info] ")
+	assert.Contains(t, lines[0], "path=/fast")
+	assert.Contains(t, lines[0], "status=201")
+	assert.Contains(t, lines[0], "bytes=2")
+	assert.Contains(t, lines[0], "request_id=id/fast")
+	assert.NotContains(t, lines[0], "slo=")
+	assert.Contains(t, lines[1], " [warning] ")
+	assert.Contains(t, lines[1], "slo=breached")
+	assert.Contains(t, lines[2], " [error] ")
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	lg := tolog.NewLogger("TestAccessLogSampling")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	handler := AccessLogWith(AccessOptions{
+		Logger:   lg,
+		Sampling: &Sampling{Rate: 0, Routes: []string{"/admin/*"}},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	for _, path := range []string{"/", "/missing", "/admin/users", "/other"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "path=/missing")
+	assert.Contains(t, lines[1], "path=/admin/users")
+
+	always := &Sampling{Rate: 1}
+	assert.True(t, always.keep(httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, tolog.StatusInfo))
+}
+
+func TestAccessLogSuppress(t *testing.T) {
+	lg := tolog.NewLogger("TestAccessLogSuppress")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	handler := AccessLogWith(AccessOptions{
+		Logger:   lg,
+		Suppress: SuppressNoise(Noise{Method: http.MethodGet, Path: "/internal/*"}),
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/healthz", nil),
+		httptest.NewRequest(http.MethodOptions, "/orders", nil),
+		httptest.NewRequest(http.MethodGet, "/favicon.ico", nil),
+		httptest.NewRequest(http.MethodPost, "/grpc.health.v1.Health/Check", nil),
+		httptest.NewRequest(http.MethodGet, "/internal/status", nil),
+		httptest.NewRequest(http.MethodPost, "/internal/status", nil),
+		httptest.NewRequest(http.MethodGet, "/healthz?fail=1", nil),
+		httptest.NewRequest(http.MethodGet, "/orders", nil),
+	}
+	for _, r := range requests {
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 3, out.String()) {
+		assert.Contains(t, lines[0], "method=POST path=/internal/status")
+		assert.Contains(t, lines[1], "path=/healthz status=503")
+		assert.Contains(t, lines[2], "path=/orders")
+	}
+}
+
+func TestAccessLogCanonical(t *testing.T) {
+	lg := tolog.NewLogger("TestAccessLogCanonical")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	handler := AccessLogWith(AccessOptions{Logger: lg, Canonical: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tolog.AddCanonicalField(r.Context(), "user", "alice")
+		line := tolog.CanonicalFromContext(r.Context())
+		line.Add("cache", "miss")
+		line.Escalate(tolog.StatusWarning)
+		w.Write([]byte("ok"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 1)
+	assert.Contains(t, lines[0], " [warning] ")
+	assert.Contains(t, lines[0], "user=alice")
+	assert.Contains(t, lines[0], "cache=miss")
+	assert.Contains(t, lines[0], "path=/users")
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.2")
+	req.Header.Set("X-Real-IP", "198.51.100.3")
+	assert.Equal(t, "10.0.0.1", ClientIP(req, false))
+	assert.Equal(t, "203.0.113.7", ClientIP(req, true))
+	req.Header.Del("X-Forwarded-For")
+	assert.Equal(t, "198.51.100.3", ClientIP(req, true))
+	req.RemoteAddr = "@"
+	assert.Equal(t, "@", ClientIP(req, false))
+
+	lg := tolog.NewLogger("TestClientIP")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+	handler := AccessLogWith(AccessOptions{Logger: lg, TrustProxyHeaders: true})(http.NotFoundHandler())
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Contains(t, out.String(), "client_ip=198.51.100.3")
+	assert.Contains(t, out.String(), "status=404")
+}
@@ -0,0 +1,55 @@
+package httplog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/callme-taota/tolog"
+)
+
+// errorResponse is the body of the error responses of WriteError.
+type errorResponse struct {
+	Error tolog.APIError `json:"error"`
+}
+
+// WriteError logs the error of the request with its context and responds
+// with the status and a JSON body holding only the public message and the
+// correlation ID of the entry:
+//
+//	{"error":{"message":"payment failed","correlation_id":"4bf92f35..."}}
+//
+// The correlation ID is the request ID of the X-Request-ID header, generated
+// with the tolog.IDGenerator and set on the response when missing, so clients can report it. It returns
+// the payload written.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, message string, err error) tolog.APIError {
+	id := r.Header.Get("X-Request-ID")
+	if id == "" {
+		id = newRequestID()
+	}
+	l := tolog.FromContext(r.Context()).Error(message).Err(err).Fields(tolog.Fields{
+		tolog.RequestIDField: id,
+		"method":             r.Method,
+		"path":               r.URL.Path,
+		"status":             status,
+	})
+	l.PrintAndWriteSafe()
+	payload := l.PublicError(message)
+	w.Header().Set("X-Request-ID", id)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: payload})
+	return payload
+}
+
+// newRequestID returns a request ID of the tolog.IDGenerator if set, of 32
+// random hex digits otherwise.
+func newRequestID() string {
+	if g := tolog.CurrentIDGenerator(); g != nil {
+		return g.NewID()
+	}
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,55 @@
+package httplog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteError(t *testing.T) {
+	lg := tolog.NewLogger("TestWriteError")
+	defer lg.Close()
+	var out bytes.Buffer
+	lg.SetConsole(io.Discard)
+	lg.AddOutput(&out)
+
+	req := httptest.NewRequest(http.MethodPost, "/charges", nil)
+	req.Header.Set("X-Request-ID", "req-7")
+	req = req.WithContext(tolog.NewContext(req.Context(), lg))
+	rec := httptest.NewRecorder()
+	payload := WriteError(rec, req, http.StatusBadGateway, "payment failed", errors.New("dial tcp 10.0.0.3:443: connection refused"))
+
+	assert.Equal(t, tolog.APIError{Message: "payment failed", CorrelationID: "req-7"}, payload)
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.JSONEq(t, `{"error":{"message":"payment failed","correlation_id":"req-7"}}`, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), "10.0.0.3")
+	line := out.String()
+	assert.Contains(t, line, "payment failed")
+	assert.Contains(t, line, "request_id=req-7")
+	assert.Contains(t, line, "connection refused")
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	payload = WriteError(rec, req.WithContext(tolog.NewContext(req.Context(), lg)), http.StatusInternalServerError, "", nil)
+	assert.Equal(t, tolog.DefaultPublicMessage, payload.Message)
+	assert.Len(t, payload.CorrelationID, 32)
+	assert.Equal(t, payload.CorrelationID, rec.Header().Get("X-Request-ID"))
+}
+
+func TestWriteErrorIDGenerator(t *testing.T) {
+	tolog.SetIDGenerator(tolog.ULID)
+	defer tolog.SetIDGenerator(nil)
+	lg := tolog.NewLogger("TestWriteErrorIDGenerator")
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	payload := WriteError(httptest.NewRecorder(), req.WithContext(tolog.NewContext(req.Context(), lg)), http.StatusInternalServerError, "", nil)
+	assert.Len(t, payload.CorrelationID, 26)
+}
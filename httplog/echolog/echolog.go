@@ -0,0 +1,29 @@
+// Package echolog adapts the httplog middleware to the Echo framework.
+package echolog
+
+import (
+	"time"
+
+	"github.com/callme-taota/tolog/httplog"
+	"github.com/labstack/echo/v4"
+)
+
+// AccessLog returns Echo middleware writing an entry per request with the
+// fields of httplog.AccessLogWith. Errors of the handlers are passed to the
+// error handler of Echo first, so the entry has the status answered.
+func AccessLog(opts httplog.AccessOptions) echo.MiddlewareFunc {
+	a := httplog.NewAccessLogger(opts)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			c.SetRequest(a.Begin(c.Request()))
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+			res := c.Response()
+			a.Log(c.Request(), res.Status, int(res.Size), time.Since(start))
+			return nil
+		}
+	}
+}
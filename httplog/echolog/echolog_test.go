@@ -0,0 +1,62 @@
+package echolog
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+	"github.com/callme-taota/tolog/httplog"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain writes the log files of the tests to a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "echolog-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	tolog.SetLogDir(dir)
+	code := m.Run()
+	tolog.CloseLogFile()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestAccessLog(t *testing.T) {
+	lg := tolog.NewLogger("TestEchoAccessLog")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	e := echo.New()
+	e.Use(AccessLog(httplog.AccessOptions{Logger: lg, TrustProxyHeaders: true}))
+	e.GET("/ok", func(c echo.Context) error {
+		return c.String(http.StatusOK, "fine")
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusTeapot, "no coffee")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	req.Header.Set("X-Request-ID", "r1")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	logged := out.String()
+	assert.Contains(t, logged, "path=/ok")
+	assert.Contains(t, logged, "status=200")
+	assert.Contains(t, logged, "bytes=4")
+	assert.Contains(t, logged, "client_ip=203.0.113.9")
+	assert.Contains(t, logged, "request_id=r1")
+	assert.Contains(t, logged, "status=418")
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+}
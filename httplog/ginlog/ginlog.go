@@ -0,0 +1,25 @@
+// Package ginlog adapts the httplog middleware to the Gin router.
+package ginlog
+
+import (
+	"time"
+
+	"github.com/callme-taota/tolog/httplog"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog returns Gin middleware writing an entry per request with the
+// fields of httplog.AccessLogWith.
+func AccessLog(opts httplog.AccessOptions) gin.HandlerFunc {
+	a := httplog.NewAccessLogger(opts)
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Request = a.Begin(c.Request)
+		c.Next()
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+		a.Log(c.Request, c.Writer.Status(), size, time.Since(start))
+	}
+}
@@ -0,0 +1,61 @@
+package ginlog
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+	"github.com/callme-taota/tolog/httplog"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain writes the log files of the tests to a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "ginlog-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	tolog.SetLogDir(dir)
+	code := m.Run()
+	tolog.CloseLogFile()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lg := tolog.NewLogger("TestGinAccessLog")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	r := gin.New()
+	r.Use(AccessLog(httplog.AccessOptions{Logger: lg, Canonical: true}))
+	r.GET("/users/:id", func(c *gin.Context) {
+		tolog.AddCanonicalField(c.Request.Context(), "user", c.Param("id"))
+		c.String(http.StatusAccepted, "ok")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-Request-ID", "abc")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	logged := out.String()
+	assert.Contains(t, logged, "method=GET")
+	assert.Contains(t, logged, "path=/users/7")
+	assert.Contains(t, logged, "status=202")
+	assert.Contains(t, logged, "bytes=2")
+	assert.Contains(t, logged, "client_ip=192.0.2.1")
+	assert.Contains(t, logged, "request_id=abc")
+	assert.Contains(t, logged, "user=7")
+	assert.Contains(t, logged, "status=404")
+}
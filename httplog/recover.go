@@ -0,0 +1,99 @@
+// Package httplog provides net/http middleware logging with tolog.
+package httplog
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/callme-taota/tolog"
+)
+
+// DefaultRedactedHeaders are the headers whose values are never logged.
+var DefaultRedactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization", "X-Api-Key"}
+
+// Redacted replaces the values of redacted headers.
+const Redacted = "[REDACTED]"
+
+// RecoverOptions configures the recover middleware.
+type RecoverOptions struct {
+	// Logger the panics are logged with, the default logger if nil.
+	Logger *tolog.Logger
+	// RedactedHeaders are logged without their values, DefaultRedactedHeaders if nil.
+	RedactedHeaders []string
+	// RequestIDHeader is the header of the correlation ID, X-Request-ID if empty.
+	RequestIDHeader string
+}
+
+// Recover returns middleware logging the panics of the next handler with the
+// default options and responding 500.
+func Recover(next http.Handler) http.Handler {
+	return RecoverWith(RecoverOptions{})(next)
+}
+
+// RecoverWith returns middleware logging the panics of the next handler with the
+// stack, the method, path and headers of the request and its correlation ID,
+// and responding 500 if nothing was written yet. http.ErrAbortHandler is
+// passed on, as net/http expects.
+func RecoverWith(opts RecoverOptions) func(http.Handler) http.Handler {
+	lg := opts.Logger
+	if lg == nil {
+		lg = tolog.Default()
+	}
+	redacted := opts.RedactedHeaders
+	if redacted == nil {
+		redacted = DefaultRedactedHeaders
+	}
+	idHeader := opts.RequestIDHeader
+	if idHeader == "" {
+		idHeader = "X-Request-ID"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &responseWriter{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				fields := tolog.Fields{
+					"method":  r.Method,
+					"path":    r.URL.Path,
+					"headers": headerSnapshot(r.Header, redacted),
+				}
+				if id := r.Header.Get(idHeader); id != "" {
+					fields[tolog.RequestIDField] = id
+				}
+				lg.ErrorCtx(r.Context(), "panic recovered").Panic(v).Fields(fields).PrintAndWriteSafe()
+				if !rw.wroteHeader {
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// headerSnapshot formats the headers sorted by name, without the values of redacted headers.
+func headerSnapshot(header http.Header, redacted []string) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		for _, r := range redacted {
+			if strings.EqualFold(name, r) {
+				value = Redacted
+				break
+			}
+		}
+		parts = append(parts, name+": "+value)
+	}
+	return strings.Join(parts, "; ")
+}
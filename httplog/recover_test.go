@@ -0,0 +1,60 @@
+package httplog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	lg := tolog.NewLogger("TestRecover")
+	defer lg.Close()
+	var out bytes.Buffer
+	lg.SetConsole(io.Discard)
+	lg.AddOutput(&out)
+
+	handler := RecoverWith(RecoverOptions{Logger: lg})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/orders?id=1", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Request-ID", "req-42")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	line := out.String()
+	assert.Contains(t, line, "panic recovered")
+	assert.Contains(t, line, "panic=boom")
+	assert.Contains(t, line, "method=POST")
+	assert.Contains(t, line, "path=/orders")
+	assert.Contains(t, line, "request_id=req-42")
+	assert.Contains(t, line, "Authorization: [REDACTED]")
+	assert.NotContains(t, line, "secret")
+	assert.Contains(t, line, "recover_test.go")
+}
+
+func TestRecoverStartedResponse(t *testing.T) {
+	lg := tolog.NewLogger("TestRecover")
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+
+	handler := RecoverWith(RecoverOptions{Logger: lg})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	assert.Panics(t, func() {
+		Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,121 @@
+package tolog
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IDGenerator generates the run IDs of SetRunID, the correlation IDs of the
+// middlewares and the entry IDs of SetEntryIDs once set with SetIDGenerator,
+// e.g. ULID or UUIDv7 for IDs sorting by time in downstream stores.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc is an IDGenerator of a function.
+type IDGeneratorFunc func() string
+
+// NewID returns the ID returned by the function.
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// The built-in generators.
+var (
+	UUIDv4 IDGenerator = IDGeneratorFunc(newUUID)   // random UUIDs
+	UUIDv7 IDGenerator = IDGeneratorFunc(newUUIDv7) // UUIDs sorting by time, RFC 9562
+	ULID   IDGenerator = IDGeneratorFunc(newULID)   // 26 characters sorting by time, monotonic within a millisecond
+)
+
+type idGeneratorBox struct{ g IDGenerator }
+
+var idGenerator atomic.Value // idGeneratorBox
+
+// SetIDGenerator sets the generator of the IDs, nil restores the defaults:
+// random UUIDs for run IDs, 32 random hex digits for correlation IDs and
+// hashes for entry IDs.
+func SetIDGenerator(g IDGenerator) {
+	idGenerator.Store(idGeneratorBox{g: g})
+}
+
+// CurrentIDGenerator returns the generator set with SetIDGenerator, nil if none.
+func CurrentIDGenerator() IDGenerator {
+	box, _ := idGenerator.Load().(idGeneratorBox)
+	return box.g
+}
+
+// newID returns an ID of the set generator, of fallback if none.
+func newID(fallback func() string) string {
+	if g := CurrentIDGenerator(); g != nil {
+		return g.NewID()
+	}
+	return fallback()
+}
+
+// newUUIDv7 returns a version 7 UUID: the Unix time in milliseconds, then the
+// fraction of the millisecond in 12 bits, so UUIDs of a process sort by time,
+// and random bits.
+func newUUIDv7() string {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		handleError(err)
+	}
+	now := time.Now()
+	ms := now.UnixMilli()
+	frac := uint16(now.Nanosecond() % int(time.Millisecond) * 4096 / int(time.Millisecond))
+	binary.BigEndian.PutUint64(b[0:8], uint64(ms)<<16)
+	binary.BigEndian.PutUint16(b[6:8], 0x7000|frac)
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// crockford is the alphabet of ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var ulidState struct {
+	sync.Mutex
+	ms      int64
+	entropy [10]byte
+}
+
+// newULID returns a ULID: the Unix time in milliseconds and 80 random bits,
+// incremented instead within the same millisecond so ULIDs stay ordered.
+func newULID() string {
+	ms := time.Now().UnixMilli()
+	ulidState.Lock()
+	if ms > ulidState.ms {
+		ulidState.ms = ms
+		if _, err := rand.Read(ulidState.entropy[:]); err != nil {
+			handleError(err)
+		}
+	} else {
+		ms = ulidState.ms
+		for i := len(ulidState.entropy) - 1; i >= 0; i-- {
+			ulidState.entropy[i]++
+			if ulidState.entropy[i] != 0 {
+				break
+			}
+		}
+	}
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[0:8], uint64(ms)<<16)
+	copy(b[6:], ulidState.entropy[:])
+	ulidState.Unlock()
+	return encodeULID(b)
+}
+
+// encodeULID encodes the 128 bits in 26 characters of 5 bits, the first of 3.
+func encodeULID(b [16]byte) string {
+	hi, lo := binary.BigEndian.Uint64(b[0:8]), binary.BigEndian.Uint64(b[8:16])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
@@ -0,0 +1,49 @@
+package tolog
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestULID(t *testing.T) {
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = ULID.NewID()
+	}
+	assert.Regexp(t, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`), ids[0])
+	assert.True(t, sort.StringsAreSorted(ids), "monotonic")
+	assert.NotEqual(t, ids[0], ids[1])
+	assert.Equal(t, "00000000000000000000000000", encodeULID([16]byte{}))
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeULID([16]byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255}))
+}
+
+func TestUUIDv7(t *testing.T) {
+	a, b := UUIDv7.NewID(), UUIDv7.NewID()
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), a)
+	assert.LessOrEqual(t, a[:13], b[:13], "the milliseconds sort by time")
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4`), UUIDv4.NewID())
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	SetIDGenerator(IDGeneratorFunc(func() string { return "fixed" }))
+	defer SetIDGenerator(nil)
+	assert.Equal(t, "fixed", SetRunID(""))
+	runID.Store("")
+	SetGlobalFields(nil)
+
+	SetEntryIDs(true)
+	defer SetEntryIDs(false)
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+	Info("identified").WriteSafe()
+	if assert.Len(t, sink.entries, 1) {
+		assert.Equal(t, "fixed", sink.entries[0].Fields[EntryIDField])
+	}
+
+	SetIDGenerator(nil)
+	assert.Nil(t, CurrentIDGenerator())
+}
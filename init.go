@@ -0,0 +1,93 @@
+package tolog
+
+import (
+	"context"
+	"time"
+)
+
+// cleanupTimeout bounds the Shutdown of the cleanup of NewLoggerForWire.
+const cleanupTimeout = 10 * time.Second
+
+// InitOption sets up the configuration applied by Init.
+type InitOption func(c *Config) error
+
+// InitConfig starts from the configuration.
+func InitConfig(cfg Config) InitOption {
+	return func(c *Config) error {
+		*c = cfg
+		return nil
+	}
+}
+
+// InitConfigFile reads the configuration file, YAML or JSON by its extension,
+// overriding the settings it sets.
+func InitConfigFile(path string) InitOption {
+	return func(c *Config) error {
+		return c.readFile(path)
+	}
+}
+
+// InitFromEnv reads the environment variables of ConfigureFromEnv, overriding
+// the settings they set.
+func InitFromEnv() InitOption {
+	return func(c *Config) error {
+		c.readEnv()
+		return nil
+	}
+}
+
+// InitPrefix sets the prefix of the log files of the default logger.
+func InitPrefix(prefix string) InitOption {
+	return func(c *Config) error {
+		c.Prefix = &prefix
+		return nil
+	}
+}
+
+// InitDir sets the directory of the log files.
+func InitDir(dir string) InitOption {
+	return func(c *Config) error {
+		c.Dir = dir
+		return nil
+	}
+}
+
+// Init sets up logging eagerly, for applications which want to report errors
+// at startup instead of at the first entry: it applies the configuration of
+// the options, in order, and opens the log file of the default logger,
+// returning the first error. Nothing is applied when the configuration is
+// invalid. Without Init the log file is opened by the first entry written.
+// The deprecated LogWithColor, LogTimeZone and LogfilePrefix variables, when
+// assigned, are applied first.
+func Init(opts ...InitOption) error {
+	adoptDeprecated()
+	var c Config
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			return err
+		}
+	}
+	if err := c.Apply(); err != nil {
+		return err
+	}
+	return std.fileWriter().open()
+}
+
+// NewLoggerForWire is a provider for dependency injection with cleanups, like
+// those of google/wire: it sets up logging with Init and returns the default
+// logger, with a cleanup flushing and closing the log files and the sinks with
+// Shutdown, so the last entries are not lost when the application stops. The
+// fxlog module does the same for go.uber.org/fx.
+func NewLoggerForWire(opts ...InitOption) (*Logger, func(), error) {
+	if err := Init(opts...); err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+		if err := Shutdown(ctx); err != nil {
+			handleError(err)
+		}
+	}
+	return std, cleanup, nil
+}
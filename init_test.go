@@ -0,0 +1,50 @@
+package tolog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInit(t *testing.T) {
+	defer resetConfig()
+	defer SetLogPrefix("")
+	dir := t.TempDir()
+	config := filepath.Join(dir, "logging.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("level: warning\n"), 0644))
+
+	require.NoError(t, Init(InitConfigFile(config), InitDir(filepath.Join(dir, "logs")), InitPrefix("TestInit")))
+	defer CloseLogFile()
+	assert.FileExists(t, filepath.Join(dir, "logs", "TestInit-log-"+currentDay()+".log"), "opened eagerly")
+	assert.Equal(t, StatusWarning, consoleLevel())
+
+	assert.ErrorContains(t, Init(InitConfig(Config{Level: "loud"})), "loud")
+	assert.Error(t, Init(InitConfigFile(filepath.Join(dir, "missing.yaml"))))
+
+	blocked := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(blocked, nil, 0644))
+	assert.Error(t, Init(InitDir(filepath.Join(blocked, "logs")), InitPrefix("TestInitBlocked")))
+}
+
+func TestNewLoggerForWire(t *testing.T) {
+	defer SetLogPrefix("")
+	dir := t.TempDir()
+	SetBatchSize(1000)
+	defer SetBatchSize(0)
+
+	lg, cleanup, err := NewLoggerForWire(InitDir(filepath.Join(dir, "logs")), InitPrefix("TestNewLoggerForWire"))
+	require.NoError(t, err)
+	defer SetLogDir(testLogDir)
+	assert.Same(t, std, lg)
+	lg.Info("last words").WriteSafe()
+	cleanup()
+	data, err := os.ReadFile(filepath.Join(dir, "logs", "TestNewLoggerForWire-log-"+currentDay()+".log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "last words")
+
+	_, _, err = NewLoggerForWire(InitConfig(Config{Level: "loud"}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,7 @@
+// Package integration holds the end-to-end tests of the network sinks against
+// collectors running in Docker containers: a TCP collector, syslog-ng, Loki and
+// Kafka. They are a module of their own, for the Kafka client, gated by the
+// integration build tag and skipped when docker is not available:
+//
+//	cd integration && go test -tags integration ./...
+package integration
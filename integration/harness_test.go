@@ -0,0 +1,237 @@
+//go:build integration
+
+package integration
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The images of the collectors.
+const (
+	socatImage   = "alpine/socat:1.7.4.4" // a TCP collector printing what it receives
+	syslogImage  = "balabit/syslog-ng:4.7.1"
+	lokiImage    = "grafana/loki:2.9.4"
+	kafkaImage   = "apache/kafka:3.7.0"
+	startTimeout = time.Minute
+)
+
+// spec describes a collector to run in Docker.
+type spec struct {
+	image    string
+	port     string   // the port of the container published on the host
+	hostPort string   // the port of the host, a free one if empty
+	env      []string // KEY=value
+	mounts   []string // host:container
+	args     []string
+	// ready reports whether the collector serves on the address of the host,
+	// by default whether the port accepts connections.
+	ready func(addr string) bool
+}
+
+// container is a collector running in Docker, removed at the end of the test.
+type container struct {
+	t    *testing.T
+	id   string
+	addr string // the address of its port on the host
+	spec spec
+}
+
+// startContainer runs the collector, publishing its port on the host, and
+// waits until it is ready.
+func startContainer(t *testing.T, s spec) *container {
+	t.Helper()
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker not available")
+	}
+	if s.hostPort == "" {
+		s.hostPort = freePort(t)
+	}
+	if s.ready == nil {
+		s.ready = accepting
+	}
+	run := []string{"run", "-d", "-p", "127.0.0.1:" + s.hostPort + ":" + s.port}
+	for _, env := range s.env {
+		run = append(run, "-e", env)
+	}
+	for _, mount := range s.mounts {
+		run = append(run, "-v", mount)
+	}
+	run = append(append(run, s.image), s.args...)
+	out, err := exec.Command("docker", run...).CombinedOutput()
+	require.NoError(t, err, "docker run %s\n%s", s.image, out)
+	c := &container{t: t, id: strings.TrimSpace(string(out)), addr: "127.0.0.1:" + s.hostPort, spec: s}
+	t.Cleanup(func() { exec.Command("docker", "rm", "-f", c.id).Run() })
+	c.waitReady()
+	return c
+}
+
+// freePort returns a port of the host nobody listens on.
+func freePort(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+}
+
+// accepting reports whether the address accepts connections.
+func accepting(addr string) bool {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// httpReady returns a check of the path answering 200 OK.
+func httpReady(path string) func(addr string) bool {
+	return func(addr string) bool {
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}
+}
+
+// waitReady waits until the collector is ready.
+func (c *container) waitReady() {
+	c.t.Helper()
+	require.Eventually(c.t, func() bool { return c.spec.ready(c.addr) }, startTimeout, 200*time.Millisecond,
+		"%s not ready on %s\n%s", c.spec.image, c.addr, strings.Join(c.lines(), "\n"))
+}
+
+// docker runs the docker command on the container.
+func (c *container) docker(command string) {
+	c.t.Helper()
+	out, err := exec.Command("docker", command, c.id).CombinedOutput()
+	require.NoError(c.t, err, "docker %s\n%s", command, out)
+}
+
+// stop stops the container, keeping its output.
+func (c *container) stop() {
+	c.t.Helper()
+	c.docker("stop")
+}
+
+// start starts the stopped container again, on the same port.
+func (c *container) start() {
+	c.t.Helper()
+	c.docker("start")
+	c.waitReady()
+}
+
+// lines returns the lines the container printed, since it was first started.
+func (c *container) lines() []string {
+	c.t.Helper()
+	out, err := exec.Command("docker", "logs", c.id).Output()
+	require.NoError(c.t, err, "docker logs")
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// waitLines waits until the lines the container printed match, returning
+// them, or fails the test after the timeout.
+func (c *container) waitLines(timeout time.Duration, match func(lines []string) bool) []string {
+	c.t.Helper()
+	var lines []string
+	require.Eventually(c.t, func() bool {
+		lines = c.lines()
+		return match(lines)
+	}, timeout, 200*time.Millisecond, "collector output:\n%s", strings.Join(lines, "\n"))
+	return lines
+}
+
+// startCollector runs a TCP collector printing the lines it receives.
+func startCollector(t *testing.T) *container {
+	return startContainer(t, spec{
+		image: socatImage,
+		port:  "5140",
+		args:  []string{"-u", "TCP-LISTEN:5140,fork,reuseaddr", "STDOUT"},
+	})
+}
+
+// syslogConfig makes syslog-ng receive on TCP and print the priority, the
+// tag and the message of every entry.
+const syslogConfig = `@version: 4.7
+source s_net { network(transport("tcp") port(601)); };
+destination d_out { file("/dev/stdout" template("<${PRI}> ${PROGRAM}[${PID}]: ${MESSAGE}\n")); };
+log { source(s_net); destination(d_out); };
+`
+
+// startSyslog runs a syslog-ng daemon receiving over TCP.
+func startSyslog(t *testing.T) *container {
+	t.Helper()
+	conf := filepath.Join(t.TempDir(), "syslog-ng.conf")
+	require.NoError(t, os.WriteFile(conf, []byte(syslogConfig), 0644))
+	return startContainer(t, spec{
+		image:  syslogImage,
+		port:   "601",
+		mounts: []string{conf + ":/etc/syslog-ng/syslog-ng.conf:ro"},
+	})
+}
+
+// startLoki runs a single Loki instance, its push and query API on the address.
+func startLoki(t *testing.T) *container {
+	return startContainer(t, spec{
+		image: lokiImage,
+		port:  "3100",
+		ready: httpReady("/ready"),
+	})
+}
+
+// startKafka runs a single Kafka broker in KRaft mode, advertising the port
+// of the host so the clients reach it.
+func startKafka(t *testing.T) *container {
+	hostPort := freePort(t)
+	return startContainer(t, spec{
+		image:    kafkaImage,
+		port:     "9092",
+		hostPort: hostPort,
+		env: []string{
+			"KAFKA_NODE_ID=1",
+			"KAFKA_PROCESS_ROLES=broker,controller",
+			"KAFKA_LISTENERS=PLAINTEXT://:9092,CONTROLLER://:9093",
+			"KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://127.0.0.1:" + hostPort,
+			"KAFKA_CONTROLLER_LISTENER_NAMES=CONTROLLER",
+			"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP=CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+			"KAFKA_CONTROLLER_QUORUM_VOTERS=1@localhost:9093",
+			"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR=1",
+			"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR=1",
+			"KAFKA_TRANSACTION_STATE_LOG_MIN_ISR=1",
+		},
+		ready: kafkaReady,
+	})
+}
+
+// countContaining returns how many lines contain all the substrings.
+func countContaining(lines []string, substrs ...string) int {
+	n := 0
+next:
+	for _, line := range lines {
+		for _, substr := range substrs {
+			if !strings.Contains(line, substr) {
+				continue next
+			}
+		}
+		n++
+	}
+	return n
+}
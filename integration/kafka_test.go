@@ -0,0 +1,93 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/callme-taota/tolog"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// kafkaReady reports whether the broker answers its metadata.
+func kafkaReady(addr string) bool {
+	conn, err := kafka.Dial("tcp", addr)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	_, err = conn.Brokers()
+	return err == nil
+}
+
+// createTopic creates the topic with a single partition.
+func createTopic(t *testing.T, addr string, topic string) {
+	t.Helper()
+	conn, err := kafka.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	controller, err := conn.Controller()
+	require.NoError(t, err)
+	cc, err := kafka.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
+	require.NoError(t, err)
+	defer cc.Close()
+	require.NoError(t, cc.CreateTopics(kafka.TopicConfig{Topic: topic, NumPartitions: 1, ReplicationFactor: 1}))
+}
+
+// TestPublishSinkKafka checks that the entries published to Kafka land on the
+// topic of their level, every one once and in order.
+func TestPublishSinkKafka(t *testing.T) {
+	c := startKafka(t)
+	w := &kafka.Writer{Addr: kafka.TCP(c.addr), BatchSize: 1, RequiredAcks: kafka.RequireAll}
+	defer w.Close()
+	s, err := tolog.NewPublishSink(tolog.PublishSinkOptions{
+		Publisher: tolog.PublisherFunc(func(topic string, data []byte) error {
+			return w.WriteMessages(context.Background(), kafka.Message{Topic: topic, Value: data})
+		}),
+		Subject: "logs.{service}.{level}",
+		Service: "integration",
+	})
+	require.NoError(t, err)
+	topic := s.Subject(tolog.StatusWarning)
+	createTopic(t, c.addr, topic)
+
+	const n = 200
+	for i := 0; i < n; i++ {
+		e := &tolog.Entry{Time: time.Now(), Level: tolog.StatusWarning, Message: "shipped", Fields: tolog.Fields{"seq": i}}
+		require.NoError(t, s.WriteEntry(e), "entry %d", i)
+	}
+	require.NoError(t, s.Close())
+
+	r := kafka.NewReader(kafka.ReaderConfig{Brokers: []string{c.addr}, Topic: topic, MaxWait: 500 * time.Millisecond})
+	defer r.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for i := 0; i < n; i++ {
+		m, err := r.ReadMessage(ctx)
+		require.NoError(t, err, "message %d", i)
+		var got struct {
+			Msg    string `json:"msg"`
+			Level  string `json:"level"`
+			Fields struct {
+				Seq int `json:"seq"`
+			} `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(m.Value, &got), "message %d %q", i, m.Value)
+		assert.Equal(t, "shipped", got.Msg)
+		assert.Equal(t, string(tolog.StatusWarning), got.Level)
+		require.Equal(t, i, got.Fields.Seq, "message %d", i)
+	}
+	leader, err := kafka.DialLeader(ctx, "tcp", c.addr, topic, 0)
+	require.NoError(t, err)
+	defer leader.Close()
+	last, err := leader.ReadLastOffset()
+	require.NoError(t, err)
+	assert.Equal(t, int64(n), last, "no message published twice")
+}
@@ -0,0 +1,119 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/callme-taota/tolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lokiPublisher pushes every message to Loki as a line of the stream labelled
+// with its subject.
+func lokiPublisher(addr string) tolog.Publisher {
+	return tolog.PublisherFunc(func(subject string, data []byte) error {
+		body, err := json.Marshal(map[string]any{"streams": []any{map[string]any{
+			"stream": map[string]string{"subject": subject},
+			"values": [][]string{{strconv.FormatInt(time.Now().UnixNano(), 10), string(data)}},
+		}}})
+		if err != nil {
+			return err
+		}
+		resp, err := http.Post("http://"+addr+"/loki/api/v1/push", "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			return fmt.Errorf("loki push: %s", resp.Status)
+		}
+		return nil
+	})
+}
+
+// queryLoki returns the lines of the stream of the subject, oldest first.
+func queryLoki(t *testing.T, addr string, subject string, since time.Time) []string {
+	t.Helper()
+	q := url.Values{
+		"query":     {fmt.Sprintf(`{subject=%q}`, subject)},
+		"start":     {strconv.FormatInt(since.UnixNano(), 10)},
+		"limit":     {"5000"},
+		"direction": {"forward"},
+	}
+	resp, err := http.Get("http://" + addr + "/loki/api/v1/query_range?" + q.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result struct {
+		Data struct {
+			Result []struct {
+				Values [][2]string `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	var lines []string
+	for _, stream := range result.Data.Result {
+		for _, v := range stream.Values {
+			lines = append(lines, v[1])
+		}
+	}
+	return lines
+}
+
+// TestPublishSinkLoki checks that the entries published to Loki land in the
+// stream of their level, every one once and in order.
+func TestPublishSinkLoki(t *testing.T) {
+	c := startLoki(t)
+	since := time.Now()
+	s, err := tolog.NewPublishSink(tolog.PublishSinkOptions{
+		Publisher: lokiPublisher(c.addr),
+		Subject:   "logs.{service}.{level}",
+		Service:   "integration",
+	})
+	require.NoError(t, err)
+	const n = 200
+	for i := 0; i < n; i++ {
+		level := tolog.StatusInfo
+		if i%10 == 0 {
+			level = tolog.StatusError
+		}
+		e := &tolog.Entry{Time: time.Now(), Level: level, Message: "shipped", Fields: tolog.Fields{"seq": i}}
+		require.NoError(t, s.WriteEntry(e), "entry %d", i)
+	}
+	require.NoError(t, s.Close())
+
+	for level, want := range map[tolog.LogStatus]int{tolog.StatusInfo: n - n/10, tolog.StatusError: n / 10} {
+		subject := s.Subject(level)
+		var lines []string
+		require.Eventually(t, func() bool {
+			lines = queryLoki(t, c.addr, subject, since)
+			return len(lines) >= want
+		}, 30*time.Second, 500*time.Millisecond, "stream %s", subject)
+		assert.Len(t, lines, want, "stream %s", subject)
+		prev := -1
+		for _, line := range lines {
+			var got struct {
+				Msg    string `json:"msg"`
+				Level  string `json:"level"`
+				Fields struct {
+					Seq int `json:"seq"`
+				} `json:"fields"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(line), &got), "line %q", line)
+			assert.Equal(t, "shipped", got.Msg)
+			assert.Equal(t, string(level), got.Level)
+			assert.Greater(t, got.Fields.Seq, prev, "stream %s out of order", subject)
+			prev = got.Fields.Seq
+		}
+	}
+}
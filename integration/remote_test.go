@@ -0,0 +1,74 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/callme-taota/tolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRemoteSinkDelivery checks that every entry written before Close reaches
+// the collector, in order.
+func TestRemoteSinkDelivery(t *testing.T) {
+	c := startCollector(t)
+	s := tolog.NewRemoteSink("tcp", c.addr, tolog.FormatJSON)
+	const n = 1000
+	for i := 0; i < n; i++ {
+		e := &tolog.Entry{Time: time.Now(), Level: tolog.StatusInfo, Message: "delivered", Fields: tolog.Fields{"seq": i}}
+		require.NoError(t, s.WriteEntry(e), "entry %d", i)
+	}
+	require.NoError(t, s.Close())
+
+	lines := c.waitLines(30*time.Second, func(lines []string) bool { return len(lines) >= n })
+	for i, line := range lines {
+		var got struct {
+			Msg    string `json:"msg"`
+			Fields struct {
+				Seq int `json:"seq"`
+			} `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &got), "line %d %q", i, line)
+		require.Equal(t, "delivered", got.Msg, "line %d", i)
+		require.Equal(t, i, got.Fields.Seq, "line %d", i)
+	}
+	assert.Len(t, lines, n)
+}
+
+// TestRemoteSinkReconnect checks that the entries written while the collector
+// is down are queued and delivered once it is back. The entries written to the
+// connection before the sink notices it broke may be lost, delivery is at
+// most once.
+func TestRemoteSinkReconnect(t *testing.T) {
+	c := startCollector(t)
+	s := tolog.NewRemoteSink("tcp", c.addr, tolog.FormatJSON)
+	defer s.Close()
+	write := func(msg string, i int) {
+		t.Helper()
+		e := &tolog.Entry{Time: time.Now(), Level: tolog.StatusInfo, Message: msg, Fields: tolog.Fields{"seq": i}}
+		require.NoError(t, s.WriteEntry(e), "%s %d", msg, i)
+	}
+	write("before", 0)
+	c.waitLines(30*time.Second, func(lines []string) bool { return countContaining(lines, `"msg":"before"`) == 1 })
+
+	c.stop()
+	for i := 0; i < 100; i++ {
+		write("during", i)
+	}
+	c.start()
+	for i := 0; i < 100; i++ {
+		write("after", i)
+	}
+
+	lines := c.waitLines(time.Minute, func(lines []string) bool {
+		return countContaining(lines, `"msg":"after"`, `"seq":99}`) == 1
+	})
+	during := countContaining(lines, `"msg":"during"`)
+	after := countContaining(lines, `"msg":"after"`)
+	t.Logf("delivered %d of the entries written while down, %d of those written after", during, after)
+	assert.Equal(t, 100, after, "entries written after the restart delivered")
+}
@@ -0,0 +1,42 @@
+//go:build integration && !windows && !plan9
+
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/callme-taota/tolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSyslogSink checks that the entries reach a syslog daemon over TCP with
+// the priority of their level, the tag and their fields.
+func TestSyslogSink(t *testing.T) {
+	c := startSyslog(t)
+	s, err := tolog.NewSyslogSink("tcp", c.addr, "integration")
+	require.NoError(t, err)
+	entries := []*tolog.Entry{
+		{Time: time.Now(), Level: tolog.StatusInfo, Message: "started", Fields: tolog.Fields{"port": 8080}},
+		{Time: time.Now(), Level: tolog.StatusError, Message: "failed"},
+	}
+	for _, e := range entries {
+		require.NoError(t, s.WriteEntry(e))
+	}
+	require.NoError(t, s.Close())
+
+	lines := c.waitLines(30*time.Second, func(lines []string) bool {
+		return countContaining(lines, "integration[") >= 2
+	})
+	var got []string
+	for _, line := range lines {
+		if countContaining([]string{line}, "integration[") == 1 {
+			got = append(got, line)
+		}
+	}
+	// user facility (8) plus the severity: informational 6, error 3
+	require.Len(t, got, 2)
+	assert.Regexp(t, `^<14> integration\[\d+\]: started port=8080$`, got[0])
+	assert.Regexp(t, `^<11> integration\[\d+\]: failed$`, got[1])
+}
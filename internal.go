@@ -0,0 +1,125 @@
+package tolog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InternalLogger is the logger field of the messages of tolog itself.
+const InternalLogger = "tolog"
+
+// EventField is the field the event of a diagnostic is written in, see Diagnostic.
+const EventField = "event"
+
+var (
+	internalLevel atomic.Value // LogStatus
+	internalSink  atomic.Value // internalHolder
+
+	// internalQueue hands the messages to the goroutine writing them to the
+	// internal sink, so a sink reporting an error of its own, from its
+	// goroutine or while writing, never waits for itself. internalWriting is
+	// the entry being written to the sink, nil if none.
+	internalQueue   = make(chan internalMessage, 4096)
+	internalStart   sync.Once
+	internalWriting atomic.Pointer[Entry]
+)
+
+// internalMessage is an entry queued for the internal sink set when it was
+// logged, or a marker closing done once the entries queued before it are written.
+type internalMessage struct {
+	e    *Entry
+	sink Sink
+	done chan struct{}
+}
+
+type internalHolder struct{ sink Sink }
+
+// SetInternalLevel sets the minimum level of the messages of tolog itself,
+// error by default: the errors it cannot return to a caller, see
+// SetErrorHandler, are errors, and its diagnostics, see SetDiagnostics, debug
+// entries. Empty restores the default.
+func SetInternalLevel(level LogStatus) {
+	internalLevel.Store(level)
+}
+
+// SetInternalSink sends the messages of tolog itself to the sink, as entries
+// with the logger field tolog, instead of printing them on stderr in the text
+// format. They never go through the log files and the sinks of AddSink, which
+// may be what fails. DiscardSink silences them, nil restores stderr.
+func SetInternalSink(sink Sink) {
+	internalSink.Store(internalHolder{sink})
+}
+
+// DiscardSink is a sink dropping every entry, e.g. to silence the messages of
+// tolog itself with SetInternalSink.
+type DiscardSink struct{}
+
+func (DiscardSink) WriteEntry(e *Entry) error { return nil }
+func (DiscardSink) Close() error              { return nil }
+
+// internalEnabled reports whether the messages of tolog of the level are written.
+func internalEnabled(level LogStatus) bool {
+	min, _ := internalLevel.Load().(LogStatus)
+	if min == "" {
+		min = StatusError
+	}
+	return levelEnabled(level, min)
+}
+
+// logInternal queues a message of tolog itself for the internal sink, or
+// prints it on stderr when there is none. A message repeating the entry the
+// sink is writing is the sink reporting on itself and is dropped, and one not
+// fitting in the queue is printed.
+func logInternal(level LogStatus, msg string, fields Fields) {
+	if !internalEnabled(level) {
+		return
+	}
+	e := &Entry{Time: time.Now().In(globalTimeZone()), Level: level, Message: msg, Fields: Fields{LoggerField: InternalLogger}.merge(fields)}
+	holder, _ := internalSink.Load().(internalHolder)
+	if holder.sink == nil {
+		printInternal(e)
+		return
+	}
+	if w := internalWriting.Load(); w != nil && w.Level == e.Level && w.Message == e.Message {
+		return
+	}
+	internalStart.Do(func() { go drainInternal() })
+	select {
+	case internalQueue <- internalMessage{e: e, sink: holder.sink}:
+	default:
+		printInternal(e)
+	}
+}
+
+// drainInternal writes the queued messages to their sinks, printing them on
+// stderr when it fails.
+func drainInternal() {
+	for m := range internalQueue {
+		if m.done != nil {
+			close(m.done)
+			continue
+		}
+		internalWriting.Store(m.e)
+		err := m.sink.WriteEntry(m.e)
+		internalWriting.Store(nil)
+		if err != nil {
+			printInternal(&Entry{Time: m.e.Time, Level: StatusError, Message: "internal sink failed: " + err.Error(), Fields: Fields{LoggerField: InternalLogger}})
+			printInternal(m.e)
+		}
+	}
+}
+
+// flushInternal waits until the messages queued for the internal sink are written.
+func flushInternal() {
+	internalStart.Do(func() { go drainInternal() })
+	done := make(chan struct{})
+	internalQueue <- internalMessage{done: done}
+	<-done
+}
+
+// printInternal prints the message on stderr in the text format.
+func printInternal(e *Entry) {
+	line, _ := TextEncoder{}.Encode(e)
+	stderr().Write(append(line, '\n'))
+}
@@ -0,0 +1,137 @@
+package tolog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternalLogger(t *testing.T) {
+	sink := &memorySink{}
+	SetInternalSink(sink)
+	defer SetInternalSink(nil)
+
+	handleError(errors.New("disk full"))
+	lg := NewLogger("TestInternalLogger")
+	lg.Info("diagnosed").WriteSafe()
+	lg.Close()
+	flushInternal()
+
+	sink.mu.Lock()
+	entries := sink.entries
+	sink.mu.Unlock()
+	require.Len(t, entries, 1)
+	assert.Equal(t, StatusError, entries[0].Level)
+	assert.Equal(t, "disk full", entries[0].Message)
+	assert.Equal(t, InternalLogger, entries[0].Fields[LoggerField])
+
+	SetInternalLevel(StatusDebug)
+	defer SetInternalLevel("")
+	lg = NewLogger("TestInternalLogger")
+	lg.Info("diagnosed").WriteSafe()
+	lg.Close()
+	flushInternal()
+	sink.mu.Lock()
+	entries = sink.entries[1:]
+	sink.mu.Unlock()
+	require.NotEmpty(t, entries)
+	assert.Equal(t, StatusDebug, entries[0].Level)
+	assert.Equal(t, "reopen", entries[0].Fields[EventField])
+
+	SetInternalSink(DiscardSink{})
+	handleError(errors.New("silenced"))
+}
+
+// recursiveSink reports an error of its own for every entry, like a sink
+// whose connection fails.
+type recursiveSink struct{ memorySink }
+
+func (s *recursiveSink) WriteEntry(e *Entry) error {
+	s.memorySink.WriteEntry(e)
+	handleError(errors.New("sink broken"))
+	return nil
+}
+
+func TestInternalLoggerConcurrent(t *testing.T) {
+	saved := os.Stderr
+	f, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	require.NoError(t, err)
+	os.Stderr = f
+	defer func() { os.Stderr = saved }()
+
+	SetInternalSink(DiscardSink{})
+	defer SetInternalSink(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				handleError(errors.New("concurrent"))
+			}
+		}()
+	}
+	wg.Wait()
+	flushInternal()
+	data, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	assert.Empty(t, string(data))
+
+	sink := &recursiveSink{}
+	SetInternalSink(sink)
+	handleError(errors.New("first"))
+	flushInternal()
+	data, err = os.ReadFile(f.Name())
+	require.NoError(t, err)
+	assert.Empty(t, string(data))
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.Len(t, sink.entries, 2)
+	assert.Equal(t, "first", sink.entries[0].Message)
+	assert.Equal(t, "sink broken", sink.entries[1].Message)
+}
+
+// reportingSink reports an error from another goroutine while writing and
+// waits for it, like a sink reconnecting in the background.
+type reportingSink struct{ memorySink }
+
+func (s *reportingSink) WriteEntry(e *Entry) error {
+	s.memorySink.WriteEntry(e)
+	if e.Message == "connect" {
+		done := make(chan struct{})
+		go func() {
+			handleError(errors.New("reconnecting"))
+			close(done)
+		}()
+		<-done
+	}
+	return nil
+}
+
+func TestInternalSinkReportingFromGoroutine(t *testing.T) {
+	sink := &reportingSink{}
+	SetInternalSink(sink)
+	defer SetInternalSink(nil)
+
+	handleError(errors.New("connect"))
+	done := make(chan struct{})
+	go func() {
+		flushInternal()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("internal sink deadlocked")
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.Len(t, sink.entries, 2)
+	assert.Equal(t, "reconnecting", sink.entries[1].Message)
+}
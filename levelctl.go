@@ -0,0 +1,132 @@
+package tolog
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The pending revert of a temporary level change, the time it is due and the
+// levels it restores.
+var (
+	levelRevert   *time.Timer
+	levelUntil    time.Time
+	revertConsole LogStatus
+	revertFile    LogStatus
+	levelRevertMu sync.Mutex
+)
+
+// SetLevel sets the minimum level of the console and the files at once, for
+// all loggers. Empty writes all entries. It cancels a pending SetLevelFor revert.
+func SetLevel(level LogStatus) {
+	levelRevertMu.Lock()
+	defer levelRevertMu.Unlock()
+	cancelLevelRevert()
+	setLevels(level, level)
+}
+
+// SetLevelFor sets the minimum level of the console and the files like
+// SetLevel, and restores the levels they had after the duration, e.g. to
+// debug a live process for ten minutes. A later change cancels the revert.
+func SetLevelFor(level LogStatus, d time.Duration) {
+	levelRevertMu.Lock()
+	defer levelRevertMu.Unlock()
+	s := globalSettings()
+	console, file := s.consoleLevel, s.fileLevel
+	if levelRevert != nil {
+		// extending a temporary change keeps the levels from before it
+		console, file = revertConsole, revertFile
+	}
+	cancelLevelRevert()
+	setLevels(level, level)
+	revertConsole, revertFile = console, file
+	levelUntil = time.Now().Add(d)
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		levelRevertMu.Lock()
+		defer levelRevertMu.Unlock()
+		if levelRevert != timer {
+			return
+		}
+		levelRevert = nil
+		levelUntil = time.Time{}
+		setLevels(console, file)
+		diag("level", "restored the levels console=%q file=%q", console, file)
+	})
+	levelRevert = timer
+	diag("level", "level %s for %s", level, d)
+}
+
+// cancelLevelRevert stops the pending revert, levelRevertMu must be held.
+func cancelLevelRevert() {
+	if levelRevert != nil {
+		levelRevert.Stop()
+		levelRevert = nil
+		levelUntil = time.Time{}
+	}
+}
+
+// setLevels sets the console and file levels in a single update.
+func setLevels(console LogStatus, file LogStatus) {
+	updateSettings(func(s *settings) {
+		s.consoleLevel = console
+		s.fileLevel = file
+	})
+}
+
+// levelState is the body of LevelHandler.
+type levelState struct {
+	Console LogStatus `json:"console"`
+	File    LogStatus `json:"file"`
+	Until   string    `json:"until,omitempty"`
+}
+
+// LevelHandler returns a handler to read and change the levels of a live
+// process, e.g. mounted on /loglevel. GET returns the console and file levels
+// as JSON. PUT or POST with the level parameter, in the query or a form, sets both, with a duration
+// parameter like 10m only for that long. An empty level writes all entries.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			if r.ParseForm(); !r.Form.Has("level") {
+				http.Error(w, "missing level", http.StatusBadRequest)
+				return
+			}
+			var level LogStatus
+			if name := r.Form.Get("level"); name != "" {
+				parsed, err := ParseLevel(name)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				level = parsed
+			}
+			if param := r.FormValue("duration"); param != "" {
+				d, err := time.ParseDuration(param)
+				if err != nil || d <= 0 {
+					http.Error(w, "invalid duration "+param, http.StatusBadRequest)
+					return
+				}
+				SetLevelFor(level, d)
+			} else {
+				SetLevel(level)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		levelRevertMu.Lock()
+		s := globalSettings()
+		state := levelState{Console: s.consoleLevel, File: s.fileLevel}
+		if !levelUntil.IsZero() {
+			state.Until = levelUntil.Format(time.RFC3339)
+		}
+		levelRevertMu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	})
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package tolog
+
+import "time"
+
+// LevelOnSIGHUP does nothing, there is no SIGHUP on this platform.
+func LevelOnSIGHUP(level LogStatus, d time.Duration) (stop func()) {
+	return func() {}
+}
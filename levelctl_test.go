@@ -0,0 +1,72 @@
+package tolog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLevelFor(t *testing.T) {
+	defer SetLevel("")
+	SetConsoleLevel(StatusWarning)
+	SetFileLevel(StatusInfo)
+
+	SetLevelFor(StatusDebug, 20*time.Millisecond)
+	assert.Equal(t, StatusDebug, consoleLevel())
+	assert.Equal(t, StatusDebug, fileLevel())
+	// extending keeps the levels from before
+	SetLevelFor(StatusDebug, 30*time.Millisecond)
+	assert.Eventually(t, func() bool { return consoleLevel() == StatusWarning }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, StatusInfo, fileLevel())
+
+	SetLevelFor(StatusDebug, 10*time.Millisecond)
+	SetLevel(StatusError)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, StatusError, consoleLevel(), "the revert is cancelled")
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer SetLevel("")
+	SetLevel(StatusInfo)
+	handler := LevelHandler()
+	call := func(method string, query string) (*httptest.ResponseRecorder, levelState) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/loglevel?"+query, nil))
+		var state levelState
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+		}
+		return rec, state
+	}
+
+	_, state := call(http.MethodGet, "")
+	assert.Equal(t, levelState{Console: StatusInfo, File: StatusInfo}, state)
+
+	_, state = call(http.MethodPut, "level=debug&duration=1h")
+	assert.Equal(t, StatusDebug, state.Console)
+	assert.Equal(t, StatusDebug, state.File)
+	assert.NotEmpty(t, state.Until)
+
+	req := httptest.NewRequest(http.MethodPost, "/loglevel", strings.NewReader("level=error"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	_, state = call(http.MethodGet, "")
+	assert.Equal(t, levelState{Console: StatusError, File: StatusError}, state, "the revert is cancelled")
+
+	rec, _ = call(http.MethodPost, "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec, _ = call(http.MethodPut, "level=loud")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec, _ = call(http.MethodPut, "level=debug&duration=soon")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec, _ = call(http.MethodDelete, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,33 @@
+//go:build unix
+
+package tolog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// LevelOnSIGHUP sets the level for the duration with SetLevelFor whenever the
+// process receives SIGHUP, e.g. kill -HUP to debug a live process. It returns
+// a function to stop handling the signal.
+func LevelOnSIGHUP(level LogStatus, d time.Duration) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-signals:
+				SetLevelFor(level, d)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
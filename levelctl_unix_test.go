@@ -0,0 +1,23 @@
+//go:build unix
+
+package tolog
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelOnSIGHUP(t *testing.T) {
+	defer SetLevel("")
+	SetLevel(StatusWarning)
+	stop := LevelOnSIGHUP(StatusDebug, time.Hour)
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	assert.Eventually(t, func() bool { return consoleLevel() == StatusDebug }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, StatusDebug, fileLevel())
+}
@@ -0,0 +1,106 @@
+package tolog
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// The problems of a LogDirError.
+const (
+	LogDirDanglingSymlink = "dangling symlink"
+	LogDirNotDirectory    = "not a directory"
+	LogDirReadOnly        = "read-only"
+)
+
+// LogDirError is returned when the directory of the log files cannot be
+// used, and passed to the error handler when the files fall back to a
+// temporary directory, see SetLogDirFallback.
+type LogDirError struct {
+	// Dir is the directory of the log files.
+	Dir string
+	// Problem is LogDirDanglingSymlink, LogDirNotDirectory or LogDirReadOnly.
+	Problem string
+	// Fallback is the directory the files are written to instead, empty
+	// without SetLogDirFallback.
+	Fallback string
+	// Err is the error of the file system.
+	Err error
+}
+
+func (e *LogDirError) Error() string {
+	msg := fmt.Sprintf("tolog: log directory %s unusable, %s: %v", e.Dir, e.Problem, e.Err)
+	if e.Fallback != "" {
+		msg += ", writing to " + e.Fallback
+	}
+	return msg
+}
+
+func (e *LogDirError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	logDirFallback int32
+	fallbackDir    atomic.Value // string
+)
+
+// SetLogDirFallback makes the log files fall back to a tolog directory in the
+// temporary directory of the system when the directory of the log files is a
+// dangling symlink, a file or on a read-only mount, instead of failing to
+// open them. The LogDirError is passed to the error handler. SetLogDir starts
+// over with the new directory.
+func SetLogDirFallback(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&logDirFallback, v)
+}
+
+// checkLogDir creates the directory of the log files if needed, returning a
+// LogDirError if it is a dangling symlink or not a directory.
+func checkLogDir(dir string) error {
+	fsys := logFS()
+	info, err := fsys.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = fsys.MkdirAll(dir, 0755)
+		if err == nil {
+			return nil
+		}
+		if _, ok := fsys.(OSFS); ok {
+			if link, lerr := os.Lstat(dir); lerr == nil && link.Mode()&fs.ModeSymlink != 0 {
+				return &LogDirError{Dir: dir, Problem: LogDirDanglingSymlink, Err: err}
+			}
+		}
+		if readOnly(err) {
+			return &LogDirError{Dir: dir, Problem: LogDirReadOnly, Err: err}
+		}
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	if err == nil && !info.IsDir() {
+		return &LogDirError{Dir: dir, Problem: LogDirNotDirectory, Err: fs.ErrExist}
+	}
+	return nil
+}
+
+// fallBack switches the log files to the fallback directory if enabled,
+// reporting the error to the error handler, or returns the error.
+func fallBack(err error) error {
+	var dirErr *LogDirError
+	if !errors.As(err, &dirErr) || atomic.LoadInt32(&logDirFallback) == 0 || dirErr.Fallback != "" {
+		return err
+	}
+	dir := filepath.Join(os.TempDir(), "tolog")
+	if err := logFS().MkdirAll(dir, 0755); err != nil {
+		return errors.Join(dirErr, err)
+	}
+	fallbackDir.Store(dir)
+	dirErr.Fallback = dir
+	diag("open", "falling back to %s: %v", dir, dirErr)
+	handleError(dirErr)
+	return nil
+}
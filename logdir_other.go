@@ -0,0 +1,9 @@
+//go:build !unix
+
+package tolog
+
+// readOnly reports false, read-only file systems are not told apart from
+// other errors on this platform.
+func readOnly(err error) bool {
+	return false
+}
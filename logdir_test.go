@@ -0,0 +1,67 @@
+package tolog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogDirError(t *testing.T) {
+	var mu sync.Mutex
+	var handled []error
+	SetErrorHandler(func(err error) {
+		mu.Lock()
+		handled = append(handled, err)
+		mu.Unlock()
+	})
+	defer SetErrorHandler(nil)
+	defer SetLogDir(testLogDir)
+	tmp := t.TempDir()
+
+	file := filepath.Join(tmp, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	dangling := filepath.Join(tmp, "dangling")
+	require.NoError(t, os.Symlink(filepath.Join(tmp, "missing", "logs"), dangling))
+
+	for dir, problem := range map[string]string{file: LogDirNotDirectory, dangling: LogDirDanglingSymlink} {
+		SetLogDir(dir)
+		lg := NewLogger("TestLogDirError")
+		err := lg.fileWriter().open()
+		var dirErr *LogDirError
+		if assert.True(t, errors.As(err, &dirErr), "%s: %v", dir, err) {
+			assert.Equal(t, dir, dirErr.Dir)
+			assert.Equal(t, problem, dirErr.Problem)
+			assert.Empty(t, dirErr.Fallback)
+		}
+		lg.Close()
+	}
+
+	SetLogDirFallback(true)
+	defer SetLogDirFallback(false)
+	SetLogDir(file)
+	lg := NewLogger("TestLogDirError")
+	lg.Info("kept").WriteSafe()
+	path := lg.FilePath()
+	defer os.Remove(path)
+	assert.NoError(t, lg.Close())
+	assert.Equal(t, filepath.Join(os.TempDir(), "tolog"), filepath.Dir(path))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "kept")
+
+	mu.Lock()
+	defer mu.Unlock()
+	var dirErr *LogDirError
+	if assert.Len(t, handled, 1) && assert.True(t, errors.As(handled[0], &dirErr)) {
+		assert.Equal(t, LogDirNotDirectory, dirErr.Problem)
+		assert.Equal(t, filepath.Dir(path), dirErr.Fallback)
+	}
+
+	SetLogDir(tmp)
+	assert.Equal(t, tmp, logDirectory(), "SetLogDir starts over")
+}
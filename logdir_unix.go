@@ -0,0 +1,13 @@
+//go:build unix
+
+package tolog
+
+import (
+	"errors"
+	"syscall"
+)
+
+// readOnly reports whether the error is of a read-only file system.
+func readOnly(err error) bool {
+	return errors.Is(err, syscall.EROFS)
+}
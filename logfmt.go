@@ -0,0 +1,34 @@
+package tolog
+
+import (
+	"bytes"
+	"time"
+)
+
+// FormatLogfmt encodes entries as time=... level=info msg="..." key=value, parsed
+// natively by Heroku style pipelines and Grafana agents and still readable.
+const FormatLogfmt LogFormat = "logfmt"
+
+func init() {
+	RegisterEncoder(FormatLogfmt, LogfmtEncoder{})
+}
+
+// LogfmtEncoder encodes entries like FormatLogfmt, the fields of groups with
+// dotted keys.
+type LogfmtEncoder struct{}
+
+// Encode encodes the entry as time=... level=info msg="..." key=value.
+func (LogfmtEncoder) Encode(e *Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("time=")
+	buf.WriteString(e.Time.Format(time.RFC3339Nano))
+	buf.WriteString(" level=")
+	buf.WriteString(string(e.Level))
+	buf.WriteString(" msg=")
+	appendFieldValue(&buf, e.Message)
+	if len(e.Fields) > 0 {
+		buf.WriteByte(' ')
+		appendFields(&buf, e.Fields)
+	}
+	return buf.Bytes(), nil
+}
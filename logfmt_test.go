@@ -0,0 +1,33 @@
+package tolog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmt(t *testing.T) {
+	lg := NewLogger("TestLogfmt")
+	defer lg.Close()
+	lg.SetFormat(FormatLogfmt)
+
+	l := lg.Warning(`disk "data" at 91%`).Fields(Fields{"free": "8 GiB", "mount": "/data", "http": Fields{"status": 507}})
+	want := "time=" + l.time.Format(time.RFC3339Nano) + ` level=warning msg="disk \"data\" at 91%" free="8 GiB" http.status=507 mount=/data`
+	assert.Equal(t, want, l.FullLog)
+
+	l = lg.Info("started")
+	assert.Equal(t, "time="+l.time.Format(time.RFC3339Nano)+" level=info msg=started", l.FullLog)
+	assert.True(t, knownFormat(FormatLogfmt))
+
+	l = lg.Error(`quoted "msg"`).WithField("path", "/a b")
+	e, err := ParseLine(l.FullLog + "\n")
+	if assert.NoError(t, err) {
+		assert.True(t, l.time.Equal(e.Time))
+		assert.Equal(t, StatusError, e.Level)
+		assert.Equal(t, `quoted "msg"`, e.Message)
+		assert.Equal(t, Fields{"path": "/a b"}, e.Fields)
+	}
+	_, err = ParseLine(`time=2024-01-02T00:00:00Z level=info msg="unterminated`)
+	assert.Error(t, err)
+}
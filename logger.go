@@ -0,0 +1,323 @@
+package tolog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Logger writes entries to the log file of its prefix. Loggers with the same
+// prefix share a single writer, so any number of them can write to the same file
+// safely: it is opened once, and lines are never duplicated or interleaved.
+// The package level functions use the default logger.
+type Logger struct {
+	mu      sync.RWMutex
+	prefix  string
+	writer  *fileWriter
+	console io.Writer
+	outputs []io.Writer
+	split   bool         // warnings and errors printed to stderr, see SetSplitStdStreams
+	outMu   sync.Mutex   // serializes the writes to the console and the outputs
+	routes  []levelRoute // replaced as a whole on change
+
+	progress    []byte    // the live line of a ProgressWriter, guarded by outMu
+	progressOut io.Writer // the writer of the live line
+
+	parent *Logger         // the logger whose file and outputs are used, if derived
+	ctx    context.Context // applied to every entry of a derived logger
+	name   string          // the name path of a child logger
+	fields Fields          // the fields of a child logger, never modified
+
+	settings atomic.Value // *settings set on the logger
+}
+
+var std = NewLogger("")
+
+// NewLogger creates a logger writing to the log file of the prefix.
+func NewLogger(prefix string) *Logger {
+	return &Logger{prefix: prefix, writer: acquireWriter(prefix)}
+}
+
+// Default returns the default logger used by the package level functions.
+func Default() *Logger {
+	return std
+}
+
+// Prefix returns the log file prefix of the logger.
+func (lg *Logger) Prefix() string {
+	lg = lg.root()
+	lg.mu.RLock()
+	defer lg.mu.RUnlock()
+	return lg.prefix
+}
+
+// SetPrefix switches the logger to the log file of the prefix.
+func (lg *Logger) SetPrefix(prefix string) {
+	lg = lg.root()
+	lg.mu.Lock()
+	old := lg.writer
+	lg.prefix = prefix
+	lg.writer = acquireWriter(prefix)
+	lg.mu.Unlock()
+	if err := old.release(); err != nil {
+		handleError(err)
+	}
+}
+
+// FilePath returns the path of the current log file of the logger.
+func (lg *Logger) FilePath() string {
+	return lg.fileWriter().path()
+}
+
+// CloseFile flushes and closes the log file, it is reopened by the next write.
+// Other loggers sharing the file reopen it as well on their next write.
+func (lg *Logger) CloseFile() error {
+	return lg.fileWriter().close()
+}
+
+// Close releases the logger, the log file is flushed and closed once no other
+// logger shares it. The logger must not be used afterwards.
+func (lg *Logger) Close() error {
+	lg.mu.Lock()
+	w := lg.writer
+	lg.writer = nil
+	lg.mu.Unlock()
+	routesErr := lg.releaseRoutes()
+	if w != nil {
+		if err := w.release(); err != nil {
+			return err
+		}
+	}
+	return routesErr
+}
+
+// root returns the logger owning the file and the outputs.
+func (lg *Logger) root() *Logger {
+	if lg.parent != nil {
+		return lg.parent
+	}
+	return lg
+}
+
+func (lg *Logger) fileWriter() *fileWriter {
+	lg = lg.root()
+	lg.mu.RLock()
+	defer lg.mu.RUnlock()
+	return lg.writer
+}
+
+// Log creates a new ToLog instance of the logger with default values and applies any specified options.
+func (lg *Logger) Log(options ...Options) *ToLog {
+	now := time.Now().In(lg.timeZone())
+	tolog := &ToLog{
+		logger:     lg,
+		logType:    StatusInfo,
+		logContext: "",
+		logTime:    formatTime(now, lg.timeFormat()),
+		time:       now,
+	}
+	if global, _ := globalFields.Load().(Fields); len(global) > 0 {
+		tolog.fields = Fields(nil).merge(global)
+	}
+	if lg.parent != nil {
+		tolog.fields = tolog.fields.merge(lg.childFields())
+	}
+	if lg.ctx != nil {
+		tolog.bindContext(lg.ctx)
+	}
+
+	for _, option := range options {
+		option(tolog)
+	}
+	tolog.watchEmit()
+
+	return tolog
+}
+
+// Info creates a "info" entry of the logger with the log context.
+func (lg *Logger) Info(ctx string) *ToLog {
+	l := lg.Log()
+	l.logType = StatusInfo
+	l.logContext = ctx
+	CreateFullLog(l)
+	return l
+}
+
+// Infof creates a "info" entry of the logger with the formatted log context.
+func (lg *Logger) Infof(format string, a ...any) *ToLog {
+	l := lg.Log()
+	l.logType = StatusInfo
+	l.logContext = fmt.Sprintf(format, a...)
+	CreateFullLog(l)
+	return l
+}
+
+// Infoln creates a "info" entry of the logger with the log context and a newline.
+func (lg *Logger) Infoln(a ...any) *ToLog {
+	l := lg.Log()
+	l.logType = StatusInfo
+	l.logContext = fmt.Sprintln(a...)
+	CreateFullLog(l)
+	return l
+}
+
+// InfoCtx creates a "info" entry of the logger with the log context and the fields extracted from ctx.
+func (lg *Logger) InfoCtx(ctx context.Context, msg string) *ToLog {
+	return lg.Info(msg).Ctx(ctx)
+}
+
+// Warning creates a "warning" entry of the logger with the log context.
+func (lg *Logger) Warning(ctx string) *ToLog {
+	l := lg.Log()
+	l.logType = StatusWarning
+	l.logContext = ctx
+	CreateFullLog(l)
+	return l
+}
+
+// Warningf creates a "warning" entry of the logger with the formatted log context.
+func (lg *Logger) Warningf(format string, a ...any) *ToLog {
+	l := lg.Log()
+	l.logType = StatusWarning
+	l.logContext = fmt.Sprintf(format, a...)
+	CreateFullLog(l)
+	return l
+}
+
+// Warningln creates a "warning" entry of the logger with the log context and a newline.
+func (lg *Logger) Warningln(a ...any) *ToLog {
+	l := lg.Log()
+	l.logType = StatusWarning
+	l.logContext = fmt.Sprintln(a...)
+	CreateFullLog(l)
+	return l
+}
+
+// WarningCtx creates a "warning" entry of the logger with the log context and the fields extracted from ctx.
+func (lg *Logger) WarningCtx(ctx context.Context, msg string) *ToLog {
+	return lg.Warning(msg).Ctx(ctx)
+}
+
+// Error creates a "error" entry of the logger with the log context.
+func (lg *Logger) Error(ctx string) *ToLog {
+	l := lg.Log()
+	l.logType = StatusError
+	l.logContext = ctx
+	CreateFullLog(l)
+	return l
+}
+
+// Errorf creates a "error" entry of the logger with the formatted log context.
+func (lg *Logger) Errorf(format string, a ...any) *ToLog {
+	l := lg.Log()
+	l.logType = StatusError
+	l.logContext = fmt.Sprintf(format, a...)
+	CreateFullLog(l)
+	return l
+}
+
+// Errorln creates a "error" entry of the logger with the log context and a newline.
+func (lg *Logger) Errorln(a ...any) *ToLog {
+	l := lg.Log()
+	l.logType = StatusError
+	l.logContext = fmt.Sprintln(a...)
+	CreateFullLog(l)
+	return l
+}
+
+// ErrorCtx creates a "error" entry of the logger with the log context and the fields extracted from ctx.
+func (lg *Logger) ErrorCtx(ctx context.Context, msg string) *ToLog {
+	return lg.Error(msg).Ctx(ctx)
+}
+
+// ErrorE creates a "error" entry of the logger with the message of the error
+// and the fields describing it, see ErrorFields.
+func (lg *Logger) ErrorE(err error) *ToLog {
+	if err == nil {
+		return lg.Error("<nil>")
+	}
+	return lg.Error(err.Error()).Err(err)
+}
+
+// Notice creates a "notice" entry of the logger with the log context.
+func (lg *Logger) Notice(ctx string) *ToLog {
+	l := lg.Log()
+	l.logType = StatusNotice
+	l.logContext = ctx
+	CreateFullLog(l)
+	return l
+}
+
+// Noticef creates a "notice" entry of the logger with the formatted log context.
+func (lg *Logger) Noticef(format string, a ...any) *ToLog {
+	l := lg.Log()
+	l.logType = StatusNotice
+	l.logContext = fmt.Sprintf(format, a...)
+	CreateFullLog(l)
+	return l
+}
+
+// Noticeln creates a "notice" entry of the logger with the log context and a newline.
+func (lg *Logger) Noticeln(a ...any) *ToLog {
+	l := lg.Log()
+	l.logType = StatusNotice
+	l.logContext = fmt.Sprintln(a...)
+	CreateFullLog(l)
+	return l
+}
+
+// NoticeCtx creates a "notice" entry of the logger with the log context and the fields extracted from ctx.
+func (lg *Logger) NoticeCtx(ctx context.Context, msg string) *ToLog {
+	return lg.Notice(msg).Ctx(ctx)
+}
+
+// strippedDebug is the entry of the debug functions in binaries built with
+// the tolog_nodebug tag, frozen so chained builders work on copies of it.
+var strippedDebug = &ToLog{logType: StatusDebug, frozen: true}
+
+// Debug creates a "debug" entry of the logger with the log context.
+func (lg *Logger) Debug(ctx string) *ToLog {
+	if DebugStripped {
+		return strippedDebug
+	}
+	l := lg.Log()
+	l.logType = StatusDebug
+	l.logContext = ctx
+	CreateFullLog(l)
+	return l
+}
+
+// Debugf creates a "debug" entry of the logger with the formatted log context.
+func (lg *Logger) Debugf(format string, a ...any) *ToLog {
+	if DebugStripped {
+		return strippedDebug
+	}
+	l := lg.Log()
+	l.logType = StatusDebug
+	l.logContext = fmt.Sprintf(format, a...)
+	CreateFullLog(l)
+	return l
+}
+
+// Debugln creates a "debug" entry of the logger with the log context and a newline.
+func (lg *Logger) Debugln(a ...any) *ToLog {
+	if DebugStripped {
+		return strippedDebug
+	}
+	l := lg.Log()
+	l.logType = StatusDebug
+	l.logContext = fmt.Sprintln(a...)
+	CreateFullLog(l)
+	return l
+}
+
+// DebugCtx creates a "debug" entry of the logger with the log context and the fields extracted from ctx.
+func (lg *Logger) DebugCtx(ctx context.Context, msg string) *ToLog {
+	if DebugStripped {
+		return strippedDebug
+	}
+	return lg.Debug(msg).Ctx(ctx)
+}
@@ -0,0 +1,32 @@
+package tolog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggersShareWriter(t *testing.T) {
+	a := NewLogger("TestShareWriter")
+	b := NewLogger("TestShareWriter")
+	c := NewLogger("TestOtherWriter")
+	assert.Same(t, a.writer, b.writer)
+	assert.NotSame(t, a.writer, c.writer)
+	assert.Equal(t, a.FilePath(), b.FilePath())
+
+	a.Info("from a").WriteSafe()
+	b.Info("from b").WriteSafe()
+	w := a.writer
+	a.Close()
+	assert.False(t, w.closed)
+	b.Close()
+	assert.True(t, w.closed)
+	c.Close()
+
+	checkMessageExistInFile(t, w.path(), "from a")
+	checkMessageExistInFile(t, w.path(), "from b")
+
+	d := NewLogger("TestShareWriter")
+	assert.NotSame(t, w, d.writer)
+	d.Close()
+}
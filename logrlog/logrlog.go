@@ -0,0 +1,52 @@
+// Package logrlog implements the logr.LogSink of github.com/go-logr/logr with
+// tolog, so the logs of controller-runtime, client-go and the other libraries
+// logging through logr flow to the files and sinks of tolog.
+package logrlog
+
+import (
+	"github.com/callme-taota/tolog"
+	"github.com/go-logr/logr"
+)
+
+// New returns a logr.Logger writing to the default logger of tolog.
+func New() logr.Logger {
+	return logr.New(NewSink(nil))
+}
+
+// NewSink returns a sink writing to the logger, the default logger if nil.
+// Info entries of level 0 are info entries, those of higher levels debug
+// entries like tolog.V, all with the v field. The names of WithName are in
+// the logger field, joined with dots, and the key/value pairs are fields.
+func NewSink(lg *tolog.Logger) logr.LogSink {
+	if lg == nil {
+		lg = tolog.Default()
+	}
+	return &sink{lg: lg}
+}
+
+type sink struct {
+	lg *tolog.Logger
+}
+
+func (s *sink) Init(info logr.RuntimeInfo) {}
+
+// Enabled reports whether entries of the level are written, see tolog.V.
+func (s *sink) Enabled(level int) bool {
+	return s.lg.V(level).Enabled()
+}
+
+func (s *sink) Info(level int, msg string, keysAndValues ...any) {
+	s.lg.V(level).InfoS(msg, keysAndValues...)
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...any) {
+	s.lg.Error(msg).Err(err).Fields(tolog.KeyValues(keysAndValues...)).PrintAndWriteSafe()
+}
+
+func (s *sink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &sink{lg: s.lg.With(tolog.KeyValues(keysAndValues...))}
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	return &sink{lg: s.lg.Named(name)}
+}
@@ -0,0 +1,57 @@
+package logrlog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+	"github.com/callme-taota/tolog/tologtest"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain writes the log files of the tests to a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "logrlog-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	tolog.SetLogDir(dir)
+	code := m.Run()
+	tolog.CloseLogFile()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestSink(t *testing.T) {
+	lg := tolog.NewLogger("TestSink")
+	defer lg.Close()
+	rec := tologtest.NewRecorder()
+	defer rec.Close()
+
+	log := logr.New(NewSink(lg)).WithName("controller").WithValues("reconciler", "pods")
+	log.Info("reconciling", "namespace", "default")
+	log.V(1).Info("hidden")
+	log.WithName("queue").Error(errors.New("conflict"), "requeue", "attempt", 2)
+	tolog.SetVerbosity(1)
+	tolog.SetLevel(tolog.StatusDebug)
+	defer tolog.SetVerbosity(0)
+	defer tolog.SetLevel("")
+	assert.True(t, log.V(1).Enabled())
+	assert.False(t, log.V(2).Enabled())
+	log.V(1).Info("detail")
+
+	tologtest.AssertLoggedInOrder(t, rec.Entries(),
+		tologtest.All(tologtest.Level(tolog.StatusInfo), tologtest.Message("reconciling"),
+			tologtest.HasField(tolog.LoggerField, "controller"), tologtest.HasField("reconciler", "pods"),
+			tologtest.HasField("namespace", "default"), tologtest.HasField(tolog.VerbosityField, 0)),
+		tologtest.All(tologtest.Level(tolog.StatusError), tologtest.Message("requeue"),
+			tologtest.HasField(tolog.LoggerField, "controller.queue"), tologtest.HasField("attempt", 2),
+			tologtest.HasField(tolog.ErrorField, "conflict"), tologtest.HasField("reconciler", "pods")),
+		tologtest.All(tologtest.Level(tolog.StatusDebug), tologtest.Message("detail"), tologtest.HasField(tolog.VerbosityField, 1)),
+	)
+	tologtest.AssertNotLogged(t, rec.Entries(), tologtest.Message("hidden"))
+}
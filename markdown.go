@@ -0,0 +1,81 @@
+package tolog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MarkdownEncoder renders entries as Markdown for alerts, chat messages and
+// reports: the level in bold, the time and single-line fields in code spans, and
+// multi-line fields, like stack traces, in code fences. With Slack set it
+// writes Slack mrkdwn instead, bold with single asterisks. Its output spans
+// several lines, so it renders entries for messages rather than log files.
+type MarkdownEncoder struct {
+	Slack bool
+}
+
+// Encode renders the entry as Markdown, or Slack mrkdwn with Slack set.
+func (m MarkdownEncoder) Encode(e *Entry) ([]byte, error) {
+	bold := "**"
+	if m.Slack {
+		bold = "*"
+	}
+	var buf bytes.Buffer
+	buf.WriteString(bold)
+	buf.WriteString(strings.ToUpper(string(e.Level)))
+	buf.WriteString(bold)
+	buf.WriteString(" `")
+	buf.WriteString(e.Time.Format(time.RFC3339Nano))
+	buf.WriteString("` ")
+	buf.WriteString(escapeMarkdown(e.Message, m.Slack))
+	f := normalizeFields(e.Fields).flatten()
+	for _, k := range f.sortedKeys() {
+		v := plainValue(f[k])
+		buf.WriteString("\n- `")
+		buf.WriteString(k)
+		buf.WriteString("`:")
+		if strings.Contains(v, "\n") {
+			buf.WriteString("\n```\n")
+			buf.WriteString(strings.ReplaceAll(strings.TrimRight(v, "\n"), "```", "` ` `"))
+			buf.WriteString("\n```")
+			continue
+		}
+		buf.WriteByte(' ')
+		if v == "" || strings.Contains(v, "`") {
+			buf.WriteString(escapeMarkdown(v, m.Slack))
+			continue
+		}
+		buf.WriteByte('`')
+		buf.WriteString(v)
+		buf.WriteByte('`')
+	}
+	return buf.Bytes(), nil
+}
+
+// plainValue formats a field value as is, without quoting it.
+func plainValue(v any) string {
+	switch v := v.(type) {
+	case LogValuer:
+		return lazyValue
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// escapeMarkdown escapes the text outside of code spans, for Slack only the
+// characters its mrkdwn reserves.
+func escapeMarkdown(s string, slack bool) string {
+	if slack {
+		return slackEscaper.Replace(s)
+	}
+	return markdownEscaper.Replace(s)
+}
+
+var (
+	markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;")
+	slackEscaper    = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
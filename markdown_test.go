@@ -0,0 +1,35 @@
+package tolog
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownEncoder(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   StatusError,
+		Message: "payment *failed* for <order>",
+		Fields: Fields{
+			"order": "A-17",
+			"http":  Fields{"status": 502},
+			"stack": "main.pay()\n\tpay.go:12\n",
+		},
+	}
+	data, err := MarkdownEncoder{}.Encode(e)
+	assert.NoError(t, err)
+	assert.Equal(t, "**ERROR** `2024-01-02T03:04:05Z` payment \\*failed\\* for &lt;order&gt;\n"+
+		"- `http.status`: `502`\n"+
+		"- `order`: `A-17`\n"+
+		"- `stack`:\n```\nmain.pay()\n\tpay.go:12\n```", string(data))
+
+	data, err = MarkdownEncoder{Slack: true}.Encode(e)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "*ERROR* `2024-01-02T03:04:05Z` payment *failed* for &lt;order&gt;\n")
+
+	data, _ = MarkdownEncoder{}.Encode(&Entry{Time: e.Time, Level: StatusInfo, Message: "ok", Fields: Fields{"err": errors.New("use `x`")}})
+	assert.Equal(t, "**INFO** `2024-01-02T03:04:05Z` ok\n- `err`: use \\`x\\`", string(data))
+}
@@ -0,0 +1,97 @@
+package tolog
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+// DefaultFileHeader is a header template marking the start of a file.
+const DefaultFileHeader = "# tolog file start {{.File}} at {{.Time.Format \"2006-01-02T15:04:05Z07:00\"}} host={{.Host}} pid={{.PID}}" +
+	"{{with .Service}} service={{.}}{{end}}{{with .Version}} version={{.}}{{end}}"
+
+// DefaultFileFooter is a footer template marking the end of a file.
+const DefaultFileFooter = "# tolog file end {{.File}} at {{.Time.Format \"2006-01-02T15:04:05Z07:00\"}}{{with .Next}} continued in {{.}}{{end}}"
+
+// FileMarker is the data of the header and footer templates.
+type FileMarker struct {
+	File    string // the path of the file
+	Next    string // the path of the next file, in footers
+	Time    time.Time
+	Host    string
+	PID     int
+	Service string
+	Version string
+}
+
+var fileHeader atomic.Value // *template.Template
+var fileFooter atomic.Value // *template.Template
+var serviceName atomic.Value
+var serviceVersion atomic.Value
+
+// SetFileHeader sets the text/template of the line written at the top of each
+// new log file, e.g. DefaultFileHeader. An empty template disables the header.
+func SetFileHeader(tmpl string) error {
+	return setMarker(&fileHeader, "header", tmpl)
+}
+
+// SetFileFooter sets the text/template of the line written at the end of a log
+// file when rotating out of it, e.g. DefaultFileFooter. An empty template
+// disables the footer.
+func SetFileFooter(tmpl string) error {
+	return setMarker(&fileFooter, "footer", tmpl)
+}
+
+// SetServiceInfo sets the service name and version available to the header and footer templates.
+func SetServiceInfo(service string, version string) {
+	serviceName.Store(service)
+	serviceVersion.Store(version)
+}
+
+func setMarker(v *atomic.Value, name string, tmpl string) error {
+	if tmpl == "" {
+		v.Store((*template.Template)(nil))
+		return nil
+	}
+	t, err := template.New(name).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	v.Store(t)
+	return nil
+}
+
+// writeMarker writes the header or footer line to the file, fileMu must be held.
+func (w *fileWriter) writeMarker(v *atomic.Value, file string, next string) {
+	t, _ := v.Load().(*template.Template)
+	if t == nil {
+		return
+	}
+	host, _ := os.Hostname()
+	service, _ := serviceName.Load().(string)
+	version, _ := serviceVersion.Load().(string)
+	data := FileMarker{
+		File:    file,
+		Next:    next,
+		Time:    time.Now().In(globalTimeZone()),
+		Host:    host,
+		PID:     os.Getpid(),
+		Service: service,
+		Version: version,
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		handleError(err)
+		return
+	}
+	line := sb.String()
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+	_, err := w.writeEncoded([]byte(line))
+	if err != nil {
+		handleError(err)
+	}
+}
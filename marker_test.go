@@ -0,0 +1,55 @@
+package tolog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMarkers(t *testing.T) {
+	lg := NewLogger("TestFileMarkers")
+	defer lg.Close()
+	base := strings.TrimSuffix(lg.FilePath(), ".log")
+	old, _ := filepath.Glob(base + "*")
+	for _, f := range old {
+		os.Remove(f)
+	}
+
+	require.NoError(t, SetFileHeader(DefaultFileHeader))
+	require.NoError(t, SetFileFooter(DefaultFileFooter))
+	SetServiceInfo("billing", "1.2.3")
+	SetMaxFileSize(300)
+	defer SetFileHeader("")
+	defer SetFileFooter("")
+	defer SetServiceInfo("", "")
+	defer SetMaxFileSize(0)
+	assert.Error(t, SetFileHeader("{{.Missing"))
+
+	line := strings.Repeat("x", 99)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, lg.WriteRaw([]byte(line)))
+		lg.CloseFile()
+	}
+
+	content, err := os.ReadFile(lg.FilePath())
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "# tolog file start "+lg.FilePath())
+	assert.Contains(t, lines[0], "service=billing version=1.2.3")
+	assert.Equal(t, line, lines[1])
+
+	backups, _ := filepath.Glob(base + "-*.log")
+	require.Len(t, backups, 1)
+	content, err = os.ReadFile(backups[0])
+	require.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[2], filepath.Base(backups[0]))
+	assert.True(t, strings.HasPrefix(lines[2], "# tolog file end "))
+	assert.Contains(t, lines[2], "continued in "+lg.FilePath())
+}
@@ -0,0 +1,102 @@
+package tolog
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LegacyFile is a log file of the flat layout of the log directory, named
+// [<prefix>-]log-<day>[<suffix>].log with .gz when compressed.
+type LegacyFile struct {
+	Name   string // the file name
+	Prefix string // the prefix of the logger, empty for the default logger
+	Day    string // the day, formatted with SetLogFileDateFormat
+	Suffix string // what follows the day, like the time of a rotated backup and .log
+}
+
+// Migration is a log file moved by MigrateLogFiles, the paths are relative to
+// the log directory.
+type Migration struct {
+	From string
+	To   string
+}
+
+// MigrateLogFiles moves the log files of the flat layout in dir, the log
+// directory if empty, to the paths returned by target relative to dir, keeping
+// the history when adopting another layout, like a directory per day. Files
+// are left in place when target returns their name or an empty path, and never
+// overwrite an existing file. The files the loggers have open are not moved.
+// It returns the files moved and the errors of those it could not move.
+func MigrateLogFiles(dir string, target func(f LegacyFile) string) ([]Migration, error) {
+	if dir == "" {
+		dir = logDirectory()
+	}
+	fsys := logFS()
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	open := make(map[string]bool)
+	for _, w := range openWriters() {
+		open[filepath.Clean(w.path())] = true
+	}
+	var moved []Migration
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		f, ok := parseLegacyName(e.Name())
+		if !ok || open[filepath.Join(dir, e.Name())] {
+			continue
+		}
+		to := filepath.Clean(target(f))
+		if to == "." || to == f.Name {
+			continue
+		}
+		from, dest := filepath.Join(dir, f.Name), filepath.Join(dir, to)
+		if _, err := fsys.Stat(dest); err == nil {
+			errs = append(errs, fmt.Errorf("tolog: migrate %s: %s already exists", f.Name, to))
+			continue
+		}
+		if err := fsys.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := fsys.Rename(from, dest); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		diag("migrate", "moved %s to %s", from, dest)
+		moved = append(moved, Migration{From: f.Name, To: to})
+	}
+	return moved, errors.Join(errs...)
+}
+
+// parseLegacyName parses the name of a log file of the flat layout.
+func parseLegacyName(name string) (LegacyFile, bool) {
+	base := strings.TrimSuffix(name, ".gz")
+	if !strings.HasSuffix(base, ".log") {
+		return LegacyFile{}, false
+	}
+	var prefix, rest string
+	if r, ok := strings.CutPrefix(base, "log-"); ok {
+		rest = r
+	} else if i := strings.Index(base, "-log-"); i > 0 {
+		prefix, rest = base[:i], base[i+len("-log-"):]
+	} else {
+		return LegacyFile{}, false
+	}
+	layout := string(fileDateFormat.Load().(DateFormat))
+	n := len(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC).Format(layout))
+	if len(rest) < n {
+		return LegacyFile{}, false
+	}
+	if _, err := time.Parse(layout, rest[:n]); err != nil {
+		return LegacyFile{}, false
+	}
+	return LegacyFile{Name: name, Prefix: prefix, Day: rest[:n], Suffix: name[len(base)-len(rest)+n:]}, true
+}
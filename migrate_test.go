@@ -0,0 +1,54 @@
+package tolog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLegacyName(t *testing.T) {
+	f, ok := parseLegacyName("api-log-2024-05-01-120000.000000.log.gz")
+	require.True(t, ok)
+	assert.Equal(t, LegacyFile{Name: "api-log-2024-05-01-120000.000000.log.gz", Prefix: "api", Day: "2024-05-01", Suffix: "-120000.000000.log.gz"}, f)
+
+	f, ok = parseLegacyName("log-2024-05-01.log")
+	require.True(t, ok)
+	assert.Equal(t, "", f.Prefix)
+	assert.Equal(t, ".log", f.Suffix)
+
+	for _, name := range []string{"notes.txt", "log-yesterday.log", "api-2024-05-01.log"} {
+		_, ok := parseLegacyName(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestMigrateLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"log-2024-05-01.log", "api-log-2024-05-02.log.gz", "notes.txt", "api-log-2024-05-03.log"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "2024-05-03"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2024-05-03", "api.log"), nil, 0644))
+
+	moved, err := MigrateLogFiles(dir, func(f LegacyFile) string {
+		prefix := f.Prefix
+		if prefix == "" {
+			prefix = "app"
+		}
+		return filepath.Join(f.Day, prefix+f.Suffix)
+	})
+	assert.ErrorContains(t, err, "already exists")
+	assert.ElementsMatch(t, []Migration{
+		{From: "log-2024-05-01.log", To: filepath.Join("2024-05-01", "app.log")},
+		{From: "api-log-2024-05-02.log.gz", To: filepath.Join("2024-05-02", "api.log.gz")},
+	}, moved)
+
+	data, err := os.ReadFile(filepath.Join(dir, "2024-05-02", "api.log.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, "api-log-2024-05-02.log.gz", string(data))
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+	assert.FileExists(t, filepath.Join(dir, "api-log-2024-05-03.log"))
+}
@@ -0,0 +1,67 @@
+package tolog
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OpenMode is how the log files are opened.
+type OpenMode int32
+
+const (
+	// OpenAppend appends to the existing file of the day, the default.
+	OpenAppend OpenMode = iota
+	// OpenTruncate empties the existing file of the day the first time the
+	// process opens it, for batch jobs wanting a fresh file per run.
+	OpenTruncate
+	// OpenPerRun writes a file of its own per run, named with the time the
+	// process started, like app-log-2024-05-01-101500.log.
+	OpenPerRun
+)
+
+var openMode int32 // OpenMode
+
+// runStart is the time the process started, naming the files of OpenPerRun.
+var runStart = time.Now()
+
+var (
+	truncated   = map[string]bool{} // the files emptied by this process
+	truncatedMu sync.Mutex
+)
+
+// SetOpenMode sets how the log files are opened. It should be set before the
+// first entry is written.
+func SetOpenMode(mode OpenMode) {
+	atomic.StoreInt32(&openMode, int32(mode))
+}
+
+// filePath returns the path of the log file of the writer for the day.
+func (w *fileWriter) filePath(day string) string {
+	if OpenMode(atomic.LoadInt32(&openMode)) == OpenPerRun {
+		day += "-" + runStart.In(globalTimeZone()).Format("150405")
+	}
+	if id := RunID(); id != "" {
+		day += "-" + id
+	}
+	return logFilePath(w.prefix, day)
+}
+
+// openLogFile opens the log file for appending, emptying it first if it was
+// not opened yet by the process in OpenTruncate mode.
+func openLogFile(path string) (File, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if OpenMode(atomic.LoadInt32(&openMode)) == OpenTruncate {
+		truncatedMu.Lock()
+		defer truncatedMu.Unlock()
+		if !truncated[path] {
+			file, err := logFS().OpenFile(path, flags|os.O_TRUNC, 0644)
+			if err == nil {
+				truncated[path] = true
+			}
+			return file, err
+		}
+	}
+	return logFS().OpenFile(path, flags, 0644)
+}
@@ -0,0 +1,48 @@
+package tolog
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenTruncate(t *testing.T) {
+	lg := NewLogger("TestOpenTruncate")
+	defer lg.Close()
+	path := lg.FilePath()
+	require.NoError(t, os.WriteFile(path, []byte("previous run\n"), 0644))
+	defer os.Remove(path)
+
+	SetOpenMode(OpenTruncate)
+	defer SetOpenMode(OpenAppend)
+	require.NoError(t, lg.WriteRaw([]byte("first")))
+	require.NoError(t, lg.CloseFile())
+	// reopening in the same run appends
+	require.NoError(t, lg.WriteRaw([]byte("second")))
+	require.NoError(t, lg.CloseFile())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(content))
+}
+
+func TestOpenPerRun(t *testing.T) {
+	lg := NewLogger("TestOpenPerRun")
+	defer lg.Close()
+	daily := lg.FilePath()
+
+	SetOpenMode(OpenPerRun)
+	defer SetOpenMode(OpenAppend)
+	path := lg.FilePath()
+	defer os.Remove(path)
+	assert.Equal(t, strings.TrimSuffix(daily, ".log")+"-"+runStart.In(globalTimeZone()).Format("150405")+".log", path)
+
+	require.NoError(t, lg.WriteRaw([]byte("run")))
+	require.NoError(t, lg.CloseFile())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "run\n", string(content))
+}
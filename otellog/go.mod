@@ -0,0 +1,23 @@
+module github.com/callme-taota/tolog/otellog
+
+go 1.20
+
+require (
+	github.com/callme-taota/tolog v0.0.0
+	github.com/stretchr/testify v1.9.0
+	go.opentelemetry.io/otel/trace v1.19.0
+)
+
+require (
+	github.com/Microsoft/go-winio v0.6.1 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.opentelemetry.io/otel v1.19.0 // indirect
+	golang.org/x/mod v0.8.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/term v0.10.0 // indirect
+	golang.org/x/tools v0.6.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/callme-taota/tolog => ../
@@ -0,0 +1,31 @@
+// Package otellog attaches the trace and span of OpenTelemetry to the entries
+// logged with a context, so log lines link to their distributed traces.
+package otellog
+
+import (
+	"context"
+
+	"github.com/callme-taota/tolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Fields returns the trace_id, span_id and trace_sampled fields of the span
+// carried by the context, nil if it carries none.
+func Fields(ctx context.Context) tolog.Fields {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return tolog.Fields{
+		tolog.TraceIDField:      sc.TraceID().String(),
+		tolog.SpanIDField:       sc.SpanID().String(),
+		tolog.TraceSampledField: sc.IsSampled(),
+	}
+}
+
+// Register registers Fields as a context extractor, so the entries logged with
+// InfoCtx and the other Ctx functions, Ctx or FromContext get the fields of
+// the span of their context.
+func Register() {
+	tolog.RegisterContextExtractor(Fields)
+}
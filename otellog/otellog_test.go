@@ -0,0 +1,91 @@
+package otellog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestMain writes the log files of the tests to a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "otellog-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	tolog.SetLogDir(dir)
+	code := m.Run()
+	tolog.CloseLogFile()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestFields(t *testing.T) {
+	assert.Nil(t, Fields(context.Background()))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	Register()
+	defer tolog.ResetContextExtractors()
+	lg := tolog.NewLogger("TestFields")
+	defer lg.Close()
+	sink := &recorder{}
+	tolog.AddSink(sink)
+	defer tolog.RemoveSink(sink)
+	lg.InfoCtx(ctx, "traced").WriteSafe()
+
+	if assert.Len(t, sink.entries, 1) {
+		fields := sink.entries[0].Fields
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields[tolog.TraceIDField])
+		assert.Equal(t, "00f067aa0ba902b7", fields[tolog.SpanIDField])
+		assert.Equal(t, true, fields[tolog.TraceSampledField])
+	}
+}
+
+func TestTraceCoSampling(t *testing.T) {
+	Register()
+	defer tolog.ResetContextExtractors()
+	tolog.SetTraceCoSampling(tolog.StatusDebug)
+	defer tolog.SetTraceCoSampling("")
+	lg := tolog.NewLogger("TestTraceCoSampling")
+	defer lg.Close()
+	sink := &recorder{}
+	tolog.AddSink(sink)
+	defer tolog.RemoveSink(sink)
+
+	config := trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{1}}
+	unsampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(config))
+	config.TraceFlags = trace.FlagsSampled
+	sampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(config))
+
+	lg.DebugCtx(unsampled, "dropped").WriteSafe()
+	lg.DebugCtx(sampled, "kept").WriteSafe()
+	lg.InfoCtx(unsampled, "above the level").WriteSafe()
+
+	var msgs []string
+	for _, e := range sink.entries {
+		msgs = append(msgs, e.Message)
+	}
+	assert.Equal(t, []string{"kept", "above the level"}, msgs)
+}
+
+type recorder struct {
+	entries []*tolog.Entry
+}
+
+func (r *recorder) WriteEntry(e *tolog.Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *recorder) Close() error { return nil }
@@ -0,0 +1,147 @@
+package tolog
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// The console and the log file are the two built-in outputs of a logger: PrintLog
+// writes to the console, WriteSafe to the log file and PrintAndWriteSafe to both.
+// Additional outputs receive every entry emitted by the logger, whichever
+// terminator is used, without color codes unless wrapped with ColorOutput.
+
+// SetConsole sets the writer of the console output of the default logger.
+func SetConsole(w io.Writer) {
+	std.SetConsole(w)
+}
+
+// SetSplitStdStreams prints the warnings and errors of the default logger to
+// stderr and the other entries to stdout, see Logger.SetSplitStdStreams.
+func SetSplitStdStreams(split bool) {
+	std.SetSplitStdStreams(split)
+}
+
+// AddOutput adds a writer receiving every entry of the default logger.
+func AddOutput(w io.Writer) {
+	std.AddOutput(w)
+}
+
+// RemoveOutputs removes the outputs added to the default logger.
+func RemoveOutputs() {
+	std.RemoveOutputs()
+}
+
+// SetConsole sets the writer of the console output, os.Stdout by default.
+// Nil restores the default.
+func (lg *Logger) SetConsole(w io.Writer) {
+	lg = lg.root()
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	lg.console = w
+}
+
+// SetSplitStdStreams prints the warnings and errors to stderr and the other
+// entries to stdout while the console is stdout, so orchestrators can tell the
+// streams apart. A console set to another writer gets every entry.
+func (lg *Logger) SetSplitStdStreams(split bool) {
+	lg = lg.root()
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	lg.split = split
+}
+
+// consoleStream returns the writer the entries of the level are printed to.
+func (lg *Logger) consoleStream(level LogStatus) io.Writer {
+	lg = lg.root()
+	lg.mu.RLock()
+	console, split := lg.console, lg.split
+	lg.mu.RUnlock()
+	if split && (console == nil || console == os.Stdout) && severity(level) >= severity(StatusWarning) {
+		console = os.Stderr
+	}
+	return realStream(console)
+}
+
+// AddOutput adds a writer receiving every entry of the logger, e.g. a network
+// connection or an in-memory buffer for tests. Writes to the outputs of a logger
+// are serialized, so the writer need not be safe for concurrent use.
+func (lg *Logger) AddOutput(w io.Writer) {
+	lg = lg.root()
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	lg.outputs = append(lg.outputs, w)
+}
+
+// RemoveOutputs removes the outputs added to the logger.
+func (lg *Logger) RemoveOutputs() {
+	lg = lg.root()
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	lg.outputs = nil
+}
+
+// print writes the entry to the console output, with the colors of its level
+// when enabled for the stream it goes to.
+func (lg *Logger) print(l *ToLog) {
+	console := lg.consoleStream(l.logType)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	lg.appendPrinted(buf, l, console)
+	root := lg.root()
+	root.outMu.Lock()
+	defer root.outMu.Unlock()
+	root.writeConsole(console, buf.Bytes())
+}
+
+// appendPrinted appends the line of the entry printed to the console, with the
+// colors of its level when enabled for it, and a newline.
+func (lg *Logger) appendPrinted(buf *bytes.Buffer, l *ToLog, console io.Writer) {
+	if lg.colorOn(console) {
+		appendLine(buf, withTimeFormat(l, lg.consoleTimeFormat()), lg.consoleFormat(), true)
+	} else {
+		buf.WriteString(l.FullLog)
+	}
+	buf.WriteByte('\n')
+}
+
+// ColorOutput wraps a writer added with AddOutput so it gets the lines of the
+// entries with the colors of their level, if mode and the writer allow it, e.g.
+// a terminal of a remote session while the log file stays plain. The mode is
+// independent of SetColorMode and of the other outputs.
+func ColorOutput(w io.Writer, mode ColorMode) io.Writer {
+	return &colorOutput{Writer: w, color: mode == ColorAlways || mode == ColorAuto && autoColor(w)}
+}
+
+// colorOutput is an output getting colored lines.
+type colorOutput struct {
+	io.Writer
+	color bool
+}
+
+// writeOutputs writes the line of the entry to the added outputs, colored for
+// those wrapped with ColorOutput. Raw lines have no entry and are not colored.
+func (lg *Logger) writeOutputs(l *ToLog, line string) {
+	lg = lg.root()
+	lg.mu.RLock()
+	outputs := lg.outputs
+	lg.mu.RUnlock()
+	if len(outputs) == 0 {
+		return
+	}
+	lg.outMu.Lock()
+	defer lg.outMu.Unlock()
+	colored := ""
+	for _, w := range outputs {
+		out := line
+		if c, ok := w.(*colorOutput); ok && c.color && l != nil {
+			if colored == "" {
+				colored = coloredFileLine(l)
+			}
+			out = colored
+		}
+		if _, err := io.WriteString(w, out); err != nil {
+			handleError(err)
+		}
+	}
+}
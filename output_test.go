@@ -0,0 +1,79 @@
+package tolog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputs(t *testing.T) {
+	lg := NewLogger("TestOutputs")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+
+	var console, out bytes.Buffer
+	lg.SetConsole(&console)
+	lg.AddOutput(&out)
+
+	lg.Info("printed").PrintLog()
+	lg.Warning("written").WriteSafe()
+	lg.Error("both").PrintAndWriteSafe()
+	assert.NoError(t, lg.WriteRaw([]byte("raw line")))
+
+	assert.Contains(t, console.String(), "printed")
+	assert.Contains(t, console.String(), "both")
+	assert.NotContains(t, console.String(), "written")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, 4)
+	assert.NotContains(t, out.String(), colorReset)
+	assert.Equal(t, "raw line", lines[3])
+
+	lg.RemoveOutputs()
+	lg.Info("not captured").PrintLog()
+	assert.NotContains(t, out.String(), "not captured")
+}
+
+func TestColorOutput(t *testing.T) {
+	lg := NewLogger("TestColorOutput")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	SetColorMode(ColorNever)
+	defer SetColorMode(ColorAuto)
+
+	var console, plain, colored, auto bytes.Buffer
+	lg.SetConsole(&console)
+	lg.AddOutput(&plain)
+	lg.AddOutput(ColorOutput(&colored, ColorAlways))
+	lg.AddOutput(ColorOutput(&auto, ColorAuto))
+
+	lg.Error("failed").PrintLog()
+	assert.NoError(t, lg.WriteRaw([]byte("raw line")))
+
+	assert.NotContains(t, console.String(), colorReset, "the console follows SetColorMode")
+	assert.NotContains(t, plain.String(), colorReset)
+	assert.NotContains(t, auto.String(), colorReset, "not a terminal")
+	assert.Contains(t, colored.String(), levelColor(StatusError)+" error "+colorReset)
+	assert.True(t, strings.HasSuffix(colored.String(), "\nraw line\n"), colored.String())
+}
+
+func TestSplitStdStreams(t *testing.T) {
+	lg := NewLogger("TestSplitStdStreams")
+	defer lg.Close()
+
+	assert.Equal(t, os.Stdout, lg.consoleStream(StatusError))
+	lg.SetSplitStdStreams(true)
+	assert.Equal(t, os.Stdout, lg.consoleStream(StatusInfo))
+	assert.Equal(t, os.Stdout, lg.consoleStream(StatusDebug))
+	assert.Equal(t, os.Stderr, lg.consoleStream(StatusWarning))
+	assert.Equal(t, os.Stderr, lg.Named("child").consoleStream(StatusError))
+
+	var console bytes.Buffer
+	lg.SetConsole(&console)
+	lg.SetColor(false)
+	lg.Error("to the console").PrintLog()
+	assert.Contains(t, console.String(), "[error]  to the console")
+}
@@ -0,0 +1,83 @@
+package tolog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy is what writing an entry does when the queue of the log file is full.
+type OverflowPolicy int32
+
+const (
+	// OverflowBlock waits until the queue has room, the default.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the entry being written.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued entry to make room.
+	OverflowDropOldest
+)
+
+var overflowPolicy int32
+
+var droppedTotal uint64
+
+// SetOverflowPolicy sets what writing an entry does when the queue of the log
+// file is full, e.g. while the disk is slow. Dropping keeps request goroutines
+// from stalling, the dropped entries are counted by Dropped and a line reporting
+// them is written with the next flush.
+func SetOverflowPolicy(policy OverflowPolicy) {
+	atomic.StoreInt32(&overflowPolicy, int32(policy))
+}
+
+// Dropped returns the number of entries dropped because the queue was full.
+func Dropped() uint64 {
+	return atomic.LoadUint64(&droppedTotal)
+}
+
+// enqueue queues the item according to the overflow policy, w.mu must be held for reading.
+func (w *fileWriter) enqueue(item writeItem) {
+	w.enqueueUntil(nil, item)
+}
+
+// enqueueUntil queues the item like enqueue, dropping it if done is closed while
+// waiting for room, w.mu must be held for reading.
+func (w *fileWriter) enqueueUntil(done <-chan struct{}, item writeItem) {
+	switch OverflowPolicy(atomic.LoadInt32(&overflowPolicy)) {
+	case OverflowDropNewest:
+		if !w.tryEnqueue(item) {
+			w.drop()
+		}
+	case OverflowDropOldest:
+		for !w.tryEnqueue(item) {
+			if _, ok := w.dequeue(); ok {
+				w.drop()
+			}
+		}
+	default:
+		if w.manual {
+			w.enqueueManual(item)
+			return
+		}
+		if !w.enqueueWait(done, item) {
+			w.drop()
+		}
+	}
+}
+
+func (w *fileWriter) drop() {
+	w.dropped.Add(1)
+	atomic.AddUint64(&droppedTotal, 1)
+}
+
+// droppedNotice returns the line reporting the entries dropped since the last
+// flush, false if none were.
+func (w *fileWriter) droppedNotice() (writeItem, bool) {
+	n := w.dropped.Swap(0)
+	if n == 0 {
+		return writeItem{}, false
+	}
+	diag("drop", "%d entries dropped, the queue of %s was full", n, w.path())
+	e := &Entry{Time: time.Now(), Level: StatusWarning, Message: fmt.Sprintf("%d entries dropped, the log queue was full", n)}
+	return writeItem{line: fileLine(e.toLog()), level: StatusWarning}, true
+}
@@ -0,0 +1,87 @@
+package tolog
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverflowPolicy(t *testing.T) {
+	defer SetOverflowPolicy(OverflowBlock)
+	defer SetQueueMode(QueueChannel)
+	for _, c := range []struct {
+		policy OverflowPolicy
+		mode   QueueMode
+	}{{OverflowDropNewest, QueueChannel}, {OverflowDropOldest, QueueChannel}, {OverflowDropNewest, QueueRing}, {OverflowDropOldest, QueueRing}, {OverflowDropNewest, QueueSharded}, {OverflowDropOldest, QueueSharded}} {
+		policy := c.policy
+		SetQueueMode(c.mode)
+		lg := NewLogger("TestOverflowPolicy")
+		path := lg.FilePath()
+		os.Remove(path)
+		SetOverflowPolicy(policy)
+		dropped := Dropped()
+
+		lg.Info("first").WriteSafe()
+		w := lg.fileWriter()
+		capacity := int(queueSize)
+		if w.queue != nil {
+			capacity = w.queue.cap()
+		}
+		w.fileMu.Lock() // stall the writer like a slow disk
+		for i := 0; i < 3*capacity; i++ {
+			lg.Info("flood").WriteSafe()
+		}
+		w.fileMu.Unlock()
+		lg.Info("last").WriteSafe()
+		lg.Close()
+
+		assert.Greater(t, Dropped()-dropped, uint64(capacity))
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "entries dropped, the log queue was full")
+		if policy == OverflowDropOldest {
+			assert.Contains(t, string(content), "last")
+		}
+		os.Remove(path)
+	}
+}
+
+func TestWriteSafeCtx(t *testing.T) {
+	defer SetQueueMode(QueueChannel)
+	for _, mode := range []QueueMode{QueueChannel, QueueRing, QueueSharded} {
+		SetQueueMode(mode)
+		lg := NewLogger("TestWriteSafeCtx")
+		path := lg.FilePath()
+		os.Remove(path)
+		dropped := Dropped()
+
+		lg.Info("first").WriteSafeCtx(context.Background())
+		w := lg.fileWriter()
+		capacity := int(queueSize)
+		if w.queue != nil {
+			capacity = w.queue.cap()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		w.fileMu.Lock() // stall the writer like a slow disk
+		start := time.Now()
+		for i := 0; i < 3*capacity; i++ {
+			lg.Info("flood").WriteSafeCtx(ctx)
+		}
+		assert.Less(t, time.Since(start), 5*time.Second)
+		w.fileMu.Unlock()
+		cancel()
+		lg.Info("last").WriteSafeCtx(context.Background())
+		lg.Close()
+
+		assert.Greater(t, Dropped()-dropped, uint64(capacity), mode)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "entries dropped, the log queue was full")
+		assert.Contains(t, string(content), "last")
+		os.Remove(path)
+	}
+}
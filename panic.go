@@ -0,0 +1,72 @@
+package tolog
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// PanicFields returns the fields describing a recovered panic value, so panics
+// are searchable by type and value instead of a single formatted string: panic
+// is the message, panic_type the Go type, panic_value the value with structs,
+// maps and slices kept structured, panic_chain the types of the wrapped errors,
+// and stack the stack of the calling goroutine, see SetStackDepth.
+func PanicFields(v any) Fields {
+	fields := Fields{
+		"panic":      fmt.Sprint(v),
+		"panic_type": fmt.Sprintf("%T", v),
+		StackField:   captureStack(1),
+	}
+	switch val := v.(type) {
+	case error:
+		fields["panic_value"] = val.Error()
+		if chain := errorChain(val); len(chain) > 1 {
+			fields["panic_chain"] = chain
+		}
+	case fmt.Stringer:
+		fields["panic_value"] = val.String()
+	default:
+		fields["panic_value"] = structuredValue(v)
+	}
+	return fields
+}
+
+// Panic adds the fields describing the recovered panic value, see PanicFields.
+func (l *ToLog) Panic(v any) *ToLog {
+	l = l.mutable()
+	l.fields = l.fields.merge(PanicFields(v))
+	CreateFullLog(l)
+	return l
+}
+
+// errorChain returns the types of the error and of the errors it wraps, depth first.
+func errorChain(err error) []string {
+	var chain []string
+	for _, e := range unwrapAll(err) {
+		chain = append(chain, fmt.Sprintf("%T", e))
+	}
+	return chain
+}
+
+// structuredValue returns the value as is when it marshals to JSON with its
+// content, its %+v form otherwise, e.g. for structs without exported fields.
+func structuredValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		data, err := json.Marshal(v)
+		if err != nil || string(data) == "{}" {
+			return fmt.Sprintf("%+v", v)
+		}
+		var out any
+		if json.Unmarshal(data, &out) != nil {
+			return fmt.Sprintf("%+v", v)
+		}
+		return out
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return fmt.Sprintf("%+v", v)
+	}
+	return v
+}
@@ -0,0 +1,45 @@
+package tolog
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type panicState struct {
+	Step  string
+	Items int
+}
+
+type opaque struct{ secret string }
+
+func TestPanicFields(t *testing.T) {
+	f := PanicFields(panicState{Step: "charge", Items: 2})
+	assert.Equal(t, "tolog.panicState", f["panic_type"])
+	assert.Equal(t, map[string]any{"Step": "charge", "Items": float64(2)}, f["panic_value"])
+	assert.Contains(t, f["stack"], "TestPanicFields")
+
+	err := fmt.Errorf("saving: %w", &fs.PathError{Op: "open", Path: "/x", Err: fs.ErrNotExist})
+	f = PanicFields(err)
+	assert.Equal(t, "*fmt.wrapError", f["panic_type"])
+	assert.Equal(t, err.Error(), f["panic_value"])
+	assert.Equal(t, []string{"*fmt.wrapError", "*fs.PathError", "*errors.errorString"}, f["panic_chain"])
+
+	f = PanicFields(errors.New("boom"))
+	assert.NotContains(t, f, "panic_chain")
+
+	assert.Equal(t, "{secret:s}", PanicFields(opaque{"s"})["panic_value"])
+	assert.Equal(t, 42, PanicFields(42)["panic_value"])
+	assert.Equal(t, "int", PanicFields(42)["panic_type"])
+}
+
+func TestPanicJSON(t *testing.T) {
+	SetLogFormat(FormatJSON)
+	defer SetLogFormat(FormatText)
+	line := Error("panic recovered").Panic(panicState{Step: "charge"}).String()
+	assert.True(t, strings.Contains(line, `"panic_value":{"Items":0,"Step":"charge"}`), line)
+}
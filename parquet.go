@@ -0,0 +1,497 @@
+package tolog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// parquetRowGroupRows is the number of rows of the row groups ExportParquet writes.
+const parquetRowGroupRows = 1 << 16
+
+// The physical types, converted types and encodings of the Parquet format used by ExportParquet.
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetUTF8            = 0
+	parquetTimestampMicros = 10
+
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+// ExportParquetFile converts the log file, gzip compressed if it ends with .gz,
+// into Parquet written to w, see ExportParquet.
+func ExportParquetFile(w io.Writer, path string) (int, error) {
+	r, err := readLogFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return ExportParquet(w, r)
+}
+
+// ExportParquet converts the lines of a log file, like the file of a day, into
+// a Parquet file written to w, to query the logs with DuckDB or Athena. It
+// returns the number of rows. The time, level and msg columns are followed by a
+// column per field key, the fields of groups with dotted keys, typed from the
+// values: booleans, integers, floating point numbers, and strings for the rest,
+// objects and arrays as JSON. File markers, blank lines and lines that don't
+// parse are skipped, see ParseLine. The entries are held in memory until
+// written, uncompressed.
+func ExportParquet(w io.Writer, r io.Reader) (int, error) {
+	var rows []parquetRow
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 16*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, err := ParseLine(line)
+		if err != nil || e.Level == "" {
+			continue
+		}
+		row := parquetRow{entry: e, fields: map[string]any{}}
+		flattenParsed(row.fields, e.Fields, "")
+		rows = append(rows, row)
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	pw := &parquetWriter{w: w}
+	pw.write([]byte("PAR1"))
+	schema := parquetSchema(rows)
+	var groups []parquetRowGroup
+	for start := 0; start < len(rows); start += parquetRowGroupRows {
+		end := start + parquetRowGroupRows
+		if end > len(rows) {
+			end = len(rows)
+		}
+		groups = append(groups, pw.rowGroup(schema, rows[start:end]))
+	}
+	footer := parquetFooter(schema, groups, len(rows))
+	pw.write(footer)
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(footer)))
+	pw.write(size[:])
+	pw.write([]byte("PAR1"))
+	return len(rows), pw.err
+}
+
+// parquetRow is an entry of ExportParquet with its fields flattened.
+type parquetRow struct {
+	entry  *Entry
+	fields map[string]any
+}
+
+// flattenParsed copies the fields into out, the fields of groups with dotted
+// keys, the groups of parsed JSON lines being maps.
+func flattenParsed(out map[string]any, fields map[string]any, prefix string) {
+	for k, v := range fields {
+		switch group := v.(type) {
+		case Fields:
+			flattenParsed(out, group, prefix+k+".")
+		case map[string]any:
+			flattenParsed(out, group, prefix+k+".")
+		default:
+			out[prefix+k] = v
+		}
+	}
+}
+
+// parquetColumn is a column of the schema of ExportParquet.
+type parquetColumn struct {
+	name      string
+	key       string // the field, empty for the time, level and msg columns
+	typ       int32
+	converted int32 // -1 for none
+	optional  bool
+}
+
+// parquetSchema returns the columns of the rows: time, level, msg and the
+// fields sorted by key, named with a fields. prefix when they clash with the
+// first three.
+func parquetSchema(rows []parquetRow) []parquetColumn {
+	columns := []parquetColumn{
+		{name: "time", typ: parquetInt64, converted: parquetTimestampMicros, optional: true},
+		{name: "level", typ: parquetByteArray, converted: parquetUTF8},
+		{name: "msg", typ: parquetByteArray, converted: parquetUTF8},
+	}
+	types := map[string]int32{}
+	for _, row := range rows {
+		for k, v := range row.fields {
+			if v == nil {
+				continue
+			}
+			t := parquetType(v)
+			if prev, ok := types[k]; ok && prev != t {
+				switch {
+				case prev == parquetInt64 && t == parquetDouble, prev == parquetDouble && t == parquetInt64:
+					t = parquetDouble
+				default:
+					t = parquetByteArray
+				}
+			}
+			types[k] = t
+		}
+	}
+	keys := make([]string, 0, len(types))
+	for k := range types {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		c := parquetColumn{name: k, key: k, typ: types[k], converted: -1, optional: true}
+		if k == "time" || k == "level" || k == "msg" {
+			c.name = "fields." + k
+		}
+		if c.typ == parquetByteArray {
+			c.converted = parquetUTF8
+		}
+		columns = append(columns, c)
+	}
+	return columns
+}
+
+// parquetType returns the physical type of a field value.
+func parquetType(v any) int32 {
+	switch v := v.(type) {
+	case bool:
+		return parquetBoolean
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		return parquetInt64
+	case float32:
+		return parquetDouble
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return parquetInt64
+		}
+		return parquetDouble
+	}
+	return parquetByteArray
+}
+
+// parquetWriter writes a Parquet file, keeping the offset and the first error.
+type parquetWriter struct {
+	w      io.Writer
+	offset int64
+	err    error
+}
+
+func (pw *parquetWriter) write(p []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(p)
+	pw.offset += int64(n)
+	pw.err = err
+}
+
+// parquetChunk is the metadata of a column chunk written.
+type parquetChunk struct {
+	offset int64
+	size   int64
+	values int
+}
+
+// parquetRowGroup is the metadata of a row group written.
+type parquetRowGroup struct {
+	chunks []parquetChunk
+	size   int64
+	rows   int
+}
+
+// rowGroup writes the rows as a row group, a data page per column.
+func (pw *parquetWriter) rowGroup(schema []parquetColumn, rows []parquetRow) parquetRowGroup {
+	group := parquetRowGroup{rows: len(rows)}
+	for _, c := range schema {
+		page := encodeParquetPage(c, rows)
+		var h thriftWriter
+		h.begin()
+		h.i32(1, 0) // DATA_PAGE
+		h.i32(2, int32(len(page)))
+		h.i32(3, int32(len(page)))
+		h.beginStruct(5)
+		h.i32(1, int32(len(rows)))
+		h.i32(2, parquetPlain)
+		h.i32(3, parquetRLE)
+		h.i32(4, parquetRLE)
+		h.endStruct()
+		h.endStruct()
+		chunk := parquetChunk{offset: pw.offset, size: int64(len(h.buf) + len(page)), values: len(rows)}
+		pw.write(h.buf)
+		pw.write(page)
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+	}
+	return group
+}
+
+// encodeParquetPage encodes the values of the column of the rows as the body
+// of a data page: the definition levels of an optional column, then the
+// values present in the plain encoding.
+func encodeParquetPage(c parquetColumn, rows []parquetRow) []byte {
+	var levels, values []byte
+	var present []bool
+	bools := 0
+	for _, row := range rows {
+		var v any
+		ok := true
+		switch c.key {
+		case "":
+			switch c.name {
+			case "time":
+				ok = !row.entry.Time.IsZero()
+				v = row.entry.Time.UnixMicro()
+			case "level":
+				v = string(row.entry.Level)
+			case "msg":
+				v = row.entry.Message
+			}
+		default:
+			v, ok = row.fields[c.key]
+			ok = ok && v != nil
+		}
+		present = append(present, ok)
+		if !ok {
+			continue
+		}
+		switch c.typ {
+		case parquetBoolean:
+			if bools%8 == 0 {
+				values = append(values, 0)
+			}
+			if v.(bool) {
+				values[len(values)-1] |= 1 << (bools % 8)
+			}
+			bools++
+		case parquetInt64:
+			values = binary.LittleEndian.AppendUint64(values, uint64(toInt64(v)))
+		case parquetDouble:
+			values = binary.LittleEndian.AppendUint64(values, math.Float64bits(toFloat64(v)))
+		default:
+			s := parquetString(v)
+			values = binary.LittleEndian.AppendUint32(values, uint32(len(s)))
+			values = append(values, s...)
+		}
+	}
+	if !c.optional {
+		return values
+	}
+	// runs of the RLE hybrid encoding with a bit width of 1
+	for i := 0; i < len(present); {
+		j := i
+		for j < len(present) && present[j] == present[i] {
+			j++
+		}
+		levels = binary.AppendUvarint(levels, uint64(j-i)<<1)
+		if present[i] {
+			levels = append(levels, 1)
+		} else {
+			levels = append(levels, 0)
+		}
+		i = j
+	}
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+	page = append(page, levels...)
+	return append(page, values...)
+}
+
+// toInt64 converts an integer field value of parquetInt64.
+func toInt64(v any) int64 {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// toFloat64 converts a number field value of parquetDouble.
+func toFloat64(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	}
+	return float64(toInt64(v))
+}
+
+// parquetString formats a field value of a string column, objects and arrays as JSON.
+func parquetString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// parquetFooter encodes the file metadata of the schema and the row groups.
+func parquetFooter(schema []parquetColumn, groups []parquetRowGroup, rows int) []byte {
+	var t thriftWriter
+	t.begin()
+	t.i32(1, 1)
+	t.list(2, thriftStruct, len(schema)+1)
+	t.beginElem()
+	t.binary(4, "schema")
+	t.i32(5, int32(len(schema)))
+	t.endStruct()
+	for _, c := range schema {
+		t.beginElem()
+		t.i32(1, c.typ)
+		if c.optional {
+			t.i32(3, 1)
+		} else {
+			t.i32(3, 0)
+		}
+		t.binary(4, c.name)
+		if c.converted >= 0 {
+			t.i32(6, c.converted)
+		}
+		t.endStruct()
+	}
+	t.i64(3, int64(rows))
+	t.list(4, thriftStruct, len(groups))
+	for _, g := range groups {
+		t.beginElem()
+		t.list(1, thriftStruct, len(g.chunks))
+		for i, chunk := range g.chunks {
+			t.beginElem()
+			t.i64(2, chunk.offset)
+			t.beginStruct(3)
+			t.i32(1, schema[i].typ)
+			t.list(2, thriftI32, 2)
+			t.zigzag(parquetPlain)
+			t.zigzag(parquetRLE)
+			t.list(3, thriftBinary, 1)
+			t.str(schema[i].name)
+			t.i32(4, 0) // UNCOMPRESSED
+			t.i64(5, int64(chunk.values))
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64(2, g.size)
+		t.i64(3, int64(g.rows))
+		t.endStruct()
+	}
+	t.binary(6, "tolog")
+	t.endStruct()
+	return t.buf
+}
+
+// The types of the Thrift compact protocol Parquet metadata is encoded in.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes structs in the Thrift compact protocol.
+type thriftWriter struct {
+	buf  []byte
+	last []int16 // the last field id of each open struct
+}
+
+// begin opens the top-level struct.
+func (t *thriftWriter) begin() {
+	t.last = append(t.last, 0)
+}
+
+// field writes the header of a field of the current struct.
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.last[len(t.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.zigzag(int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) zigzag(v int64) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(v<<1^v>>63))
+}
+
+func (t *thriftWriter) str(s string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.zigzag(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.str(s)
+}
+
+// list writes the header of a list field, its n elements follow.
+func (t *thriftWriter) list(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf = append(t.buf, byte(n)<<4|elem)
+		return
+	}
+	t.buf = append(t.buf, 0xf0|elem)
+	t.buf = binary.AppendUvarint(t.buf, uint64(n))
+}
+
+// beginStruct opens a struct field, closed by endStruct.
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.last = append(t.last, 0)
+}
+
+// beginElem opens a struct element of a list, closed by endStruct.
+func (t *thriftWriter) beginElem() {
+	t.last = append(t.last, 0)
+}
+
+// endStruct closes the current struct.
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	t.last = t.last[:len(t.last)-1]
+}
@@ -0,0 +1,180 @@
+package tolog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportParquet(t *testing.T) {
+	log := strings.Join([]string{
+		`# tolog file marker`,
+		`{"time":"2024-01-02T03:04:05Z","level":"info","msg":"request","fields":{"status":200,"ok":true,"http":{"path":"/a"}}}`,
+		`not a log line`,
+		`{"time":"2024-01-02T03:04:06Z","level":"error","msg":"failed","fields":{"status":500,"ok":false,"took":1.5}}`,
+		`time=2024-01-02T03:04:07Z level=warning msg=slow took=2`,
+		``,
+	}, "\n")
+	var out bytes.Buffer
+	rows, err := ExportParquet(&out, strings.NewReader(log))
+	require.NoError(t, err)
+	assert.Equal(t, 3, rows)
+
+	data := out.Bytes()
+	require.True(t, bytes.HasPrefix(data, []byte("PAR1")))
+	require.True(t, bytes.HasSuffix(data, []byte("PAR1")))
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := readThrift(t, data[len(data)-8-size:len(data)-8])
+	assert.EqualValues(t, 3, meta[3])
+
+	var names []string
+	types := map[string]int64{}
+	for _, el := range meta[2].([]any)[1:] {
+		s := el.(map[int16]any)
+		names = append(names, s[4].(string))
+		types[s[4].(string)] = s[1].(int64)
+	}
+	assert.Equal(t, []string{"time", "level", "msg", "http.path", "ok", "status", "took"}, names)
+	assert.Equal(t, map[string]int64{"time": parquetInt64, "level": parquetByteArray, "msg": parquetByteArray,
+		"http.path": parquetByteArray, "ok": parquetBoolean, "status": parquetInt64, "took": parquetByteArray}, types)
+
+	chunks := meta[4].([]any)[0].(map[int16]any)[1].([]any)
+	page := func(i int) []byte {
+		md := chunks[i].(map[int16]any)[3].(map[int16]any)
+		offset := int(md[9].(int64))
+		var n int
+		header := readThriftN(t, data[offset:], &n)
+		return data[offset+n : offset+n+int(header[3].(int64))]
+	}
+
+	// msg is required, three plain byte arrays
+	msgs := page(2)
+	var got []string
+	for len(msgs) > 0 {
+		n := binary.LittleEndian.Uint32(msgs)
+		got = append(got, string(msgs[4:4+n]))
+		msgs = msgs[4+n:]
+	}
+	assert.Equal(t, []string{"request", "failed", "slow"}, got)
+
+	// time is optional, all present: one run of three, then the microseconds
+	times := page(0)
+	assert.Equal(t, []byte{2, 0, 0, 0, 3 << 1, 1}, times[:6])
+	assert.EqualValues(t, time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC).UnixMicro(), binary.LittleEndian.Uint64(times[14:]))
+
+	// status is missing from the last row
+	status := page(5)
+	assert.Equal(t, []byte{4, 0, 0, 0, 2 << 1, 1, 1 << 1, 0}, status[:8])
+	assert.EqualValues(t, 500, binary.LittleEndian.Uint64(status[16:]))
+
+	// ok packs its two booleans in a byte
+	assert.Equal(t, []byte{0b01}, page(4)[8:])
+
+	// took mixes 1.5 with the string 2 of logfmt, a string column
+	assert.Equal(t, []byte{3, 0, 0, 0, '1', '.', '5', 1, 0, 0, 0, '2'}, page(6)[8:])
+
+	out.Reset()
+	rows, err = ExportParquet(&out, strings.NewReader(""))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rows)
+	assert.True(t, bytes.HasSuffix(out.Bytes(), []byte("PAR1")))
+}
+
+// readThrift decodes a struct of the Thrift compact protocol into its fields by id.
+func readThrift(t *testing.T, data []byte) map[int16]any {
+	var n int
+	return readThriftN(t, data, &n)
+}
+
+func readThriftN(t *testing.T, data []byte, n *int) map[int16]any {
+	r := &thriftReader{data: data}
+	s := r.readStruct()
+	require.NoError(t, r.err)
+	*n = r.pos
+	return s
+}
+
+type thriftReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *thriftReader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.err = assert.AnError
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.err = assert.AnError
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.uvarint())
+		s := string(r.data[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case thriftList:
+		h := r.byte()
+		n := int(h >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = r.value(h & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	r.err = assert.AnError
+	return nil
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	s := map[int16]any{}
+	var last int16
+	for r.err == nil {
+		h := r.byte()
+		if h == 0 {
+			break
+		}
+		id := last + int16(h>>4)
+		if h>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		s[id] = r.value(h & 0x0f)
+		last = id
+	}
+	return s
+}
@@ -0,0 +1,141 @@
+package tolog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseLine parses a line of a log file back into an entry. It understands the
+// text format, with or without color, using the current log time format, the
+// JSON based formats and logfmt. Text lines keep their fields in the message.
+func ParseLine(line string) (*Entry, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "{") {
+		return parseJSONLine(line)
+	}
+	if strings.HasPrefix(line, "time=") {
+		return parseLogfmtLine(line)
+	}
+	return parseTextLine(line)
+}
+
+// parseTextLine parses "[time] [level]  msg" lines, the colored lines of the
+// console and the "[time]  level  msg" lines of older versions.
+func parseTextLine(line string) (*Entry, error) {
+	if !strings.HasPrefix(line, "[") {
+		return nil, fmt.Errorf("parse log line: missing time: %q", line)
+	}
+	end := strings.Index(line, "] ")
+	if end < 0 {
+		return nil, fmt.Errorf("parse log line: unterminated time: %q", line)
+	}
+	e := &Entry{}
+	if t, err := time.ParseInLocation(string(globalTimeFormat()), line[1:end], globalTimeZone()); err == nil {
+		e.Time = t
+	}
+	rest := line[end+2:]
+	var level string
+	if strings.HasPrefix(rest, "[") {
+		level, rest, _ = strings.Cut(rest[1:], "] ")
+	} else {
+		if strings.HasPrefix(rest, "\033[") {
+			if i := strings.IndexByte(rest, 'm'); i >= 0 {
+				rest = rest[i+1:]
+			}
+		}
+		level, rest, _ = strings.Cut(strings.TrimPrefix(rest, " "), " ")
+		rest = strings.TrimPrefix(rest, colorReset)
+	}
+	e.Level = LogStatus(level)
+	e.Message = strings.TrimPrefix(rest, " ")
+	return e, nil
+}
+
+// jsonKeys lists the keys the JSON based formats store the entry attributes in.
+var jsonKeys = struct{ time, level, message []string }{
+	time:    []string{"time", "@timestamp", "timestamp"},
+	level:   []string{"level", "log.level", "severity"},
+	message: []string{"msg", "message"},
+}
+
+// parseJSONLine parses a line of one of the JSON based formats.
+func parseJSONLine(line string) (*Entry, error) {
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return nil, fmt.Errorf("parse log line: %w", err)
+	}
+	e := &Entry{}
+	if v, ok := takeKey(obj, jsonKeys.time); ok {
+		switch t := v.(type) {
+		case string:
+			e.Time, _ = time.Parse(time.RFC3339Nano, t)
+		case float64:
+			e.Time = time.Unix(0, int64(t*float64(time.Second)))
+		}
+	}
+	if v, ok := takeKey(obj, jsonKeys.level); ok {
+		e.Level = LogStatus(strings.ToLower(toString(v)))
+	}
+	if v, ok := takeKey(obj, jsonKeys.message); ok {
+		e.Message = toString(v)
+	}
+	if nested, ok := obj["fields"].(map[string]any); ok {
+		delete(obj, "fields")
+		e.Fields = Fields(nested)
+	}
+	for k, v := range obj {
+		if k == "ecs.version" {
+			continue
+		}
+		e.Fields = e.Fields.merge(Fields{k: v})
+	}
+	return e, nil
+}
+
+// parseLogfmtLine parses a line of FormatLogfmt, the other keys become fields.
+func parseLogfmtLine(line string) (*Entry, error) {
+	e := &Entry{}
+	for rest := line; rest != ""; {
+		rest = strings.TrimLeft(rest, " ")
+		key, after, ok := strings.Cut(rest, "=")
+		if !ok || key == "" || strings.Contains(key, " ") {
+			return nil, fmt.Errorf("parse log line: malformed logfmt pair: %q", line)
+		}
+		value := after
+		if strings.HasPrefix(after, "\"") {
+			quoted, err := strconv.QuotedPrefix(after)
+			if err != nil {
+				return nil, fmt.Errorf("parse log line: %w: %q", err, line)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = after[len(quoted):]
+		} else {
+			value, rest, _ = strings.Cut(after, " ")
+		}
+		switch key {
+		case "time":
+			e.Time, _ = time.Parse(time.RFC3339Nano, value)
+		case "level":
+			e.Level = LogStatus(value)
+		case "msg":
+			e.Message = value
+		default:
+			e.Fields = e.Fields.merge(Fields{key: value})
+		}
+	}
+	return e, nil
+}
+
+// takeKey removes and returns the first of the keys present in obj.
+func takeKey(obj map[string]any, keys []string) (any, bool) {
+	for _, k := range keys {
+		if v, ok := obj[k]; ok {
+			delete(obj, k)
+			return v, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,60 @@
+package tolog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	l := Warning("disk almost full")
+	e, err := ParseLine(l.FullLog)
+	require.NoError(t, err)
+	assert.Equal(t, StatusWarning, e.Level)
+	assert.Equal(t, "disk almost full", e.Message)
+	assert.True(t, l.time.Truncate(1e9).Equal(e.Time.Truncate(1e9)))
+
+	SetLogWithColor(true)
+	l = Notice("\033[1mbold\033[0m message")
+	e, err = ParseLine(l.String())
+	require.NoError(t, err)
+	assert.Equal(t, StatusNotice, e.Level)
+	assert.Equal(t, "\033[1mbold\033[0m message", e.Message)
+
+	e, err = ParseLine("[" + l.logTime + "]  info  written by older versions")
+	require.NoError(t, err)
+	assert.Equal(t, StatusInfo, e.Level)
+	assert.Equal(t, "written by older versions", e.Message)
+
+	SetLogWithColor(false)
+	l = Error("no color")
+	SetLogWithColor(true)
+	e, err = ParseLine(l.FullLog + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, StatusError, e.Level)
+	assert.Equal(t, "no color", e.Message)
+
+	_, err = ParseLine("not a log line")
+	assert.Error(t, err)
+}
+
+func TestParseJSONLine(t *testing.T) {
+	SetLogFormat(FormatECS)
+	defer SetLogFormat(FormatText)
+	RegisterContextExtractor(func(ctx context.Context) Fields { return Fields{"user": "bob"} })
+	defer ResetContextExtractors()
+
+	l := NoticeCtx(context.Background(), "ecs entry")
+	e, err := ParseLine(l.FullLog)
+	require.NoError(t, err)
+	assert.Equal(t, StatusNotice, e.Level)
+	assert.Equal(t, "ecs entry", e.Message)
+	assert.Equal(t, Fields{"user": "bob"}, e.Fields)
+	assert.True(t, l.time.Equal(e.Time))
+
+	e, err = ParseLine(`{"time":"2024-05-01T10:00:00Z","level":"info","msg":"json","fields":{"n":1}}`)
+	require.NoError(t, err)
+	assert.Equal(t, Fields{"n": float64(1)}, e.Fields)
+}
@@ -0,0 +1,181 @@
+package tolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LevelSource fetches the document of PollLevels, like LevelsFromURL, or a key
+// of etcd or Consul read with their client.
+type LevelSource func(ctx context.Context) ([]byte, error)
+
+// RemoteLevels is the document of PollLevels, JSON or YAML. Empty settings are
+// left as they are.
+type RemoteLevels struct {
+	// Level is the minimum level of the console and the files.
+	Level LogStatus `json:"level" yaml:"level"`
+	// ConsoleLevel and FileLevel override Level for the console and the files.
+	ConsoleLevel LogStatus `json:"console_level" yaml:"console_level"`
+	FileLevel    LogStatus `json:"file_level" yaml:"file_level"`
+	// Loggers are the levels of the named loggers, see SetLoggerLevel. The
+	// names left out of the next document have their level removed.
+	Loggers map[string]LogStatus `json:"loggers" yaml:"loggers"`
+	// Sampling configures SetSampling.
+	Sampling *RemoteSampling `json:"sampling" yaml:"sampling"`
+}
+
+// RemoteSampling are the arguments of SetSampling, Per a duration like 1s.
+type RemoteSampling struct {
+	Initial    int    `json:"initial" yaml:"initial"`
+	Thereafter int    `json:"thereafter" yaml:"thereafter"`
+	Per        string `json:"per" yaml:"per"`
+}
+
+var (
+	pollMu      sync.Mutex
+	polledNames map[string]bool // the loggers of the last document applied
+)
+
+// PollLevels fetches the levels from the source now and every interval, and
+// applies them when they changed, all at once or, when the document is
+// invalid, none of them, reporting the error to the error handler. It returns
+// a function to stop polling.
+func PollLevels(source LevelSource, interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var last []byte
+		for {
+			data, err := source(ctx)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				handleError(fmt.Errorf("tolog: polling levels: %w", err))
+			case !bytes.Equal(data, last):
+				if err := ApplyLevels(data); err != nil {
+					handleError(fmt.Errorf("tolog: polling levels: %w", err))
+				} else {
+					last = data
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return cancel
+}
+
+// LevelsFromURL returns a source getting the document at the URL, like a key
+// of Consul with ?raw.
+func LevelsFromURL(url string) LevelSource {
+	return func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	}
+}
+
+// ApplyLevels parses the document of PollLevels and applies it, nothing is
+// applied when it is invalid.
+func ApplyLevels(data []byte) error {
+	var doc RemoteLevels
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return doc.apply()
+}
+
+// apply validates the levels and applies them.
+func (r RemoteLevels) apply() error {
+	var errs []error
+	parse := func(level *LogStatus) {
+		if *level == "" {
+			return
+		}
+		parsed, err := ParseLevel(string(*level))
+		if err != nil {
+			errs = append(errs, err)
+		}
+		*level = parsed
+	}
+	parse(&r.Level)
+	parse(&r.ConsoleLevel)
+	parse(&r.FileLevel)
+	loggers := make(map[string]LogStatus, len(r.Loggers))
+	for name, level := range r.Loggers {
+		parse(&level)
+		loggers[name] = level
+	}
+	var per time.Duration
+	if r.Sampling != nil && r.Sampling.Per != "" {
+		d, err := time.ParseDuration(r.Sampling.Per)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		per = d
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if r.Level != "" || r.ConsoleLevel != "" || r.FileLevel != "" {
+		levelRevertMu.Lock()
+		cancelLevelRevert()
+		s := globalSettings()
+		console, file := s.consoleLevel, s.fileLevel
+		if r.Level != "" {
+			console, file = r.Level, r.Level
+		}
+		if r.ConsoleLevel != "" {
+			console = r.ConsoleLevel
+		}
+		if r.FileLevel != "" {
+			file = r.FileLevel
+		}
+		setLevels(console, file)
+		levelRevertMu.Unlock()
+	}
+	pollMu.Lock()
+	var removed []string
+	for name := range polledNames {
+		if _, ok := loggers[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	setLoggerLevels(loggers, removed)
+	polledNames = make(map[string]bool, len(loggers))
+	for name := range loggers {
+		polledNames[name] = true
+	}
+	pollMu.Unlock()
+	if r.Sampling != nil {
+		SetSampling(r.Sampling.Initial, r.Sampling.Thereafter, per)
+	}
+	diag("level", "applied remote levels console=%q file=%q", globalSettings().consoleLevel, globalSettings().fileLevel)
+	return nil
+}
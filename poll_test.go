@@ -0,0 +1,57 @@
+package tolog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollLevels(t *testing.T) {
+	defer SetLevel("")
+	defer SetSampling(0, 0, 0)
+	defer SetLoggerLevel("payments", "")
+
+	var mu sync.Mutex
+	doc := `{"level": "warning", "file_level": "info", "loggers": {"payments": "debug"}, "sampling": {"initial": 5, "thereafter": 10, "per": "1s"}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Write([]byte(doc))
+	}))
+	defer srv.Close()
+	errs := make(chan error, 10)
+	SetErrorHandler(func(err error) { errs <- err })
+	defer SetErrorHandler(nil)
+
+	stop := PollLevels(LevelsFromURL(srv.URL), 10*time.Millisecond)
+	defer stop()
+	require.Eventually(t, func() bool { return consoleLevel() == StatusWarning }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, StatusInfo, fileLevel())
+	assert.Equal(t, StatusDebug, loggerLevel("payments/stripe"))
+	sampling.mu.Lock()
+	assert.Equal(t, time.Second, sampling.per)
+	sampling.mu.Unlock()
+
+	mu.Lock()
+	doc = "level: nope\n"
+	mu.Unlock()
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrInvalidLevel)
+	case <-time.After(time.Second):
+		t.Fatal("invalid document not reported")
+	}
+	assert.Equal(t, StatusWarning, consoleLevel(), "nothing applied")
+
+	mu.Lock()
+	doc = "level: error\n"
+	mu.Unlock()
+	require.Eventually(t, func() bool { return consoleLevel() == StatusError }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, StatusError, fileLevel())
+	assert.Equal(t, LogStatus(""), loggerLevel("payments"), "left out of the document")
+}
@@ -0,0 +1,82 @@
+package tolog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// FormatPrettyJSON encodes entries like FormatJSON indented over several lines,
+// for teams reading the log files in editors rather than pipelines. Every
+// entry starts with a { line and ends with a } line, see CompactJSON to turn a
+// file back into a line per entry.
+const FormatPrettyJSON LogFormat = "json-pretty"
+
+func init() {
+	RegisterEncoder(FormatPrettyJSON, PrettyJSONEncoder{})
+}
+
+// PrettyJSONEncoder encodes entries like FormatPrettyJSON.
+type PrettyJSONEncoder struct{}
+
+// Encode encodes the entry as {"time":...,"level":...,"msg":...,"fields":{...}}
+// indented by two spaces.
+func (PrettyJSONEncoder) Encode(e *Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, encodeEntryJSON(e), "", "  "); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CompactJSON copies a log file of FormatPrettyJSON to w with an entry per
+// line like FormatJSON, for the tools reading a line per entry, returning the
+// number of entries. File markers and the lines outside of entries are copied
+// as they are, an entry cut short by a crash too.
+func CompactJSON(w io.Writer, r io.Reader) (int, error) {
+	bw := bufio.NewWriter(w)
+	br := bufio.NewReader(r)
+	var record bytes.Buffer
+	n := 0
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			text := strings.TrimRight(line, "\r\n")
+			switch {
+			case record.Len() > 0:
+				record.WriteString(line)
+				if text == "}" {
+					n += compactRecord(bw, &record, line[len(text):])
+				}
+			case text == "{":
+				record.WriteString(line)
+			default:
+				bw.WriteString(line)
+			}
+		}
+		if err == io.EOF {
+			bw.Write(record.Bytes())
+			return n, bw.Flush()
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+// compactRecord writes the record compacted on a line ending like its last
+// line and resets it, as it is if it is no JSON. It returns the number of
+// entries written.
+func compactRecord(w *bufio.Writer, record *bytes.Buffer, ending string) int {
+	defer record.Reset()
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, record.Bytes()); err != nil {
+		w.Write(record.Bytes())
+		return 0
+	}
+	buf.WriteString(ending)
+	w.Write(buf.Bytes())
+	return 1
+}
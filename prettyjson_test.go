@@ -0,0 +1,46 @@
+package tolog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrettyJSON(t *testing.T) {
+	lg := NewLogger("TestPrettyJSON")
+	lg.SetFormat(FormatPrettyJSON)
+	path := lg.FilePath()
+	defer os.Remove(path)
+
+	lg.Info("started").Fields(Fields{"port": 8080, "tls": Fields{"enabled": true}}).Write()
+	lg.Warning("slow").Write()
+	require.NoError(t, lg.Close())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "{\n  \"fields\": {\n    \"port\": 8080,\n    \"tls\": {\n      \"enabled\": true\n    }\n  },\n")
+	assert.Equal(t, 2, strings.Count(string(data), "\n}\n"))
+
+	var out bytes.Buffer
+	n, err := CompactJSON(&out, bytes.NewReader(append([]byte("# tolog file marker\n"), data...)))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "# tolog file marker", lines[0])
+	e, err := ParseLine(lines[1])
+	require.NoError(t, err)
+	assert.Equal(t, "started", e.Message)
+	e, err = ParseLine(lines[2])
+	require.NoError(t, err)
+	assert.Equal(t, StatusWarning, e.Level)
+
+	out.Reset()
+	n, err = CompactJSON(&out, strings.NewReader("{\n  \"msg\": \"cut"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, "{\n  \"msg\": \"cut", out.String())
+}
@@ -0,0 +1,45 @@
+package tolog
+
+// urgentQueueSize is the number of priority lines queued for the writing
+// goroutine, more wait in the queue of the other lines.
+const urgentQueueSize = 64
+
+// SetPriorityLevel makes the lines of the entries of the level and above, like
+// errors, skip the queue of the log file: the writing goroutine writes them
+// at once, ahead of the lines queued before them, so they reach the file with
+// little latency even behind a backlog of debug noise, before a crash. Empty
+// disables it, the default. It has no effect with SetManualFlush.
+func SetPriorityLevel(level LogStatus) {
+	updateSettings(func(s *settings) { s.priorityLevel = level })
+}
+
+// urgentItem reports whether the item takes the priority lane, see SetPriorityLevel.
+func (w *fileWriter) urgentItem(item writeItem) bool {
+	min := globalSettings().priorityLevel
+	return min != "" && item.level != "" && !w.manual && levelEnabled(item.level, min)
+}
+
+// flushUrgent writes the batch and the queued priority lines. If the write
+// fails, flush keeps the bytes left to write and the next flush writes them
+// first, still ahead of the buffer. Only the writing goroutine, or the caller
+// of a manual flush, takes from the priority lane.
+func (w *fileWriter) flushUrgent(batch ...writeItem) {
+	for len(w.urgent) > 0 {
+		batch = append(batch, <-w.urgent)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	synced := false
+	for _, item := range batch {
+		if _, sync := flushOn(item); sync {
+			synced = true
+		}
+	}
+	if err := w.flush(&batch); err != nil {
+		return
+	}
+	if synced {
+		w.syncNow()
+	}
+}
@@ -0,0 +1,64 @@
+package tolog
+
+import (
+	"bytes"
+	"io"
+)
+
+// clearLine moves to the start of the line and clears it.
+const clearLine = "\r\033[K"
+
+// ProgressWriter wraps the writer a progress bar draws to, see Logger.ProgressWriter.
+func ProgressWriter(w io.Writer) io.Writer {
+	return std.ProgressWriter(w)
+}
+
+// ProgressWriter wraps the writer a progress bar or spinner draws its live line
+// to, redrawn with carriage returns, the console if nil. The entries printed by
+// the logger while the line is drawn clear it, are printed in its place and
+// redraw it below them, so the line is never corrupted. The line is done once
+// it ends with a newline.
+func (lg *Logger) ProgressWriter(w io.Writer) io.Writer {
+	if w == nil {
+		w = lg.consoleStream(StatusInfo)
+	}
+	return &progressWriter{lg: lg.root(), w: w}
+}
+
+type progressWriter struct {
+	lg *Logger
+	w  io.Writer
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	lg := p.lg
+	lg.outMu.Lock()
+	defer lg.outMu.Unlock()
+	lg.progress = liveLine(append(lg.progress, b...))
+	lg.progressOut = p.w
+	return p.w.Write(b)
+}
+
+// liveLine returns what a terminal shows of the last line of the output, after
+// its last newline and carriage return.
+func liveLine(b []byte) []byte {
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		b = b[i+1:]
+	}
+	if i := bytes.LastIndexByte(bytes.TrimRight(b, "\r"), '\r'); i >= 0 {
+		b = b[i+1:]
+	}
+	return b
+}
+
+// writeConsole writes the lines to the console, above the progress line if one
+// is drawn, outMu of the root logger must be held.
+func (lg *Logger) writeConsole(console io.Writer, lines []byte) {
+	if len(lg.progress) == 0 {
+		console.Write(lines)
+		return
+	}
+	io.WriteString(lg.progressOut, clearLine)
+	console.Write(lines)
+	lg.progressOut.Write(lg.progress)
+}
@@ -0,0 +1,36 @@
+package tolog
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveLine(t *testing.T) {
+	assert.Equal(t, "[==  ] 50%", string(liveLine([]byte("\r[=   ] 25%\r[==  ] 50%"))))
+	assert.Equal(t, "[==  ] 50%\r", string(liveLine([]byte("[=   ] 25%\r[==  ] 50%\r"))))
+	assert.Empty(t, liveLine([]byte("\r[====] 100%\n")))
+}
+
+func TestProgressWriter(t *testing.T) {
+	lg := NewLogger("TestProgressWriter")
+	defer lg.Close()
+	var term bytes.Buffer
+	lg.SetConsole(&term)
+	lg.SetColor(false)
+	bar := lg.ProgressWriter(nil)
+
+	fmt.Fprint(bar, "\r[=   ] 25%")
+	lg.Info("step done").PrintLog()
+	fmt.Fprint(bar, "\r[==  ] 50%")
+	fmt.Fprint(bar, "\r[====] 100%\n")
+	lg.Info("finished").PrintLog()
+
+	out := term.String()
+	assert.Contains(t, out, "\r[=   ] 25%"+clearLine+"[")
+	assert.Contains(t, out, "step done\n[=   ] 25%\r[==  ] 50%")
+	assert.Contains(t, out, "100%\n[", "no redraw once the line is done")
+	assert.NotContains(t, out, "finished\n[")
+}
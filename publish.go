@@ -0,0 +1,92 @@
+package tolog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Publisher publishes a message on a subject or topic. *nats.Conn satisfies it.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// PublisherFunc adapts a function to a Publisher, e.g. to wrap an MQTT client.
+type PublisherFunc func(subject string, data []byte) error
+
+// Publish calls f(subject, data).
+func (f PublisherFunc) Publish(subject string, data []byte) error {
+	return f(subject, data)
+}
+
+// PublishSinkOptions configures a PublishSink.
+type PublishSinkOptions struct {
+	// Publisher delivers the messages to the broker.
+	Publisher Publisher
+	// Subject is the subject or topic template, {level} and {service} are replaced,
+	// e.g. "logs.{service}.{level}" for NATS or "logs/{service}/{level}" for MQTT.
+	Subject string
+	// Service replaces {service} in the subject.
+	Service string
+	// BatchSize and FlushInterval bound how long entries are queued, default 100 and 1s.
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// PublishSink publishes every entry as a JSON message to a pub/sub broker like NATS or MQTT.
+type PublishSink struct {
+	publisher Publisher
+	subject   *strings.Replacer
+	template  string
+	batcher   *batcher
+}
+
+// NewPublishSink creates a sink publishing to a broker. Add it with AddSink.
+func NewPublishSink(opts PublishSinkOptions) (*PublishSink, error) {
+	if opts.Publisher == nil {
+		return nil, fmt.Errorf("tolog: publish sink: missing publisher")
+	}
+	if opts.Subject == "" {
+		return nil, fmt.Errorf("tolog: publish sink: missing subject")
+	}
+	s := &PublishSink{
+		publisher: opts.Publisher,
+		subject:   strings.NewReplacer("{service}", opts.Service),
+		template:  opts.Subject,
+	}
+	s.batcher = newBatcher(opts.BatchSize, opts.FlushInterval, s.publish)
+	return s, nil
+}
+
+// WriteEntry queues the entry for publishing, dropping it with
+// ErrChannelFull if the queue is full.
+func (s *PublishSink) WriteEntry(e *Entry) error {
+	return s.batcher.add(e)
+}
+
+// Close publishes the queued entries and stops the sink.
+func (s *PublishSink) Close() error {
+	s.batcher.close()
+	return nil
+}
+
+// Subject returns the subject entries of the level are published on.
+func (s *PublishSink) Subject(level LogStatus) string {
+	return strings.ReplaceAll(s.subject.Replace(s.template), "{level}", string(level))
+}
+
+// publish publishes every entry of the batch, a failed message does not keep
+// the others from being published.
+func (s *PublishSink) publish(batch []*Entry) error {
+	var errs []error
+	for _, e := range batch {
+		if err := s.publisher.Publish(s.Subject(e.Level), encodeEntryJSON(e)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("tolog: publish sink: %d of %d messages dropped: %w", len(errs), len(batch), errors.Join(errs...))
+}
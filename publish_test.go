@@ -0,0 +1,67 @@
+package tolog
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishSink(t *testing.T) {
+	var mu sync.Mutex
+	published := map[string][]byte{}
+	publisher := PublisherFunc(func(subject string, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		published[subject] = data
+		return nil
+	})
+
+	_, err := NewPublishSink(PublishSinkOptions{Subject: "logs"})
+	assert.Error(t, err)
+
+	sink, err := NewPublishSink(PublishSinkOptions{Publisher: publisher, Subject: "logs/{service}/{level}", Service: "edge"})
+	require.NoError(t, err)
+	assert.Equal(t, "logs/edge/error", sink.Subject(StatusError))
+
+	require.NoError(t, sink.WriteEntry(&Entry{Time: time.Now(), Level: StatusError, Message: "sensor offline", Fields: Fields{"sensor": "t1"}}))
+	require.NoError(t, sink.Close())
+
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal(published["logs/edge/error"], &obj))
+	assert.Equal(t, "sensor offline", obj["msg"])
+	assert.Equal(t, "error", obj["level"])
+	assert.Equal(t, map[string]any{"sensor": "t1"}, obj["fields"])
+}
+
+func TestPublishSinkFailedMessage(t *testing.T) {
+	var mu sync.Mutex
+	var published []string
+	publisher := PublisherFunc(func(subject string, data []byte) error {
+		if subject == "logs.warning" {
+			return errors.New("slow consumer")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, subject)
+		return nil
+	})
+	var errs []error
+	SetErrorHandler(func(err error) { errs = append(errs, err) })
+	defer SetErrorHandler(nil)
+
+	sink, err := NewPublishSink(PublishSinkOptions{Publisher: publisher, Subject: "logs.{level}"})
+	require.NoError(t, err)
+	for _, level := range []LogStatus{StatusInfo, StatusWarning, StatusError} {
+		require.NoError(t, sink.WriteEntry(&Entry{Time: time.Now(), Level: level, Message: "m"}))
+	}
+	require.NoError(t, sink.Close())
+
+	assert.Equal(t, []string{"logs.info", "logs.error"}, published)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "tolog: publish sink: 1 of 3 messages dropped: slow consumer")
+}
@@ -0,0 +1,82 @@
+package tolog
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var manualFlush int32
+
+// SetManualFlush disables the goroutines writing the queued lines to the log
+// files in the background, for deterministic tests: WriteSafe only queues the
+// lines until Pump, FlushNow, Flush or Close writes them. A full queue is
+// written by the writing goroutine instead of blocking. It applies to the files
+// opened after it is set, set it before logging, e.g. in TestMain.
+func SetManualFlush(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&manualFlush, v)
+}
+
+// Pump writes the lines queued for the log files of all loggers, without
+// syncing the files to disk. With SetManualFlush it writes them from the
+// calling goroutine, otherwise it asks the goroutines of the files to write
+// them at once and waits until they did.
+func Pump() error {
+	var errs []error
+	for _, w := range openWriters() {
+		if err := w.writeOut(false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Pump writes the lines queued for the log file of the logger, see Pump.
+func (lg *Logger) Pump() error {
+	return lg.fileWriter().writeOut(false)
+}
+
+// FlushNow writes the lines queued for the log files of all loggers like Pump,
+// in both modes, and syncs the files to disk. It is Flush, under the name
+// used with SetManualFlush.
+func FlushNow() error {
+	return Flush()
+}
+
+// FlushNow writes the lines queued for the log file of the logger and syncs
+// it to disk, see FlushNow.
+func (lg *Logger) FlushNow() error {
+	return lg.Flush()
+}
+
+// pump writes the queued lines from the calling goroutine in manual mode, w.mu
+// must be held.
+func (w *fileWriter) pump() error {
+	w.pumpMu.Lock()
+	defer w.pumpMu.Unlock()
+	var buffer []writeItem
+	for w.queued() > 0 {
+		item, ok := w.dequeue()
+		if !ok {
+			continue
+		}
+		buffer = append(buffer, item)
+	}
+	if len(buffer) == 0 && !w.pending() {
+		return nil
+	}
+	return w.flush(&buffer)
+}
+
+// enqueueManual queues the item, writing the queue first when it is full, w.mu
+// must be held for reading.
+func (w *fileWriter) enqueueManual(item writeItem) {
+	for !w.tryEnqueue(item) {
+		if err := w.pump(); err != nil {
+			handleError(err)
+		}
+	}
+}
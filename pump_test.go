@@ -0,0 +1,81 @@
+package tolog
+
+import (
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManualFlush(t *testing.T) {
+	SetManualFlush(true)
+	lg := NewLogger("TestManualFlush")
+	SetManualFlush(false)
+	path := lg.FilePath()
+	os.Remove(path)
+	defer os.Remove(path)
+
+	read := func() string {
+		content, _ := os.ReadFile(path)
+		return string(content)
+	}
+	lg.Info("queued").WriteSafe()
+	assert.NotContains(t, read(), "queued")
+	require.NoError(t, lg.Pump())
+	assert.Contains(t, read(), "queued")
+
+	// more than the queue holds
+	for i := 0; i < 350; i++ {
+		lg.Infof("line %d", i).WriteSafe()
+	}
+	require.NoError(t, Flush())
+	assert.Contains(t, read(), "line 349")
+
+	lg.Info("closing").WriteSafe()
+	require.NoError(t, lg.Close())
+	assert.Equal(t, 1, strings.Count(read(), "closing"))
+}
+
+func TestPumpAndFlushNow(t *testing.T) {
+	var syncs int64
+	SetFS(syncCountFS{syncs: &syncs})
+	defer SetFS(nil)
+	SetLogTickerTime(time.Hour)
+	defer SetLogTickerTime(500 * time.Millisecond)
+
+	for _, manual := range []bool{true, false} {
+		SetManualFlush(manual)
+		lg := NewLogger("TestPumpAndFlushNow")
+		SetManualFlush(false)
+		path := lg.FilePath()
+		os.Remove(path)
+		read := func() string {
+			content, _ := os.ReadFile(path)
+			return string(content)
+		}
+		atomic.StoreInt64(&syncs, 0)
+
+		lg.Info("pumped").WriteSafe()
+		require.NoError(t, lg.Pump(), "manual %v", manual)
+		assert.Contains(t, read(), "pumped", "manual %v", manual)
+		assert.Equal(t, int64(0), atomic.LoadInt64(&syncs), "Pump does not sync, manual %v", manual)
+
+		lg.Info("flushed").WriteSafe()
+		require.NoError(t, lg.FlushNow(), "manual %v", manual)
+		assert.Contains(t, read(), "flushed", "manual %v", manual)
+		assert.Equal(t, int64(1), atomic.LoadInt64(&syncs), "FlushNow syncs, manual %v", manual)
+
+		lg.Info("all").WriteSafe()
+		require.NoError(t, Pump())
+		assert.Contains(t, read(), "all", "manual %v", manual)
+		require.NoError(t, FlushNow())
+		assert.Equal(t, int64(2), atomic.LoadInt64(&syncs), "manual %v", manual)
+
+		require.NoError(t, lg.Close())
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,379 @@
+package tolog
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// QueueMode is the kind of queue the lines of a log file wait in for the
+// goroutine writing them.
+type QueueMode int32
+
+const (
+	// QueueChannel queues the lines in a Go channel, the default.
+	QueueChannel QueueMode = iota
+	// QueueRing queues the lines in a lock-free ring buffer, cheaper than a
+	// channel for many concurrent writers at very high throughput, and
+	// dropping the oldest line without contending with the writing goroutine.
+	QueueRing
+	// QueueSharded spreads the lines over several ring buffers, see
+	// SetQueueShards, merged back in the order they were written by the
+	// writing goroutine, for machines with many cores where even a single
+	// ring is contended.
+	QueueSharded
+)
+
+var (
+	queueMode   int32
+	queueShards int32
+)
+
+// SetQueueMode sets the kind of queue of the log files, see QueueMode. It
+// applies to the files opened after it is set, set it before logging. The ring
+// buffer holds the size of SetLogChannelSize rounded up to a power of two.
+func SetQueueMode(mode QueueMode) {
+	atomic.StoreInt32(&queueMode, int32(mode))
+}
+
+// SetQueueShards sets the number of ring buffers of QueueSharded, sharing the
+// size of SetLogChannelSize, GOMAXPROCS if zero or less.
+func SetQueueShards(n int) {
+	atomic.StoreInt32(&queueShards, int32(n))
+}
+
+// lineQueue is the queue of the lines of a log file with QueueRing and
+// QueueSharded, the channel of the writer otherwise.
+type lineQueue interface {
+	tryPush(item writeItem) bool
+	// push queues the item, waiting for room until done is closed, false if it was.
+	push(done <-chan struct{}, item writeItem) bool
+	pop() (writeItem, bool)
+	len() int
+	cap() int
+	// wake is signalled after pushes, for the writing goroutine.
+	wake() <-chan struct{}
+}
+
+// newLineQueue returns the queue of the mode, nil for QueueChannel.
+func newLineQueue(mode QueueMode, size int) lineQueue {
+	switch mode {
+	case QueueRing:
+		return newRingQueue(size)
+	case QueueSharded:
+		n := int(atomic.LoadInt32(&queueShards))
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		return newShardedQueue(n, size)
+	}
+	return nil
+}
+
+// queued returns the number of queued lines, w.mu must be held for reading.
+func (w *fileWriter) queued() int {
+	if w.queue != nil {
+		return w.queue.len()
+	}
+	return len(w.lines)
+}
+
+// tryEnqueue queues the item unless the queue is full, w.mu must be held for reading.
+func (w *fileWriter) tryEnqueue(item writeItem) bool {
+	if w.queue != nil {
+		return w.queue.tryPush(item)
+	}
+	select {
+	case w.lines <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+// enqueueWait queues the item, waiting for room until done is closed, false if
+// it was, w.mu must be held for reading.
+func (w *fileWriter) enqueueWait(done <-chan struct{}, item writeItem) bool {
+	if w.queue != nil {
+		return w.queue.push(done, item)
+	}
+	select {
+	case w.lines <- item:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// dequeue takes the oldest queued item, false if none is queued.
+func (w *fileWriter) dequeue() (writeItem, bool) {
+	if w.queue != nil {
+		return w.queue.pop()
+	}
+	select {
+	case item := <-w.lines:
+		return item, true
+	default:
+		return writeItem{}, false
+	}
+}
+
+// ringQueue is a bounded lock-free queue of lines, after Dmitry Vyukov's
+// bounded MPMC queue: every slot holds a sequence number telling whether it is
+// free for the push of a position or filled for its pop.
+type ringQueue struct {
+	slots []ringSlot
+	mask  uint64
+
+	_    [56]byte // keeps the positions in cache lines of their own
+	tail atomic.Uint64
+	_    [56]byte
+	head atomic.Uint64
+	_    [56]byte
+
+	ready chan struct{} // signalled after a push, for the writing goroutine
+	space chan struct{} // signalled after a pop, for a writer waiting on a full queue
+}
+
+type ringSlot struct {
+	seq  atomic.Uint64
+	item writeItem
+}
+
+// newRingQueue returns a ring queue holding size items rounded up to a power
+// of two, at least two for the sequence numbers to tell the rounds apart.
+func newRingQueue(size int) *ringQueue {
+	n := 2
+	for n < size {
+		n <<= 1
+	}
+	q := &ringQueue{
+		slots: make([]ringSlot, n),
+		mask:  uint64(n - 1),
+		ready: make(chan struct{}, 1),
+		space: make(chan struct{}, 1),
+	}
+	for i := range q.slots {
+		q.slots[i].seq.Store(uint64(i))
+	}
+	return q
+}
+
+// tryPush queues the item unless the queue is full.
+func (q *ringQueue) tryPush(item writeItem) bool {
+	pos := q.tail.Load()
+	for {
+		slot := &q.slots[pos&q.mask]
+		seq := slot.seq.Load()
+		switch diff := int64(seq - pos); {
+		case diff == 0:
+			if q.tail.CompareAndSwap(pos, pos+1) {
+				slot.item = item
+				slot.seq.Store(pos + 1)
+				select {
+				case q.ready <- struct{}{}:
+				default:
+				}
+				return true
+			}
+			pos = q.tail.Load()
+		case diff < 0:
+			return false
+		default:
+			pos = q.tail.Load()
+		}
+	}
+}
+
+// push queues the item, waiting while the queue is full until done is closed.
+// Writers waiting at the same time are woken one per pop, the others check
+// again every millisecond.
+func (q *ringQueue) push(done <-chan struct{}, item writeItem) bool {
+	for !q.tryPush(item) {
+		select {
+		case <-q.space:
+		case <-time.After(time.Millisecond):
+		case <-done:
+			return false
+		}
+	}
+	return true
+}
+
+// pop takes the oldest item, false if the queue is empty or its oldest item
+// is still being pushed.
+func (q *ringQueue) pop() (writeItem, bool) {
+	pos := q.head.Load()
+	for {
+		slot := &q.slots[pos&q.mask]
+		seq := slot.seq.Load()
+		switch diff := int64(seq - (pos + 1)); {
+		case diff == 0:
+			if q.head.CompareAndSwap(pos, pos+1) {
+				item := slot.item
+				slot.item = writeItem{}
+				slot.seq.Store(pos + q.mask + 1)
+				select {
+				case q.space <- struct{}{}:
+				default:
+				}
+				return item, true
+			}
+			pos = q.head.Load()
+		case diff < 0:
+			return writeItem{}, false
+		default:
+			pos = q.head.Load()
+		}
+	}
+}
+
+// len returns the number of queued items, pushes in progress included.
+func (q *ringQueue) len() int {
+	n := int64(q.tail.Load() - q.head.Load())
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
+
+func (q *ringQueue) cap() int {
+	return len(q.slots)
+}
+
+func (q *ringQueue) wake() <-chan struct{} {
+	return q.ready
+}
+
+// shardedQueue spreads the items round-robin over ring queues, numbering them
+// to pop them in the order they were pushed.
+type shardedQueue struct {
+	shards []*ringQueue
+	seq    atomic.Uint64
+	ready  chan struct{}
+
+	mu      sync.Mutex // guards the popping side below
+	pending []writeItem
+	next    uint64              // the sequence number of the next item to pop
+	skipped map[uint64]struct{} // the numbers of the items a full shard refused
+}
+
+// newShardedQueue returns a queue of n shards sharing size items.
+func newShardedQueue(n, size int) *shardedQueue {
+	q := &shardedQueue{
+		shards:  make([]*ringQueue, n),
+		ready:   make(chan struct{}, 1),
+		skipped: map[uint64]struct{}{},
+	}
+	for i := range q.shards {
+		q.shards[i] = newRingQueue((size + n - 1) / n)
+		q.shards[i].ready = q.ready
+	}
+	return q
+}
+
+// shard numbers the item and returns the shard it goes to.
+func (q *shardedQueue) shard(item *writeItem) *ringQueue {
+	item.seq = q.seq.Add(1) - 1
+	return q.shards[item.seq%uint64(len(q.shards))]
+}
+
+// tryPush queues the item unless its shard is full, in which case its number
+// is skipped by pop.
+func (q *shardedQueue) tryPush(item writeItem) bool {
+	if q.shard(&item).tryPush(item) {
+		return true
+	}
+	q.skip(item.seq)
+	return false
+}
+
+// push queues the item, waiting while its shard is full until done is closed,
+// in which case its number is skipped by pop.
+func (q *shardedQueue) push(done <-chan struct{}, item writeItem) bool {
+	if q.shard(&item).push(done, item) {
+		return true
+	}
+	q.skip(item.seq)
+	return false
+}
+
+// skip marks the number of an item that was not queued, for pop to go past it.
+func (q *shardedQueue) skip(seq uint64) {
+	q.mu.Lock()
+	q.skipped[seq] = struct{}{}
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop takes the next item in sequence, false if it is still being pushed,
+// even when later ones are queued.
+func (q *shardedQueue) pop() (writeItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	collected := false
+	for {
+		if len(q.pending) > 0 && q.pending[0].seq == q.next {
+			item := q.pending[0]
+			q.pending[0] = writeItem{}
+			q.pending = q.pending[1:]
+			q.next++
+			return item, true
+		}
+		if _, ok := q.skipped[q.next]; ok {
+			delete(q.skipped, q.next)
+			q.next++
+			continue
+		}
+		if collected {
+			return writeItem{}, false
+		}
+		q.collect()
+		collected = true
+	}
+}
+
+// collect moves the items of the shards to pending, in sequence, q.mu must be
+// held. While the next number is still being pushed, only its shard is
+// emptied once pending holds the capacity of the queue: the other shards
+// fill up and block their writers, instead of piling their items up here.
+func (q *shardedQueue) collect() {
+	n := len(q.pending)
+	first := int(q.next % uint64(len(q.shards)))
+	for i := range q.shards {
+		s := q.shards[(first+i)%len(q.shards)]
+		for i == 0 || len(q.pending) < q.cap() {
+			item, ok := s.pop()
+			if !ok {
+				break
+			}
+			q.pending = append(q.pending, item)
+		}
+	}
+	if len(q.pending) > n {
+		sort.Slice(q.pending, func(i, j int) bool { return q.pending[i].seq < q.pending[j].seq })
+	}
+}
+
+func (q *shardedQueue) len() int {
+	q.mu.Lock()
+	n := len(q.pending)
+	q.mu.Unlock()
+	for _, s := range q.shards {
+		n += s.len()
+	}
+	return n
+}
+
+func (q *shardedQueue) cap() int {
+	return len(q.shards) * q.shards[0].cap()
+}
+
+func (q *shardedQueue) wake() <-chan struct{} {
+	return q.ready
+}
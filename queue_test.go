@@ -0,0 +1,202 @@
+package tolog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingQueue(t *testing.T) {
+	assert.Len(t, newRingQueue(1).slots, 2)
+	q := newRingQueue(3)
+	assert.Len(t, q.slots, 4)
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 4; i++ {
+			assert.True(t, q.tryPush(writeItem{line: fmt.Sprint(i)}))
+		}
+		assert.False(t, q.tryPush(writeItem{line: "full"}))
+		assert.Equal(t, 4, q.len())
+		for i := 0; i < 4; i++ {
+			item, ok := q.pop()
+			assert.True(t, ok)
+			assert.Equal(t, fmt.Sprint(i), item.line)
+		}
+		_, ok := q.pop()
+		assert.False(t, ok)
+	}
+
+	q = newRingQueue(64)
+	var wg sync.WaitGroup
+	for p := 0; p < 8; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				q.push(nil, writeItem{line: fmt.Sprintf("%d-%d", p, i)})
+			}
+		}(p)
+	}
+	seen := map[string]bool{}
+	last := map[string]int{}
+	for len(seen) < 8000 {
+		item, ok := q.pop()
+		if !ok {
+			<-q.ready
+			continue
+		}
+		assert.False(t, seen[item.line], "popped twice: %s", item.line)
+		seen[item.line] = true
+		var p, i int
+		fmt.Sscanf(item.line, "%d-%d", &p, &i)
+		if prev, ok := last[fmt.Sprint(p)]; ok {
+			assert.Greater(t, i, prev, "lines of a writer out of order")
+		}
+		last[fmt.Sprint(p)] = i
+	}
+	wg.Wait()
+}
+
+func TestShardedQueue(t *testing.T) {
+	q := newShardedQueue(4, 16)
+	assert.Equal(t, 16, q.cap())
+	var wg sync.WaitGroup
+	for p := 0; p < 8; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				q.push(nil, writeItem{line: fmt.Sprintf("%d-%d", p, i)})
+			}
+		}(p)
+	}
+	last := map[int]int{}
+	var seq uint64
+	for popped := 0; popped < 8000; {
+		item, ok := q.pop()
+		if !ok {
+			select {
+			case <-q.wake():
+			case <-time.After(time.Millisecond):
+			}
+			continue
+		}
+		popped++
+		assert.Equal(t, seq, item.seq, "popped out of sequence")
+		seq = item.seq + 1
+		var p, i int
+		fmt.Sscanf(item.line, "%d-%d", &p, &i)
+		if prev, ok := last[p]; ok {
+			assert.Equal(t, prev+1, i, "lines of a writer out of order")
+		}
+		last[p] = i
+	}
+	wg.Wait()
+	assert.Equal(t, 0, q.len())
+
+	// the number of a dropped line is skipped
+	q = newShardedQueue(2, 4)
+	for _, line := range []string{"a", "b", "c", "d"} {
+		assert.True(t, q.tryPush(writeItem{line: line}))
+	}
+	assert.False(t, q.tryPush(writeItem{line: "dropped"}))
+	for _, want := range []string{"a", "b", "c", "d"} {
+		item, ok := q.pop()
+		assert.True(t, ok)
+		assert.Equal(t, want, item.line)
+	}
+	assert.True(t, q.tryPush(writeItem{line: "e"}))
+	item, ok := q.pop()
+	assert.True(t, ok)
+	assert.Equal(t, "e", item.line)
+	assert.Empty(t, q.skipped)
+
+	// the items pushed while a number is still being pushed do not pile up
+	q = newShardedQueue(4, 16)
+	held := writeItem{line: "held"}
+	s := q.shard(&held)
+	for p := 0; p < 8; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				q.push(nil, writeItem{line: fmt.Sprintf("%d-%d", p, i)})
+			}
+		}(p)
+	}
+	for i := 0; i < 200; i++ {
+		_, ok := q.pop()
+		require.False(t, ok)
+		q.mu.Lock()
+		assert.LessOrEqual(t, len(q.pending), q.cap()+s.cap()+8)
+		q.mu.Unlock()
+		time.Sleep(100 * time.Microsecond)
+	}
+	s.push(nil, held)
+	seq = 0
+	for popped := 0; popped < 801; {
+		item, ok := q.pop()
+		if !ok {
+			select {
+			case <-q.wake():
+			case <-time.After(time.Millisecond):
+			}
+			continue
+		}
+		if popped == 0 {
+			assert.Equal(t, "held", item.line)
+		}
+		popped++
+		assert.Equal(t, seq, item.seq, "popped out of sequence")
+		seq = item.seq + 1
+	}
+	wg.Wait()
+}
+
+func TestQueueModes(t *testing.T) {
+	defer SetQueueMode(QueueChannel)
+	defer SetQueueShards(0)
+	SetQueueShards(3)
+	for _, mode := range []QueueMode{QueueRing, QueueSharded} {
+		SetQueueMode(mode)
+		lg := NewLogger("TestQueueModes")
+		path := lg.FilePath()
+		os.Remove(path)
+
+		var wg sync.WaitGroup
+		for p := 0; p < 4; p++ {
+			wg.Add(1)
+			go func(p int) {
+				defer wg.Done()
+				for i := 0; i < 500; i++ {
+					lg.Infof("queued %d-%d", p, i).WriteSafe()
+				}
+			}(p)
+		}
+		wg.Wait()
+		require.NotNil(t, lg.fileWriter().queue)
+		assert.NoError(t, lg.Flush())
+		lg.Close()
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, 2000, strings.Count(string(content), "queued "), "mode %d", mode)
+		last := map[int]int{}
+		for _, line := range strings.Split(string(content), "\n") {
+			var p, i int
+			if _, err := fmt.Sscanf(line[strings.Index(line, "queued ")+1:], "ueued %d-%d", &p, &i); err != nil || !strings.Contains(line, "queued ") {
+				continue
+			}
+			if prev, ok := last[p]; ok {
+				assert.Equal(t, prev+1, i, "mode %d: lines of a writer out of order", mode)
+			}
+			last[p] = i
+		}
+		os.Remove(path)
+	}
+}
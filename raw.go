@@ -0,0 +1,64 @@
+package tolog
+
+import (
+	"errors"
+	"sync"
+)
+
+// RawSink is implemented by outputs which accept pre-formatted lines as is,
+// without any entry formatting or color handling. The line is shared by the
+// sinks and must not be modified or retained.
+type RawSink interface {
+	WriteRaw(p []byte) error
+}
+
+var rawSinks []RawSink
+var rawSinksMu sync.RWMutex
+
+// AddRawSink registers a sink which receives every line passed to WriteRaw.
+func AddRawSink(sink RawSink) {
+	rawSinksMu.Lock()
+	defer rawSinksMu.Unlock()
+	rawSinks = append(rawSinks, sink)
+}
+
+// RemoveRawSinks unregisters all raw sinks.
+func RemoveRawSinks() {
+	rawSinksMu.Lock()
+	defer rawSinksMu.Unlock()
+	rawSinks = nil
+}
+
+// WriteRaw writes an already formatted line to the log file and the raw sinks,
+// skipping formatting and color stripping. A newline is appended if missing.
+func WriteRaw(p []byte) error {
+	return std.WriteRaw(p)
+}
+
+// WriteRaw writes an already formatted line to the log file of the logger, its
+// outputs and the raw sinks, skipping formatting and color stripping. A sink
+// failing does not keep the line from the others, the errors are joined.
+func (lg *Logger) WriteRaw(p []byte) error {
+	line := string(p)
+	if len(line) == 0 || line[len(line)-1] != '\n' {
+		line += "\n"
+	}
+	if err := lg.fileWriter().write(writeItem{line: line}); err != nil {
+		return err
+	}
+	lg.writeOutputs(nil, line)
+
+	rawSinksMu.RLock()
+	defer rawSinksMu.RUnlock()
+	if len(rawSinks) == 0 {
+		return nil
+	}
+	data := []byte(line)
+	var errs []error
+	for _, sink := range rawSinks {
+		if err := sink.WriteRaw(data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
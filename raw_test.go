@@ -0,0 +1,61 @@
+package tolog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bufferRawSink struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (s *bufferRawSink) WriteRaw(p []byte) error {
+	s.buf.Write(p)
+	return s.err
+}
+
+func TestWriteRaw(t *testing.T) {
+	logPrefix := "TestWriteRaw"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
+	cleanLogFiles(t, logFilePath)
+	SetLogPrefix(logPrefix)
+
+	sink := &bufferRawSink{}
+	AddRawSink(sink)
+	defer RemoveRawSinks()
+
+	require.NoError(t, WriteRaw([]byte("2024-01-01 external process line")))
+	require.NoError(t, WriteRaw([]byte("\033[48;5;27m kept as is \033[0m\n")))
+	CloseLogFile()
+
+	checkMessageExistInFile(t, logFilePath, "2024-01-01 external process line\n")
+	checkMessageExistInFile(t, logFilePath, "\033[48;5;27m kept as is \033[0m\n")
+	assert.Equal(t, "2024-01-01 external process line\n\033[48;5;27m kept as is \033[0m\n", sink.buf.String())
+}
+
+func TestWriteRawSinkErrors(t *testing.T) {
+	lg := NewLogger("TestWriteRawSinkErrors")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	first := &bufferRawSink{err: errors.New("disk full")}
+	second := &bufferRawSink{}
+	third := &bufferRawSink{err: errors.New("broken pipe")}
+	AddRawSink(first)
+	AddRawSink(second)
+	AddRawSink(third)
+	defer RemoveRawSinks()
+
+	err := lg.WriteRaw([]byte("line"))
+	assert.ErrorContains(t, err, "disk full")
+	assert.ErrorContains(t, err, "broken pipe")
+	for _, sink := range []*bufferRawSink{first, second, third} {
+		assert.Equal(t, "line\n", sink.buf.String())
+	}
+}
@@ -0,0 +1,144 @@
+package tolog
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recentCache keeps the last written entries in a ring, for Recent.
+type recentCache struct {
+	mu      sync.Mutex
+	entries []*Entry // the ring, nil while disabled
+	next    int      // the slot of the next entry
+	full    bool
+	maxAge  time.Duration
+	on      atomic.Bool // entries is not nil, read without the lock on the hot path
+}
+
+var recent = &recentCache{}
+
+// SetRecentCache keeps the last max written entries in memory, at most maxAge
+// old if positive, for Recent and RecentHandler, e.g. for an admin page which
+// shows the recent errors without reading the log files back. Zero turns it
+// off, the default, forgetting the entries.
+func SetRecentCache(max int, maxAge time.Duration) {
+	recent.mu.Lock()
+	defer recent.mu.Unlock()
+	recent.entries = nil
+	if max > 0 {
+		recent.entries = make([]*Entry, max)
+	}
+	recent.next = 0
+	recent.full = false
+	recent.maxAge = maxAge
+	recent.on.Store(max > 0)
+}
+
+// Recent returns the cached entries of the level and above, all if empty,
+// written since the time, the oldest first. It is empty unless SetRecentCache
+// turned the cache on.
+func Recent(level LogStatus, since time.Time) []*Entry {
+	c := recent
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxAge > 0 {
+		if oldest := time.Now().Add(-c.maxAge); oldest.After(since) {
+			since = oldest
+		}
+	}
+	start, n := 0, c.next
+	if c.full {
+		start, n = c.next, len(c.entries)
+	}
+	var entries []*Entry
+	for i := 0; i < n; i++ {
+		e := c.entries[(start+i)%len(c.entries)]
+		if levelEnabled(e.Level, level) && !e.Time.Before(since) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// enabled reports whether the cache keeps entries.
+func (c *recentCache) enabled() bool {
+	return c.on.Load()
+}
+
+// add caches the entry, replacing the oldest one when full.
+func (c *recentCache) add(e *Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		return
+	}
+	c.entries[c.next] = e
+	c.next++
+	if c.next == len(c.entries) {
+		c.next = 0
+		c.full = true
+	}
+}
+
+// RecentHandler returns a handler serving the cached entries as a JSON array
+// of entries in the JSON format, e.g. mounted on /logs/recent, or as an HTML
+// page with format=html. The level parameter sets the minimum level and the
+// since parameter, a duration like 5m or an RFC 3339 time, how far back they go.
+func RecentHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var level LogStatus
+		if name := r.FormValue("level"); name != "" {
+			parsed, err := ParseLevel(name)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			level = parsed
+		}
+		var since time.Time
+		if param := r.FormValue("since"); param != "" {
+			if d, err := time.ParseDuration(param); err == nil {
+				since = time.Now().Add(-d)
+			} else if t, err := time.Parse(time.RFC3339, param); err == nil {
+				since = t
+			} else {
+				http.Error(w, "invalid since "+param, http.StatusBadRequest)
+				return
+			}
+		}
+		if r.FormValue("format") == string(FormatHTML) {
+			writeRecentHTML(w, Recent(level, since))
+			return
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, e := range Recent(level, since) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(encodeEntryJSON(e))
+		}
+		buf.WriteString("]\n")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(buf.Bytes())
+	})
+}
+
+// writeRecentHTML writes the entries as an HTML page styled with HTMLStyle.
+func writeRecentHTML(w http.ResponseWriter, entries []*Entry) {
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Recent entries</title><style>\n")
+	buf.WriteString(HTMLStyle)
+	buf.WriteString("</style></head><body>\n")
+	for _, e := range entries {
+		data, _ := HTMLEncoder{}.Encode(e)
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("</body></html>\n")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
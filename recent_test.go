@@ -0,0 +1,51 @@
+package tolog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecent(t *testing.T) {
+	assert.Empty(t, Recent("", time.Time{}))
+	SetRecentCache(3, time.Hour)
+	defer SetRecentCache(0, 0)
+	lg := NewLogger("TestRecent")
+	defer lg.Close()
+
+	lg.Info("first").WriteSafe()
+	start := time.Now()
+	lg.Error("second").WriteSafe()
+	lg.Info("third").WriteSafe()
+	lg.Warning("fourth").WriteSafe()
+	lg.Info("printed only").PrintLog()
+
+	messages := func(entries []*Entry) []string {
+		var msgs []string
+		for _, e := range entries {
+			msgs = append(msgs, e.Message)
+		}
+		return msgs
+	}
+	assert.Equal(t, []string{"second", "third", "fourth"}, messages(Recent("", time.Time{})))
+	assert.Equal(t, []string{"second", "fourth"}, messages(Recent(StatusWarning, start)))
+	assert.Empty(t, Recent("", time.Now().Add(time.Minute)))
+
+	rec := httptest.NewRecorder()
+	RecentHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?level=error&since=1m", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"msg":"second"`)
+	assert.NotContains(t, rec.Body.String(), "fourth")
+
+	rec = httptest.NewRecorder()
+	RecentHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?since=yesterday", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	SetRecentCache(2, time.Nanosecond)
+	lg.Info("expired").WriteSafe()
+	time.Sleep(time.Millisecond)
+	assert.Empty(t, Recent("", time.Time{}))
+}
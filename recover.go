@@ -0,0 +1,66 @@
+package tolog
+
+// RecoverOption configures RecoverAndLog and Go.
+type RecoverOption func(c *recoverConfig)
+
+type recoverConfig struct {
+	message string
+	repanic bool
+}
+
+// Repanic panics again with the value once it is logged and the log file
+// flushed, so the process still crashes but the panic is in the log.
+func Repanic() RecoverOption {
+	return func(c *recoverConfig) { c.repanic = true }
+}
+
+// RecoverMessage sets the message of the entry, "panic recovered" by default.
+func RecoverMessage(msg string) RecoverOption {
+	return func(c *recoverConfig) { c.message = msg }
+}
+
+// RecoverAndLog recovers a panic when deferred, and logs it with the default
+// logger as an error with the panic value and the stack, see PanicFields:
+//
+//	defer tolog.RecoverAndLog()
+func RecoverAndLog(opts ...RecoverOption) {
+	if v := recover(); v != nil {
+		std.logPanic(v, opts)
+	}
+}
+
+// RecoverAndLog recovers a panic when deferred, and logs it with the logger,
+// see the package RecoverAndLog.
+func (lg *Logger) RecoverAndLog(opts ...RecoverOption) {
+	if v := recover(); v != nil {
+		lg.logPanic(v, opts)
+	}
+}
+
+// Go runs the function in a goroutine, recovering and logging its panic with
+// the default logger like RecoverAndLog.
+func Go(fn func(), opts ...RecoverOption) {
+	std.Go(fn, opts...)
+}
+
+// Go runs the function in a goroutine, recovering and logging its panic with
+// the logger like RecoverAndLog.
+func (lg *Logger) Go(fn func(), opts ...RecoverOption) {
+	go func() {
+		defer lg.RecoverAndLog(opts...)
+		fn()
+	}()
+}
+
+// logPanic logs the recovered panic value, and panics again with Repanic.
+func (lg *Logger) logPanic(v any, opts []RecoverOption) {
+	c := recoverConfig{message: "panic recovered"}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	lg.Error(c.message).Panic(v).PrintAndWriteSafe()
+	if c.repanic {
+		lg.Flush()
+		panic(v)
+	}
+}
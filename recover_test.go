@@ -0,0 +1,49 @@
+package tolog
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverAndLog(t *testing.T) {
+	lg := NewLogger("TestRecoverAndLog")
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+
+	func() {
+		defer lg.RecoverAndLog()
+		panic("boom")
+	}()
+
+	lg.Go(func() {
+		panic(io.ErrUnexpectedEOF)
+	}, RecoverMessage("worker crashed"))
+	assert.Eventually(t, func() bool {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		return len(sink.entries) == 2
+	}, time.Second, time.Millisecond)
+
+	assert.PanicsWithValue(t, "again", func() {
+		defer lg.RecoverAndLog(Repanic())
+		panic("again")
+	})
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if assert.Len(t, sink.entries, 3) {
+		assert.Equal(t, "panic recovered", sink.entries[0].Message)
+		assert.Equal(t, StatusError, sink.entries[0].Level)
+		assert.Equal(t, "boom", sink.entries[0].Fields["panic"])
+		assert.Contains(t, sink.entries[0].Fields[StackField], "TestRecoverAndLog")
+		assert.Equal(t, "worker crashed", sink.entries[1].Message)
+		assert.Equal(t, "*errors.errorString", sink.entries[1].Fields["panic_type"])
+		assert.Equal(t, "again", sink.entries[2].Fields["panic"])
+	}
+}
@@ -0,0 +1,118 @@
+package tolog
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+)
+
+// ObjectRef is the compact identity of an object logged with Ref, in place of
+// the object itself.
+type ObjectRef struct {
+	Type string
+	ID   any     // the ID field or method of the object, nil if it has none
+	Addr uintptr // the address of pointers, maps and slices, 0 for values
+	Len  int     // the length of strings, slices, maps, arrays and channels, -1 otherwise
+	Size int     // the approximate size in bytes, shallow
+}
+
+// String formats the reference, e.g. *app.Order id=42 addr=0xc000010000 size=96.
+func (r ObjectRef) String() string {
+	s := r.Type
+	if r.ID != nil {
+		s += " id=" + fmt.Sprint(r.ID)
+	}
+	if r.Addr != 0 {
+		s += " addr=0x" + strconv.FormatUint(uint64(r.Addr), 16)
+	}
+	if r.Len >= 0 {
+		s += " len=" + strconv.Itoa(r.Len)
+	}
+	return s + " size=" + strconv.Itoa(r.Size)
+}
+
+var refDump int32
+
+// SetRefDump enables also dumping the full object logged with Ref on debug
+// entries, the most verbose level, as the ref_dump field. Disabled by default.
+func SetRefDump(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&refDump, v)
+}
+
+// RefOf returns the compact identity of the object.
+func RefOf(obj any) ObjectRef {
+	if obj == nil {
+		return ObjectRef{Type: "nil", Len: -1}
+	}
+	v := reflect.ValueOf(obj)
+	ref := ObjectRef{Type: v.Type().String(), Len: -1}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		ref.Addr = v.Pointer()
+	}
+	ref.ID = objectID(v)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ref
+		}
+		v = v.Elem()
+	}
+	ref.Size = int(v.Type().Size())
+	switch v.Kind() {
+	case reflect.String:
+		ref.Len = v.Len()
+		ref.Size += v.Len()
+	case reflect.Slice:
+		ref.Len = v.Len()
+		ref.Size += v.Len() * int(v.Type().Elem().Size())
+	case reflect.Map:
+		ref.Len = v.Len()
+		ref.Size += v.Len() * int(v.Type().Key().Size()+v.Type().Elem().Size())
+	case reflect.Array, reflect.Chan:
+		ref.Len = v.Len()
+	}
+	return ref
+}
+
+// objectID returns the result of the ID method, or the value of the ID field, of the object.
+func objectID(v reflect.Value) any {
+	if m := v.MethodByName("ID"); m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() == 1 {
+		if v.Kind() != reflect.Pointer || !v.IsNil() {
+			return m.Call(nil)[0].Interface()
+		}
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, name := range []string{"ID", "Id"} {
+		if f, ok := v.Type().FieldByName(name); ok && f.IsExported() {
+			return v.FieldByIndex(f.Index).Interface()
+		}
+	}
+	return nil
+}
+
+// Ref adds the compact identity of the object as the ref field instead of
+// serializing it, preventing accidental multi-megabyte entries. With SetRefDump
+// the full object is also written on debug entries.
+func (l *ToLog) Ref(obj any) *ToLog {
+	l = l.mutable()
+	fields := Fields{"ref": RefOf(obj)}
+	if atomic.LoadInt32(&refDump) == 1 && l.logType == StatusDebug {
+		fields["ref_dump"] = fmt.Sprintf("%+v", obj)
+	}
+	l.fields = l.fields.merge(fields)
+	CreateFullLog(l)
+	return l
+}
@@ -0,0 +1,50 @@
+package tolog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type refOrder struct {
+	ID    int
+	Items []string
+}
+
+type refUser struct{ name string }
+
+func (u *refUser) ID() string { return u.name }
+
+func TestRefOf(t *testing.T) {
+	order := &refOrder{ID: 42, Items: []string{"a", "b"}}
+	ref := RefOf(order)
+	assert.Equal(t, "*tolog.refOrder", ref.Type)
+	assert.Equal(t, 42, ref.ID)
+	assert.NotZero(t, ref.Addr)
+	assert.Equal(t, -1, ref.Len)
+
+	ref = RefOf(&refUser{name: "ann"})
+	assert.Equal(t, "ann", ref.ID)
+
+	ref = RefOf(make([]byte, 1000))
+	assert.Equal(t, 1000, ref.Len)
+	assert.Greater(t, ref.Size, 1000)
+	assert.Contains(t, ref.String(), "[]uint8 addr=0x")
+
+	assert.Equal(t, "nil size=0", RefOf(nil).String())
+}
+
+func TestRef(t *testing.T) {
+	defer SetRefDump(false)
+	order := &refOrder{ID: 7, Items: []string{"secret payload"}}
+
+	l := Info("order").Ref(order)
+	assert.Contains(t, l.FullLog, "id=7")
+	assert.NotContains(t, l.FullLog, "secret payload")
+
+	SetRefDump(true)
+	assert.NotContains(t, Info("order").Ref(order).FullLog, "secret payload")
+	if !DebugStripped {
+		assert.Contains(t, Debug("order").Ref(order).FullLog, "secret payload")
+	}
+}
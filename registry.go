@@ -0,0 +1,94 @@
+package tolog
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// The loggers of GetLogger by name.
+var (
+	registry   = map[string]*Logger{}
+	registryMu sync.Mutex
+)
+
+// The minimum levels of the named loggers set with SetLoggerLevel, replaced as a whole on change.
+var loggerLevels atomic.Value // map[string]LogStatus
+
+// GetLogger returns the logger of the name, created on first use as a child of
+// the default logger named name, like "payments/stripe", and the same logger
+// for every later call. Packages can keep one in a package variable without
+// plumbing it through, and configure it by name with SetLoggerLevel.
+func GetLogger(name string) *Logger {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	lg, ok := registry[name]
+	if !ok {
+		lg = std.Named(name)
+		registry[name] = lg
+	}
+	return lg
+}
+
+// SetLoggerLevel sets the minimum level of the entries of the loggers of the
+// name and of the names below it, separated by a slash or a dot, like
+// "payments" for "payments/stripe" and "payments.refunds", unless they have a
+// level of their own. It applies to the loggers of GetLogger and Named, in
+// addition to the console and file levels. Empty removes the level of the name.
+func SetLoggerLevel(name string, level LogStatus) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	old, _ := loggerLevels.Load().(map[string]LogStatus)
+	levels := make(map[string]LogStatus, len(old)+1)
+	for n, l := range old {
+		levels[n] = l
+	}
+	if level == "" {
+		delete(levels, name)
+	} else {
+		levels[name] = level
+	}
+	loggerLevels.Store(levels)
+}
+
+// setLoggerLevels sets the levels of the names, removing those empty and those
+// of the removed names, in a single update.
+func setLoggerLevels(set map[string]LogStatus, removed []string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	old, _ := loggerLevels.Load().(map[string]LogStatus)
+	levels := make(map[string]LogStatus, len(old)+len(set))
+	for n, l := range old {
+		levels[n] = l
+	}
+	for _, n := range removed {
+		delete(levels, n)
+	}
+	for n, l := range set {
+		if l == "" {
+			delete(levels, n)
+			continue
+		}
+		levels[n] = l
+	}
+	loggerLevels.Store(levels)
+}
+
+// loggerLevel returns the minimum level of the logger name, set on it or the
+// closest name above it, and empty if none is.
+func loggerLevel(name string) LogStatus {
+	levels, _ := loggerLevels.Load().(map[string]LogStatus)
+	if len(levels) == 0 || name == "" {
+		return ""
+	}
+	for {
+		if level, ok := levels[name]; ok {
+			return level
+		}
+		i := strings.LastIndexAny(name, "/.")
+		if i < 0 {
+			return ""
+		}
+		name = name[:i]
+	}
+}
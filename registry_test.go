@@ -0,0 +1,34 @@
+package tolog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLogger(t *testing.T) {
+	lg := GetLogger("TestGetLogger/stripe")
+	assert.Same(t, lg, GetLogger("TestGetLogger/stripe"))
+	assert.NotSame(t, lg, GetLogger("TestGetLogger/refunds"))
+	assert.Equal(t, "TestGetLogger/stripe", lg.Info("named").entry().Fields[LoggerField])
+
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+	SetLoggerLevel("TestGetLogger", StatusWarning)
+	defer SetLoggerLevel("TestGetLogger", "")
+	SetLoggerLevel("TestGetLogger/refunds", StatusInfo)
+	defer SetLoggerLevel("TestGetLogger/refunds", "")
+
+	lg.Info("below the inherited level").WriteSafe()
+	lg.Warning("at the inherited level").WriteSafe()
+	lg.Named("retry").Info("below the level of the parent name").WriteSafe()
+	GetLogger("TestGetLogger/refunds").Info("own level").WriteSafe()
+	GetLogger("TestGetLoggerOther").Info("other name").WriteSafe()
+
+	var msgs []string
+	for _, e := range sink.entries {
+		msgs = append(msgs, e.Message)
+	}
+	assert.Equal(t, []string{"at the inherited level", "own level", "other name"}, msgs)
+}
@@ -0,0 +1,132 @@
+package tolog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+)
+
+// Source produces entries for a Relay. Next returns io.EOF when it is exhausted.
+type Source interface {
+	Next(ctx context.Context) (*Entry, error)
+}
+
+// Relay reads entries from a source and forwards them to sinks, a tiny shipper
+// embedded in the same binary.
+type Relay struct {
+	source Source
+	sinks  []Sink
+}
+
+// NewRelay creates a relay forwarding the entries of the source to the sinks.
+func NewRelay(source Source, sinks ...Sink) *Relay {
+	return &Relay{source: source, sinks: sinks}
+}
+
+// Run forwards entries until the source is exhausted or the context is done.
+// Sink errors are printed and do not stop the relay. The sinks are not closed.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		e, err := r.source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, s := range r.sinks {
+			if err := s.WriteEntry(e); err != nil {
+				handleError(err)
+			}
+		}
+	}
+}
+
+// Subscription is a Sink queuing the written entries, and a Source for a Relay.
+type Subscription struct {
+	entries chan *Entry
+	done    chan struct{}
+}
+
+// Subscribe registers a subscription receiving every written entry with AddSink.
+// Entries are dropped when more than size are queued.
+func Subscribe(size int) *Subscription {
+	s := &Subscription{entries: make(chan *Entry, size), done: make(chan struct{})}
+	AddSink(s)
+	return s
+}
+
+// WriteEntry queues the entry, dropping it with ErrChannelFull if the queue is full.
+func (s *Subscription) WriteEntry(e *Entry) error {
+	select {
+	case <-s.done:
+		return ErrClosed
+	default:
+	}
+	select {
+	case s.entries <- e:
+		return nil
+	default:
+		diag("drop", "subscription full, entry dropped")
+		return ErrChannelFull
+	}
+}
+
+// Close unregisters the subscription, Next returns io.EOF once the queue is drained.
+func (s *Subscription) Close() error {
+	RemoveSink(s)
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	return nil
+}
+
+// Next returns the next queued entry.
+func (s *Subscription) Next(ctx context.Context) (*Entry, error) {
+	select {
+	case e := <-s.entries:
+		return e, nil
+	default:
+	}
+	select {
+	case e := <-s.entries:
+		return e, nil
+	case <-s.done:
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// LineSource is a Source parsing the lines of a reader with ParseLine.
+type LineSource struct {
+	scanner *bufio.Scanner
+}
+
+// NewLineSource creates a source reading log lines from r, e.g. an exported file.
+func NewLineSource(r io.Reader) *LineSource {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &LineSource{scanner: scanner}
+}
+
+// Next returns the entry of the next parsable line, skipping the others.
+func (s *LineSource) Next(ctx context.Context) (*Entry, error) {
+	for s.scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		e, err := ParseLine(s.scanner.Text())
+		if err != nil {
+			continue
+		}
+		return e, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
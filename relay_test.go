@@ -0,0 +1,43 @@
+package tolog
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelaySubscription(t *testing.T) {
+	logPrefix := "TestRelay"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
+	cleanLogFiles(t, logFilePath)
+	SetLogPrefix(logPrefix)
+	defer CloseLogFile()
+
+	sub := Subscribe(10)
+	Info("first").WriteSafe()
+	Error("second").WriteSafe()
+	require.NoError(t, sub.Close())
+	Info("after close").WriteSafe()
+
+	sink := &memorySink{}
+	require.NoError(t, NewRelay(sub, sink).Run(context.Background()))
+	if assert.Len(t, sink.entries, 2) {
+		assert.Equal(t, "first", sink.entries[0].Message)
+		assert.Equal(t, "second", sink.entries[1].Message)
+	}
+}
+
+func TestRelayLineSource(t *testing.T) {
+	input := Info("from file").FullLog + "\ngarbage\n" + `{"level":"error","msg":"json line"}` + "\n"
+
+	sink := &memorySink{}
+	require.NoError(t, NewRelay(NewLineSource(strings.NewReader(input)), sink).Run(context.Background()))
+	if assert.Len(t, sink.entries, 2) {
+		assert.Equal(t, "from file", sink.entries[0].Message)
+		assert.Equal(t, StatusError, sink.entries[1].Level)
+	}
+}
@@ -0,0 +1,155 @@
+package tolog
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RemoteQueueSize is the number of entries a RemoteSink queues while the
+// connection is down, further entries are dropped.
+var RemoteQueueSize = 10000
+
+// The delays between reconnection attempts of a RemoteSink, doubling up to the maximum.
+var (
+	remoteBackoffMin = 100 * time.Millisecond
+	remoteBackoffMax = 30 * time.Second
+)
+
+// The time a write to the collector may take before the connection is dropped.
+const remoteWriteTimeout = 10 * time.Second
+
+// RemoteSink streams entries encoded one per line to a remote collector like
+// Logstash, Vector or Fluent Bit over TCP or UDP. It reconnects with backoff and
+// queues the entries in memory while the connection is down.
+type RemoteSink struct {
+	network string
+	addr    string
+	format  LogFormat
+	ending  atomic.Value // string
+
+	entries chan *Entry
+	done    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	conn    net.Conn // used by the run goroutine only
+}
+
+// NewRemoteSink creates a sink streaming to the collector at addr over network,
+// "tcp" or "udp", in the format. It connects in the background. Add it with AddSink.
+func NewRemoteSink(network string, addr string, format LogFormat) *RemoteSink {
+	s := &RemoteSink{
+		network: network,
+		addr:    addr,
+		format:  format,
+		entries: make(chan *Entry, RemoteQueueSize),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s
+}
+
+// AddRemoteSink creates a RemoteSink and registers it with AddSink.
+func AddRemoteSink(network string, addr string, format LogFormat) *RemoteSink {
+	s := NewRemoteSink(network, addr, format)
+	AddSink(s)
+	return s
+}
+
+// WriteEntry queues the entry, dropping it with ErrChannelFull if the queue is full.
+func (s *RemoteSink) WriteEntry(e *Entry) error {
+	select {
+	case <-s.done:
+		return ErrClosed
+	default:
+	}
+	select {
+	case s.entries <- e:
+		return nil
+	default:
+		diag("drop", "remote sink %s queue full, entry dropped", s.addr)
+		return ErrChannelFull
+	}
+}
+
+// Close sends the queued entries if connected and closes the connection.
+func (s *RemoteSink) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+	return nil
+}
+
+func (s *RemoteSink) run() {
+	defer s.wg.Done()
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}()
+	for {
+		select {
+		case e := <-s.entries:
+			if !s.send(e) {
+				return
+			}
+		case <-s.done:
+			// send what is queued, without waiting for a lost connection
+			for {
+				select {
+				case e := <-s.entries:
+					if s.conn == nil || s.write(e) != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// send writes the entry, reconnecting with backoff until it is written. It
+// returns false if the sink was closed meanwhile.
+func (s *RemoteSink) send(e *Entry) bool {
+	backoff := remoteBackoffMin
+	for {
+		if s.conn == nil {
+			conn, err := net.DialTimeout(s.network, s.addr, remoteWriteTimeout)
+			if err == nil {
+				diag("sink", "connected to %s", s.addr)
+				s.conn = conn
+			} else {
+				diag("sink", "connecting to %s failed, retrying in %s: %v", s.addr, backoff, err)
+			}
+		}
+		if s.conn != nil {
+			err := s.write(e)
+			if err == nil {
+				return true
+			}
+			diag("sink", "writing to %s failed, reconnecting: %v", s.addr, err)
+			s.conn.Close()
+			s.conn = nil
+		}
+		select {
+		case <-time.After(backoff):
+		case <-s.done:
+			return false
+		}
+		if backoff *= 2; backoff > remoteBackoffMax {
+			backoff = remoteBackoffMax
+		}
+	}
+}
+
+func (s *RemoteSink) write(e *Entry) error {
+	ending, _ := s.ending.Load().(string)
+	line := withLineEnding(encodeLine(e.toLog(), s.format, false)+"\n", ending)
+	s.conn.SetWriteDeadline(time.Now().Add(remoteWriteTimeout))
+	_, err := s.conn.Write([]byte(line))
+	return err
+}
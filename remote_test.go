@@ -0,0 +1,45 @@
+package tolog
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	defer func(min time.Duration) { remoteBackoffMin = min }(remoteBackoffMin)
+	remoteBackoffMin = 10 * time.Millisecond
+
+	s := NewRemoteSink("tcp", addr, FormatJSON)
+	defer s.Close()
+	// queued while the collector is down
+	require.NoError(t, s.WriteEntry(&Entry{Time: time.Now(), Level: StatusWarning, Message: "queued"}))
+	time.Sleep(30 * time.Millisecond)
+
+	ln, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	defer ln.Close()
+	require.NoError(t, s.WriteEntry(&Entry{Time: time.Now(), Level: StatusInfo, Message: "live", Fields: Fields{"k": "v"}}))
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"msg":"queued"`)
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"fields":{"k":"v"}`)
+	assert.Contains(t, line, `"msg":"live"`)
+}
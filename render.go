@@ -0,0 +1,20 @@
+package tolog
+
+import "time"
+
+// String returns the rendered line of the entry without printing or writing
+// it, with colors when enabled, for embedding log styled lines in other UIs.
+func (l *ToLog) String() string {
+	if !l.frozen {
+		l.resolve()
+		CreateFullLog(l)
+	}
+	return consoleLine(l)
+}
+
+// Format returns the line an entry of the level, message and fields renders to
+// now, with colors when enabled, without printing or writing it.
+func Format(level LogStatus, msg string, fields Fields) string {
+	e := &Entry{Time: time.Now(), Level: level, Message: msg, Fields: Fields(nil).merge(fields).resolveValues()}
+	return consoleLine(e.toLog())
+}
@@ -0,0 +1,23 @@
+package tolog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	defer SetLogWithColor(LogWithColor)
+	SetLogWithColor(false)
+
+	l := Warning("disk almost full").WithField("free", "2GB")
+	assert.Contains(t, l.String(), "[warning]  disk almost full free=2GB")
+	assert.Equal(t, l.String(), fmt.Sprint(l))
+
+	line := Format(StatusError, "failed", Fields{"code": 3})
+	assert.Regexp(t, `^\[.+\] \[error\]  failed code=3$`, line)
+
+	SetLogWithColor(true)
+	assert.Contains(t, Format(StatusError, "failed", nil), colorErrorBg)
+}
@@ -0,0 +1,58 @@
+package tolog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Replay re-emits the entries of a log file, gzip compressed if it ends with
+// .gz, through the sinks with the original time between them divided by
+// speed, e.g. 10 to replay an hour in six minutes, to test alerting rules and
+// dashboards. A speed of zero or less replays them without waiting. The
+// entries are given the time they are replayed at, which keeps their gaps
+// scaled, so time windows see them as fresh. Sink errors are printed and do
+// not stop the replay, the sinks are not closed. It returns when the file is
+// replayed or the context is done.
+func Replay(ctx context.Context, path string, speed float64, sinks ...Sink) error {
+	r, err := readLogFile(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	source := NewLineSource(r)
+	var first, start time.Time
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+	for {
+		e, err := source.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first.IsZero() && !e.Time.IsZero() {
+			first, start = e.Time, time.Now()
+		}
+		if speed > 0 && !e.Time.IsZero() {
+			at := start.Add(time.Duration(float64(e.Time.Sub(first)) / speed))
+			if wait := time.Until(at); wait > 0 {
+				timer.Reset(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		e.Time = time.Now()
+		for _, s := range sinks {
+			if err := s.WriteEntry(e); err != nil {
+				handleError(err)
+			}
+		}
+	}
+}
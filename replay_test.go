@@ -0,0 +1,46 @@
+package tolog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log-2024-01-02.log")
+	lines := `{"time":"2024-01-02T03:00:00Z","level":"info","msg":"first"}
+{"time":"2024-01-02T03:00:01Z","level":"error","msg":"second"}
+garbage
+{"time":"2024-01-02T03:00:02Z","level":"info","msg":"third"}
+`
+	assert.NoError(t, os.WriteFile(path, []byte(lines), 0644))
+
+	sink := &memorySink{}
+	start := time.Now()
+	assert.NoError(t, Replay(context.Background(), path, 20, sink))
+	took := time.Since(start)
+	assert.GreaterOrEqual(t, took, 100*time.Millisecond)
+	if assert.Len(t, sink.entries, 3) {
+		assert.Equal(t, "second", sink.entries[1].Message)
+		assert.Equal(t, StatusError, sink.entries[1].Level)
+		assert.False(t, sink.entries[0].Time.Before(start))
+		gap := sink.entries[2].Time.Sub(sink.entries[0].Time)
+		assert.InDelta(t, float64(100*time.Millisecond), float64(gap), float64(50*time.Millisecond))
+	}
+
+	sink = &memorySink{}
+	assert.NoError(t, Replay(context.Background(), path, 0, sink))
+	assert.Len(t, sink.entries, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	sink = &memorySink{}
+	assert.ErrorIs(t, Replay(ctx, path, 1, sink), context.DeadlineExceeded)
+	assert.Len(t, sink.entries, 1)
+
+	assert.Error(t, Replay(context.Background(), filepath.Join(t.TempDir(), "missing.log"), 1))
+}
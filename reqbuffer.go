@@ -0,0 +1,89 @@
+package tolog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RequestBufferOptions configures when buffered debug entries of a request are emitted.
+type RequestBufferOptions struct {
+	// LatencyThreshold flushes the buffer when the request took longer, zero disables it.
+	LatencyThreshold time.Duration
+	// MaxEntries bounds the held entries, the oldest are dropped first. Default 1000.
+	MaxEntries int
+}
+
+type requestBufferKey struct{}
+
+// requestBuffer holds the debug entries of a single request.
+type requestBuffer struct {
+	mu      sync.Mutex
+	start   time.Time
+	opts    RequestBufferOptions
+	pending []func()
+	ended   bool
+}
+
+// WithRequestBuffer returns a context whose debug entries are held until EndRequest
+// decides whether to emit or discard them.
+func WithRequestBuffer(ctx context.Context, opts RequestBufferOptions) context.Context {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 1000
+	}
+	return context.WithValue(ctx, requestBufferKey{}, &requestBuffer{start: time.Now(), opts: opts})
+}
+
+// EndRequest ends the buffered request of the context. The held debug entries are
+// emitted if err is not nil or the request exceeded the latency threshold, otherwise
+// discarded. It reports whether the entries were emitted.
+func EndRequest(ctx context.Context, err error) bool {
+	rb := requestBufferFromContext(ctx)
+	if rb == nil {
+		return false
+	}
+	rb.mu.Lock()
+	pending := rb.pending
+	rb.pending = nil
+	rb.ended = true
+	rb.mu.Unlock()
+
+	threshold := rb.opts.LatencyThreshold
+	if err == nil && (threshold <= 0 || time.Since(rb.start) <= threshold) {
+		return false
+	}
+	for _, emit := range pending {
+		emit()
+	}
+	return true
+}
+
+func requestBufferFromContext(ctx context.Context) *requestBuffer {
+	if ctx == nil {
+		return nil
+	}
+	rb, _ := ctx.Value(requestBufferKey{}).(*requestBuffer)
+	return rb
+}
+
+// hold keeps a copy of a debug entry in its request buffer until the request
+// ends, reporting whether the entry was held instead of emitted.
+func (l *ToLog) hold(emit func(*ToLog)) bool {
+	rb := l.buffer
+	if rb == nil || l.logType != StatusDebug {
+		return false
+	}
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if rb.ended {
+		return false
+	}
+	l.buffer = nil
+	l.frozen = true
+	held := l.clone()
+	if len(rb.pending) >= rb.opts.MaxEntries {
+		rb.pending = rb.pending[1:]
+	}
+	rb.pending = append(rb.pending, func() { emit(held) })
+	return true
+}
@@ -0,0 +1,46 @@
+package tolog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBuffer(t *testing.T) {
+	if DebugStripped {
+		t.Skip("holds debug entries, stripped by tolog_nodebug")
+	}
+	logPrefix := "TestRequestBuffer"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
+	cleanLogFiles(t, logFilePath)
+	SetLogPrefix(logPrefix)
+
+	ok := WithRequestBuffer(context.Background(), RequestBufferOptions{})
+	DebugCtx(ok, "discarded debug detail").WriteSafe()
+	InfoCtx(ok, "always written info").WriteSafe()
+	assert.False(t, EndRequest(ok, nil))
+
+	failed := WithRequestBuffer(context.Background(), RequestBufferOptions{})
+	DebugCtx(failed, "kept debug detail").WriteSafe()
+	assert.True(t, EndRequest(failed, errors.New("boom")))
+
+	slow := WithRequestBuffer(context.Background(), RequestBufferOptions{LatencyThreshold: time.Nanosecond})
+	DebugCtx(slow, "slow debug detail").WriteSafe()
+	time.Sleep(time.Millisecond)
+	assert.True(t, EndRequest(slow, nil))
+
+	CloseLogFile()
+
+	content, err := os.ReadFile(logFilePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "discarded debug detail")
+	assert.Contains(t, string(content), "always written info")
+	assert.Contains(t, string(content), "kept debug detail")
+	assert.Contains(t, string(content), "slow debug detail")
+}
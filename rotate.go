@@ -0,0 +1,241 @@
+package tolog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// The size a log file may grow to before it is rotated, default 0 is unlimited.
+var maxFileSize int64
+
+// The number of lines a log file may hold before it is rotated, default 0 is unlimited.
+var maxFileLines int64
+
+// The number of old log files kept per prefix, default 0 keeps all.
+var maxBackups int64
+
+// The number of days old log files are kept, default 0 keeps them forever.
+var maxAge int64
+
+// SetMaxFileSize sets the size in bytes after which the log file is renamed to a
+// timestamped backup and a new file is started. Zero disables size rotation.
+func SetMaxFileSize(bytes int64) {
+	atomic.StoreInt64(&maxFileSize, bytes)
+}
+
+// SetMaxFileLines sets the number of lines after which the log file is renamed
+// to a timestamped backup and a new file is started, e.g. for jobs splitting or
+// importing files by line count. Lines of entries and of WriteRaw are counted,
+// not the file markers. Zero disables line rotation.
+func SetMaxFileLines(lines int64) {
+	atomic.StoreInt64(&maxFileLines, lines)
+}
+
+// SetMaxBackups sets how many old log files of a prefix, rotated by size or by
+// date, are kept. The oldest are deleted first. Zero keeps all.
+func SetMaxBackups(n int) {
+	atomic.StoreInt64(&maxBackups, int64(n))
+}
+
+// SetMaxAge sets after how many days old log files of a prefix are deleted. Zero keeps them.
+func SetMaxAge(days int) {
+	atomic.StoreInt64(&maxAge, int64(days))
+}
+
+// backupPath returns the name a log file is renamed to when rotated by size.
+func backupPath(path string, t time.Time) string {
+	return strings.TrimSuffix(path, ".log") + "-" + t.Format("150405.000000") + ".log"
+}
+
+// rotateIfFull renames the log file to a backup when writing n more bytes would
+// exceed the maximum size, fileMu must be held.
+func (w *fileWriter) rotateIfFull(n int) {
+	max := atomic.LoadInt64(&maxFileSize)
+	if max <= 0 || w.size == 0 || w.size+int64(n) <= max {
+		return
+	}
+	w.rotate(fmt.Sprintf("reached %d bytes", max))
+}
+
+// writeLines writes the data to the file, rotating it whenever it holds the
+// maximum number of lines, fileMu must be held.
+func (w *fileWriter) writeLines(data []byte, max int64) (int, error) {
+	written := 0
+	for len(data) > 0 {
+		if w.lineCount >= max {
+			w.rotate(fmt.Sprintf("reached %d lines", max))
+		}
+		cut := len(data)
+		room := max - w.lineCount
+		for i := 0; i < len(data); i++ {
+			if data[i] == '\n' {
+				if room--; room == 0 {
+					cut = i + 1
+					break
+				}
+			}
+		}
+		w.rotateIfFull(cut)
+		n, err := w.writeFile(data[:cut])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		data = data[cut:]
+	}
+	return written, nil
+}
+
+// rotate renames the log file to a timestamped backup and starts a new one,
+// fileMu must be held. The footer is written to the backup once the new file
+// is open, a failed rotation continues in the file without it.
+func (w *fileWriter) rotate(reason string) {
+	if w.file == nil {
+		return // reopened by the write
+	}
+	path := w.file.Name()
+	backup := backupPath(path, time.Now().In(globalTimeZone()))
+	w.file.Close()
+	fsys := logFS()
+	if err := fsys.Rename(path, backup); err != nil {
+		w.keepFile(path, "renaming", err)
+		return
+	}
+	file, err := fsys.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		// move the backup back and continue in it rather than in a closed file
+		fsys.Rename(backup, path)
+		w.keepFile(path, "opening the new", err)
+		return
+	}
+	if old, err := fsys.OpenFile(backup, os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+		w.file = old
+		w.writeMarker(&fileFooter, backup, path)
+		old.Close()
+	}
+	w.file = file
+	w.size = 0
+	w.lineCount = 0
+	w.writeMarker(&fileHeader, w.file.Name(), "")
+	diag("rotate", "%s %s, moved to %s", path, reason, backup)
+	atomic.AddUint64(&rotationCount, 1)
+	compressLater(backup)
+	w.removeOldFiles()
+}
+
+// keepFile reopens the log file a failed rotation left in place to continue
+// in it. If that fails too no file is left open, the next write opens one,
+// fileMu must be held.
+func (w *fileWriter) keepFile(path string, step string, err error) {
+	diag("rotate", "%s %s failed, continuing in it: %v", step, path, err)
+	handleError(fmt.Errorf("%w: %v", ErrRotateFailed, err))
+	file, err := logFS().OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		diag("rotate", "reopening %s failed: %v", path, err)
+		w.file = nil
+		return
+	}
+	w.file = file
+	if info, _ := file.Stat(); info != nil {
+		w.size = info.Size()
+	}
+}
+
+// reopenFile opens the log file of the day when a failed rotation left none
+// open, fileMu must be held.
+func (w *fileWriter) reopenFile() error {
+	day := currentDay()
+	file, err := openLogFile(w.filePath(day))
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.date = day
+	w.size = 0
+	w.lineCount = 0
+	if info, err := file.Stat(); err == nil {
+		w.size = info.Size()
+		w.lineCount = countLines(file)
+	}
+	if w.size == 0 {
+		w.writeMarker(&fileHeader, w.file.Name(), "")
+	}
+	diag("open", "reopened %s", file.Name())
+	return nil
+}
+
+// countLines returns the number of lines of the file, when rotating by lines.
+func countLines(file File) int64 {
+	if atomic.LoadInt64(&maxFileLines) <= 0 {
+		return 0
+	}
+	f, err := openRead(logFS(), file.Name())
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	var lines int64
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.Read(buf)
+		lines += int64(bytes.Count(buf[:n], []byte{'\n'}))
+		if err != nil {
+			return lines
+		}
+	}
+}
+
+// removeOldFiles deletes the old log files of the prefix beyond the maximum
+// number of backups or older than the maximum age, fileMu must be held.
+func (w *fileWriter) removeOldFiles() {
+	backups, age := int(atomic.LoadInt64(&maxBackups)), int(atomic.LoadInt64(&maxAge))
+	if backups <= 0 && age <= 0 {
+		return
+	}
+	files := w.oldFiles()
+	cutoff := time.Now().AddDate(0, 0, -age)
+	for i, f := range files {
+		if (backups > 0 && i >= backups) || (age > 0 && f.modTime.Before(cutoff)) {
+			if err := logFS().Remove(f.path); err != nil {
+				diag("rotate", "removing %s failed: %v", f.path, err)
+				continue
+			}
+			diag("rotate", "removed old log file %s", f.path)
+		}
+	}
+}
+
+type oldFile struct {
+	path    string
+	modTime time.Time
+}
+
+// oldFiles returns the log files of the prefix except the active one, newest first.
+func (w *fileWriter) oldFiles() []oldFile {
+	fsys := logFS()
+	base := filepath.Base(logFilePath(w.prefix, ""))
+	pattern := globEscape(strings.TrimSuffix(base, ".log")) + "*"
+	dir := filepath.Dir(w.file.Name())
+	entries, _ := fsys.ReadDir(dir)
+	active := filepath.Clean(w.file.Name())
+	var files []oldFile
+	for _, e := range entries {
+		m := filepath.Join(dir, e.Name())
+		if ok, _ := filepath.Match(pattern, e.Name()); !ok || e.IsDir() || filepath.Clean(m) == active {
+			continue
+		}
+		info, err := fsys.Stat(m)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, oldFile{path: m, modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	return files
+}
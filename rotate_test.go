@@ -0,0 +1,179 @@
+package tolog
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeRotation(t *testing.T) {
+	lg := NewLogger("TestSizeRotation")
+	defer lg.Close()
+	path := lg.FilePath()
+	old, _ := filepath.Glob(strings.TrimSuffix(path, ".log") + "*")
+	for _, f := range old {
+		os.Remove(f)
+	}
+
+	SetMaxFileSize(100)
+	SetMaxBackups(2)
+	defer SetMaxFileSize(0)
+	defer SetMaxBackups(0)
+
+	line := strings.Repeat("x", 59)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, lg.WriteRaw([]byte(line)))
+		lg.CloseFile()
+		time.Sleep(time.Millisecond)
+	}
+
+	files, _ := filepath.Glob(strings.TrimSuffix(path, ".log") + "*")
+	assert.Len(t, files, 3)
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		require.NoError(t, err)
+		assert.Equal(t, line+"\n", string(content))
+	}
+}
+
+// failOpenFS fails as many next openings for write as it is armed with.
+type failOpenFS struct {
+	OSFS
+	armed *int32
+}
+
+func (f failOpenFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	for n := atomic.LoadInt32(f.armed); flag&os.O_CREATE != 0 && n > 0; n = atomic.LoadInt32(f.armed) {
+		if atomic.CompareAndSwapInt32(f.armed, n, n-1) {
+			return nil, errors.New("too many open files")
+		}
+	}
+	return f.OSFS.OpenFile(name, flag, perm)
+}
+
+func TestRotateOpenFailure(t *testing.T) {
+	var armed int32
+	SetFS(failOpenFS{armed: &armed})
+	defer SetFS(nil)
+	var errs []error
+	SetErrorHandler(func(err error) { errs = append(errs, err) })
+	defer SetErrorHandler(nil)
+	lg := NewLogger("TestRotateOpenFailure")
+	defer lg.Close()
+	path := lg.FilePath()
+	old, _ := filepath.Glob(strings.TrimSuffix(path, ".log") + "*")
+	for _, f := range old {
+		os.Remove(f)
+	}
+
+	SetMaxFileSize(100)
+	defer SetMaxFileSize(0)
+	require.NoError(t, SetFileFooter("# end of {{.File}}"))
+	defer SetFileFooter("")
+	line := strings.Repeat("x", 59)
+	require.NoError(t, lg.WriteRaw([]byte(line)))
+	require.NoError(t, lg.Flush())
+	atomic.StoreInt32(&armed, 1)
+	require.NoError(t, lg.WriteRaw([]byte(line)))
+	require.NoError(t, lg.Flush())
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrRotateFailed)
+	require.NoError(t, lg.WriteRaw([]byte(line)))
+	lg.CloseFile()
+
+	// the write after the failure went on in the original file, rotated again by
+	// the third, the footer written only then
+	files, _ := filepath.Glob(strings.TrimSuffix(path, ".log") + "*")
+	require.Len(t, files, 2)
+	var backup string
+	for _, f := range files {
+		if f != path {
+			backup = f
+		}
+	}
+	content, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, line+"\n"+line+"\n# end of "+backup+"\n", string(content))
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, line+"\n", string(content))
+}
+
+func TestRotateReopenFailure(t *testing.T) {
+	var armed int32
+	SetFS(failOpenFS{armed: &armed})
+	defer SetFS(nil)
+	var errs []error
+	SetErrorHandler(func(err error) { errs = append(errs, err) })
+	defer SetErrorHandler(nil)
+	lg := NewLogger("TestRotateReopenFailure")
+	defer lg.Close()
+	path := lg.FilePath()
+	old, _ := filepath.Glob(strings.TrimSuffix(path, ".log") + "*")
+	for _, f := range old {
+		os.Remove(f)
+	}
+
+	SetMaxFileSize(100)
+	defer SetMaxFileSize(0)
+	line := strings.Repeat("x", 59)
+	require.NoError(t, lg.WriteRaw([]byte(line)))
+	require.NoError(t, lg.Flush())
+	// the new file and the reopening of the old one fail, then the write
+	atomic.StoreInt32(&armed, 3)
+	require.NoError(t, lg.WriteRaw([]byte(line)))
+	assert.Error(t, lg.Flush())
+	require.NotEmpty(t, errs)
+	assert.ErrorIs(t, errs[0], ErrRotateFailed)
+
+	// the next flush opens the file again and writes the line left over
+	require.NoError(t, lg.Flush())
+	lg.CloseFile()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, line+"\n"+line+"\n", string(content))
+}
+
+func TestBackupPath(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 15, 0, 123456000, time.UTC)
+	assert.Equal(t, "./logs/app-log-2024-05-01-101500.123456.log", backupPath("./logs/app-log-2024-05-01.log", at))
+}
+
+func TestLineRotation(t *testing.T) {
+	lg := NewLogger("TestLineRotation")
+	defer lg.Close()
+	path := lg.FilePath()
+	old, _ := filepath.Glob(strings.TrimSuffix(path, ".log") + "*")
+	for _, f := range old {
+		os.Remove(f)
+	}
+
+	SetMaxFileLines(3)
+	defer SetMaxFileLines(0)
+
+	require.NoError(t, lg.WriteRaw([]byte("1\n2")))
+	require.NoError(t, lg.CloseFile())
+	// the reopened file already holds two lines
+	require.NoError(t, lg.WriteRaw([]byte("3\n4\n5\n6\n7")))
+	require.NoError(t, lg.CloseFile())
+
+	files, _ := filepath.Glob(strings.TrimSuffix(path, ".log") + "*")
+	require.Len(t, files, 3)
+	var contents []string
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		require.NoError(t, err)
+		contents = append(contents, string(content))
+		os.Remove(f)
+	}
+	// the backups sort by time before the current file
+	assert.Equal(t, []string{"1\n2\n3\n", "4\n5\n6\n", "7\n"}, contents)
+}
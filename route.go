@@ -0,0 +1,76 @@
+package tolog
+
+// levelRoute writes the entries of a level and above to a log file of their own.
+type levelRoute struct {
+	level  LogStatus
+	writer *fileWriter
+}
+
+// RouteLevelToFile also writes the entries of the level and above of the
+// default logger to a log file of the name, see Logger.RouteLevelToFile.
+func RouteLevelToFile(level LogStatus, name string) {
+	std.RouteLevelToFile(level, name)
+}
+
+// RouteLevelToFile also writes the entries of the level and above to the log
+// file of the name, app-errors-log-2024-05-01.log for the prefix app and the
+// name errors, e.g. to tail the errors only. They are still written to the
+// main log file. Routing a level again replaces its route, an empty name
+// removes it. The name is taken with the prefix of the logger at the time.
+func (lg *Logger) RouteLevelToFile(level LogStatus, name string) {
+	lg = lg.root()
+	lg.mu.Lock()
+	var released *fileWriter
+	routes := make([]levelRoute, 0, len(lg.routes)+1)
+	for _, r := range lg.routes {
+		if r.level == level {
+			released = r.writer
+			continue
+		}
+		routes = append(routes, r)
+	}
+	if name != "" {
+		prefix := name
+		if lg.prefix != "" {
+			prefix = lg.prefix + "-" + name
+		}
+		routes = append(routes, levelRoute{level: level, writer: acquireWriter(prefix)})
+	}
+	lg.routes = routes
+	lg.mu.Unlock()
+	if released != nil {
+		if err := released.release(); err != nil {
+			handleError(err)
+		}
+	}
+}
+
+// routeWriters returns the writers of the routes taking entries of the level.
+func (lg *Logger) routeWriters(level LogStatus) []*fileWriter {
+	lg = lg.root()
+	lg.mu.RLock()
+	routes := lg.routes
+	lg.mu.RUnlock()
+	var ws []*fileWriter
+	for _, r := range routes {
+		if levelEnabled(level, r.level) {
+			ws = append(ws, r.writer)
+		}
+	}
+	return ws
+}
+
+// releaseRoutes releases the writers of the routes when the logger is closed.
+func (lg *Logger) releaseRoutes() error {
+	lg.mu.Lock()
+	routes := lg.routes
+	lg.routes = nil
+	lg.mu.Unlock()
+	var err error
+	for _, r := range routes {
+		if e := r.writer.release(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
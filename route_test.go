@@ -0,0 +1,44 @@
+package tolog
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteLevelToFile(t *testing.T) {
+	lg := NewLogger("TestRoute")
+	mainPath := lg.FilePath()
+	defer os.Remove(mainPath)
+	errorsPath := logFilePath("TestRoute-errors", currentDay())
+	warningsPath := logFilePath("TestRoute-warnings", currentDay())
+	defer os.Remove(errorsPath)
+	defer os.Remove(warningsPath)
+
+	lg.RouteLevelToFile(StatusError, "errors")
+	lg.RouteLevelToFile(StatusWarning, "warnings")
+	lg.Info("started").WriteSafe()
+	lg.Warning("slow").WriteSafe()
+	lg.Error("failed").WriteSafe()
+	lg.RouteLevelToFile(StatusWarning, "")
+	lg.Warning("slow again").WriteSafe()
+	require.NoError(t, lg.Close())
+
+	main, err := os.ReadFile(mainPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(main), "started")
+	assert.Contains(t, string(main), "slow again")
+	assert.Contains(t, string(main), "failed")
+	errs, err := os.ReadFile(errorsPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(errs), "started")
+	assert.NotContains(t, string(errs), "slow")
+	assert.Contains(t, string(errs), "failed")
+	warnings, err := os.ReadFile(warningsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(warnings), "slow")
+	assert.Contains(t, string(warnings), "failed")
+	assert.NotContains(t, string(warnings), "slow again")
+}
@@ -0,0 +1,40 @@
+package tolog
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync/atomic"
+)
+
+// runID is the ID of the run of the process, empty until SetRunID.
+var runID atomic.Value
+
+// SetRunID sets the ID of the run, added to the names of the log files and as
+// the run_id field of every entry, so overlapping runs of the same job never
+// write to the same file. An empty id generates one with the IDGenerator, a
+// random UUID by default. It should be set before the first entry is written.
+func SetRunID(id string) string {
+	if id == "" {
+		id = newID(newUUID)
+	}
+	runID.Store(id)
+	AddGlobalField("run_id", id)
+	return id
+}
+
+// RunID returns the ID set by SetRunID, empty if none.
+func RunID() string {
+	id, _ := runID.Load().(string)
+	return id
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		handleError(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
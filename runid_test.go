@@ -0,0 +1,40 @@
+package tolog
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunID(t *testing.T) {
+	lg := NewLogger("TestRunID")
+	defer lg.Close()
+	daily := lg.FilePath()
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+	defer func() {
+		runID.Store("")
+		SetGlobalFields(nil)
+	}()
+
+	id := SetRunID("")
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+	assert.NotEqual(t, id, newUUID())
+	assert.Equal(t, "nightly-42", SetRunID("nightly-42"))
+	assert.Equal(t, "nightly-42", RunID())
+
+	path := lg.FilePath()
+	defer os.Remove(path)
+	assert.Equal(t, strings.TrimSuffix(daily, ".log")+"-nightly-42.log", path)
+	lg.Info("started").Write()
+	require.NoError(t, lg.CloseFile())
+	assert.Contains(t, out.String(), "run_id=nightly-42")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "started")
+}
@@ -0,0 +1,172 @@
+package tolog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SampleKey selects which entries the sampler considers repetitions of each other.
+type SampleKey int
+
+const (
+	// SampleByMessage samples entries with the same level and message, the default.
+	SampleByMessage SampleKey = iota
+	// SampleByLevel samples all entries of a level together.
+	SampleByLevel
+)
+
+// sampler logs the first entries of a key in a window and then one out of every
+// thereafter, writing a summary of the suppressed ones when the window ends.
+type sampler struct {
+	mu         sync.Mutex
+	initial    int
+	thereafter int
+	per        time.Duration
+	key        SampleKey
+	counts     map[sampleKey]*sampleCount
+	swept      time.Time
+}
+
+type sampleKey struct {
+	logger  *Logger
+	level   LogStatus
+	message string
+}
+
+type sampleCount struct {
+	start      time.Time
+	n          int
+	suppressed int
+	emit       func(*ToLog)
+	timer      *time.Timer
+}
+
+var sampling = &sampler{counts: map[sampleKey]*sampleCount{}}
+
+// SetSampling limits repetitive entries: within every period per, the first
+// initial entries with the same level and message are logged, then one out of
+// every thereafter, zero suppressing all of them. A "suppressed N duplicates"
+// entry reports the others at the end of the period. A period of zero turns
+// sampling off, the default.
+func SetSampling(initial, thereafter int, per time.Duration) {
+	sampling.mu.Lock()
+	sampling.initial = initial
+	sampling.thereafter = thereafter
+	sampling.per = per
+	summaries := sampling.flushAll()
+	sampling.mu.Unlock()
+	writeSummaries(summaries)
+}
+
+// SetSamplingKey sets which entries the sampler counts together.
+func SetSamplingKey(key SampleKey) {
+	sampling.mu.Lock()
+	sampling.key = key
+	summaries := sampling.flushAll()
+	sampling.mu.Unlock()
+	writeSummaries(summaries)
+}
+
+// sample reports whether the entry is suppressed by the sampler, emit writes
+// the summary like the entry would have been written.
+func (l *ToLog) sample(emit func(*ToLog)) bool {
+	if l.summary {
+		return false
+	}
+	s := sampling
+	s.mu.Lock()
+	if s.per <= 0 {
+		s.mu.Unlock()
+		return false
+	}
+	now := time.Now()
+	s.sweep(now)
+	k := sampleKey{logger: l.log(), level: l.logType}
+	if s.key == SampleByMessage {
+		k.message = l.logContext
+	}
+	var summary func()
+	c, ok := s.counts[k]
+	if ok && now.Sub(c.start) >= s.per {
+		summary = s.flush(k, c)
+		ok = false
+	}
+	if !ok {
+		c = &sampleCount{start: now}
+		s.counts[k] = c
+	}
+	c.n++
+	suppressed := c.n > s.initial && (s.thereafter <= 0 || (c.n-s.initial)%s.thereafter != 0)
+	if suppressed {
+		c.suppressed++
+		c.emit = emit
+		if c.timer == nil {
+			c.timer = time.AfterFunc(c.start.Add(s.per).Sub(now), func() {
+				s.mu.Lock()
+				var summary func()
+				if s.counts[k] == c {
+					summary = s.flush(k, c)
+				}
+				s.mu.Unlock()
+				writeSummaries([]func(){summary})
+			})
+		}
+	}
+	s.mu.Unlock()
+	writeSummaries([]func(){summary})
+	return suppressed
+}
+
+// flush forgets the key, returning the function writing the summary of its
+// suppressed entries, nil if none were, s.mu must be held.
+func (s *sampler) flush(k sampleKey, c *sampleCount) func() {
+	delete(s.counts, k)
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	if c.suppressed == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("suppressed %d duplicates", c.suppressed)
+	if k.message != "" {
+		msg += fmt.Sprintf(" of %q", k.message)
+	}
+	summary := k.logger.Log(WithType(k.level), WithContext(msg), WithFields(Fields{"suppressed": c.suppressed}))
+	summary.summary = true
+	emit := c.emit
+	return func() { emit(summary) }
+}
+
+// flushAll forgets all keys, returning the functions writing their summaries,
+// s.mu must be held.
+func (s *sampler) flushAll() []func() {
+	var summaries []func()
+	for k, c := range s.counts {
+		summaries = append(summaries, s.flush(k, c))
+	}
+	return summaries
+}
+
+// writeSummaries writes the summaries returned by flush, s.mu must not be held.
+func writeSummaries(summaries []func()) {
+	for _, write := range summaries {
+		if write != nil {
+			write()
+		}
+	}
+}
+
+// sweep forgets the keys whose period ended without suppressing anything, at
+// most once per period, s.mu must be held.
+func (s *sampler) sweep(now time.Time) {
+	if now.Sub(s.swept) < s.per {
+		return
+	}
+	s.swept = now
+	for k, c := range s.counts {
+		if c.suppressed == 0 && now.Sub(c.start) >= s.per {
+			delete(s.counts, k)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package tolog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampling(t *testing.T) {
+	lg := NewLogger("TestSampling")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	SetSampling(2, 3, time.Hour)
+	defer SetSampling(0, 0, 0)
+	for i := 0; i < 10; i++ {
+		lg.Warning("disk almost full").PrintLog()
+	}
+	lg.Info("other").PrintLog()
+	// entries 1, 2, 5 and 8 are logged
+	assert.Equal(t, 4, strings.Count(out.String(), "disk almost full"))
+	assert.Contains(t, out.String(), "other")
+
+	SetSampling(0, 0, 0)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	last := lines[len(lines)-1]
+	assert.Contains(t, last, `suppressed 6 duplicates of "disk almost full"`)
+	assert.Contains(t, last, "suppressed=6")
+	assert.Contains(t, last, " [warning] ")
+}
+
+func TestSamplingPeriod(t *testing.T) {
+	lg := NewLogger("TestSamplingPeriod")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var mu sync.Mutex
+	var out bytes.Buffer
+	lg.AddOutput(writerFunc(func(p []byte) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return out.Write(p)
+	}))
+	output := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return out.String()
+	}
+
+	SetSampling(1, 0, 20*time.Millisecond)
+	defer SetSampling(0, 0, 0)
+	SetSamplingKey(SampleByLevel)
+	defer SetSamplingKey(SampleByMessage)
+	lg.Info("a").PrintLog()
+	lg.Info("b").PrintLog()
+	lg.Info("c").PrintLog()
+	assert.Eventually(t, func() bool {
+		return strings.Contains(output(), "suppressed 2 duplicates")
+	}, time.Second, 5*time.Millisecond)
+	lg.Info("d").PrintLog()
+	assert.Contains(t, output(), "d\n")
+	assert.NotContains(t, output(), " b")
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
@@ -0,0 +1,135 @@
+package tolog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FieldType is the expected type of a field value in a Schema.
+type FieldType string
+
+// Field types which can be declared in a Schema.
+const (
+	FieldAny    FieldType = "any"
+	FieldString FieldType = "string"
+	FieldInt    FieldType = "int"
+	FieldFloat  FieldType = "float"
+	FieldBool   FieldType = "bool"
+)
+
+// SchemaMode decides what happens to entries violating the schema.
+type SchemaMode int
+
+const (
+	SchemaOff    SchemaMode = iota // no validation, default
+	SchemaFlag                     // violating entries get a schema_violation field
+	SchemaStrict                   // violating entries are rejected
+)
+
+// SchemaViolationField is the field added to flagged entries.
+const SchemaViolationField = "schema_violation"
+
+// Schema declares the required and allowed fields of log entries.
+type Schema struct {
+	// Required lists the field names every entry must carry.
+	Required []string
+	// Allowed maps the allowed field names to their type, nil allows any field.
+	Allowed map[string]FieldType
+}
+
+// SchemaError lists the violations of an entry.
+type SchemaError struct {
+	Violations []string
+}
+
+func (e *SchemaError) Error() string {
+	return "schema violation: " + strings.Join(e.Violations, ", ")
+}
+
+var schema *Schema
+var schemaMode = SchemaOff
+var schemaMu sync.RWMutex
+
+// SetSchema sets the schema entries are validated against and the validation mode.
+func SetSchema(s *Schema, mode SchemaMode) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	schema = s
+	schemaMode = mode
+}
+
+// Validate checks the fields against the schema, returning a *SchemaError on violations.
+func (s *Schema) Validate(fields Fields) error {
+	var violations []string
+	for _, name := range s.Required {
+		if _, ok := fields[name]; !ok {
+			violations = append(violations, "missing "+name)
+		}
+	}
+	if s.Allowed != nil {
+		for _, name := range fields.sortedKeys() {
+			if name == SchemaViolationField {
+				continue
+			}
+			typ, ok := s.Allowed[name]
+			if !ok {
+				violations = append(violations, "unknown "+name)
+				continue
+			}
+			if !typ.matches(fields[name]) {
+				violations = append(violations, fmt.Sprintf("%s is not %s", name, typ))
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	sort.Strings(violations)
+	return &SchemaError{Violations: violations}
+}
+
+// matches reports whether the value is of the field type.
+func (t FieldType) matches(v any) bool {
+	if t == FieldAny || t == "" {
+		return true
+	}
+	if v == nil {
+		return false
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String:
+		return t == FieldString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return t == FieldInt
+	case reflect.Float32, reflect.Float64:
+		return t == FieldFloat
+	case reflect.Bool:
+		return t == FieldBool
+	}
+	return false
+}
+
+// checkSchema validates the entry against the schema, flagging it or
+// reporting whether it is rejected depending on the schema mode.
+func (l *ToLog) checkSchema() bool {
+	schemaMu.RLock()
+	s, mode := schema, schemaMode
+	schemaMu.RUnlock()
+	if s == nil || mode == SchemaOff {
+		return true
+	}
+	err := s.Validate(l.fields)
+	if err == nil {
+		return true
+	}
+	if mode == SchemaStrict {
+		handleError(err)
+		return false
+	}
+	l.fields = l.fields.merge(Fields{SchemaViolationField: strings.Join(err.(*SchemaError).Violations, ", ")})
+	return true
+}
@@ -0,0 +1,42 @@
+package tolog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldsKey struct{}
+
+func TestSchemaValidate(t *testing.T) {
+	s := &Schema{
+		Required: []string{"request_id"},
+		Allowed:  map[string]FieldType{"request_id": FieldString, "latency_ms": FieldInt},
+	}
+	assert.NoError(t, s.Validate(Fields{"request_id": "abc", "latency_ms": 12}))
+
+	err := s.Validate(Fields{"latency_ms": "12", "userId": 1})
+	assert.Equal(t, &SchemaError{Violations: []string{"latency_ms is not int", "missing request_id", "unknown userId"}}, err)
+}
+
+func TestSchemaModes(t *testing.T) {
+	RegisterContextExtractor(func(ctx context.Context) Fields {
+		fields, _ := ctx.Value(fieldsKey{}).(Fields)
+		return fields
+	})
+	defer ResetContextExtractors()
+	defer SetSchema(nil, SchemaOff)
+
+	ctx := context.WithValue(context.Background(), fieldsKey{}, Fields{"user": 1})
+	s := &Schema{Required: []string{"request_id"}}
+
+	SetSchema(s, SchemaFlag)
+	l := InfoCtx(ctx, "flagged")
+	assert.False(t, l.intercept((*ToLog).WriteSafe))
+	assert.Equal(t, "missing request_id", l.fields[SchemaViolationField])
+
+	SetSchema(s, SchemaStrict)
+	l = InfoCtx(ctx, "rejected")
+	assert.True(t, l.intercept((*ToLog).WriteSafe))
+}
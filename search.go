@@ -0,0 +1,114 @@
+package tolog
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// SearchLine is a line of a log file read by SearchWithContext.
+type SearchLine struct {
+	Number int // from 1
+	Text   string
+	// Entry is the line parsed, nil for the lines which are no entries, like
+	// file markers and stack traces, see ParseLine.
+	Entry *Entry
+}
+
+// SearchMatch is a line matching the pattern of SearchWithContext with the
+// lines around it.
+type SearchMatch struct {
+	Line   SearchLine
+	Before []SearchLine
+	After  []SearchLine
+}
+
+// SearchWithContext returns the lines of the log file, gzip compressed if it
+// ends with .gz, matching the regular expression, each with up to before lines
+// before it and after lines after it, like grep -B and -A. The lines around
+// close matches are in the context of each, matching lines included.
+func SearchWithContext(path, pattern string, before, after int) ([]SearchMatch, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	r, err := readLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var matches []SearchMatch
+	err = SearchReader(r, re, before, after, func(m SearchMatch) error {
+		matches = append(matches, m)
+		return nil
+	})
+	return matches, err
+}
+
+// SearchReader streams the matches of the lines of r like SearchWithContext to
+// fn as soon as their after lines are read, holding only the lines of the
+// context in memory. It stops at the first error of fn, returning it.
+func SearchReader(r io.Reader, re *regexp.Regexp, before, after int, fn func(m SearchMatch) error) error {
+	br := bufio.NewReader(r)
+	var recent []SearchLine
+	var waiting []SearchMatch // matches still reading their after lines
+	number := 0
+	for {
+		text, err := br.ReadString('\n')
+		if text == "" && err == io.EOF {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		number++
+		line := searchLine(number, text)
+		done := 0
+		for i := range waiting {
+			waiting[i].After = append(waiting[i].After, line)
+			if len(waiting[i].After) == after {
+				if err := fn(waiting[i]); err != nil {
+					return err
+				}
+				done++
+			}
+		}
+		waiting = waiting[done:]
+		if re.MatchString(line.Text) {
+			m := SearchMatch{Line: line, Before: append([]SearchLine(nil), recent...)}
+			if after <= 0 {
+				if err := fn(m); err != nil {
+					return err
+				}
+			} else {
+				waiting = append(waiting, m)
+			}
+		}
+		if before > 0 {
+			if len(recent) == before {
+				recent = recent[1:]
+			}
+			recent = append(recent, line)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	for _, m := range waiting {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchLine returns the line of the number, parsed if it is an entry.
+func searchLine(number int, text string) SearchLine {
+	text = strings.TrimRight(text, "\r\n")
+	line := SearchLine{Number: number, Text: text}
+	if e, err := ParseLine(text); err == nil && e.Level != "" {
+		line.Entry = e
+	}
+	return line
+}
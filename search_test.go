@@ -0,0 +1,63 @@
+package tolog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchWithContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+		`# tolog file marker`,
+		`{"time":"2024-01-02T03:04:01Z","level":"info","msg":"accepted"}`,
+		`{"time":"2024-01-02T03:04:02Z","level":"error","msg":"timeout"}`,
+		`{"time":"2024-01-02T03:04:03Z","level":"info","msg":"retrying"}`,
+		`{"time":"2024-01-02T03:04:04Z","level":"error","msg":"timeout again"}`,
+		``,
+	}, "\n")), 0644))
+
+	matches, err := SearchWithContext(path, "timeout", 2, 1)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	m := matches[0]
+	assert.Equal(t, 3, m.Line.Number)
+	assert.Equal(t, StatusError, m.Line.Entry.Level)
+	require.Len(t, m.Before, 2)
+	assert.Nil(t, m.Before[0].Entry)
+	assert.Equal(t, "# tolog file marker", m.Before[0].Text)
+	assert.Equal(t, "accepted", m.Before[1].Entry.Message)
+	require.Len(t, m.After, 1)
+	assert.Equal(t, "retrying", m.After[0].Entry.Message)
+
+	m = matches[1]
+	assert.Equal(t, "timeout again", m.Line.Entry.Message)
+	assert.Equal(t, []int{3, 4}, []int{m.Before[0].Number, m.Before[1].Number})
+	assert.Empty(t, m.After)
+
+	matches, err = SearchWithContext(path, "accepted", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Empty(t, matches[0].Before)
+
+	_, err = SearchWithContext(path, "(", 0, 0)
+	assert.Error(t, err)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	stop := errors.New("stop")
+	calls := 0
+	err = SearchReader(f, regexp.MustCompile("timeout"), 0, 0, func(SearchMatch) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
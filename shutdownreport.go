@@ -0,0 +1,49 @@
+package tolog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ShutdownReportMessage is the message of the entry of SetShutdownReport.
+const ShutdownReportMessage = "session summary"
+
+var (
+	shutdownReport   int32
+	shutdownReported int32
+)
+
+// SetShutdownReport makes CloseLogFile and Shutdown write a final notice
+// summarizing the session before closing the files, for postmortems: the
+// entries per level, dropped, bytes_written, rotations and uptime. It is
+// written once, enabling it again arms it for another close.
+func SetShutdownReport(enabled bool) {
+	v := int32(0)
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&shutdownReport, v)
+	atomic.StoreInt32(&shutdownReported, 0)
+}
+
+// writeShutdownReport writes the report of the session with the default
+// logger, if enabled and not written yet.
+func writeShutdownReport() {
+	if atomic.LoadInt32(&shutdownReport) == 0 || !atomic.CompareAndSwapInt32(&shutdownReported, 0, 1) {
+		return
+	}
+	s := GetStats()
+	entries := Fields{}
+	for _, l := range statLevels {
+		if n := s.Entries[l]; n > 0 {
+			entries[string(l)] = n
+		}
+	}
+	Notice(ShutdownReportMessage).Fields(Fields{
+		"entries":       entries,
+		"dropped":       s.Dropped,
+		"bytes_written": s.BytesWritten,
+		"rotations":     s.Rotations,
+		"uptime":        time.Since(runStart).Round(time.Millisecond).String(),
+	}).WriteSafe()
+}
@@ -0,0 +1,40 @@
+package tolog
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetShutdownReport(t *testing.T) {
+	logPrefix := "TestSetShutdownReport"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
+	cleanLogFiles(t, logFilePath)
+	SetLogPrefix(logPrefix)
+	defer SetShutdownReport(false)
+
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+	Error("failed").WriteSafe()
+	assert.NoError(t, CloseLogFile())
+	assert.Len(t, sink.entries, 1, "disabled by default")
+
+	SetShutdownReport(true)
+	Warning("slow").WriteSafe()
+	before := GetStats()
+	assert.NoError(t, CloseLogFile())
+	assert.NoError(t, CloseLogFile())
+	if assert.Len(t, sink.entries, 3, "written once") {
+		e := sink.entries[2]
+		assert.Equal(t, StatusNotice, e.Level)
+		assert.Equal(t, ShutdownReportMessage, e.Message)
+		entries := e.Fields["entries"].(Fields)
+		assert.Equal(t, before.Entries[StatusWarning], entries["warning"])
+		assert.Equal(t, before.Rotations, e.Fields["rotations"])
+		assert.Contains(t, e.Fields, "dropped")
+		assert.Contains(t, e.Fields, "bytes_written")
+		assert.NotEmpty(t, e.Fields["uptime"])
+	}
+}
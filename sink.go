@@ -0,0 +1,240 @@
+package tolog
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Entry is an immutable snapshot of a ToLog handed to sinks once it is written.
+type Entry struct {
+	Time    time.Time
+	Level   LogStatus
+	Message string
+	Fields  Fields
+}
+
+// Sink receives every written entry in addition to the log file. WriteEntry must
+// not block, sinks talking to remote services queue and batch entries themselves.
+type Sink interface {
+	WriteEntry(e *Entry) error
+	Close() error
+}
+
+var sinks []Sink
+var sinksMu sync.RWMutex
+
+// AddSink registers a sink which receives every written entry.
+func AddSink(sink Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, sink)
+}
+
+// RemoveSink unregisters a sink without closing it.
+func RemoveSink(sink Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	for i, s := range sinks {
+		if s == sink {
+			sinks = append(sinks[:i:i], sinks[i+1:]...)
+			return
+		}
+	}
+}
+
+// WithTemporarySink registers the sink while fn runs, then unregisters and
+// closes it, even if fn panics, returning the error of closing it. Like every
+// sink it receives the entries of all goroutines while registered, e.g. to
+// capture the entries of a job run in an extra file.
+func WithTemporarySink(sink Sink, fn func()) (err error) {
+	AddSink(sink)
+	defer func() {
+		RemoveSink(sink)
+		if cerr := sink.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	fn()
+	return nil
+}
+
+// CaptureToString runs fn and returns the lines of the entries written while it
+// runs, in the format of the log files, e.g. to attach the log of a job to its
+// result. Entries only printed are not captured, and like WithTemporarySink
+// those of all goroutines are.
+func CaptureToString(fn func()) (string, error) {
+	sink := &stringSink{}
+	err := WithTemporarySink(sink, fn)
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	return sink.buf.String(), err
+}
+
+// stringSink collects the lines of the entries for CaptureToString.
+type stringSink struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (s *stringSink) WriteEntry(e *Entry) error {
+	line := fileLine(e.toLog())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.WriteString(line)
+	return nil
+}
+
+func (s *stringSink) Close() error {
+	return nil
+}
+
+// CloseSinks closes and unregisters all sinks, returning the first error.
+func CloseSinks() error {
+	sinksMu.Lock()
+	closing := sinks
+	sinks = nil
+	sinksMu.Unlock()
+
+	var firstErr error
+	for _, s := range closing {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// entry returns a snapshot of the log entry.
+func (l *ToLog) entry() *Entry {
+	return &Entry{
+		Time:    l.time,
+		Level:   l.logType,
+		Message: l.logContext,
+		Fields:  l.fields.copy(),
+	}
+}
+
+// dispatch hands the entry to every registered sink and the recent cache.
+func (l *ToLog) dispatch() {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	cached := recent.enabled()
+	if len(sinks) == 0 && !cached {
+		return
+	}
+	e := l.entry()
+	if cached {
+		recent.add(e)
+	}
+	for _, s := range sinks {
+		if !sinkEnabled(s, e.Level) {
+			continue
+		}
+		if err := s.WriteEntry(e); err != nil {
+			diag("sink", "%T rejected entry: %v", s, err)
+			handleError(err)
+		}
+	}
+}
+
+// batcher collects entries on a channel and flushes them in batches from a
+// goroutine, when the batch is full or the interval elapsed, like writeToFile.
+type batcher struct {
+	entries  chan *Entry
+	done     chan struct{}
+	flush    func(batch []*Entry) error
+	size     int
+	interval time.Duration
+	wg       sync.WaitGroup
+	once     sync.Once
+
+	mu     sync.RWMutex // held by add while queueing, so close drains every entry queued
+	closed bool
+}
+
+// newBatcher starts a batcher flushing up to size entries at least every interval.
+func newBatcher(size int, interval time.Duration, flush func(batch []*Entry) error) *batcher {
+	if size <= 0 {
+		size = 100
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	b := &batcher{
+		entries:  make(chan *Entry, atomic.LoadInt64(&queueSize)),
+		done:     make(chan struct{}),
+		flush:    flush,
+		size:     size,
+		interval: interval,
+	}
+	b.wg.Add(1)
+	go b.run()
+	return b
+}
+
+// add queues the entry without blocking, dropping it with ErrChannelFull if the
+// queue is full and returning ErrClosed if the batcher is closed.
+func (b *batcher) add(e *Entry) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+	select {
+	case b.entries <- e:
+		return nil
+	default:
+		diag("drop", "sink queue full, entry dropped")
+		return ErrChannelFull
+	}
+}
+
+func (b *batcher) run() {
+	defer b.wg.Done()
+	batch := make([]*Entry, 0, b.size)
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := b.flush(batch); err != nil {
+			diag("sink", "flushing %d entries failed: %v", len(batch), err)
+			handleError(err)
+		}
+		batch = make([]*Entry, 0, b.size)
+	}
+	for {
+		select {
+		case e := <-b.entries:
+			batch = append(batch, e)
+			if len(batch) >= b.size {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case <-b.done:
+			for len(b.entries) > 0 {
+				batch = append(batch, <-b.entries)
+				if len(batch) >= b.size {
+					send()
+				}
+			}
+			send()
+			return
+		}
+	}
+}
+
+// close flushes the queued entries and stops the goroutine.
+func (b *batcher) close() {
+	b.once.Do(func() {
+		b.mu.Lock()
+		b.closed = true
+		b.mu.Unlock()
+		close(b.done)
+		b.wg.Wait()
+	})
+}
@@ -0,0 +1,163 @@
+package tolog
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	entries []*Entry
+	closed  bool
+}
+
+func (s *memorySink) WriteEntry(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestSinks(t *testing.T) {
+	logPrefix := "TestSinks"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
+	cleanLogFiles(t, logFilePath)
+	SetLogPrefix(logPrefix)
+	defer CloseLogFile()
+
+	sink := &memorySink{}
+	AddSink(sink)
+	Info("written").WriteSafe()
+	Info("printed only").PrintLog()
+	Error("also written").PrintAndWriteSafe()
+	RemoveSink(sink)
+	Info("after remove").WriteSafe()
+
+	if assert.Len(t, sink.entries, 2) {
+		assert.Equal(t, "written", sink.entries[0].Message)
+		assert.Equal(t, StatusError, sink.entries[1].Level)
+	}
+
+	AddSink(sink)
+	assert.NoError(t, CloseSinks())
+	assert.True(t, sink.closed)
+}
+
+func TestBatcher(t *testing.T) {
+	var batches [][]*Entry
+	b := newBatcher(2, time.Hour, func(batch []*Entry) error {
+		batches = append(batches, batch)
+		return nil
+	})
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, b.add(&Entry{}))
+	}
+	b.close()
+	assert.ErrorIs(t, b.add(&Entry{}), ErrClosed)
+	assert.Len(t, batches, 2)
+	assert.Len(t, batches[0], 2)
+	assert.Len(t, batches[1], 1)
+}
+
+func TestBatcherCloseConcurrent(t *testing.T) {
+	var flushed atomic.Int64
+	b := newBatcher(10, time.Hour, func(batch []*Entry) error {
+		flushed.Add(int64(len(batch)))
+		return nil
+	})
+	var added atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				err := b.add(&Entry{})
+				if err == ErrClosed {
+					return
+				}
+				if err == nil {
+					added.Add(1)
+				}
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	b.close()
+	wg.Wait()
+	assert.Equal(t, added.Load(), flushed.Load(), "every entry accepted is flushed")
+}
+
+func TestBatcherFull(t *testing.T) {
+	stalled := make(chan struct{})
+	b := newBatcher(1, time.Hour, func([]*Entry) error {
+		<-stalled // a slow endpoint
+		return nil
+	})
+	defer b.close()
+	defer close(stalled)
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		for i := 0; i < int(queueSize)+2 && err == nil; i++ {
+			err = b.add(&Entry{})
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrChannelFull)
+	case <-time.After(5 * time.Second):
+		t.Fatal("add blocked on a full queue")
+	}
+}
+
+func TestWithTemporarySink(t *testing.T) {
+	sink := &memorySink{}
+	err := WithTemporarySink(sink, func() {
+		Info("during the job").WriteSafe()
+	})
+	assert.NoError(t, err)
+	Info("after the job").WriteSafe()
+
+	assert.True(t, sink.closed)
+	if assert.Len(t, sink.entries, 1) {
+		assert.Equal(t, "during the job", sink.entries[0].Message)
+	}
+
+	panicking := &memorySink{}
+	assert.Panics(t, func() {
+		_ = WithTemporarySink(panicking, func() { panic("job failed") })
+	})
+	assert.True(t, panicking.closed)
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	assert.NotContains(t, sinks, Sink(panicking))
+}
+
+func TestCaptureToString(t *testing.T) {
+	Info("before").WriteSafe()
+	out, err := CaptureToString(func() {
+		Info("step one").WriteSafe()
+		Warning("step two").WithField("n", 2).PrintAndWriteSafe()
+	})
+	Info("after").WriteSafe()
+
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if assert.Len(t, lines, 2, out) {
+		assert.Contains(t, lines[0], "[info]  step one")
+		assert.Contains(t, lines[1], "step two n=2")
+	}
+}
@@ -0,0 +1,216 @@
+package tolog
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Placeholder renders the n-th (1 based) bind parameter of a SQL statement.
+type Placeholder func(n int) string
+
+// Placeholders of the common database/sql drivers.
+var (
+	QuestionPlaceholder Placeholder = func(int) string { return "?" }                     // SQLite, MySQL
+	DollarPlaceholder   Placeholder = func(n int) string { return "$" + strconv.Itoa(n) } // PostgreSQL
+)
+
+// SQLSinkOptions configures a SQLSink.
+type SQLSinkOptions struct {
+	// Table is the table entries are stored in, default "logs".
+	Table string
+	// Placeholder renders bind parameters, default QuestionPlaceholder.
+	Placeholder Placeholder
+	// CreateTable creates the table and its indexes if they do not exist.
+	CreateTable bool
+	// BatchSize and FlushInterval bound the entries per transaction, default 100 and 1s.
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// SQLSink persists entries to a database through database/sql, one row per entry
+// with the time in unix nanoseconds and the fields as a JSON object.
+type SQLSink struct {
+	db      *sql.DB
+	opts    SQLSinkOptions
+	insert  string
+	batcher *batcher
+}
+
+// NewSQLSink creates a sink storing entries in the database. Add it with
+// AddSink. The table, written into the statements, is an identifier like
+// app_logs.
+func NewSQLSink(db *sql.DB, opts SQLSinkOptions) (*SQLSink, error) {
+	if db == nil {
+		return nil, fmt.Errorf("tolog: sql sink: missing database")
+	}
+	if opts.Table == "" {
+		opts.Table = "logs"
+	}
+	if !isIdentifier(opts.Table, false) {
+		return nil, fmt.Errorf("tolog: sql sink: invalid table name %q", opts.Table)
+	}
+	if opts.Placeholder == nil {
+		opts.Placeholder = QuestionPlaceholder
+	}
+	s := &SQLSink{
+		db:   db,
+		opts: opts,
+		insert: fmt.Sprintf("INSERT INTO %s (time, level, message, fields) VALUES (%s, %s, %s, %s)",
+			opts.Table, opts.Placeholder(1), opts.Placeholder(2), opts.Placeholder(3), opts.Placeholder(4)),
+	}
+	if opts.CreateTable {
+		schema, err := SQLSchema(opts.Table)
+		if err != nil {
+			return nil, err
+		}
+		for _, stmt := range schema {
+			if _, err := db.Exec(stmt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	s.batcher = newBatcher(opts.BatchSize, opts.FlushInterval, s.write)
+	return s, nil
+}
+
+// SQLSchema returns the statements creating the table of a SQLSink and its
+// indexes, or an error if the table is not an identifier.
+func SQLSchema(table string) ([]string, error) {
+	if !isIdentifier(table, false) {
+		return nil, fmt.Errorf("tolog: sql sink: invalid table name %q", table)
+	}
+	return []string{
+		"CREATE TABLE IF NOT EXISTS " + table + " (time BIGINT NOT NULL, level VARCHAR(16) NOT NULL, message TEXT NOT NULL, fields TEXT NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS " + table + "_time_idx ON " + table + " (time)",
+		"CREATE INDEX IF NOT EXISTS " + table + "_level_idx ON " + table + " (level, time)",
+	}, nil
+}
+
+// WriteEntry queues the entry for the next transaction, dropping it with
+// ErrChannelFull if the queue is full.
+func (s *SQLSink) WriteEntry(e *Entry) error {
+	return s.batcher.add(e)
+}
+
+// Close writes the queued entries and stops the sink, the database is left open.
+func (s *SQLSink) Close() error {
+	s.batcher.close()
+	return nil
+}
+
+// write inserts a batch of entries in a single transaction.
+func (s *SQLSink) write(batch []*Entry) error {
+	return insertBatch(s.db, s.insert, batch, func(e *Entry) []any {
+		return []any{e.Time.UnixNano(), string(e.Level), e.Message, fieldsJSON(e.Fields)}
+	})
+}
+
+// insertBatch executes the prepared insert statement once per entry in a single transaction.
+func insertBatch(db *sql.DB, insert string, batch []*Entry, values func(e *Entry) []any) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(insert)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range batch {
+		if _, err := stmt.Exec(values(e)...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// fieldsJSON encodes the fields as a JSON object.
+func fieldsJSON(f Fields) string {
+	obj := make(map[string]any, len(f))
+	for k, v := range normalizeFields(f) {
+		obj[k] = jsonValue(v)
+	}
+	return marshalLine(obj)
+}
+
+// SQLQuery selects entries stored by a SQLSink. Zero values do not filter.
+type SQLQuery struct {
+	Table       string
+	Placeholder Placeholder
+	Level       LogStatus
+	Since       time.Time
+	Until       time.Time
+	Contains    string // substring of the message
+	Limit       int
+}
+
+// QuerySQL returns the entries matching the query, oldest first.
+func QuerySQL(db *sql.DB, q SQLQuery) ([]*Entry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("tolog: sql query: missing database")
+	}
+	if q.Table == "" {
+		q.Table = "logs"
+	}
+	if !isIdentifier(q.Table, false) {
+		return nil, fmt.Errorf("tolog: sql query: invalid table name %q", q.Table)
+	}
+	if q.Placeholder == nil {
+		q.Placeholder = QuestionPlaceholder
+	}
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, cond+" "+q.Placeholder(len(args)))
+	}
+	if q.Level != "" {
+		add("level =", string(q.Level))
+	}
+	if !q.Since.IsZero() {
+		add("time >=", q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		add("time <", q.Until.UnixNano())
+	}
+	if q.Contains != "" {
+		add("message LIKE", "%"+q.Contains+"%")
+	}
+	query := "SELECT time, level, message, fields FROM " + q.Table
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY time"
+	if q.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(q.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []*Entry
+	for rows.Next() {
+		var nanos int64
+		var level, message, fields string
+		if err := rows.Scan(&nanos, &level, &message, &fields); err != nil {
+			return nil, err
+		}
+		e := &Entry{Time: time.Unix(0, nanos).In(globalTimeZone()), Level: LogStatus(level), Message: message}
+		if err := json.Unmarshal([]byte(fields), &e.Fields); err != nil {
+			return nil, err
+		}
+		if len(e.Fields) == 0 {
+			e.Fields = nil
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
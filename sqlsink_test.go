@@ -0,0 +1,145 @@
+package tolog
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver is a minimal database/sql driver keeping a fakeDB per data source name.
+type fakeDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*fakeDB
+}
+
+// fakeDB records statements, inserted rows are returned unfiltered by every query.
+type fakeDB struct {
+	mu        sync.Mutex
+	execs     []string
+	rows      [][]driver.Value
+	lastQuery string
+	lastArgs  []driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{d.db(name)}, nil
+}
+
+func (d *fakeDriver) db(name string) *fakeDB {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dbs[name] == nil {
+		d.dbs[name] = &fakeDB{}
+	}
+	return d.dbs[name]
+}
+
+type fakeConn struct{ d *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.d, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	d     *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.execs = append(s.d.execs, s.query)
+	if strings.HasPrefix(s.query, "INSERT") {
+		s.d.rows = append(s.d.rows, args)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.lastQuery, s.d.lastArgs = s.query, args
+	return &fakeRows{rows: append([][]driver.Value(nil), s.d.rows...)}, nil
+}
+
+type fakeRows struct{ rows [][]driver.Value }
+
+func (r *fakeRows) Columns() []string { return []string{"time", "level", "message", "fields"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var fakeSQL = &fakeDriver{dbs: map[string]*fakeDB{}}
+
+func init() {
+	sql.Register("tolog-fake", fakeSQL)
+}
+
+func TestSQLSink(t *testing.T) {
+	db, err := sql.Open("tolog-fake", t.Name())
+	require.NoError(t, err)
+	defer db.Close()
+	fake := fakeSQL.db(t.Name())
+
+	sink, err := NewSQLSink(db, SQLSinkOptions{Table: "app_logs", CreateTable: true})
+	require.NoError(t, err)
+	now := time.Now().In(globalTimeZone())
+	require.NoError(t, sink.WriteEntry(&Entry{Time: now, Level: StatusError, Message: "payment failed", Fields: Fields{"order": 42}}))
+	require.NoError(t, sink.Close())
+
+	schema, err := SQLSchema("app_logs")
+	require.NoError(t, err)
+	assert.Equal(t, schema, fake.execs[:3])
+	assert.Equal(t, "INSERT INTO app_logs (time, level, message, fields) VALUES (?, ?, ?, ?)", fake.execs[3])
+
+	entries, err := QuerySQL(db, SQLQuery{Table: "app_logs", Placeholder: DollarPlaceholder, Level: StatusError, Since: now.Add(-time.Minute), Contains: "payment", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT time, level, message, fields FROM app_logs WHERE level = $1 AND time >= $2 AND message LIKE $3 ORDER BY time LIMIT 10", fake.lastQuery)
+	assert.Equal(t, []driver.Value{"error", now.Add(-time.Minute).UnixNano(), "%payment%"}, fake.lastArgs)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "payment failed", entries[0].Message)
+	assert.Equal(t, Fields{"order": float64(42)}, entries[0].Fields)
+	assert.True(t, now.Equal(entries[0].Time))
+}
+
+func TestSQLSinkInvalid(t *testing.T) {
+	_, err := NewSQLSink(nil, SQLSinkOptions{})
+	assert.EqualError(t, err, "tolog: sql sink: missing database")
+
+	db, err := sql.Open("tolog-fake", t.Name())
+	require.NoError(t, err)
+	defer db.Close()
+	fake := fakeSQL.db(t.Name())
+	_, err = NewSQLSink(db, SQLSinkOptions{Table: "logs; DROP TABLE users", CreateTable: true})
+	assert.EqualError(t, err, `tolog: sql sink: invalid table name "logs; DROP TABLE users"`)
+	_, err = SQLSchema("logs (x INT); --")
+	assert.EqualError(t, err, `tolog: sql sink: invalid table name "logs (x INT); --"`)
+	_, err = QuerySQL(db, SQLQuery{Table: "logs WHERE 1=1 --"})
+	assert.EqualError(t, err, `tolog: sql query: invalid table name "logs WHERE 1=1 --"`)
+	_, err = QuerySQL(nil, SQLQuery{})
+	assert.EqualError(t, err, "tolog: sql query: missing database")
+	assert.Empty(t, fake.execs)
+	assert.Empty(t, fake.lastQuery)
+}
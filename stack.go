@@ -0,0 +1,104 @@
+package tolog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// StackField is the field Stack and PanicFields write the stack in.
+const StackField = "stack"
+
+// The settings of the captured stacks.
+var (
+	stackDepth   int64
+	stackSkip    atomic.Value // []string
+	stackCompact int32
+)
+
+// SetStackDepth sets the maximum number of frames of the captured stacks,
+// after the skipped ones. Zero keeps all frames, the default.
+func SetStackDepth(frames int) {
+	atomic.StoreInt64(&stackDepth, int64(frames))
+}
+
+// SetStackSkip leaves the frames whose function or file starts with one of
+// the prefixes out of the captured stacks, e.g. "runtime." for the frames of
+// the runtime or the path of the vendor directory. No prefix keeps all frames.
+func SetStackSkip(prefixes ...string) {
+	stackSkip.Store(append([]string(nil), prefixes...))
+}
+
+// SetStackCompact sets whether the captured stacks are written on one line,
+// like main.handle(main.go:12) < net/http.HandlerFunc.ServeHTTP(server.go:2136),
+// which keeps text lines readable. By default a stack has two lines per frame.
+func SetStackCompact(compact bool) {
+	var v int32
+	if compact {
+		v = 1
+	}
+	atomic.StoreInt32(&stackCompact, v)
+}
+
+// Stack adds the stack of the calling goroutine in the stack field.
+func (l *ToLog) Stack() *ToLog {
+	l = l.mutable()
+	l.fields = l.fields.merge(Fields{StackField: captureStack(1)})
+	CreateFullLog(l)
+	return l
+}
+
+// captureStack returns the stack of the calling goroutine above skip frames
+// of the caller, formatted with the stack settings.
+func captureStack(skip int) string {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(skip+2, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+	return formatStack(pcs)
+}
+
+// formatStack formats the frames of the program counters with the stack settings.
+func formatStack(pcs []uintptr) string {
+	max := int(atomic.LoadInt64(&stackDepth))
+	skipped, _ := stackSkip.Load().([]string)
+	compact := atomic.LoadInt32(&stackCompact) == 1
+
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for count := 0; max <= 0 || count < max; {
+		frame, more := frames.Next()
+		if !skipFrame(frame, skipped) {
+			if compact {
+				if count > 0 {
+					sb.WriteString(" < ")
+				}
+				fmt.Fprintf(&sb, "%s(%s:%d)", frame.Function, filepath.Base(frame.File), frame.Line)
+			} else {
+				fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			}
+			count++
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// skipFrame reports whether the function or the file of the frame starts with one of the prefixes.
+func skipFrame(frame runtime.Frame, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(frame.Function, p) || strings.HasPrefix(frame.File, p) {
+			return true
+		}
+	}
+	return false
+}
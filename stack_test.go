@@ -0,0 +1,36 @@
+package tolog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStack(t *testing.T) {
+	defer SetStackDepth(0)
+	defer SetStackSkip()
+	defer SetStackCompact(false)
+
+	stack := std.Info("x").Stack().fields[StackField].(string)
+	lines := strings.Split(strings.TrimSpace(stack), "\n")
+	assert.Contains(t, lines[0], "tolog.TestStack")
+	assert.Contains(t, lines[1], "stack_test.go:")
+	assert.Contains(t, stack, "testing.tRunner")
+
+	SetStackSkip("testing.")
+	SetStackDepth(1)
+	stack = captureStack(0)
+	assert.Equal(t, 2, strings.Count(stack, "\n"))
+	assert.Contains(t, stack, "tolog.TestStack")
+
+	SetStackDepth(0)
+	SetStackCompact(true)
+	stack = captureStack(0)
+	assert.NotContains(t, stack, "\n")
+	assert.NotContains(t, stack, "testing.")
+	assert.Regexp(t, `^github.com/callme-taota/tolog.TestStack\(stack_test.go:\d+\) < runtime.goexit\(asm_\w+.s:\d+\)$`, stack)
+
+	SetStackSkip("runtime.", "testing.", "github.com/callme-taota/tolog.TestStack")
+	assert.Equal(t, "", captureStack(0))
+}
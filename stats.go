@@ -0,0 +1,155 @@
+package tolog
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// statLevels are the levels entries are counted by, in the order of the counters.
+var statLevels = []LogStatus{StatusDebug, StatusInfo, StatusNotice, StatusWarning, StatusError, StatusUnknown}
+
+var (
+	entryCounts  [6]uint64
+	bytesWritten uint64
+	flushCount   uint64
+	flushNanos   uint64
+	lastFlush    int64 // nanoseconds
+
+	rotationCount uint64
+)
+
+// Stats are counters about the logger itself, e.g. to alert on error-rate
+// spikes or detect when the queues of the log files are backing up.
+type Stats struct {
+	// Entries is the number of entries emitted per level.
+	Entries map[LogStatus]uint64
+	// BytesWritten is the number of bytes written to the log files and collectors.
+	BytesWritten uint64
+	// Dropped is the number of entries dropped because a queue was full.
+	Dropped uint64
+	// VolumeCapDropped is the number of lines dropped by the daily volume cap.
+	VolumeCapDropped uint64
+	// Queued is the number of lines waiting in the queues of the log files.
+	Queued int
+	// Flushes is the number of batches written from the queues.
+	Flushes uint64
+	// FlushLatency is the total time spent writing the batches.
+	FlushLatency time.Duration
+	// LastFlushLatency is the time spent writing the last batch.
+	LastFlushLatency time.Duration
+	// Rotations is the number of times a log file was rotated or switched to
+	// the file of a new day.
+	Rotations uint64
+}
+
+// countEntry counts an emitted entry of the level at the time, in total and
+// in the histogram of its minute.
+func countEntry(level LogStatus, t time.Time) {
+	i := len(statLevels) - 1
+	for j, l := range statLevels {
+		if l == level {
+			i = j
+			break
+		}
+	}
+	atomic.AddUint64(&entryCounts[i], 1)
+	countMinute(i, t)
+}
+
+// entryCount returns the number of entries of the level emitted.
+func entryCount(level LogStatus) uint64 {
+	for i, l := range statLevels {
+		if l == level {
+			return atomic.LoadUint64(&entryCounts[i])
+		}
+	}
+	return 0
+}
+
+// countFlush records the latency of a flush.
+func countFlush(d time.Duration) {
+	atomic.AddUint64(&flushCount, 1)
+	atomic.AddUint64(&flushNanos, uint64(d))
+	atomic.StoreInt64(&lastFlush, int64(d))
+}
+
+// GetStats returns the counters of all loggers since the process started.
+func GetStats() Stats {
+	s := Stats{
+		Entries:          make(map[LogStatus]uint64, len(statLevels)),
+		BytesWritten:     atomic.LoadUint64(&bytesWritten),
+		Dropped:          Dropped(),
+		VolumeCapDropped: VolumeCapDropped(),
+		Flushes:          atomic.LoadUint64(&flushCount),
+		FlushLatency:     time.Duration(atomic.LoadUint64(&flushNanos)),
+		LastFlushLatency: time.Duration(atomic.LoadInt64(&lastFlush)),
+		Rotations:        atomic.LoadUint64(&rotationCount),
+	}
+	for i, l := range statLevels {
+		s.Entries[l] = atomic.LoadUint64(&entryCounts[i])
+	}
+	writersMu.Lock()
+	for _, w := range writers {
+		w.mu.RLock()
+		if !w.closed {
+			s.Queued += w.queued()
+		}
+		w.mu.RUnlock()
+	}
+	writersMu.Unlock()
+	return s
+}
+
+// PublishExpvar publishes the stats as the expvar variable of the name, served
+// on /debug/vars. Like expvar.Publish it panics if the name is already used.
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return GetStats() }))
+}
+
+// WriteMetrics writes the stats in the Prometheus text exposition format.
+func WriteMetrics(w io.Writer) error {
+	s := GetStats()
+	ew := &errWriter{w: w}
+	ew.printf("# HELP tolog_entries_total Entries emitted per level.\n# TYPE tolog_entries_total counter\n")
+	for _, l := range statLevels {
+		ew.printf("tolog_entries_total{level=%q} %d\n", l, s.Entries[l])
+	}
+	ew.printf("# HELP tolog_bytes_written_total Bytes written to the log files.\n# TYPE tolog_bytes_written_total counter\n")
+	ew.printf("tolog_bytes_written_total %d\n", s.BytesWritten)
+	ew.printf("# HELP tolog_dropped_total Entries dropped because a queue was full.\n# TYPE tolog_dropped_total counter\n")
+	ew.printf("tolog_dropped_total %d\n", s.Dropped)
+	ew.printf("# HELP tolog_volume_cap_dropped_total Lines dropped by the daily volume cap.\n# TYPE tolog_volume_cap_dropped_total counter\n")
+	ew.printf("tolog_volume_cap_dropped_total %d\n", s.VolumeCapDropped)
+	ew.printf("# HELP tolog_queued Lines waiting to be written.\n# TYPE tolog_queued gauge\n")
+	ew.printf("tolog_queued %d\n", s.Queued)
+	ew.printf("# HELP tolog_flush_seconds Time spent writing batches.\n# TYPE tolog_flush_seconds summary\n")
+	ew.printf("tolog_flush_seconds_sum %g\n", s.FlushLatency.Seconds())
+	ew.printf("tolog_flush_seconds_count %d\n", s.Flushes)
+	ew.printf("# HELP tolog_rotations_total Rotations of the log files.\n# TYPE tolog_rotations_total counter\n")
+	ew.printf("tolog_rotations_total %d\n", s.Rotations)
+	return ew.err
+}
+
+// MetricsHandler returns a handler serving the stats to a Prometheus scraper.
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteMetrics(w)
+	})
+}
+
+// errWriter keeps the first error of a sequence of writes.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, a ...any) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, a...)
+	}
+}
@@ -0,0 +1,39 @@
+package tolog
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	lg := NewLogger("TestStats")
+	path := lg.FilePath()
+	defer os.Remove(path)
+	before := GetStats()
+
+	lg.Error("failed").WriteSafe()
+	lg.Error("failed again").WriteSafe()
+	lg.Info("detail").WriteSafe()
+	assert.NoError(t, lg.Close())
+
+	after := GetStats()
+	assert.Equal(t, before.Entries[StatusError]+2, after.Entries[StatusError])
+	assert.Equal(t, before.Entries[StatusInfo]+1, after.Entries[StatusInfo])
+	assert.Greater(t, after.BytesWritten, before.BytesWritten)
+	assert.Greater(t, after.Flushes, before.Flushes)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteMetrics(&buf))
+	assert.Contains(t, buf.String(), `tolog_entries_total{level="error"} `)
+	assert.Contains(t, buf.String(), "# TYPE tolog_flush_seconds summary")
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+	assert.Contains(t, rec.Body.String(), "tolog_bytes_written_total ")
+}
@@ -0,0 +1,45 @@
+package tolog
+
+import (
+	"log"
+	"strings"
+)
+
+// entryWriter turns every write, a line of a log.Logger, into an entry.
+type entryWriter struct {
+	logger *Logger
+	level  LogStatus
+	fields Fields
+}
+
+// Write logs p as an entry printed and written to the log file.
+func (w *entryWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\r\n")
+	w.logger.Log(WithType(w.level), WithFields(w.fields)).Context(msg).PrintAndWriteSafe()
+	return len(p), nil
+}
+
+// StdLogger returns a log.Logger whose lines become entries of the level of
+// the default logger, printed and written to the log file, for libraries only
+// accepting a log.Logger like database drivers.
+func StdLogger(level LogStatus) *log.Logger {
+	return std.StdLogger(level)
+}
+
+// StdLogger returns a log.Logger whose lines become entries of the level of the logger.
+func (lg *Logger) StdLogger(level LogStatus) *log.Logger {
+	return log.New(&entryWriter{logger: lg, level: level}, "", 0)
+}
+
+// ErrorLogger returns a log.Logger writing error entries of the default logger
+// with the component=http field, for http.Server.ErrorLog and other consumers of
+// a log.Logger.
+func ErrorLogger() *log.Logger {
+	return std.ErrorLogger()
+}
+
+// ErrorLogger returns a log.Logger writing error entries of the logger with the
+// component=http field, for http.Server.ErrorLog and other consumers of a log.Logger.
+func (lg *Logger) ErrorLogger() *log.Logger {
+	return log.New(&entryWriter{logger: lg, level: StatusError, fields: Fields{"component": "http"}}, "", 0)
+}
@@ -0,0 +1,41 @@
+package tolog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorLogger(t *testing.T) {
+	lg := NewLogger("TestErrorLogger")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.SetConsole(io.Discard)
+	lg.AddOutput(&out)
+
+	lg.ErrorLogger().Printf("http: TLS handshake error from %s", "10.0.0.1:5000")
+	assert.Contains(t, out.String(), " [error] ")
+	assert.Contains(t, out.String(), "http: TLS handshake error from 10.0.0.1:5000 component=http\n")
+}
+
+func TestStdLogger(t *testing.T) {
+	lg := NewLogger("TestStdLogger")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.SetConsole(io.Discard)
+	lg.AddOutput(&out)
+
+	std := lg.StdLogger(StatusWarning)
+	std.Println("connection pool exhausted")
+	std.Print("retrying\n")
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), " [warning] ")
+	assert.True(t, bytes.HasSuffix(lines[0], []byte(" connection pool exhausted")), string(lines[0]))
+	assert.True(t, bytes.HasSuffix(lines[1], []byte(" retrying")), string(lines[1]))
+}
@@ -0,0 +1,7 @@
+//go:build !tolog_nodebug
+
+package tolog
+
+// DebugStripped reports whether the binary is built with the tolog_nodebug
+// tag, debug entries are then dropped at compile time.
+const DebugStripped = false
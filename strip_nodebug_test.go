@@ -0,0 +1,31 @@
+//go:build tolog_nodebug
+
+package tolog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugStripped(t *testing.T) {
+	lg := NewLogger("TestDebugStripped")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	var out bytes.Buffer
+	lg.SetConsole(io.Discard)
+	lg.AddOutput(&out)
+
+	assert.True(t, DebugStripped)
+	lg.Debug("cache miss").WithField("key", "a").PrintAndWriteSafe()
+	lg.Debugf("cache %s", "miss").Msg("cache miss")
+	lg.Log(WithType(StatusDebug)).Context("typed").PrintAndWriteSafe()
+	lg.Info("kept").PrintAndWriteSafe()
+	assert.NotContains(t, out.String(), "cache miss")
+	assert.NotContains(t, out.String(), "typed")
+	assert.Contains(t, out.String(), "kept")
+	assert.Empty(t, lg.Debug("x").FullLog)
+}
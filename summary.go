@@ -0,0 +1,100 @@
+package tolog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RunSummary counts the warnings and errors emitted by all loggers since the
+// process started, for the final report of command line tools.
+type RunSummary struct {
+	Errors   uint64
+	Warnings uint64
+	// Files are the log files of the loggers, where the entries are detailed.
+	Files []string
+}
+
+// Summary returns the warnings and errors emitted so far and the log files.
+func Summary() RunSummary {
+	s := RunSummary{
+		Errors:   entryCount(StatusError),
+		Warnings: entryCount(StatusWarning),
+	}
+	for _, w := range openWriters() {
+		if w.socket == "" {
+			s.Files = append(s.Files, w.path())
+		}
+	}
+	sort.Strings(s.Files)
+	return s
+}
+
+// String returns the summary as one line, like
+// "2 errors, 5 warnings — see ./logs/log-2024-01-02.log".
+func (s RunSummary) String() string {
+	return s.format(false)
+}
+
+// format formats the summary, the counts in the colors of their levels if color is set.
+func (s RunSummary) format(color bool) string {
+	paint := func(text, bg string) string {
+		if color {
+			return bg + text + colorReset
+		}
+		return text
+	}
+	var sb strings.Builder
+	switch {
+	case s.Errors == 0 && s.Warnings == 0:
+		sb.WriteString("no errors or warnings")
+	case s.Warnings == 0:
+		sb.WriteString(paint(plural(s.Errors, "error"), colorErrorBg))
+	case s.Errors == 0:
+		sb.WriteString(paint(plural(s.Warnings, "warning"), colorWarningBg))
+	default:
+		sb.WriteString(paint(plural(s.Errors, "error"), colorErrorBg))
+		sb.WriteString(", ")
+		sb.WriteString(paint(plural(s.Warnings, "warning"), colorWarningBg))
+	}
+	if len(s.Files) > 0 {
+		sb.WriteString(" — see ")
+		sb.WriteString(strings.Join(s.Files, ", "))
+	}
+	return sb.String()
+}
+
+// ExitCode returns the exit code of the run, 1 if errors were emitted and 0 otherwise.
+func (s RunSummary) ExitCode() int {
+	if s.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// PrintSummary prints the summary to stderr, colored like the console of the
+// default logger would be, and returns it.
+func PrintSummary() RunSummary {
+	s := Summary()
+	out := stderr()
+	fmt.Fprintln(out, s.format(std.colorOn(out)))
+	return s
+}
+
+// Exit flushes and closes the log files and sinks, prints the summary and exits
+// the process with its exit code, e.g. as the last line of main.
+func Exit() {
+	s := PrintSummary()
+	Shutdown(context.Background())
+	os.Exit(s.ExitCode())
+}
+
+// plural formats the count of the noun, adding an s unless it is one.
+func plural(n uint64, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,40 @@
+package tolog
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	lg := NewLogger("TestSummary")
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+
+	before := Summary()
+	lg.Error("failed").PrintLog()
+	lg.Warning("slow").PrintLog()
+	lg.Warning("slower").PrintLog()
+	after := Summary()
+	assert.Equal(t, before.Errors+1, after.Errors)
+	assert.Equal(t, before.Warnings+2, after.Warnings)
+	assert.Contains(t, after.Files, lg.FilePath())
+	assert.Equal(t, 1, after.ExitCode())
+
+	tests := []struct {
+		summary RunSummary
+		want    string
+	}{
+		{RunSummary{}, "no errors or warnings"},
+		{RunSummary{Errors: 1}, "1 error"},
+		{RunSummary{Warnings: 5, Files: []string{"./logs/a.log"}}, "5 warnings — see ./logs/a.log"},
+		{RunSummary{Errors: 2, Warnings: 1, Files: []string{"a.log", "b.log"}}, "2 errors, 1 warning — see a.log, b.log"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.summary.String())
+	}
+	assert.Equal(t, 0, RunSummary{Warnings: 3}.ExitCode())
+	assert.Equal(t, colorErrorBg+"2 errors"+colorReset+", "+colorWarningBg+"1 warning"+colorReset,
+		RunSummary{Errors: 2, Warnings: 1}.format(true))
+}
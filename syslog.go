@@ -0,0 +1,74 @@
+//go:build !windows && !plan9
+
+package tolog
+
+import (
+	"log/syslog"
+)
+
+// SyslogSink forwards every entry to a local or remote syslog daemon, mapping
+// the levels to the syslog severities.
+type SyslogSink struct {
+	writer  *syslog.Writer
+	batcher *batcher
+}
+
+// NewSyslogSink connects to the syslog daemon at addr over network, "udp" or
+// "tcp", or to the local daemon if network is empty. Add it with AddSink.
+func NewSyslogSink(network string, addr string, tag string) (*SyslogSink, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	s := &SyslogSink{writer: w}
+	s.batcher = newBatcher(0, 0, s.send)
+	return s, nil
+}
+
+// EnableSyslog connects a SyslogSink and registers it with AddSink.
+func EnableSyslog(network string, addr string, tag string) error {
+	s, err := NewSyslogSink(network, addr, tag)
+	if err != nil {
+		return err
+	}
+	AddSink(s)
+	return nil
+}
+
+// WriteEntry queues the entry for the daemon, dropping it with
+// ErrChannelFull if the queue is full.
+func (s *SyslogSink) WriteEntry(e *Entry) error {
+	return s.batcher.add(e)
+}
+
+// Close sends the queued entries and closes the connection.
+func (s *SyslogSink) Close() error {
+	s.batcher.close()
+	return s.writer.Close()
+}
+
+func (s *SyslogSink) send(batch []*Entry) error {
+	for _, e := range batch {
+		msg := e.Message
+		if len(e.Fields) > 0 {
+			msg += " " + renderFields(e.Fields)
+		}
+		var err error
+		switch e.Level {
+		case StatusDebug:
+			err = s.writer.Debug(msg)
+		case StatusNotice:
+			err = s.writer.Notice(msg)
+		case StatusWarning:
+			err = s.writer.Warning(msg)
+		case StatusError:
+			err = s.writer.Err(msg)
+		default:
+			err = s.writer.Info(msg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+//go:build windows || plan9
+
+package tolog
+
+import "errors"
+
+var errNoSyslog = errors.New("tolog: syslog is not supported on this platform")
+
+// SyslogSink forwards entries to a syslog daemon, not supported on this platform.
+type SyslogSink struct{}
+
+// NewSyslogSink returns an error, syslog is not supported on this platform.
+func NewSyslogSink(network string, addr string, tag string) (*SyslogSink, error) {
+	return nil, errNoSyslog
+}
+
+// EnableSyslog returns an error, syslog is not supported on this platform.
+func EnableSyslog(network string, addr string, tag string) error {
+	return errNoSyslog
+}
+
+// WriteEntry returns an error, syslog is not supported on this platform.
+func (s *SyslogSink) WriteEntry(e *Entry) error {
+	return errNoSyslog
+}
+
+// Close does nothing.
+func (s *SyslogSink) Close() error {
+	return nil
+}
@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+
+package tolog
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := NewSyslogSink("udp", conn.LocalAddr().String(), "billing")
+	require.NoError(t, err)
+	require.NoError(t, s.WriteEntry(&Entry{Time: time.Now(), Level: StatusError, Message: "charge failed", Fields: Fields{"order": 7}}))
+	require.NoError(t, s.WriteEntry(&Entry{Time: time.Now(), Level: StatusDebug, Message: "retrying"}))
+	require.NoError(t, s.Close())
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	// user facility 1 * 8 + error severity 3
+	assert.Regexp(t, `^<11>.* billing\[\d+\]: charge failed order=7`, string(buf[:n]))
+	n, _, err = conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Regexp(t, `^<15>.* billing\[\d+\]: retrying`, string(buf[:n]))
+}
@@ -0,0 +1,25 @@
+package tolog
+
+// Target selects where To writes an entry, combined with |.
+type Target uint8
+
+const (
+	TargetConsole Target = 1 << iota // the console, like PrintLog
+	TargetFile                       // the log file, its routes and the outputs, like WriteSafe
+	TargetSinks                      // the sinks and the recent entries
+
+	// targetOutputs writes the outputs without the file, for PrintLog.
+	targetOutputs
+
+	TargetAll = TargetConsole | TargetFile | TargetSinks // like PrintAndWriteSafe
+)
+
+// To writes the entry to the targets, e.g. To(TargetFile) for the file only
+// or To(TargetConsole|TargetSinks) for the console and the sinks without the
+// file. The file is written through the channel like WriteSafe.
+func (l *ToLog) To(targets Target) {
+	if l.intercept(func(c *ToLog) { c.To(targets) }) {
+		return
+	}
+	l.emit(targets&TargetAll, (*fileWriter).write)
+}
@@ -0,0 +1,47 @@
+package tolog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTo(t *testing.T) {
+	lg := NewLogger("TestTo")
+	defer lg.Close()
+	path := lg.FilePath()
+	defer os.Remove(path)
+	var console bytes.Buffer
+	lg.SetConsole(&console)
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+
+	lg.Info("file only").To(TargetFile)
+	lg.Info("console only").To(TargetConsole)
+	lg.Info("console and sinks").To(TargetConsole | TargetSinks)
+	lg.Info("everywhere").To(TargetAll)
+	lg.Info("nowhere").To(0)
+	require.NoError(t, lg.CloseFile())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "file only")
+	assert.Contains(t, string(content), "everywhere")
+	assert.NotContains(t, string(content), "console")
+
+	assert.NotContains(t, console.String(), "file only")
+	assert.Contains(t, console.String(), "console only")
+	assert.Contains(t, console.String(), "console and sinks")
+	assert.Contains(t, console.String(), "everywhere")
+	assert.NotContains(t, console.String(), "nowhere")
+
+	var messages []string
+	for _, e := range sink.entries {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{"console and sinks", "everywhere"}, messages)
+}
@@ -0,0 +1,205 @@
+package tolog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Formatter renders an entry as a line of the text format, replacing the
+// default layout. The caller is in the fields with SetReportCaller.
+type Formatter func(e *Entry) string
+
+// textLayout is the custom layout of the text format, a template or a formatter.
+type textLayout struct {
+	template  []templatePart
+	formatter Formatter
+	caller    bool // the template shows the caller
+}
+
+var layout atomic.Value // *textLayout
+
+// templatePart is a literal or a placeholder of a template.
+type templatePart struct {
+	literal string
+	name    string
+	pad     int
+	upper   bool
+	lower   bool
+}
+
+// SetFormatter sets the function rendering the lines of the text format, nil
+// restores the default layout. It replaces the template set by SetLogTemplate.
+func SetFormatter(f Formatter) {
+	if f == nil {
+		layout.Store((*textLayout)(nil))
+		return
+	}
+	layout.Store(&textLayout{formatter: f})
+}
+
+// SetLogTemplate sets the layout of the lines of the text format, like
+//
+//	{time} | {level:pad5} | {caller} | {msg} {fields}
+//
+// The placeholders are time, level, msg, fields, caller, function and prefix,
+// optionally followed by the modifiers padN, padding to N characters, upper or
+// lower, like {level:upper:pad7}. {{ and }} are literal braces. An empty
+// template restores the default layout.
+func SetLogTemplate(tmpl string) error {
+	if tmpl == "" {
+		layout.Store((*textLayout)(nil))
+		return nil
+	}
+	parts, err := parseTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+	l := &textLayout{template: parts}
+	for _, p := range parts {
+		if p.name == "caller" || p.name == "function" {
+			l.caller = true
+		}
+	}
+	layout.Store(l)
+	return nil
+}
+
+// parseTemplate splits the template into literals and placeholders.
+func parseTemplate(tmpl string) ([]templatePart, error) {
+	var parts []templatePart
+	var literal strings.Builder
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		if (c == '{' || c == '}') && i+1 < len(tmpl) && tmpl[i+1] == c {
+			literal.WriteByte(c)
+			i++
+			continue
+		}
+		if c == '}' {
+			return nil, fmt.Errorf("tolog: unmatched } at %d in template %q", i, tmpl)
+		}
+		if c != '{' {
+			literal.WriteByte(c)
+			continue
+		}
+		end := strings.IndexByte(tmpl[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("tolog: unclosed { at %d in template %q", i, tmpl)
+		}
+		p, err := parsePlaceholder(tmpl[i+1 : i+end])
+		if err != nil {
+			return nil, fmt.Errorf("tolog: template %q: %w", tmpl, err)
+		}
+		if literal.Len() > 0 {
+			parts = append(parts, templatePart{literal: literal.String()})
+			literal.Reset()
+		}
+		parts = append(parts, p)
+		i += end
+	}
+	if literal.Len() > 0 {
+		parts = append(parts, templatePart{literal: literal.String()})
+	}
+	return parts, nil
+}
+
+// parsePlaceholder parses a placeholder without its braces, like level:pad5.
+func parsePlaceholder(s string) (templatePart, error) {
+	mods := strings.Split(s, ":")
+	p := templatePart{name: mods[0]}
+	switch p.name {
+	case "time", "level", "msg", "fields", "caller", "function", "prefix":
+	default:
+		return p, fmt.Errorf("unknown placeholder {%s}", s)
+	}
+	for _, mod := range mods[1:] {
+		switch {
+		case mod == "upper":
+			p.upper = true
+		case mod == "lower":
+			p.lower = true
+		case strings.HasPrefix(mod, "pad"):
+			n, err := strconv.Atoi(mod[len("pad"):])
+			if err != nil || n < 0 {
+				return p, fmt.Errorf("invalid modifier %q of {%s}", mod, s)
+			}
+			p.pad = n
+		default:
+			return p, fmt.Errorf("unknown modifier %q of {%s}", mod, s)
+		}
+	}
+	return p, nil
+}
+
+// loadLayout returns the custom layout of the text format, nil for the default.
+func loadLayout() *textLayout {
+	l, _ := layout.Load().(*textLayout)
+	return l
+}
+
+// templateShowsCaller reports whether the template shows the caller, which is
+// then captured even if not reported in the fields.
+func templateShowsCaller() bool {
+	t := loadLayout()
+	return t != nil && t.caller
+}
+
+// render renders the entry with the layout, coloring the level if color is set.
+func (t *textLayout) render(l *ToLog, color bool) string {
+	if t.formatter != nil {
+		return t.formatter(l.entry())
+	}
+	var sb strings.Builder
+	for _, p := range t.template {
+		if p.name == "" {
+			sb.WriteString(p.literal)
+			continue
+		}
+		v := t.value(l, p.name)
+		switch {
+		case p.upper:
+			v = strings.ToUpper(v)
+		case p.lower:
+			v = strings.ToLower(v)
+		}
+		if n := p.pad - len(v); n > 0 {
+			v += strings.Repeat(" ", n)
+		}
+		if p.name == "level" && color {
+			v = levelColor(l.logType) + v + colorReset
+		}
+		sb.WriteString(v)
+	}
+	return sb.String()
+}
+
+// value returns the value of the placeholder for the entry.
+func (t *textLayout) value(l *ToLog, name string) string {
+	switch name {
+	case "time":
+		return l.logTime
+	case "level":
+		return string(l.logType)
+	case "msg":
+		return l.logContext
+	case "fields":
+		fields := l.fields
+		if t.caller {
+			fields = fields.without(CallerField, FunctionField)
+		}
+		return renderFields(fields)
+	case "caller":
+		if l.caller != nil {
+			return l.caller.short()
+		}
+	case "function":
+		if l.caller != nil {
+			return l.caller.function
+		}
+	case "prefix":
+		return l.log().Prefix()
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package tolog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogTemplate(t *testing.T) {
+	color := LogWithColor
+	SetLogWithColor(false)
+	defer SetLogWithColor(color)
+	defer SetLogTemplate("")
+
+	assert.NoError(t, SetLogTemplate("{time} | {level:upper:pad7} | {caller} | {msg} {fields} {{x}}"))
+	l := Warning("disk almost full").WithField("free", "1GB")
+	l.captureSite(0)
+	CreateFullLog(l)
+	parts := strings.Split(l.FullLog, " | ")
+	assert.Len(t, parts, 4)
+	assert.Equal(t, l.logTime, parts[0])
+	assert.Equal(t, "WARNING", parts[1])
+	assert.Regexp(t, `^\w+/template_test\.go:\d+$`, parts[2])
+	assert.Equal(t, "disk almost full free=1GB {x}", parts[3])
+
+	SetLogWithColor(true)
+	assert.Contains(t, l.String(), colorWarningBg+"WARNING"+colorReset)
+	assert.Equal(t, "WARNING", strings.Split(l.FullLog, " | ")[1])
+	SetLogWithColor(false)
+
+	assert.NoError(t, SetLogTemplate(""))
+	assert.Contains(t, Warning("disk almost full").FullLog, "] [warning]  disk almost full")
+
+	for _, bad := range []string{"{nope}", "{level:pad}", "{level:bold}", "{msg", "msg}"} {
+		assert.Error(t, SetLogTemplate(bad), bad)
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	defer SetFormatter(nil)
+	SetFormatter(func(e *Entry) string {
+		return strings.ToUpper(string(e.Level)) + ": " + e.Message
+	})
+	assert.Equal(t, "ERROR: failed", Error("failed").FullLog)
+	assert.Equal(t, "ERROR: failed\n", fileLine(Error("failed")))
+	SetFormatter(nil)
+	assert.Contains(t, Error("failed").FullLog, "] ")
+}
@@ -0,0 +1,74 @@
+package tolog
+
+import (
+	"strconv"
+	"time"
+)
+
+// Numeric time formats, the time since the Unix epoch. The JSON formats write
+// them as numbers, for ingestion pipelines expecting epoch times.
+const (
+	UnixSeconds DateFormat = "unix"
+	UnixMillis  DateFormat = "unixmilli"
+	UnixNanos   DateFormat = "unixnano"
+)
+
+// SetConsoleTimeFormat sets the time format of the entries printed on the
+// console, overriding SetLogTimeFormat, and the time of the JSON format on the
+// console, RFC 3339 otherwise. Empty follows SetLogTimeFormat again.
+func SetConsoleTimeFormat(format DateFormat) {
+	updateSettings(func(s *settings) { s.consoleTimeFormat = format })
+}
+
+// SetFileTimeFormat sets the time format of the entries written to the log
+// files and the outputs, overriding SetLogTimeFormat, and the time of the JSON
+// format there, RFC 3339 otherwise. Empty follows SetLogTimeFormat again.
+func SetFileTimeFormat(format DateFormat) {
+	updateSettings(func(s *settings) { s.fileTimeFormat = format })
+}
+
+// appendTime appends the time in the format, a layout or a numeric format.
+func appendTime(b []byte, t time.Time, format DateFormat) []byte {
+	switch format {
+	case UnixSeconds:
+		return strconv.AppendInt(b, t.Unix(), 10)
+	case UnixMillis:
+		return strconv.AppendInt(b, t.UnixMilli(), 10)
+	case UnixNanos:
+		return strconv.AppendInt(b, t.UnixNano(), 10)
+	}
+	return t.AppendFormat(b, string(format))
+}
+
+// formatTime formats the time like appendTime.
+func formatTime(t time.Time, format DateFormat) string {
+	return string(appendTime(nil, t, format))
+}
+
+// jsonTime returns the time of the JSON formats in the format, a number for the
+// numeric formats and RFC 3339 if empty.
+func jsonTime(t time.Time, format DateFormat) any {
+	switch format {
+	case "":
+		return t.Format(time.RFC3339Nano)
+	case UnixSeconds:
+		return t.Unix()
+	case UnixMillis:
+		return t.UnixMilli()
+	case UnixNanos:
+		return t.UnixNano()
+	}
+	return t.Format(string(format))
+}
+
+// withTimeFormat returns the entry with its time in the format of a sink, a
+// copy unless the format is empty, the sink then uses the time of the entry.
+func withTimeFormat(l *ToLog, format DateFormat) *ToLog {
+	if format == "" {
+		return l
+	}
+	c := *l
+	c.logTime = formatTime(l.time, format)
+	c.timeLayout = format
+	return &c
+}
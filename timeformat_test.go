@@ -0,0 +1,69 @@
+package tolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendTime(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+	assert.Equal(t, "1714566600", formatTime(ts, UnixSeconds))
+	assert.Equal(t, "1714566600123", formatTime(ts, UnixMillis))
+	assert.Equal(t, "1714566600123456789", formatTime(ts, UnixNanos))
+	assert.Equal(t, "2024-05-01 12:30:00", formatTime(ts, DateTime))
+}
+
+func TestSinkTimeFormats(t *testing.T) {
+	lg := NewLogger("TestSinkTimeFormats")
+	defer lg.Close()
+	path := lg.FilePath()
+	defer os.Remove(path)
+	var console bytes.Buffer
+	lg.SetConsole(&console)
+	lg.SetColor(false)
+
+	SetConsoleTimeFormat(TimeOnly)
+	SetFileTimeFormat(UnixMillis)
+	SetFileFormat(FormatJSON)
+	defer SetConsoleTimeFormat("")
+	defer SetFileTimeFormat("")
+	defer SetFileFormat("")
+
+	before := time.Now().UnixMilli()
+	lg.Info("started").PrintAndWriteSafe()
+	require.NoError(t, lg.CloseFile())
+
+	prefix, _, _ := strings.Cut(console.String(), "]")
+	_, err := time.Parse(string(TimeOnly), strings.TrimPrefix(prefix, "["))
+	assert.NoError(t, err, console.String())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var line struct {
+		Time json.Number `json:"time"`
+		Msg  string      `json:"msg"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&line))
+	assert.Equal(t, "started", line.Msg)
+	ms, err := strconv.ParseInt(string(line.Time), 10, 64)
+	require.NoError(t, err, string(content))
+	assert.GreaterOrEqual(t, ms, before)
+}
+
+func TestJSONTimeDefault(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	data := encodeEntryJSON(&Entry{Time: ts, Level: StatusInfo, Message: "m"})
+	assert.Contains(t, string(data), `"time":"2024-05-01T12:30:00Z"`)
+	data = encodeEntryJSONTime(&Entry{Time: ts, Level: StatusInfo, Message: "m"}, UnixSeconds)
+	assert.Contains(t, string(data), `"time":1714566600`)
+}
@@ -0,0 +1,59 @@
+package tolog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var stampAtEmit int32 = 1
+
+// SetTimestampAtEmit sets whether entries get the time their terminator, like
+// PrintLog, WriteSafe or Msg, is called at, the default, or keep the time they
+// were created at by Log or a level function. Entries held in a request buffer
+// or sampled keep the time of their first terminator.
+func SetTimestampAtEmit(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&stampAtEmit, v)
+}
+
+// WithTime sets the time of the entry, for records imported or replayed from
+// elsewhere, like backfilled events, to keep their original time. The entry
+// keeps it when emitted, see SetTimestampAtEmit.
+func WithTime(t time.Time) Options {
+	return func(l *ToLog) {
+		lg := l.log()
+		l.time = t.In(lg.timeZone())
+		l.logTime = formatTime(l.time, lg.timeFormat())
+		l.timed = true
+		CreateFullLog(l)
+	}
+}
+
+// Time returns the time of the entry, for encoders and hooks needing it
+// unformatted. It is the time of the terminator once emitted.
+func (l *ToLog) Time() time.Time {
+	return l.time
+}
+
+// stamp sets the time of the entry to now when stamped at emit, once. The
+// formatted time is only replaced when it changes, without allocating otherwise.
+func (l *ToLog) stamp() {
+	if l.timed {
+		return
+	}
+	l.timed = true
+	if atomic.LoadInt32(&stampAtEmit) == 0 {
+		return
+	}
+	lg := l.log()
+	l.time = time.Now().In(lg.timeZone())
+	buf := getBuffer()
+	defer putBuffer(buf)
+	formatted := appendTime(buf.Bytes(), l.time, lg.timeFormat())
+	if string(formatted) != l.logTime {
+		l.logTime = string(formatted)
+	}
+}
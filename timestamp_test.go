@@ -0,0 +1,52 @@
+package tolog
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestampAtEmit(t *testing.T) {
+	lg := NewLogger("TestTimestampAtEmit")
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+
+	early := func(msg string) *ToLog {
+		l := lg.Info(msg)
+		l.time = l.time.Add(-time.Hour)
+		l.logTime = l.time.Format(string(lg.timeFormat()))
+		return l
+	}
+
+	l := early("built early")
+	created := l.Time()
+	l.PrintLog()
+	assert.True(t, l.Time().After(created.Add(59*time.Minute)))
+	assert.Equal(t, l.Time().Format(string(lg.timeFormat())), l.logTime)
+	assert.Contains(t, l.FullLog, "["+l.logTime+"]")
+
+	emitted := l.Time()
+	l.PrintLog()
+	assert.Equal(t, emitted, l.Time())
+
+	SetTimestampAtEmit(false)
+	defer SetTimestampAtEmit(true)
+	l = early("created time")
+	created = l.Time()
+	l.PrintLog()
+	assert.Equal(t, created, l.Time())
+}
+
+func TestWithTime(t *testing.T) {
+	lg := NewLogger("TestWithTime")
+	defer lg.Close()
+	lg.SetConsole(io.Discard)
+
+	at := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	l := lg.Log(WithTime(at), WithContext("backfilled")).PrintLog()
+	assert.True(t, at.Equal(l.Time()))
+	assert.Equal(t, formatTime(at.In(lg.timeZone()), lg.timeFormat()), l.logTime)
+	assert.Contains(t, l.FullLog, "["+l.logTime+"]")
+}
@@ -1,11 +1,12 @@
 package tolog
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,6 +22,35 @@ const (
 	StatusUnknown LogStatus = "unknown"
 )
 
+// ParseLevel returns the level of the name, case insensitive, or ErrInvalidLevel.
+func ParseLevel(name string) (LogStatus, error) {
+	level := LogStatus(strings.ToLower(name))
+	if !validLevel(level) {
+		return StatusUnknown, fmt.Errorf("%w: %q", ErrInvalidLevel, name)
+	}
+	return level, nil
+}
+
+// validLevel reports whether the level is one of the known levels except unknown.
+func validLevel(level LogStatus) bool {
+	return level == StatusInfo || level == StatusWarning || level == StatusError || level == StatusNotice || level == StatusDebug
+}
+
+// severity orders the levels from debug to error, unknown levels rank as info.
+func severity(level LogStatus) int {
+	switch level {
+	case StatusDebug:
+		return 0
+	case StatusNotice:
+		return 2
+	case StatusWarning:
+		return 3
+	case StatusError:
+		return 4
+	}
+	return 1
+}
+
 type DateFormat string
 
 const (
@@ -46,50 +76,88 @@ const (
 	TimeOnly   DateFormat = "15:04:05"
 )
 
-var logFileDateFormat = DateOnly
-var logTimeFormat = DateTime
-
 var (
 	// Background color codes for different log levels.
 	colorInfoBg    = "\033[48;5;27m"  // blue background
-	colorWarningBg = "\033[48;5;226m" // orange background
+	colorWarningBg = "\033[48;5;226m" // yellow background
 	colorErrorBg   = "\033[48;5;196m" // red background
 	colorDebugBg   = "\033[48;5;45m"  // green background
 	colorNoticeBg  = "\033[48;5;165m" // purple background
 	colorReset     = "\033[0m"        // reset color
 )
 
-// Global variable to store the current log date.
-var currentLogDate string
-
 // LogfilePrefix The prefix of the log file, default is null. Use set prefix to set.
+//
+// Deprecated: use SetLogPrefix or Default().SetPrefix. An assignment made
+// before Init is applied by Init until the next major version.
 var LogfilePrefix = ""
 
 // LogWithColor The variable of whether to use color in the log, default is true.
+//
+// Deprecated: use SetLogWithColor. An assignment made before Init is applied
+// by Init until the next major version, reading it is not synchronized with
+// the setter.
 var LogWithColor = true
 
 // LogTimeZone The time zoon logger will print time at. Default is Local.
+//
+// Deprecated: use SetLogTimeZone. An assignment made before Init is applied by
+// Init until the next major version, reading it is not synchronized with the
+// setter.
 var LogTimeZone = time.Local
 
-// Variables for managing log file and writing to file concurrently.
-var logFile *os.File
-var writeChannel chan string
-var closeChannel chan struct{}
-var isLogFileClosed bool = true
-var wg sync.WaitGroup
-
-// The size of go channel, default 300.
-var channelSize = 300
+// The values of the deprecated variables last set by the setters or applied,
+// guarded by configMu.
+var (
+	knownPrefix = ""
+	knownColor  = true
+	knownZone   = time.Local
+)
 
-// The time of writing to file, default 500ms.
-var logTicker = time.Millisecond * 500
+// adoptDeprecated applies the deprecated variables the application assigned,
+// read once by Init as a fallback of the setters until the next major version.
+func adoptDeprecated() {
+	configMu.Lock()
+	prefix, color, zone := LogfilePrefix, LogWithColor, LogTimeZone
+	setPrefix, setColor, setZone := prefix != knownPrefix, color != knownColor, zone != knownZone
+	knownPrefix, knownColor, knownZone = prefix, color, zone
+	configMu.Unlock()
+	if setColor {
+		diag("config", "LogWithColor assigned %v, use SetLogWithColor", color)
+		SetLogWithColor(color)
+	}
+	if setZone && zone != nil {
+		diag("config", "LogTimeZone assigned %s, use SetLogTimeZone", zone)
+		SetLogTimeZone(zone)
+	}
+	if setPrefix {
+		diag("config", "LogfilePrefix assigned %q, use SetLogPrefix", prefix)
+		SetLogPrefix(prefix)
+	}
+}
 
 // ToLog represents a log entry with various attributes.
+//
+// An entry is frozen once it is written or held in a request buffer: writing
+// it again is fine, but Context, Type, Ctx and the other chained setters then
+// return a modified copy, so nothing already queued can be changed afterwards.
+// An entry must not be used from several goroutines concurrently.
 type ToLog struct {
+	logger     *Logger
 	logType    LogStatus
 	logContext string
 	logTime    string
-	FullLog    string
+	timeLayout DateFormat // the format of logTime in the copy of a sink, empty otherwise
+	time       time.Time
+	caller     *callerInfo
+	fields     Fields
+	group      []string // the path of the Group new fields are added to
+	buffer     *requestBuffer
+	frozen     bool
+	emitted    bool   // a terminator was called, checked by SetEmitCheck
+	timed      bool   // the time is final, stamped by the first terminator
+	summary    bool   // a summary of the sampler or the deduper, never sampled or coalesced itself
+	FullLog    string // the line in the console format, without colors
 }
 
 // Options is a function type for specifying log options using functional options pattern.
@@ -98,7 +166,7 @@ type Options func(l *ToLog)
 // WithType sets the log type using functional options.
 func WithType(level LogStatus) Options {
 	return func(l *ToLog) {
-		if level != StatusInfo && level != StatusWarning && level != StatusError && level != StatusNotice && level != StatusDebug {
+		if !validLevel(level) {
 			level = StatusUnknown
 		}
 		l.logType = level
@@ -113,16 +181,25 @@ func WithContext(ctx string) Options {
 	}
 }
 
-// SetLogWithColor sets the log shows colors or not.
+// SetLogWithColor sets the log shows colors or not, like SetColorMode with
+// ColorAlways or ColorNever.
 func SetLogWithColor(flag bool) {
-	LogWithColor = flag
+	if flag {
+		SetColorMode(ColorAlways)
+		return
+	}
+	SetColorMode(ColorNever)
 }
 
 // SetLogPrefix sets the log file prefix.
 func SetLogPrefix(prefix string) {
-	LogfilePrefix = prefix
-	CloseLogFile()
-	initLog()
+	configMu.Lock()
+	LogfilePrefix, knownPrefix = prefix, prefix
+	configMu.Unlock()
+	std.SetPrefix(prefix)
+	if err := std.fileWriter().open(); err != nil {
+		handleError(err)
+	}
 }
 
 // SetLogChannelSize set the size of go channel for cache.
@@ -130,46 +207,57 @@ func SetLogChannelSize(size int) {
 	if size < 101 {
 		return
 	}
-	channelSize = size
+	atomic.StoreInt64(&queueSize, int64(size))
 }
 
 // SetLogTickerTime set the duration of saving log to file.
 func SetLogTickerTime(duration time.Duration) {
-	logTicker = duration
+	atomic.StoreInt64(&flushInterval, int64(duration))
 }
 
 // SetLogFileDateFormat sets the date format for log file.
 func SetLogFileDateFormat(format DateFormat) {
-	logFileDateFormat = format
+	fileDateFormat.Store(format)
 }
 
 // SetLogTimeFormat sets the date format for log time.
 func SetLogTimeFormat(format DateFormat) {
-	logTimeFormat = format
+	updateSettings(func(s *settings) { s.timeFormat = format })
 }
 
 // SetLogTimeZone sets the time zone for log time.
 func SetLogTimeZone(zone *time.Location) {
-	LogTimeZone = zone
+	updateSettings(func(s *settings) {
+		s.timeZone = zone
+		LogTimeZone, knownZone = zone, zone
+	})
 }
 
 // Log creates a new ToLog instance with default values and applies any specified options.
 func Log(options ...Options) *ToLog {
-	tolog := &ToLog{
-		logType:    StatusInfo,
-		logContext: "",
-		logTime:    time.Now().In(LogTimeZone).Format(string(logTimeFormat)),
-	}
+	return std.Log(options...)
+}
 
-	for _, option := range options {
-		option(tolog)
-	}
+// clone returns an unfrozen copy of the entry with its own fields.
+func (l *ToLog) clone() *ToLog {
+	c := *l
+	c.fields = l.fields.copy()
+	c.frozen = false
+	c.emitted = false
+	return &c
+}
 
-	return tolog
+// mutable returns the entry itself, or a copy of it once it is frozen.
+func (l *ToLog) mutable() *ToLog {
+	if l.frozen {
+		return l.clone()
+	}
+	return l
 }
 
 // Context sets the log context for an existing ToLog instance.
 func (l *ToLog) Context(ctx string) *ToLog {
+	l = l.mutable()
 	l.logContext = ctx
 	CreateFullLog(l)
 	return l
@@ -177,387 +265,376 @@ func (l *ToLog) Context(ctx string) *ToLog {
 
 // Type sets the log type for an existing ToLog instance.
 func (l *ToLog) Type(le string) *ToLog {
-	level := strings.ToLower(le)
-	if level != string(StatusInfo) && level != string(StatusWarning) && level != string(StatusError) && level != string(StatusNotice) && level != string(StatusDebug) {
-		level = string(StatusUnknown)
-	}
-	l.logType = LogStatus(level)
+	l = l.mutable()
+	level, _ := ParseLevel(le)
+	l.logType = level
 	CreateFullLog(l)
 	return l
 }
 
 // Info sets the log type to "info" and sets the log context for an existing ToLog instance.
 func Info(ctx string) *ToLog {
-	l := Log()
-	l.logType = StatusInfo
-	l.logContext = ctx
-	CreateFullLog(l)
-	return l
+	return std.Info(ctx)
 }
 
 // Infof sets the log type to "info" and sets the formatted log context for an existing ToLog instance.
 func Infof(format string, a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusInfo
-	l.logContext = fmt.Sprintf(format, a...)
-	CreateFullLog(l)
-	return l
+	return std.Infof(format, a...)
 }
 
 // Infoln sets the log type to "info" and sets the log context with a newline for an existing ToLog instance.
 func Infoln(a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusInfo
-	l.logContext = fmt.Sprintln(a...)
-	CreateFullLog(l)
-	return l
+	return std.Infoln(a...)
 }
 
 // Warning sets the log type to "warning" and sets the log context for an existing ToLog instance.
 func Warning(ctx string) *ToLog {
-	l := Log()
-	l.logType = StatusWarning
-	l.logContext = ctx
-	CreateFullLog(l)
-	return l
+	return std.Warning(ctx)
 }
 
 // Warningf sets the log type to "warning" and sets the formatted log context for an existing ToLog instance.
 func Warningf(format string, a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusWarning
-	l.logContext = fmt.Sprintf(format, a...)
-	CreateFullLog(l)
-	return l
+	return std.Warningf(format, a...)
 }
 
 // Warningln sets the log type to "warning" and sets the log context with a newline for an existing ToLog instance.
 func Warningln(a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusWarning
-	l.logContext = fmt.Sprintln(a...)
-	CreateFullLog(l)
-	return l
+	return std.Warningln(a...)
 }
 
 // Error sets the log type to "error" and sets the log context for an existing ToLog instance.
 func Error(ctx string) *ToLog {
-	l := Log()
-	l.logType = StatusError
-	l.logContext = ctx
-	CreateFullLog(l)
-	return l
+	return std.Error(ctx)
 }
 
 // Errorf sets the log type to "error" and sets the formatted log context for an existing ToLog instance.
 func Errorf(format string, a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusError
-	l.logContext = fmt.Sprintf(format, a...)
-	CreateFullLog(l)
-	return l
+	return std.Errorf(format, a...)
 }
 
 // Errorln sets the log type to "error" and sets the log context with a newline for an existing ToLog instance.
 func Errorln(a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusError
-	l.logContext = fmt.Sprintln(a...)
-	CreateFullLog(l)
-	return l
+	return std.Errorln(a...)
 }
 
 // Notice sets the log type to "notice" and sets the log context for an existing ToLog instance.
 func Notice(ctx string) *ToLog {
-	l := Log()
-	l.logType = StatusNotice
-	l.logContext = ctx
-	CreateFullLog(l)
-	return l
+	return std.Notice(ctx)
 }
 
 // Noticef sets the log type to "notice" and sets the formatted log context for an existing ToLog instance.
 func Noticef(format string, a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusNotice
-	l.logContext = fmt.Sprintf(format, a...)
-	CreateFullLog(l)
-	return l
+	return std.Noticef(format, a...)
 }
 
 // Noticeln sets the log type to "notice" and sets the log context with a newline for an existing ToLog instance.
 func Noticeln(a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusNotice
-	l.logContext = fmt.Sprintln(a...)
-	CreateFullLog(l)
-	return l
+	return std.Noticeln(a...)
 }
 
 // Debug sets the log type to "debug" and sets the log context for an existing ToLog instance.
 func Debug(ctx string) *ToLog {
-	l := Log()
-	l.logType = StatusDebug
-	l.logContext = ctx
-	CreateFullLog(l)
-	return l
+	return std.Debug(ctx)
 }
 
 // Debugf sets the log type to "debug" and sets the formatted log context for an existing ToLog instance.
 func Debugf(format string, a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusDebug
-	l.logContext = fmt.Sprintf(format, a...)
-	CreateFullLog(l)
-	return l
+	return std.Debugf(format, a...)
 }
 
 // Debugln sets the log type to "debug" and sets the log context with a newline for an existing ToLog instance.
 func Debugln(a ...any) *ToLog {
-	l := Log()
-	l.logType = StatusDebug
-	l.logContext = fmt.Sprintln(a...)
-	CreateFullLog(l)
-	return l
+	return std.Debugln(a...)
+}
+
+// intercept runs before an entry is emitted, reporting whether it must not be
+// emitted now because it is below the level of its logger name, of a trace
+// not sampled, held in a request buffer, rejected by the schema or a filter,
+// sampled out or coalesced.
+func (l *ToLog) intercept(emit func(*ToLog)) bool {
+	if DebugStripped && l.logType == StatusDebug {
+		return true
+	}
+	l.emitted = true
+	if !levelEnabled(l.logType, loggerLevel(l.log().name)) || l.traceUnsampled() {
+		return true
+	}
+	l.stamp()
+	l.captureSite(2)
+	if l.hold(emit) {
+		return true
+	}
+	if !l.checkSchema() {
+		return true
+	}
+	if l.filter() {
+		return true
+	}
+	if l.sample(emit) {
+		return true
+	}
+	if l.dedup(emit) {
+		return true
+	}
+	l.addEntryID()
+	countEntry(l.logType, l.time)
+	return false
 }
 
 // PrintLog prints the full log to the console for an existing ToLog instance.
 func (l *ToLog) PrintLog() *ToLog {
-	CreateFullLog(l)
-	fmt.Println(l.FullLog)
+	if l.intercept(func(c *ToLog) { c.PrintLog() }) {
+		return l
+	}
+	l.emit(TargetConsole|targetOutputs, nil)
 	return l
 }
 
-// CreateFullLog creates the full log message by combining log time, type, and context.
+// CreateFullLog creates the full log message by combining log time, type, and
+// context, in the console format without colors. The console gets the colors
+// of the level when it is printed.
 func CreateFullLog(l *ToLog) {
-	var bgColor string
+	if DebugStripped && l.logType == StatusDebug {
+		return
+	}
+	l.sanitize()
+	lg := l.log()
+	l.FullLog = encodeLine(withTimeFormat(l, lg.consoleTimeFormat()), lg.consoleFormat(), false)
+}
 
-	if !LogWithColor {
-		fullLog := "[" + l.logTime + "] [" + string(l.logType) + "] " + " " + l.logContext
-		l.FullLog = fullLog
+// encodeLine encodes the entry in the format, the text format with colors if color is set.
+func encodeLine(l *ToLog, format LogFormat, color bool) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	appendLine(buf, l, format, color)
+	return buf.String()
+}
+
+// appendLine appends the entry encoded like encodeLine to the buffer.
+func appendLine(buf *bytes.Buffer, l *ToLog, format LogFormat, color bool) {
+	if appendEncoded(buf, l, format) {
 		return
 	}
-	switch l.logType {
+	switch format {
+	case FormatJSON:
+		buf.Write(encodeEntryJSONTime(l.entry(), l.timeLayout))
+	case FormatECS:
+		buf.WriteString(encodeECS(l))
+	case FormatGCP:
+		buf.WriteString(encodeGCP(l))
+	default:
+		if t := loadLayout(); t != nil {
+			buf.WriteString(t.render(l, color))
+			return
+		}
+		appendText(buf, l, color)
+	}
+}
+
+// appendText appends the entry in the default text layout to the buffer, with
+// the color codes of the level only if color is set.
+func appendText(buf *bytes.Buffer, l *ToLog, color bool) {
+	buf.WriteByte('[')
+	buf.WriteString(l.logTime)
+	if color {
+		buf.WriteString("] ")
+		buf.WriteString(levelColor(l.logType))
+		buf.WriteByte(' ')
+		buf.WriteString(string(l.logType))
+		buf.WriteByte(' ')
+		buf.WriteString(colorReset)
+		buf.WriteByte(' ')
+	} else {
+		buf.WriteString("] [")
+		buf.WriteString(string(l.logType))
+		buf.WriteString("]  ")
+	}
+	buf.WriteString(l.logContext)
+	if len(l.fields) > 0 {
+		buf.WriteByte(' ')
+		appendFields(buf, l.fields)
+	}
+}
+
+// levelColor returns the color code of the level, its background color unless
+// set with SetLevelColor.
+func levelColor(level LogStatus) string {
+	if colors, _ := levelColors.Load().(map[LogStatus]string); colors != nil {
+		if code, ok := colors[level]; ok {
+			return code
+		}
+	}
+	switch level {
 	case StatusInfo:
-		bgColor = colorInfoBg
+		return colorInfoBg
 	case StatusWarning:
-		bgColor = colorWarningBg
+		return colorWarningBg
 	case StatusError:
-		bgColor = colorErrorBg
+		return colorErrorBg
 	case StatusDebug:
-		bgColor = colorDebugBg
+		return colorDebugBg
 	case StatusNotice:
-		bgColor = colorNoticeBg
-	default:
-		bgColor = ""
+		return colorNoticeBg
 	}
-
-	fullLog := "[" + l.logTime + "] " + bgColor + " " + string(l.logType) + " " + colorReset + " " + l.logContext
-	l.FullLog = fullLog
-	return
+	return ""
 }
 
 // Deprecated:  WriteSafe instead
 func (l *ToLog) Write() {
-	CreateFullLog(l)
-	if logFile == nil {
-		err := initLog()
-		if err != nil {
-			return
-		}
-	}
-	if LogWithColor {
-		logFile.WriteString(stripColors(l.FullLog) + "\n")
-
-	} else {
-		logFile.WriteString(l.FullLog + "\n")
+	if l.intercept((*ToLog).Write) {
+		return
 	}
-	return
+	l.emit(TargetFile|TargetSinks, (*fileWriter).writeDirect)
 }
 
 // WriteSafe writes the full log to the log file using a concurrent channel.
 func (l *ToLog) WriteSafe() {
-	CreateFullLog(l)
-	if logFile == nil {
-		err := initLog()
-		if err != nil {
-			return
-		}
+	if l.intercept((*ToLog).WriteSafe) {
+		return
+	}
+	l.emit(TargetFile|TargetSinks, (*fileWriter).write)
+}
+
+// WriteSafeCtx writes the full log to the log file like WriteSafe, but gives up
+// waiting for room in a full queue when the context is done, e.g. at the
+// deadline of a request, dropping the entry and counting it in Dropped.
+func (l *ToLog) WriteSafeCtx(ctx context.Context) {
+	if l.intercept(func(l *ToLog) { l.WriteSafeCtx(ctx) }) {
+		return
 	}
-	writeChannel <- l.FullLog + "\n"
+	l.emit(TargetFile|TargetSinks, func(w *fileWriter, item writeItem) error {
+		return w.writeUntil(ctx.Done(), item)
+	})
 }
 
 // Deprecated:  PrintAndWriteSafe instead
 func (l *ToLog) PrintAndWrite() {
-	CreateFullLog(l)
-	fmt.Println(l.FullLog)
-	if logFile == nil || writeChannel == nil {
-		err := initLog()
-		if err != nil {
-			return
-		}
+	if l.intercept((*ToLog).PrintAndWrite) {
+		return
 	}
-	if LogWithColor {
-		logFile.WriteString(stripColors(l.FullLog) + "\n")
+	l.emit(TargetAll, (*fileWriter).writeDirect)
+}
 
-	} else {
-		logFile.WriteString(l.FullLog + "\n")
+func (l *ToLog) PrintAndWriteSafe() {
+	if l.intercept((*ToLog).PrintAndWriteSafe) {
+		return
 	}
-	return
+	l.emit(TargetAll, (*fileWriter).write)
 }
 
-func (l *ToLog) PrintAndWriteSafe() {
+// emit encodes the entry and writes it to the targets, to the log file with
+// write. The outputs get the entries of the file, and those printed with
+// targetOutputs. The console and the file only get the entries of their
+// minimum level.
+func (l *ToLog) emit(targets Target, write func(w *fileWriter, item writeItem) error) {
+	l.resolve()
 	CreateFullLog(l)
-	fmt.Println(l.FullLog)
-	if logFile == nil {
-		err := initLog()
-		if err != nil {
-			return
+	lg := l.log()
+	fileLevelOK := levelEnabled(l.logType, fileLevel())
+	toFile := fileLevelOK && targets&TargetFile != 0
+	if fileLevelOK && targets&(TargetFile|targetOutputs) != 0 {
+		lg.writeOutputs(l, fileLine(l))
+	}
+	// with SetAsyncConsole the console line is queued with the file line
+	var printed writeItem
+	if targets&TargetConsole != 0 && levelEnabled(l.logType, consoleLevel()) {
+		if atomic.LoadInt32(&asyncConsole) == 1 {
+			printed = writeItem{level: l.logType, printer: lg.root(), console: lg.printedLine(l)}
+		} else {
+			lg.print(l)
 		}
 	}
-	writeChannel <- l.FullLog + "\n"
-}
-
-// writeToFile is a goroutine that continuously writes log entries to the log file using the channel.
-func writeToFile() {
-	defer wg.Done()
-	buffer := []string{}
-	ticker := time.NewTicker(logTicker)
-	defer ticker.Stop()
-	for {
-		select {
-		case logEntry := <-writeChannel:
-			buffer = append(buffer, logEntry)
-			if len(buffer) >= 100 {
-				flushBuffer(&buffer)
-			}
-		case <-ticker.C:
-			if len(buffer) > 0 {
-				flushBuffer(&buffer)
-			}
-		case <-closeChannel:
-			if len(buffer) > 0 {
-				flushBuffer(&buffer)
-			}
-
-			for len(writeChannel) > 0 {
-				logEntry := <-writeChannel
-				buffer = append(buffer, logEntry)
-				if len(buffer) >= 100 {
-					flushBuffer(&buffer)
-				}
-			}
-
-			if len(buffer) > 0 {
-				flushBuffer(&buffer)
+	if targets&TargetFile == 0 {
+		lg.printQueued(l, printed)
+		if targets&TargetSinks != 0 {
+			l.dispatch()
+		}
+		return
+	}
+	l.frozen = true
+	if toFile {
+		item := l.writeItem()
+		item.printer, item.console = printed.printer, printed.console
+		if err := lg.writeFile(write, item); err != nil {
+			handleError(err)
+			if item.console != "" {
+				lg.print(l)
 			}
-
 			return
 		}
+		// the flush hooks get the entry and the console its line from the main file only
+		item.entry, item.console = nil, ""
+		for _, w := range lg.routeWriters(l.logType) {
+			if err := write(w, item); err != nil {
+				handleError(err)
+			}
+		}
+	} else {
+		lg.printQueued(l, printed)
 	}
-}
-
-// flushBuffer writes the contents of the buffer to the log file.
-func flushBuffer(buffer *[]string) {
-	checkLogFileDate()
-	data := strings.Join(*buffer, "")
-	if LogWithColor {
-		data = stripColors(data)
-	}
-	_, err := logFile.WriteString(data)
-	if err != nil {
-		fmt.Println("[error]", err)
-		return
-	}
-	*buffer = (*buffer)[:0]
-}
-
-// checkLogFileDate can change file over a day
-func checkLogFileDate() {
-	currentDay := time.Now().In(LogTimeZone).Format(string(logFileDateFormat))
-	if currentLogDate != currentDay {
-		CloseLogFile()
-		initLog()
+	if targets&TargetSinks != 0 {
+		l.dispatch()
 	}
 }
 
-// initLog initializes the log file and sets up the writeToFile goroutine.
-func initLog() error {
-	currentDay := time.Now().In(LogTimeZone).Format(string(logFileDateFormat))
-	logFilePath := ""
-	if LogfilePrefix != "" {
-		logFilePath = "./logs/" + LogfilePrefix + "-log-" + currentDay + ".log"
-	} else {
-		logFilePath = "./logs/log-" + currentDay + ".log"
-	}
-	currentLogDate = currentDay
-
-	// Create the logs directory if it doesn't exist
-	logDir := "./logs"
-	if _, err := os.Stat(logDir); os.IsNotExist(err) {
-		err = os.Mkdir(logDir, 0755)
-		if err != nil {
-			fmt.Println("[error] Failed to create logs directory:", err)
-			return err
+// writeFile writes the item to the file of the logger with write, again to the
+// new file if the prefix of the logger changed meanwhile.
+func (lg *Logger) writeFile(write func(w *fileWriter, item writeItem) error, item writeItem) error {
+	w := lg.fileWriter()
+	err := write(w, item)
+	if errors.Is(err, ErrClosed) {
+		if current := lg.fileWriter(); current != w {
+			err = write(current, item)
 		}
 	}
-
-	file, err := os.OpenFile(logFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
-	if err != nil {
-		fmt.Println("[error]", err)
-		return err
-	}
-	logFile = file
-
-	isLogFileClosed = false
-
-	writeChannel = make(chan string, channelSize)
-	closeChannel = make(chan struct{})
-	wg.Add(1)
-	go writeToFile()
-
-	return nil
+	return err
 }
 
-// CloseLogFile closes the log file.
-func CloseLogFile() {
-	if logFile == nil || isLogFileClosed {
-		return
-	}
-
-	close(closeChannel)
-
-	if writeChannel != nil { // wait the writeToFile goroutine to finish
-		close(writeChannel)
+// log returns the logger of the entry.
+func (l *ToLog) log() *Logger {
+	if l.logger == nil {
+		return std
 	}
-
-	wg.Wait() // wait the writeToFile goroutine to finish
-
-	err := logFile.Close()
-	if err != nil {
-		log.Fatal("Failed to close log file:", err)
-		return
-	}
-	isLogFileClosed = true
-	logFile = nil
+	return l.logger
 }
 
-var replacements = []struct {
-	old string
-	new string
-}{
-	{colorInfoBg, ""},
-	{colorWarningBg, ""},
-	{colorErrorBg, ""},
-	{colorDebugBg, ""},
-	{colorNoticeBg, ""},
-	{colorReset, ""},
+// consoleLine returns the line printed to the console for the entry, the full
+// log with the colors of the level when enabled.
+func consoleLine(l *ToLog) string {
+	lg := l.log()
+	if !lg.withColor() {
+		return l.FullLog
+	}
+	return encodeLine(withTimeFormat(l, lg.consoleTimeFormat()), lg.consoleFormat(), true)
 }
 
-// stripColors removes ANSI color codes from a string
-func stripColors(log string) string {
-	for _, r := range replacements {
-		log = strings.ReplaceAll(log, r.old, r.new)
+// fileLine returns the line written to the log file for the entry.
+func fileLine(l *ToLog) string {
+	lg := l.log()
+	format := lg.fileFormat()
+	layout := lg.fileTimeFormat()
+	if format == lg.consoleFormat() && layout == lg.consoleTimeFormat() {
+		return l.FullLog + "\n"
 	}
-	return log
+	buf := getBuffer()
+	defer putBuffer(buf)
+	appendLine(buf, withTimeFormat(l, layout), format, false)
+	buf.WriteByte('\n')
+	return buf.String()
+}
+
+// coloredFileLine returns the line of the file for the entry with the colors of its level.
+func coloredFileLine(l *ToLog) string {
+	lg := l.log()
+	buf := getBuffer()
+	defer putBuffer(buf)
+	appendLine(buf, withTimeFormat(l, lg.fileTimeFormat()), lg.fileFormat(), true)
+	buf.WriteByte('\n')
+	return buf.String()
+}
+
+// CloseLogFile closes the log file, returning the error of closing it.
+func CloseLogFile() error {
+	writeShutdownReport()
+	return std.CloseFile()
 }
@@ -1,8 +1,11 @@
 package tolog
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -14,6 +17,23 @@ import (
 
 var timeZone, _ = time.LoadLocation("Asia/Shanghai")
 
+// testLogDir holds the log files of the tests, removed after them.
+var testLogDir string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "tolog-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	testLogDir = dir
+	SetLogDir(dir)
+	code := m.Run()
+	CloseLogFile()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 // TestToLog tests the ToLog package.
 func TestToLog(t *testing.T) {
 	SetLogTimeZone(timeZone)
@@ -24,7 +44,7 @@ func TestToLog(t *testing.T) {
 
 func LevelLogInsert(t *testing.T) {
 	logPrefix := "TestLevelInsert"
-	logFilePath := "./logs/" + logPrefix + "-log-" + time.Now().Format(string(DateOnly)) + ".log"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
 	cleanLogFiles(t, logFilePath)
 	SetLogPrefix(logPrefix)
 
@@ -58,7 +78,7 @@ func LevelLogInsertTest(t *testing.T) {
 // TestLogFunction tests the logging functionality.
 func ManyLogInsert(t *testing.T) {
 	logPrefix := "TestManyInsert"
-	logFilePath := "./logs/" + logPrefix + "-log-" + time.Now().Format(string(DateOnly)) + ".log"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
 	cleanLogFiles(t, logFilePath)
 	SetLogPrefix(logPrefix)
 
@@ -97,7 +117,7 @@ func Log5kInsert(k int) {
 
 func SingleLogInsert(t *testing.T) {
 	logPrefix := "TestSingleInsert"
-	logFilePath := "./logs/" + logPrefix + "-log-" + time.Now().Format(string(DateOnly)) + ".log"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
 	cleanLogFiles(t, logFilePath)
 	SetLogPrefix(logPrefix)
 
@@ -124,3 +144,45 @@ func checkMessageExistInFile(t *testing.T, filePath string, message string) {
 func cleanLogFiles(t *testing.T, filePath string) {
 	os.Remove(filePath)
 }
+
+// TestEntryFreeze tests that written entries cannot be changed afterwards, run it with -race.
+func TestEntryFreeze(t *testing.T) {
+	logPrefix := "TestEntryFreeze"
+	logFilePath := filepath.Join(testLogDir, logPrefix+"-log-"+currentDay()+".log")
+	cleanLogFiles(t, logFilePath)
+	SetLogPrefix(logPrefix)
+
+	l := Info("original")
+	l.WriteSafe()
+	changed := l.Context("changed").Type("error")
+	assert.NotSame(t, l, changed)
+	assert.Contains(t, l.FullLog, "original")
+	assert.Equal(t, StatusInfo, l.logType)
+	assert.Contains(t, changed.FullLog, "changed")
+
+	unwritten := Info("unwritten")
+	assert.Same(t, unwritten, unwritten.Context("still the same"))
+
+	ctx := WithRequestBuffer(context.Background(), RequestBufferOptions{})
+	held := DebugCtx(ctx, "held original")
+	held.WriteSafe()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		EndRequest(ctx, errors.New("failed"))
+	}()
+	for i := 0; i < 100; i++ {
+		held.Context(fmt.Sprintf("mutated %d", i))
+	}
+	wg.Wait()
+	CloseLogFile()
+
+	checkMessageExistInFile(t, logFilePath, "original")
+	if !DebugStripped {
+		checkMessageExistInFile(t, logFilePath, "held original")
+	}
+	content, err := os.ReadFile(logFilePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "mutated")
+}
@@ -0,0 +1,192 @@
+package tologtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+)
+
+// Matcher matches entries in the assertions, described by its String in their
+// failure messages. Matchers are values, to be listed in table-driven tests.
+type Matcher struct {
+	desc  string
+	match func(e *tolog.Entry) bool
+}
+
+// Matches returns a matcher of the function, described as desc.
+func Matches(desc string, fn func(e *tolog.Entry) bool) Matcher {
+	return Matcher{desc: desc, match: fn}
+}
+
+// Match reports whether the entry matches.
+func (m Matcher) Match(e *tolog.Entry) bool {
+	return m.match == nil || m.match(e)
+}
+
+// String returns the description of the matcher.
+func (m Matcher) String() string {
+	return m.desc
+}
+
+// Level matches the entries of the level.
+func Level(level tolog.LogStatus) Matcher {
+	return Matches("level "+string(level), func(e *tolog.Entry) bool { return e.Level == level })
+}
+
+// Message matches the entries with exactly the message.
+func Message(msg string) Matcher {
+	return Matches(fmt.Sprintf("message %q", msg), func(e *tolog.Entry) bool { return e.Message == msg })
+}
+
+// MessageContains matches the entries whose message contains the substring.
+func MessageContains(substr string) Matcher {
+	return Matches(fmt.Sprintf("message containing %q", substr), func(e *tolog.Entry) bool {
+		return strings.Contains(e.Message, substr)
+	})
+}
+
+// HasKey matches the entries with the field, whatever its value. Fields of
+// groups are named with dotted keys, like http.status.
+func HasKey(key string) Matcher {
+	return Matches("field "+key, func(e *tolog.Entry) bool {
+		_, ok := fieldValue(e.Fields, key)
+		return ok
+	})
+}
+
+// HasField matches the entries with the field of the value, equal when deeply
+// equal or formatted alike, so HasField("user_id", 42) matches an int64 42.
+// Fields of groups are named with dotted keys, like http.status.
+func HasField(key string, value any) Matcher {
+	return Matches(fmt.Sprintf("field %s=%v", key, value), func(e *tolog.Entry) bool {
+		v, ok := fieldValue(e.Fields, key)
+		return ok && (reflect.DeepEqual(v, value) || fmt.Sprint(v) == fmt.Sprint(value))
+	})
+}
+
+// ShutdownReport matches the report of tolog.SetShutdownReport.
+func ShutdownReport() Matcher {
+	return Matches("shutdown report", func(e *tolog.Entry) bool {
+		return e.Level == tolog.StatusNotice && e.Message == tolog.ShutdownReportMessage
+	})
+}
+
+// All matches the entries matching every matcher.
+func All(matchers ...Matcher) Matcher {
+	descs := make([]string, len(matchers))
+	for i, m := range matchers {
+		descs[i] = m.String()
+	}
+	return Matches(strings.Join(descs, " and "), func(e *tolog.Entry) bool {
+		for _, m := range matchers {
+			if !m.Match(e) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// fieldValue returns the field of the key, looking into the groups for dotted keys.
+func fieldValue(fields tolog.Fields, key string) (any, bool) {
+	if v, ok := fields[key]; ok {
+		return v, true
+	}
+	group, rest, ok := strings.Cut(key, ".")
+	if !ok {
+		return nil, false
+	}
+	nested, ok := fields[group].(tolog.Fields)
+	if !ok {
+		return nil, false
+	}
+	return fieldValue(nested, rest)
+}
+
+// AssertLoggedInOrder fails the test unless the entries hold an entry for each
+// matcher in the order of the matchers, other entries may come in between.
+// It returns whether the assertion passed.
+func AssertLoggedInOrder(t testing.TB, entries []*tolog.Entry, matchers ...Matcher) bool {
+	t.Helper()
+	next := 0
+	for i, m := range matchers {
+		found := false
+		for ; next < len(entries); next++ {
+			if m.Match(entries[next]) {
+				found = true
+				next++
+				break
+			}
+		}
+		if !found {
+			t.Errorf("no entry with %s after the entries matching the %d matchers before it in:\n%s", m, i, formatEntries(entries))
+			return false
+		}
+	}
+	return true
+}
+
+// AssertLogged fails the test unless an entry matches every matcher.
+// It returns whether the assertion passed.
+func AssertLogged(t testing.TB, entries []*tolog.Entry, matchers ...Matcher) bool {
+	t.Helper()
+	m := All(matchers...)
+	for _, e := range entries {
+		if m.Match(e) {
+			return true
+		}
+	}
+	t.Errorf("no entry with %s in:\n%s", m, formatEntries(entries))
+	return false
+}
+
+// AssertNotLogged fails the test if an entry matches every matcher.
+// It returns whether the assertion passed.
+func AssertNotLogged(t testing.TB, entries []*tolog.Entry, matchers ...Matcher) bool {
+	t.Helper()
+	m := All(matchers...)
+	for _, e := range entries {
+		if m.Match(e) {
+			t.Errorf("unexpected entry with %s: %s", m, formatEntry(e))
+			return false
+		}
+	}
+	return true
+}
+
+// AssertNoErrors fails the test for every error entry.
+// It returns whether the assertion passed.
+func AssertNoErrors(t testing.TB, entries []*tolog.Entry) bool {
+	t.Helper()
+	passed := true
+	for _, e := range entries {
+		if e.Level == tolog.StatusError {
+			t.Errorf("unexpected error entry: %s", formatEntry(e))
+			passed = false
+		}
+	}
+	return passed
+}
+
+// formatEntries formats the entries one per line for failure messages.
+func formatEntries(entries []*tolog.Entry) string {
+	if len(entries) == 0 {
+		return "\t(no entries)"
+	}
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = fmt.Sprintf("\t%d: %s", i, formatEntry(e))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatEntry formats the level, message and fields of the entry.
+func formatEntry(e *tolog.Entry) string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("[%s] %q", e.Level, e.Message)
+	}
+	return fmt.Sprintf("[%s] %q %v", e.Level, e.Message, e.Fields)
+}
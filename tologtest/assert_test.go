@@ -0,0 +1,77 @@
+package tologtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+)
+
+// failures records the failures of an assertion instead of failing the test.
+type failures struct {
+	testing.TB
+	errors []string
+}
+
+func (f *failures) Helper() {}
+
+func (f *failures) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertions(t *testing.T) {
+	entries := []*tolog.Entry{
+		{Level: tolog.StatusInfo, Message: "order received", Fields: tolog.Fields{"user_id": int64(42)}},
+		{Level: tolog.StatusDebug, Message: "cache miss"},
+		{Level: tolog.StatusInfo, Message: "order placed", Fields: tolog.Fields{"http": tolog.Fields{"status": 201}}},
+	}
+
+	tests := []struct {
+		name   string
+		assert func(t testing.TB) bool
+		passes bool
+	}{
+		{"in order", func(t testing.TB) bool {
+			return AssertLoggedInOrder(t, entries, HasField("user_id", 42), Message("order placed"))
+		}, true},
+		{"out of order", func(t testing.TB) bool {
+			return AssertLoggedInOrder(t, entries, Message("order placed"), Message("order received"))
+		}, false},
+		{"same entry twice", func(t testing.TB) bool {
+			return AssertLoggedInOrder(t, entries, Message("cache miss"), Level(tolog.StatusDebug))
+		}, false},
+		{"grouped field", func(t testing.TB) bool {
+			return AssertLogged(t, entries, Level(tolog.StatusInfo), HasField("http.status", 201))
+		}, true},
+		{"missing field", func(t testing.TB) bool {
+			return AssertLogged(t, entries, HasKey("order_id"))
+		}, false},
+		{"not logged", func(t testing.TB) bool {
+			return AssertNotLogged(t, entries, MessageContains("failed"))
+		}, true},
+		{"logged", func(t testing.TB) bool {
+			return AssertNotLogged(t, entries, MessageContains("cache"))
+		}, false},
+		{"no errors", func(t testing.TB) bool {
+			return AssertNoErrors(t, entries)
+		}, true},
+		{"shutdown report", func(t testing.TB) bool {
+			report := &tolog.Entry{Level: tolog.StatusNotice, Message: tolog.ShutdownReportMessage, Fields: tolog.Fields{"entries": tolog.Fields{"info": uint64(2)}}}
+			return AssertLogged(t, append(entries, report), All(ShutdownReport(), HasField("entries.info", 2))) && AssertNoErrors(t, append(entries, report))
+		}, true},
+		{"errors", func(t testing.TB) bool {
+			return AssertNoErrors(t, append(entries, &tolog.Entry{Level: tolog.StatusError, Message: "payment failed"}))
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &failures{TB: t}
+			if got := tt.assert(f); got != tt.passes {
+				t.Fatalf("assertion returned %v, want %v", got, tt.passes)
+			}
+			if tt.passes != (len(f.errors) == 0) {
+				t.Errorf("failures %q", f.errors)
+			}
+		})
+	}
+}
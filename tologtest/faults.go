@@ -0,0 +1,175 @@
+package tologtest
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/callme-taota/tolog"
+)
+
+// ErrInjected is the error of the failures injected by FaultySink and
+// FaultyWriter when Faults.Err is nil.
+var ErrInjected = errors.New("tologtest: injected fault")
+
+// Faults configures the failures FaultySink and FaultyWriter inject, to check
+// how the code around a sink or a writer holds up when it misbehaves.
+type Faults struct {
+	// ErrorRate is the fraction of the writes failing with Err, from 0 to 1.
+	ErrorRate float64
+	// Err is the error of the failed writes, ErrInjected if nil.
+	Err error
+	// PartialRate is the fraction of the writes cut short: a writer writes
+	// half of the bytes and a sink passes on the entry with half of its
+	// message, both failing with io.ErrShortWrite.
+	PartialRate float64
+	// Latency delays every write, with up to Jitter more picked at random.
+	Latency time.Duration
+	Jitter  time.Duration
+	// Seed seeds the random choices, so a failing test can be rerun alike.
+	Seed int64
+}
+
+// faults picks the failures of the writes, shared by FaultySink and FaultyWriter.
+type faults struct {
+	mu       sync.Mutex
+	f        Faults
+	rnd      *rand.Rand
+	injected int
+}
+
+func (fs *faults) set(f Faults) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.f = f
+	fs.rnd = rand.New(rand.NewSource(f.Seed))
+}
+
+// next sleeps the latency and returns the failure of the next write: whether
+// it is partial, and the error.
+func (fs *faults) next() (bool, error) {
+	fs.mu.Lock()
+	f := fs.f
+	delay := f.Latency
+	if f.Jitter > 0 {
+		delay += time.Duration(fs.rnd.Int63n(int64(f.Jitter) + 1))
+	}
+	roll := fs.rnd.Float64()
+	failed := roll < f.ErrorRate
+	partial := !failed && roll < f.ErrorRate+f.PartialRate
+	if failed || partial {
+		fs.injected++
+	}
+	fs.mu.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	switch {
+	case failed && f.Err != nil:
+		return false, f.Err
+	case failed:
+		return false, ErrInjected
+	case partial:
+		return true, io.ErrShortWrite
+	}
+	return false, nil
+}
+
+func (fs *faults) count() int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.injected
+}
+
+// FaultySink wraps a sink, injecting the failures of its Faults into the
+// entries written to it.
+type FaultySink struct {
+	sink   tolog.Sink
+	faults faults
+}
+
+var _ tolog.Sink = (*FaultySink)(nil)
+
+// NewFaultySink returns a sink writing to sink with the failures of f.
+func NewFaultySink(sink tolog.Sink, f Faults) *FaultySink {
+	s := &FaultySink{sink: sink}
+	s.faults.set(f)
+	return s
+}
+
+// SetFaults replaces the failures, e.g. to end an outage, restarting the
+// random choices from the seed.
+func (s *FaultySink) SetFaults(f Faults) {
+	s.faults.set(f)
+}
+
+// Injected returns the number of writes failed or cut short so far.
+func (s *FaultySink) Injected() int {
+	return s.faults.count()
+}
+
+// WriteEntry writes the entry to the wrapped sink unless the write fails. A
+// partial write passes on a copy of the entry with half of its message.
+func (s *FaultySink) WriteEntry(e *tolog.Entry) error {
+	partial, err := s.faults.next()
+	if err != nil && !partial {
+		return err
+	}
+	if partial {
+		cut := *e
+		cut.Message = cut.Message[:len(cut.Message)/2]
+		e = &cut
+	}
+	if werr := s.sink.WriteEntry(e); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// Close closes the wrapped sink.
+func (s *FaultySink) Close() error {
+	return s.sink.Close()
+}
+
+// FaultyWriter wraps a writer, like a tolog output or the connection of a
+// sink, injecting the failures of its Faults into the writes.
+type FaultyWriter struct {
+	w      io.Writer
+	faults faults
+}
+
+// NewFaultyWriter returns a writer writing to w with the failures of f.
+func NewFaultyWriter(w io.Writer, f Faults) *FaultyWriter {
+	fw := &FaultyWriter{w: w}
+	fw.faults.set(f)
+	return fw
+}
+
+// SetFaults replaces the failures, restarting the random choices from the seed.
+func (fw *FaultyWriter) SetFaults(f Faults) {
+	fw.faults.set(f)
+}
+
+// Injected returns the number of writes failed or cut short so far.
+func (fw *FaultyWriter) Injected() int {
+	return fw.faults.count()
+}
+
+// Write writes p to the wrapped writer unless the write fails. A partial
+// write writes the first half of p.
+func (fw *FaultyWriter) Write(p []byte) (int, error) {
+	partial, err := fw.faults.next()
+	if err != nil && !partial {
+		return 0, err
+	}
+	if partial {
+		n, werr := fw.w.Write(p[:len(p)/2])
+		if werr != nil {
+			return n, werr
+		}
+		return n, err
+	}
+	return fw.w.Write(p)
+}
@@ -0,0 +1,75 @@
+package tologtest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/callme-taota/tolog"
+)
+
+func TestFaultySink(t *testing.T) {
+	rec := &Recorder{}
+	sink := NewFaultySink(rec, Faults{ErrorRate: 1})
+	if err := sink.WriteEntry(&tolog.Entry{Message: "lost"}); !errors.Is(err, ErrInjected) {
+		t.Errorf("WriteEntry = %v, want ErrInjected", err)
+	}
+	if len(rec.Entries()) != 0 {
+		t.Errorf("failed write reached the sink: %s", formatEntries(rec.Entries()))
+	}
+
+	outage := errors.New("outage")
+	sink.SetFaults(Faults{ErrorRate: 1, Err: outage})
+	if err := sink.WriteEntry(&tolog.Entry{Message: "lost"}); err != outage {
+		t.Errorf("WriteEntry = %v, want the configured error", err)
+	}
+
+	sink.SetFaults(Faults{PartialRate: 1})
+	if err := sink.WriteEntry(&tolog.Entry{Message: "truncated"}); err != io.ErrShortWrite {
+		t.Errorf("WriteEntry = %v, want io.ErrShortWrite", err)
+	}
+	if entries := rec.Entries(); len(entries) != 1 || entries[0].Message != "trun" {
+		t.Errorf("partial write recorded %s, want the message cut in half", formatEntries(entries))
+	}
+
+	sink.SetFaults(Faults{ErrorRate: 0.5, Seed: 7})
+	failed := 0
+	for i := 0; i < 1000; i++ {
+		if sink.WriteEntry(&tolog.Entry{Message: "maybe"}) != nil {
+			failed++
+		}
+	}
+	if failed < 400 || failed > 600 {
+		t.Errorf("%d of 1000 writes failed at a rate of 0.5", failed)
+	}
+	if got := sink.Injected(); got != failed+3 {
+		t.Errorf("Injected = %d, want %d", got, failed+3)
+	}
+
+	sink.SetFaults(Faults{Latency: 20 * time.Millisecond})
+	start := time.Now()
+	if err := sink.WriteEntry(&tolog.Entry{Message: "slow"}); err != nil {
+		t.Errorf("WriteEntry = %v", err)
+	}
+	if took := time.Since(start); took < 20*time.Millisecond {
+		t.Errorf("write took %s, want the latency of 20ms", took)
+	}
+}
+
+func TestFaultyWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewFaultyWriter(&buf, Faults{PartialRate: 1})
+	n, err := w.Write([]byte("abcdef"))
+	if n != 3 || err != io.ErrShortWrite || buf.String() != "abc" {
+		t.Errorf("Write = %d, %v, wrote %q, want half of the bytes and io.ErrShortWrite", n, err, buf.String())
+	}
+	w.SetFaults(Faults{})
+	if n, err := w.Write([]byte("gh")); n != 2 || err != nil || buf.String() != "abcgh" {
+		t.Errorf("Write = %d, %v, wrote %q without faults", n, err, buf.String())
+	}
+	if w.Injected() != 1 {
+		t.Errorf("Injected = %d, want 1", w.Injected())
+	}
+}
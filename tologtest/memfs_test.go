@@ -0,0 +1,51 @@
+package tologtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+)
+
+func TestMemFS(t *testing.T) {
+	fsys := NewMemFS()
+	tolog.SetFS(fsys)
+	defer tolog.SetFS(nil)
+	tolog.SetMaxFileSize(200)
+	defer tolog.SetMaxFileSize(0)
+
+	lg := tolog.NewLogger("TestMemFS")
+	path := lg.FilePath()
+	for i := 0; i < 10; i++ {
+		lg.Infof("in memory %d", i).WriteSafe()
+		lg.Flush()
+	}
+	lg.CloseFile()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("log file %s written to disk", path)
+	}
+	files := fsys.Files()
+	if len(files) < 2 {
+		t.Fatalf("no rotated files in %v", files)
+	}
+	var all string
+	for _, f := range files {
+		if filepath.Dir(f) != filepath.Dir(filepath.Clean(path)) {
+			t.Errorf("file %s outside the log directory", f)
+		}
+		data, err := fsys.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all += string(data)
+	}
+	for i := 0; i < 10; i++ {
+		if !strings.Contains(all, fmt.Sprintf("in memory %d\n", i)) {
+			t.Errorf("line %d missing from the files:\n%s", i, all)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package tologtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+)
+
+// Recorder is a sink keeping the written entries in memory, so tests can check
+// what was logged without reading the log files back.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []*tolog.Entry
+}
+
+var _ tolog.Sink = (*Recorder)(nil)
+
+// NewRecorder returns a recorder registered as a sink, receiving the entries
+// written by every logger until it is closed. Entries only printed to the
+// console aren't recorded.
+func NewRecorder() *Recorder {
+	r := &Recorder{}
+	tolog.AddSink(r)
+	return r
+}
+
+// WriteEntry records the entry.
+func (r *Recorder) WriteEntry(e *tolog.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+// Close unregisters the recorder, the recorded entries stay.
+func (r *Recorder) Close() error {
+	tolog.RemoveSink(r)
+	return nil
+}
+
+// Entries returns the recorded entries in the order they were written, to
+// pass to the assertions of this package.
+func (r *Recorder) Entries() []*tolog.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*tolog.Entry(nil), r.entries...)
+}
+
+// Reset forgets the recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// Contains reports whether an entry of the level has a message containing the
+// substring. An empty level matches all levels.
+func (r *Recorder) Contains(level tolog.LogStatus, substr string) bool {
+	for _, e := range r.Entries() {
+		if (level == "" || e.Level == level) && strings.Contains(e.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AssertContains fails the test unless an entry of the level has a message
+// containing the substring. It returns whether the assertion passed.
+func (r *Recorder) AssertContains(t testing.TB, level tolog.LogStatus, substr string) bool {
+	t.Helper()
+	matchers := []Matcher{MessageContains(substr)}
+	if level != "" {
+		matchers = append(matchers, Level(level))
+	}
+	return AssertLogged(t, r.Entries(), matchers...)
+}
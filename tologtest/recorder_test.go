@@ -0,0 +1,39 @@
+package tologtest
+
+import (
+	"testing"
+
+	"github.com/callme-taota/tolog"
+)
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder()
+	defer rec.Close()
+	lg := tolog.NewLogger("TestRecorder")
+	defer lg.Close()
+
+	lg.Info("order received").WithField("user_id", 42).WriteSafe()
+	lg.Error("payment declined").WriteSafe()
+	lg.Warning("printed only").PrintLog()
+
+	if !rec.Contains(tolog.StatusError, "declined") || !rec.Contains("", "order") {
+		t.Errorf("recorded entries missing: %s", formatEntries(rec.Entries()))
+	}
+	if rec.Contains(tolog.StatusInfo, "declined") || rec.Contains("", "printed only") {
+		t.Errorf("unexpected entries recorded: %s", formatEntries(rec.Entries()))
+	}
+	rec.AssertContains(t, tolog.StatusInfo, "received")
+	AssertLoggedInOrder(t, rec.Entries(), HasField("user_id", 42), Level(tolog.StatusError))
+
+	f := &failures{TB: t}
+	if rec.AssertContains(f, tolog.StatusDebug, "order") || len(f.errors) != 1 {
+		t.Errorf("AssertContains passed for a missing entry: %v", f.errors)
+	}
+
+	rec.Reset()
+	rec.Close()
+	lg.Info("after close").WriteSafe()
+	if len(rec.Entries()) != 0 {
+		t.Errorf("entries recorded after Reset and Close: %s", formatEntries(rec.Entries()))
+	}
+}
@@ -0,0 +1,118 @@
+package tologtest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+)
+
+var update = flag.Bool("update", false, "update the golden files of tologtest.Snapshot")
+
+// Snapshot compares the entries with the golden file of the test,
+// testdata/<test name>.golden, failing the test when they differ. Run the tests
+// with -update to write the golden files instead. Entries are normalized to
+// their level, message and fields sorted by key, without their time, and the
+// fields listed in ignore, like request IDs, are left out, as is the shutdown
+// report of tolog.SetShutdownReport whose counters vary from run to run.
+func Snapshot(t testing.TB, entries []*tolog.Entry, ignore ...string) {
+	t.Helper()
+	path := filepath.Join("testdata", filepath.FromSlash(t.Name())+".golden")
+	got := normalize(entries, ignore)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s missing, run the test with -update to create it", path)
+	}
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("entries differ from %s, run the test with -update to accept them:\n%s", path, diffLines(string(want), string(got)))
+	}
+}
+
+// normalize renders the entries one per line, without their time and the ignored fields.
+func normalize(entries []*tolog.Entry, ignore []string) []byte {
+	ignored := make(map[string]bool, len(ignore))
+	for _, k := range ignore {
+		ignored[k] = true
+	}
+	report := ShutdownReport()
+	var buf bytes.Buffer
+	for _, e := range entries {
+		if report.Match(e) {
+			continue
+		}
+		fmt.Fprintf(&buf, "[%s] %s", e.Level, quote(e.Message))
+		fields := tolog.Fields{}
+		flatten(fields, e.Fields, "")
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			if !ignored[k] {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&buf, " %s=%s", k, quote(fmt.Sprint(fields[k])))
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// flatten copies the fields into out, the fields of groups with dotted keys.
+func flatten(out, fields tolog.Fields, prefix string) {
+	for k, v := range fields {
+		if group, ok := v.(tolog.Fields); ok {
+			flatten(out, group, prefix+k+".")
+			continue
+		}
+		out[prefix+k] = v
+	}
+}
+
+// quote quotes the value when it is empty or holds spaces, quotes or newlines.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// diffLines lists the lines of want missing from got with -, and those of
+// got missing from want with +, position by position.
+func diffLines(want, got string) string {
+	wl := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	gl := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	var sb strings.Builder
+	for i := 0; i < len(wl) || i < len(gl); i++ {
+		switch {
+		case i >= len(gl):
+			fmt.Fprintf(&sb, "-%s\n", wl[i])
+		case i >= len(wl):
+			fmt.Fprintf(&sb, "+%s\n", gl[i])
+		case wl[i] != gl[i]:
+			fmt.Fprintf(&sb, "-%s\n+%s\n", wl[i], gl[i])
+		default:
+			fmt.Fprintf(&sb, " %s\n", wl[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,28 @@
+package tologtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+)
+
+func TestSnapshot(t *testing.T) {
+	entries := []*tolog.Entry{
+		{Level: tolog.StatusInfo, Message: "order received", Fields: tolog.Fields{"user_id": 42, "request_id": "8f3a"}},
+		{Level: tolog.StatusWarning, Message: "stock low", Fields: tolog.Fields{"item": tolog.Fields{"sku": "A-1", "left": 2}}},
+		{Level: tolog.StatusError, Message: "payment failed", Fields: tolog.Fields{"reason": "card declined"}},
+		{Level: tolog.StatusNotice, Message: tolog.ShutdownReportMessage, Fields: tolog.Fields{"uptime": "1.5s"}},
+	}
+	Snapshot(t, entries, "request_id")
+	if *update {
+		return
+	}
+
+	f := &failures{TB: t}
+	entries[2].Message = "payment refused"
+	Snapshot(f, entries, "request_id")
+	if len(f.errors) != 1 || !strings.Contains(f.errors[0], "+[error] \"payment refused\"") {
+		t.Errorf("failures %q", f.errors)
+	}
+}
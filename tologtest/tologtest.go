@@ -0,0 +1,66 @@
+// Package tologtest provides helpers for testing code which logs with tolog.
+package tologtest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+)
+
+const sharedLine = "tologtest shared writer line "
+
+// SharedWriter writes lines concurrently through several loggers with the same
+// prefix, and fails the test unless every line ends up in the log file exactly
+// once and intact. The log file of the prefix is removed first.
+func SharedWriter(t testing.TB, prefix string, loggers, lines int) {
+	t.Helper()
+	lgs := make([]*tolog.Logger, loggers)
+	for i := range lgs {
+		lgs[i] = tolog.NewLogger(prefix)
+	}
+	path := lgs[0].FilePath()
+	lgs[0].CloseFile()
+	os.Remove(path)
+
+	var wg sync.WaitGroup
+	for i, lg := range lgs {
+		wg.Add(1)
+		go func(i int, lg *tolog.Logger) {
+			defer wg.Done()
+			for j := 0; j < lines; j++ {
+				lg.Infof("%s%d-%d", sharedLine, i, j).WriteSafe()
+			}
+		}(i, lg)
+	}
+	wg.Wait()
+	lgs[0].CloseFile()
+	for _, lg := range lgs {
+		lg.Close()
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read shared log file: %v", err)
+	}
+	seen := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+		i := strings.Index(line, sharedLine)
+		if i < 0 {
+			t.Errorf("corrupted line in %s: %q", path, line)
+			continue
+		}
+		seen[line[i+len(sharedLine):]]++
+	}
+	for i := 0; i < loggers; i++ {
+		for j := 0; j < lines; j++ {
+			id := fmt.Sprintf("%d-%d", i, j)
+			if seen[id] != 1 {
+				t.Errorf("line %s written %d times to %s, want once", id, seen[id], path)
+			}
+		}
+	}
+}
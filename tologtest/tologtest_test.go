@@ -0,0 +1,27 @@
+package tologtest
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/callme-taota/tolog"
+)
+
+// TestMain writes the log files of the tests to a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "tologtest-test")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	tolog.SetLogDir(dir)
+	code := m.Run()
+	tolog.CloseLogFile()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestSharedWriter(t *testing.T) {
+	SharedWriter(t, "TestSharedWriter", 8, 250)
+}
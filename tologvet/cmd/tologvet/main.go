@@ -0,0 +1,12 @@
+// Command tologvet reports misuses of the chained tolog API, run it with
+// go vet -vettool=$(which tologvet) ./...
+package main
+
+import (
+	"github.com/callme-taota/tolog/tologvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(tologvet.Analyzer)
+}
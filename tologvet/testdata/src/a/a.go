@@ -0,0 +1,25 @@
+package a
+
+import "github.com/callme-taota/tolog"
+
+func emitted() {
+	tolog.Info("written").WriteSafe()
+	tolog.Info("printed").PrintLog()
+	l := tolog.Info("kept")
+	l.WriteSafe()
+}
+
+func missingTerminator() {
+	tolog.Info("lost")                     // want `tolog entry is never emitted`
+	tolog.Info("lost").WithField("k", "v") // want `tolog entry is never emitted`
+}
+
+func formats(name string, args []any) {
+	tolog.Infof("hello %s", name).WriteSafe()
+	tolog.Infof("100%% of %d", 3).WriteSafe()
+	tolog.Infof("%*d", 4, 2).WriteSafe()
+	tolog.Infof("%[1]s %[1]s", name).WriteSafe()
+	tolog.Infof("%v", args...).WriteSafe()
+	tolog.Infof("hello %s").WriteSafe()             // want `Infof format reads 1 args, but call has 0`
+	tolog.Errorf("%s failed", name, 42).WriteSafe() // want `Errorf format reads 1 args, but call has 2`
+}
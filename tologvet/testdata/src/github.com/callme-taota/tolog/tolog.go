@@ -0,0 +1,10 @@
+package tolog
+
+type ToLog struct{}
+
+func Info(msg string) *ToLog                      { return &ToLog{} }
+func Infof(format string, a ...any) *ToLog        { return &ToLog{} }
+func Errorf(format string, a ...any) *ToLog       { return &ToLog{} }
+func (l *ToLog) WithField(k string, v any) *ToLog { return l }
+func (l *ToLog) PrintLog() *ToLog                 { return l }
+func (l *ToLog) WriteSafe()                       {}
@@ -0,0 +1,163 @@
+// Package tologvet provides an analyzer reporting misuses of the chained tolog
+// API: entries built but never emitted because the terminator is missing, and
+// format strings not matching their arguments.
+//
+// Run it with go vet:
+//
+//	go install github.com/callme-taota/tolog/tologvet/cmd/tologvet@latest
+//	go vet -vettool=$(which tologvet) ./...
+package tologvet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const tologPath = "github.com/callme-taota/tolog"
+
+// Analyzer reports tolog entries without terminator and mismatched format verbs.
+var Analyzer = &analysis.Analyzer{
+	Name:     "tologvet",
+	Doc:      "report tolog entries never emitted and format strings not matching their arguments",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// terminators emit an entry, their result may be discarded.
+var terminators = map[string]bool{
+	"PrintLog":          true,
+	"WriteSafe":         true,
+	"PrintAndWriteSafe": true,
+	"Write":             true,
+	"PrintAndWrite":     true,
+}
+
+// formatFuncs take a format string followed by its arguments.
+var formatFuncs = map[string]bool{
+	"Infof":    true,
+	"Warningf": true,
+	"Errorf":   true,
+	"Noticef":  true,
+	"Debugf":   true,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodes := []ast.Node{(*ast.ExprStmt)(nil), (*ast.CallExpr)(nil)}
+	ins.Preorder(nodes, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.ExprStmt:
+			checkTerminator(pass, n)
+		case *ast.CallExpr:
+			checkFormat(pass, n)
+		}
+	})
+	return nil, nil
+}
+
+// checkTerminator reports a statement discarding an entry which was not emitted.
+func checkTerminator(pass *analysis.Pass, stmt *ast.ExprStmt) {
+	call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+	if !ok || !isToLog(pass.TypesInfo.TypeOf(call)) {
+		return
+	}
+	if fn := callee(pass, call); fn != nil && terminators[fn.Name()] {
+		return
+	}
+	pass.Reportf(call.Pos(), "tolog entry is never emitted, add .WriteSafe() or .PrintLog()")
+}
+
+// checkFormat reports a call to a format function of tolog whose constant
+// format string does not match the number of arguments.
+func checkFormat(pass *analysis.Pass, call *ast.CallExpr) {
+	fn := callee(pass, call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != tologPath || !formatFuncs[fn.Name()] {
+		return
+	}
+	if len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return
+	}
+	tv, ok := pass.TypesInfo.Types[call.Args[0]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return
+	}
+	want, ok := countVerbs(constant.StringVal(tv.Value))
+	if !ok {
+		return
+	}
+	if got := len(call.Args) - 1; got != want {
+		pass.Reportf(call.Pos(), "%s format reads %d args, but call has %d", fn.Name(), want, got)
+	}
+}
+
+// countVerbs returns the number of arguments the format string reads, false
+// when it uses explicit argument indexes.
+func countVerbs(format string) (int, bool) {
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		// flags, width and precision
+		for ; i < len(format); i++ {
+			c := format[i]
+			if c == '[' {
+				return 0, false
+			}
+			if c == '*' {
+				n++
+				continue
+			}
+			if !strings.ContainsRune("+-# 0.123456789", rune(c)) {
+				break
+			}
+		}
+		if i >= len(format) {
+			break
+		}
+		if format[i] == '%' {
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(format[i:])
+		i += size - 1
+		n++
+	}
+	return n, true
+}
+
+// callee returns the function or method called, nil for other calls.
+func callee(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	var id *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := pass.TypesInfo.Uses[id].(*types.Func)
+	return fn
+}
+
+// isToLog reports whether the type is *tolog.ToLog.
+func isToLog(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == tologPath && obj.Name() == "ToLog"
+}
@@ -0,0 +1,33 @@
+package tologvet
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestCountVerbs(t *testing.T) {
+	for format, want := range map[string]int{
+		"":                0,
+		"plain":           0,
+		"%s and %d":       2,
+		"100%%":           0,
+		"%-10.3f|%+v":     2,
+		"%*d":             2,
+		"%.*f":            2,
+		"trailing %":      0,
+		"unicode %s é %q": 2,
+	} {
+		got, ok := countVerbs(format)
+		if !ok || got != want {
+			t.Errorf("countVerbs(%q) = %d, %v, want %d", format, got, ok, want)
+		}
+	}
+	if _, ok := countVerbs("%[2]d %[1]d"); ok {
+		t.Error("countVerbs accepted explicit indexes")
+	}
+}
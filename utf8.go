@@ -0,0 +1,86 @@
+package tolog
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// UTF8Mode is how invalid UTF-8 in messages and fields is handled before encoding.
+type UTF8Mode int32
+
+const (
+	// UTF8Replace replaces invalid bytes with the replacement character U+FFFD, the default.
+	UTF8Replace UTF8Mode = iota
+	// UTF8Escape escapes invalid bytes as \xNN, keeping the original bytes recoverable.
+	UTF8Escape
+	// UTF8Keep writes invalid bytes as is.
+	UTF8Keep
+)
+
+var utf8Mode int32
+
+// SetUTF8Mode sets how invalid UTF-8 in messages, field keys and string or
+// []byte field values is handled, preventing broken lines for downstream parsers
+// when logging raw bytes.
+func SetUTF8Mode(mode UTF8Mode) {
+	atomic.StoreInt32(&utf8Mode, int32(mode))
+}
+
+// sanitizeUTF8 returns the string with its invalid UTF-8 handled as configured.
+func sanitizeUTF8(s string) string {
+	mode := UTF8Mode(atomic.LoadInt32(&utf8Mode))
+	if mode == UTF8Keep || utf8.ValidString(s) {
+		return s
+	}
+	if mode == UTF8Replace {
+		return strings.ToValidUTF8(s, string(utf8.RuneError))
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			fmt.Fprintf(&sb, `\x%02x`, s[i])
+		} else {
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// sanitize handles the invalid UTF-8 of the message and the fields of the entry.
+func (l *ToLog) sanitize() {
+	if UTF8Mode(atomic.LoadInt32(&utf8Mode)) == UTF8Keep {
+		return
+	}
+	l.logContext = sanitizeUTF8(l.logContext)
+	var fields Fields
+	for k, v := range l.fields {
+		key := sanitizeUTF8(k)
+		changed := key != k
+		value := v
+		switch s := v.(type) {
+		case string:
+			if clean := sanitizeUTF8(s); clean != s {
+				value, changed = clean, true
+			}
+		case []byte:
+			if !utf8.Valid(s) {
+				value, changed = sanitizeUTF8(string(s)), true
+			}
+		}
+		if !changed {
+			continue
+		}
+		if fields == nil {
+			fields = Fields(nil).merge(l.fields)
+		}
+		delete(fields, k)
+		fields[key] = value
+	}
+	if fields != nil {
+		l.fields = fields
+	}
+}
@@ -0,0 +1,30 @@
+package tolog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeUTF8(t *testing.T) {
+	defer SetUTF8Mode(UTF8Replace)
+	invalid := "ok \xff\xfe done"
+
+	assert.Equal(t, "ok � done", sanitizeUTF8(invalid))
+	assert.Equal(t, "héllo", sanitizeUTF8("héllo"))
+
+	SetUTF8Mode(UTF8Escape)
+	assert.Equal(t, `ok \xff\xfe done`, sanitizeUTF8(invalid))
+
+	SetUTF8Mode(UTF8Keep)
+	assert.Equal(t, invalid, sanitizeUTF8(invalid))
+}
+
+func TestSanitizeEntry(t *testing.T) {
+	defer SetLogFormat(FormatText)
+	SetLogFormat(FormatJSON)
+	l := Info("bad \xc3").WithField("raw", []byte{0xff, 'a'}).WithField("n", []int{1})
+	CreateFullLog(l)
+	assert.Contains(t, l.FullLog, `"msg":"bad �"`)
+	assert.Contains(t, l.FullLog, `"raw":"�a"`)
+}
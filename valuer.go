@@ -0,0 +1,84 @@
+package tolog
+
+import "fmt"
+
+// LogValuer is implemented by types controlling how they are logged, like
+// slog.LogValuer, e.g. to log only the ID of a user or to redact a token.
+// LogValue is called once the entry is written, after the request buffers,
+// the schema and the sampler, so expensive values of dropped entries are never
+// rendered. It may return another LogValuer, or Fields logged as a group.
+type LogValuer interface {
+	LogValue() any
+}
+
+// lazyValue renders the LogValuer fields of lines encoded before the entry is
+// written, like the FullLog of an entry still being built.
+const lazyValue = "<lazy>"
+
+// The number of LogValuers resolved in a row before giving up, as in slog.
+const maxLogValueDepth = 100
+
+// resolveValue returns the value a LogValuer logs as, or v itself.
+func resolveValue(v any) (value any) {
+	defer func() {
+		if r := recover(); r != nil {
+			value = fmt.Sprintf("!PANIC: %v", r)
+		}
+	}()
+	for i := 0; i < maxLogValueDepth; i++ {
+		lv, ok := v.(LogValuer)
+		if !ok {
+			return v
+		}
+		v = lv.LogValue()
+	}
+	return fmt.Sprintf("!ERROR: LogValue exceeded %d levels", maxLogValueDepth)
+}
+
+// resolveValues returns the fields with their LogValuers and those of their
+// groups resolved and their joined errors split, f itself if it has none.
+func (f Fields) resolveValues() Fields {
+	resolved, _ := f.resolveChanged()
+	return resolved
+}
+
+// resolveChanged resolves the LogValuers of the fields, reporting whether there were any.
+func (f Fields) resolveChanged() (Fields, bool) {
+	var out Fields
+	for k, v := range f {
+		switch val := v.(type) {
+		case LogValuer:
+			v = resolveValue(val)
+			if group, ok := v.(Fields); ok {
+				v = group.resolveValues()
+			}
+		case Fields:
+			group, changed := val.resolveChanged()
+			if !changed {
+				continue
+			}
+			v = group
+		case error:
+			errs := multiErrors(val)
+			if len(errs) == 0 {
+				continue
+			}
+			v = joinedErrorFields(errs)
+		default:
+			continue
+		}
+		if out == nil {
+			out = f.copy()
+		}
+		out[k] = v
+	}
+	if out == nil {
+		return f, false
+	}
+	return out, true
+}
+
+// resolve resolves the LogValuer fields of the entry before it is written.
+func (l *ToLog) resolve() {
+	l.fields = l.fields.resolveValues()
+}
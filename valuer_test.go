@@ -0,0 +1,62 @@
+package tolog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	id    int
+	token string
+	calls *int
+}
+
+func (u testUser) LogValue() any {
+	*u.calls++
+	return Fields{"id": u.id}
+}
+
+type panicValuer struct{}
+
+func (panicValuer) LogValue() any { panic("boom") }
+
+type loopValuer struct{}
+
+func (v loopValuer) LogValue() any { return v }
+
+func TestLogValuer(t *testing.T) {
+	lg := NewLogger("TestLogValuer")
+	defer lg.Close()
+	defer os.Remove(lg.FilePath())
+	lg.SetColor(false)
+	var out bytes.Buffer
+	lg.AddOutput(&out)
+
+	calls := 0
+	user := testUser{id: 7, token: "secret", calls: &calls}
+	l := lg.Info("login").WithField("user", user).WithField("attempt", 2)
+	assert.Equal(t, 0, calls, "not rendered while building the entry")
+	assert.Contains(t, l.FullLog, "user=<lazy>")
+
+	l.WriteSafe()
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, out.String(), "attempt=2 user.id=7")
+	assert.NotContains(t, out.String(), "secret")
+	assert.Equal(t, Fields{"id": 7}, l.fields["user"])
+	assert.Equal(t, user, l.WithField("again", 1).WithField("user", user).fields["user"])
+
+	SetSampling(1, 0, time.Hour)
+	defer SetSampling(0, 0, 0)
+	lg.Info("sampled").WithField("user", user).WriteSafe()
+	lg.Info("sampled").WithField("user", user).WriteSafe()
+	assert.Equal(t, 2, calls, "dropped entries are not rendered")
+	SetSampling(0, 0, 0)
+
+	assert.Contains(t, lg.Info("x").WithField("v", panicValuer{}).String(), "v=\"!PANIC: boom\"")
+	assert.Contains(t, lg.Info("x").Group("g").WithField("v", loopValuer{}).String(), "g.v=\"!ERROR: LogValue exceeded 100 levels\"")
+	assert.Contains(t, Format(StatusInfo, "x", Fields{"user": user}), "user.id=7")
+}
@@ -0,0 +1,91 @@
+package tolog
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// VerbosityField is the field holding the verbosity of the entries of V.
+const VerbosityField = "v"
+
+var verbosity int32
+
+// SetVerbosity sets the highest verbosity V writes, like the -v flag of glog
+// and klog, zero by default.
+func SetVerbosity(v int) {
+	atomic.StoreInt32(&verbosity, int32(v))
+}
+
+// Verbose writes the entries of a verbosity, or nothing when it is above the
+// one of SetVerbosity, see V.
+type Verbose struct {
+	lg    *Logger
+	level LogStatus
+	v     int
+	on    bool
+}
+
+// V returns the writer of the entries of the verbosity of the default logger,
+// for code written against glog and klog:
+//
+//	tolog.V(2).Infof("syncing %d pods", n)
+//
+// Verbosity 0 writes info entries, higher ones debug entries, all with the v
+// field, so they are also subject to the levels: raise the verbosity and lower
+// the level to debug to see them.
+func V(level int) Verbose {
+	return std.V(level)
+}
+
+// V returns the writer of the entries of the verbosity of the logger, see V.
+func (lg *Logger) V(level int) Verbose {
+	entryLevel := StatusInfo
+	if level > 0 {
+		entryLevel = StatusDebug
+	}
+	on := int32(level) <= atomic.LoadInt32(&verbosity) && lg.Enabled(entryLevel)
+	return Verbose{lg: lg, level: entryLevel, v: level, on: on}
+}
+
+// Enabled reports whether the entries are written, to guard expensive
+// preparation like glog's if glog.V(2) { ... }.
+func (v Verbose) Enabled() bool {
+	return v.on
+}
+
+// Info prints and writes the entry of the arguments formatted like fmt.Sprint.
+func (v Verbose) Info(args ...any) {
+	if v.on {
+		v.write(fmt.Sprint(args...))
+	}
+}
+
+// Infof prints and writes the entry of the arguments formatted like fmt.Sprintf.
+func (v Verbose) Infof(format string, args ...any) {
+	if v.on {
+		v.write(fmt.Sprintf(format, args...))
+	}
+}
+
+// Infoln prints and writes the entry of the arguments formatted like fmt.Sprintln.
+func (v Verbose) Infoln(args ...any) {
+	if v.on {
+		v.write(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+	}
+}
+
+// InfoS prints and writes the entry of the message with the key/value pairs of
+// klog's structured logging.
+func (v Verbose) InfoS(msg string, keysAndValues ...any) {
+	if v.on {
+		v.write(msg, keysAndValues...)
+	}
+}
+
+// write prints and writes the entry with the verbosity and the key/value pairs.
+func (v Verbose) write(msg string, keysAndValues ...any) {
+	fields := KeyValues(keysAndValues...)
+	fields[VerbosityField] = v.v
+	v.lg.Log(WithType(v.level), WithFields(fields)).Context(msg).PrintAndWriteSafe()
+}
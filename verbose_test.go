@@ -0,0 +1,45 @@
+package tolog
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestV(t *testing.T) {
+	if DebugStripped {
+		t.Skip("logs the verbose levels at debug, stripped by tolog_nodebug")
+	}
+	lg := NewLogger("TestV")
+	defer os.Remove(lg.FilePath())
+	defer lg.Close()
+	defer SetVerbosity(0)
+	defer SetLevel("")
+	SetVerbosity(2)
+	SetLevel(StatusInfo)
+	assert.True(t, lg.V(0).Enabled())
+	assert.False(t, lg.V(2).Enabled(), "debug disabled")
+	SetVerbosity(0)
+	SetLevel("")
+
+	sink := &memorySink{}
+	AddSink(sink)
+	defer RemoveSink(sink)
+
+	lg.V(0).Infof("starting %d workers", 4)
+	lg.V(1).Info("hidden")
+	SetVerbosity(2)
+	assert.True(t, lg.V(2).Enabled())
+	assert.False(t, lg.V(3).Enabled())
+	lg.V(2).InfoS("synced", "pods", 3, "namespace")
+	lg.V(3).Infoln("too verbose")
+
+	if assert.Len(t, sink.entries, 2) {
+		assert.Equal(t, StatusInfo, sink.entries[0].Level)
+		assert.Equal(t, "starting 4 workers", sink.entries[0].Message)
+		assert.Equal(t, 0, sink.entries[0].Fields[VerbosityField])
+		assert.Equal(t, StatusDebug, sink.entries[1].Level)
+		assert.Equal(t, Fields{VerbosityField: 2, "pods": 3, "namespace": "(MISSING)"}, sink.entries[1].Fields)
+	}
+}
@@ -0,0 +1,159 @@
+package tolog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// The kinds of the issues reported by Verify.
+const (
+	IssueUnparsable    = "unparsable"     // the line is no entry of a known format
+	IssueTimeBackwards = "time_backwards" // the time is before an earlier line beyond the tolerance
+	IssueNUL           = "nul_bytes"      // the line holds NUL bytes, left by a crash while writing
+	IssueTruncated     = "truncated"      // the last line has no newline
+)
+
+// VerifyOptions configures Verify.
+type VerifyOptions struct {
+	// Tolerance is how far a time may go back from the latest time before it,
+	// as lines of concurrent writers may be flushed slightly out of order,
+	// one second if zero.
+	Tolerance time.Duration
+	// MaxIssues is the number of issues after which the file is no longer
+	// checked, 100 if zero.
+	MaxIssues int
+}
+
+// VerifyIssue is a problem of a line of a log file.
+type VerifyIssue struct {
+	Line   int // from 1
+	Kind   string
+	Detail string
+}
+
+// String formats the issue as line N: kind: detail.
+func (i VerifyIssue) String() string {
+	return fmt.Sprintf("line %d: %s: %s", i.Line, i.Kind, i.Detail)
+}
+
+// VerifyReport is the result of verifying a log file.
+type VerifyReport struct {
+	Lines   int // the lines read, markers and blank lines included
+	Entries int // the lines parsed as entries
+	Issues  []VerifyIssue
+	// Incomplete is set when the file was not read to the end after MaxIssues.
+	Incomplete bool
+}
+
+// OK reports whether the file has no issues.
+func (r VerifyReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// VerifyFile checks that the log file, gzip compressed if it ends with .gz, is
+// well formed before it is archived, see Verify.
+func VerifyFile(path string, opts VerifyOptions) (VerifyReport, error) {
+	r, err := readLogFile(path)
+	if err != nil {
+		return VerifyReport{}, err
+	}
+	defer r.Close()
+	return Verify(r, opts)
+}
+
+// readLogFile opens the log file for reading, decompressing it if its name
+// ends with .gz.
+func readLogFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return gzipFile{gz, f}, nil
+}
+
+// gzipFile reads a gzip compressed file, closing both.
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+// Close closes the reader and the file.
+func (g gzipFile) Close() error {
+	g.Reader.Close()
+	return g.f.Close()
+}
+
+// Verify checks that the lines of a log file are well formed: every line parses
+// as an entry, see ParseLine, times don't go back beyond the tolerance, no
+// line holds the NUL bytes a crash leaves and the last line is complete. File
+// markers and blank lines are skipped. The error is the one of reading.
+func Verify(r io.Reader, opts VerifyOptions) (VerifyReport, error) {
+	if opts.Tolerance == 0 {
+		opts.Tolerance = time.Second
+	}
+	if opts.MaxIssues <= 0 {
+		opts.MaxIssues = 100
+	}
+	var report VerifyReport
+	add := func(kind string, format string, a ...any) {
+		report.Issues = append(report.Issues, VerifyIssue{Line: report.Lines, Kind: kind, Detail: fmt.Sprintf(format, a...)})
+	}
+	br := bufio.NewReader(r)
+	var latest time.Time
+	for len(report.Issues) < opts.MaxIssues {
+		line, err := br.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			return report, nil
+		}
+		if err != nil && err != io.EOF {
+			return report, err
+		}
+		report.Lines++
+		if err == io.EOF {
+			add(IssueTruncated, "no newline at the end of the file")
+		}
+		if i := bytes.IndexByte(line, 0); i >= 0 {
+			add(IssueNUL, "NUL byte at column %d", i+1)
+			continue
+		}
+		text := strings.TrimRight(string(line), "\r\n")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		e, perr := ParseLine(text)
+		switch {
+		case perr != nil:
+			add(IssueUnparsable, "%v", perr)
+			continue
+		case e.Level == "" || e.Time.IsZero():
+			add(IssueUnparsable, "no time or level: %.80q", text)
+			continue
+		}
+		report.Entries++
+		if !latest.IsZero() && e.Time.Before(latest.Add(-opts.Tolerance)) {
+			add(IssueTimeBackwards, "%s is %s before %s", e.Time.Format(time.RFC3339Nano), latest.Sub(e.Time), latest.Format(time.RFC3339Nano))
+		}
+		if latest.IsZero() || e.Time.After(latest) {
+			latest = e.Time
+		}
+	}
+	// stopped at MaxIssues, unless the file ends here
+	if _, err := br.Peek(1); err == nil {
+		report.Incomplete = true
+	}
+	return report, nil
+}
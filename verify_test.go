@@ -0,0 +1,75 @@
+package tolog
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	content := strings.Join([]string{
+		"# tolog file start logs/app.log",
+		"time=2024-01-02T15:04:05Z level=info msg=started",
+		"time=2024-01-02T15:04:06Z level=info msg=second",
+		"time=2024-01-02T15:04:05.500Z level=info msg=\"flushed late, within the tolerance\"",
+		"",
+		"time=2024-01-02T15:03:00Z level=warning msg=\"clock went back\"",
+		"\x00\x00\x00\x00time=2024-01-02T15:04:07Z level=info msg=\"after a crash\"",
+		"garbage",
+		"time=2024-01-02T15:04:08Z level=error msg=cut",
+	}, "\n")
+	report, err := Verify(strings.NewReader(content), VerifyOptions{})
+	require.NoError(t, err)
+	assert.False(t, report.OK())
+	assert.Equal(t, 9, report.Lines)
+	assert.Equal(t, 5, report.Entries)
+	var kinds []string
+	var lines []int
+	for _, issue := range report.Issues {
+		kinds = append(kinds, issue.Kind)
+		lines = append(lines, issue.Line)
+	}
+	assert.Equal(t, []string{IssueTimeBackwards, IssueNUL, IssueUnparsable, IssueTruncated}, kinds)
+	assert.Equal(t, []int{6, 7, 8, 9}, lines)
+	assert.Equal(t, "line 7: nul_bytes: NUL byte at column 1", report.Issues[1].String())
+
+	report, err = Verify(strings.NewReader(content), VerifyOptions{MaxIssues: 1})
+	require.NoError(t, err)
+	assert.Len(t, report.Issues, 1)
+	assert.True(t, report.Incomplete)
+}
+
+func TestVerifyFile(t *testing.T) {
+	lg := NewLogger("TestVerifyFile")
+	defer lg.Close()
+	path := lg.FilePath()
+	os.Remove(path)
+	defer os.Remove(path)
+	lg.Info("first").WriteSafe()
+	lg.Error("second").WithField("n", 2).WriteSafe()
+	lg.CloseFile()
+
+	report, err := VerifyFile(path, VerifyOptions{})
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "%v", report.Issues)
+	assert.Equal(t, 2, report.Entries)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	gzPath := filepath.Join(t.TempDir(), "app.log.gz")
+	f, err := os.Create(gzPath)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	gz.Write(data)
+	gz.Close()
+	f.Close()
+	report, err = VerifyFile(gzPath, VerifyOptions{})
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "%v", report.Issues)
+	assert.Equal(t, 2, report.Entries)
+}
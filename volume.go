@@ -0,0 +1,83 @@
+package tolog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// VolumeCapMode is what happens to entries once the daily volume cap is reached.
+type VolumeCapMode int32
+
+const (
+	// VolumeCapErrorsOnly keeps writing error entries and drops the others, the default.
+	VolumeCapErrorsOnly VolumeCapMode = iota
+	// VolumeCapDropAll drops every entry until the next day.
+	VolumeCapDropAll
+)
+
+// The bytes a prefix may write to its log files per day, default 0 is unlimited.
+var dailyVolumeCap int64
+
+var volumeCapMode int32 // VolumeCapMode
+
+var volumeCapDropped uint64
+
+// SetDailyVolumeCap limits the bytes written to the log files of a prefix per day.
+// When the cap is reached a single notice is written and further entries are
+// dropped according to the volume cap mode until the day changes, protecting
+// shared disks from runaway verbosity. Zero disables the cap.
+func SetDailyVolumeCap(bytes int64) {
+	atomic.StoreInt64(&dailyVolumeCap, bytes)
+}
+
+// SetVolumeCapMode sets what happens to entries once the daily volume cap is reached.
+func SetVolumeCapMode(mode VolumeCapMode) {
+	atomic.StoreInt32(&volumeCapMode, int32(mode))
+}
+
+// VolumeCapDropped returns the number of lines dropped by the daily volume cap.
+func VolumeCapDropped() uint64 {
+	return atomic.LoadUint64(&volumeCapDropped)
+}
+
+// applyVolumeCap returns the items of the batch still allowed under the daily
+// volume cap, with a notice when the cap is reached, fileMu must be held.
+func (w *fileWriter) applyVolumeCap(batch []writeItem) []writeItem {
+	limit := atomic.LoadInt64(&dailyVolumeCap)
+	if limit <= 0 {
+		return batch
+	}
+	mode := VolumeCapMode(atomic.LoadInt32(&volumeCapMode))
+	used := w.dayBytes
+	kept := make([]writeItem, 0, len(batch))
+	for _, item := range batch {
+		if item.line == "" {
+			continue // printed to the console only
+		}
+		if used < limit || (mode == VolumeCapErrorsOnly && item.level == StatusError) {
+			kept = append(kept, item)
+			used += int64(len(item.line))
+			continue
+		}
+		if !w.capped {
+			w.capped = true
+			kept = append(kept, volumeCapNotice(limit, mode))
+			diag("drop", "daily volume cap of %d bytes reached for %s", limit, w.name())
+		}
+		atomic.AddUint64(&volumeCapDropped, 1)
+	}
+	return kept
+}
+
+// volumeCapNotice returns the line written once the daily volume cap is reached.
+func volumeCapNotice(limit int64, mode VolumeCapMode) writeItem {
+	msg := fmt.Sprintf("daily volume cap of %d bytes reached, ", limit)
+	if mode == VolumeCapErrorsOnly {
+		msg += "writing only errors until tomorrow"
+	} else {
+		msg += "dropping entries until tomorrow"
+	}
+	e := &Entry{Time: time.Now(), Level: StatusWarning, Message: msg}
+	return writeItem{line: fileLine(e.toLog()), level: StatusWarning}
+}
@@ -0,0 +1,44 @@
+package tolog
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyVolumeCap(t *testing.T) {
+	lg := NewLogger("TestDailyVolumeCap")
+	defer lg.Close()
+	path := lg.FilePath()
+	os.Remove(path)
+
+	SetDailyVolumeCap(200)
+	defer SetDailyVolumeCap(0)
+	dropped := VolumeCapDropped()
+
+	for i := 0; i < 20; i++ {
+		lg.Info("verbose entry").WriteSafe()
+	}
+	lg.Error("still written").WriteSafe()
+	lg.Info("dropped entry").WriteSafe()
+	lg.CloseFile()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "daily volume cap of 200 bytes reached")
+	assert.Contains(t, string(content), "still written")
+	assert.NotContains(t, string(content), "dropped entry")
+	assert.Equal(t, 1, strings.Count(string(content), "daily volume cap"))
+	assert.Greater(t, VolumeCapDropped(), dropped)
+
+	SetVolumeCapMode(VolumeCapDropAll)
+	defer SetVolumeCapMode(VolumeCapErrorsOnly)
+	lg.Error("dropped error").WriteSafe()
+	lg.CloseFile()
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "dropped error")
+}
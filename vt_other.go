@@ -0,0 +1,10 @@
+//go:build !windows
+
+package tolog
+
+import "os"
+
+// enableVirtualTerminal reports true, terminals support ANSI escape codes.
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}
@@ -0,0 +1,567 @@
+package tolog
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// fileWriter writes the lines of the log files of one prefix from a background
+// goroutine. Loggers with the same prefix share a fileWriter, so a file is never
+// opened twice in a process and lines of different loggers never interleave.
+type fileWriter struct {
+	prefix string
+	socket string // the collector address the lines are forwarded to instead of a file
+	refs   int    // guarded by writersMu
+
+	mu     sync.RWMutex // guards the lifecycle below, held for reading while enqueuing
+	closed bool
+	manual bool // no goroutine writes the queued lines, Pump does, see SetManualFlush
+	lines  chan writeItem
+	syncs  chan syncRequest // requests of Flush and Pump
+	pumpMu sync.Mutex       // serializes Pump in manual mode
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	retired bool // released by its last logger and never opened again, guarded by mu
+
+	queue  lineQueue      // queues the lines instead of lines with QueueRing and QueueSharded
+	urgent chan writeItem // the lines of SetPriorityLevel
+
+	fileMu sync.Mutex // guards the file, swapped on rotation
+	file   File
+	conn   net.Conn // set instead of the file when forwarding
+	date   string
+	size   int64
+
+	lineCount int64 // lines written to the file, counted when rotating by lines
+
+	unsynced int64     // lines written since the last sync, counted with SetSyncEvery or SetSyncInterval
+	syncedAt time.Time // the last sync, guarded by fileMu
+
+	dayBytes int64 // written to the files of the day, guarded by fileMu
+	capped   bool  // the daily volume cap is reached
+
+	dropped atomic.Uint64 // entries dropped since the last flush
+
+	unwritten []byte // the bytes a failed flush left to write, guarded by fileMu
+}
+
+// writeItem is a line queued for the log file, with the entry it encodes
+// while a flush hook needs it. Raw lines have no entry and no level.
+type writeItem struct {
+	line  string
+	level LogStatus
+	entry *Entry
+
+	console string  // the line printed to the console of the printer, see SetAsyncConsole
+	printer *Logger // the root logger whose console prints the line
+
+	seq uint64 // orders the lines of QueueSharded
+
+	pairs []string // the rendered fields of a text line, see SetFieldElision
+}
+
+var writers = map[string]*fileWriter{}
+var writersMu sync.Mutex
+
+// acquireWriter returns the shared writer of the prefix, creating it if needed.
+func acquireWriter(prefix string) *fileWriter {
+	writersMu.Lock()
+	defer writersMu.Unlock()
+	w, ok := writers[prefix]
+	if !ok {
+		w = &fileWriter{prefix: prefix, closed: true}
+		writers[prefix] = w
+	}
+	w.refs++
+	return w
+}
+
+// acquireForwarder returns the shared writer forwarding lines to the collector
+// listening on the address, creating it if needed.
+func acquireForwarder(socket string) *fileWriter {
+	writersMu.Lock()
+	defer writersMu.Unlock()
+	key := collectorName(socket)
+	w, ok := writers[key]
+	if !ok {
+		w = &fileWriter{socket: socket, closed: true}
+		writers[key] = w
+	}
+	w.refs++
+	return w
+}
+
+// key returns the key of the writer in the registry.
+func (w *fileWriter) key() string {
+	if w.socket != "" {
+		return collectorName(w.socket)
+	}
+	return w.prefix
+}
+
+// release drops a reference to the writer, closing it when it was the last one.
+func (w *fileWriter) release() error {
+	writersMu.Lock()
+	w.refs--
+	last := w.refs == 0
+	if last {
+		delete(writers, w.key())
+	}
+	writersMu.Unlock()
+	if last {
+		w.mu.Lock()
+		w.retired = true
+		w.mu.Unlock()
+		return w.close()
+	}
+	return nil
+}
+
+// logFilePath returns the path of the log file of the prefix for the day.
+func logFilePath(prefix string, day string) string {
+	dir := strings.TrimSuffix(logDirectory(), "/") + "/"
+	if prefix != "" {
+		return dir + prefix + "-log-" + day + ".log"
+	}
+	return dir + "log-" + day + ".log"
+}
+
+// SetLogDir sets the directory of the log files, ./logs by default. It is
+// created when missing, empty restores the default. Open files move to it when they are next opened.
+func SetLogDir(dir string) {
+	logDir.Store(dir)
+	fallbackDir.Store("")
+}
+
+// logDirectory returns the directory of the log files, the fallback directory
+// after falling back to it.
+func logDirectory() string {
+	if dir, _ := fallbackDir.Load().(string); dir != "" {
+		return dir
+	}
+	if dir, _ := logDir.Load().(string); dir != "" {
+		return dir
+	}
+	return defaultLogDir
+}
+
+// path returns the path of the current log file.
+func (w *fileWriter) path() string {
+	if w == nil {
+		return ""
+	}
+	if w.socket != "" {
+		return collectorName(w.socket)
+	}
+	return w.filePath(currentDay())
+}
+
+// open opens the log file and starts the goroutine writing to it, if not running yet.
+func (w *fileWriter) open() error {
+	if w == nil {
+		return ErrClosed
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		return nil
+	}
+	if w.retired {
+		return ErrClosed
+	}
+	if err := w.openFile(); err != nil {
+		return err
+	}
+	w.closed = false
+	w.manual = atomic.LoadInt32(&manualFlush) == 1
+	w.lines = nil
+	w.queue = newLineQueue(QueueMode(atomic.LoadInt32(&queueMode)), int(atomic.LoadInt64(&queueSize)))
+	if w.queue == nil {
+		w.lines = make(chan writeItem, atomic.LoadInt64(&queueSize))
+	}
+	w.urgent = make(chan writeItem, urgentQueueSize)
+	w.syncs = make(chan syncRequest)
+	w.done = make(chan struct{})
+	if w.manual {
+		return nil
+	}
+	w.wg.Add(1)
+	go w.run()
+	return nil
+}
+
+// openFile opens the log file of the current day, creating the logs directory if needed.
+func (w *fileWriter) openFile() error {
+	if w.socket != "" {
+		return w.dial()
+	}
+	day := currentDay()
+
+	// Create the logs directory if it doesn't exist
+	dir := logDirectory()
+	if err := checkLogDir(dir); err != nil {
+		if err := fallBack(err); err != nil {
+			return err
+		}
+	}
+
+	file, err := openLogFile(w.filePath(day))
+	if err != nil && readOnly(err) {
+		err = fallBack(&LogDirError{Dir: dir, Problem: LogDirReadOnly, Err: err})
+		if err == nil {
+			file, err = openLogFile(w.filePath(day))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.fileMu.Lock()
+	if w.date != day {
+		w.capped = false
+	}
+	w.file = file
+	w.date = day
+	w.size = info.Size()
+	w.dayBytes = info.Size()
+	w.lineCount = countLines(file)
+	if w.size == 0 {
+		w.writeMarker(&fileHeader, w.file.Name(), "")
+	}
+	w.fileMu.Unlock()
+	diag("open", "opened %s", file.Name())
+	return nil
+}
+
+// write queues the line for the background goroutine, opening the file if needed.
+func (w *fileWriter) write(item writeItem) error {
+	return w.writeUntil(nil, item)
+}
+
+// writeUntil queues the line like write, dropping it if done is closed while
+// waiting for room in the queue.
+func (w *fileWriter) writeUntil(done <-chan struct{}, item writeItem) error {
+	if w == nil {
+		return ErrClosed
+	}
+	for {
+		w.mu.RLock()
+		if !w.closed {
+			if w.large(item) {
+				w.mu.RUnlock()
+				return w.writeDirect(item)
+			}
+			if w.urgentItem(item) {
+				select {
+				case w.urgent <- item:
+					w.mu.RUnlock()
+					return nil
+				default:
+				}
+			}
+			w.enqueueUntil(done, item)
+			w.mu.RUnlock()
+			return nil
+		}
+		w.mu.RUnlock()
+		diag("reopen", "opening %s for write", w.path())
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+}
+
+// writeDirect writes the item to the file from the calling goroutine.
+func (w *fileWriter) writeDirect(item writeItem) error {
+	if w == nil {
+		return ErrClosed
+	}
+	for {
+		w.mu.RLock()
+		if !w.closed {
+			buf := getBuffer()
+			w.fileMu.Lock()
+			for _, item := range w.applyVolumeCap([]writeItem{item}) {
+				buf.WriteString(item.line)
+			}
+			n, err := w.writeData(buf.Bytes())
+			if err == nil {
+				w.syncIfDue(1)
+			}
+			w.fileMu.Unlock()
+			putBuffer(buf)
+			printConsole([]writeItem{item})
+			afterWrite(n, err)
+			w.mu.RUnlock()
+			return err
+		}
+		w.mu.RUnlock()
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+}
+
+// run is a goroutine that continuously writes log entries to the log file using the channel.
+func (w *fileWriter) run() {
+	defer w.wg.Done()
+	buffer := []writeItem{}
+	pacer := newFlushPacer()
+	ticker := time.NewTicker(pacer.interval)
+	defer ticker.Stop()
+	add := func(item writeItem) {
+		buffer = append(buffer, item)
+		if flush, sync := flushOn(item); flush {
+			if w.flush(&buffer) == nil && sync {
+				w.syncNow()
+			}
+			return
+		}
+		if len(buffer) >= pacer.batch() {
+			w.flush(&buffer)
+			if pacer.full() {
+				ticker.Reset(pacer.interval)
+			}
+		}
+	}
+	var ready <-chan struct{} // nil unless the lines are queued in a lineQueue
+	if w.queue != nil {
+		ready = w.queue.wake()
+	}
+	drain := func() {
+		for {
+			item, ok := w.queue.pop()
+			if !ok {
+				return
+			}
+			add(item)
+		}
+	}
+	for {
+		select {
+		case logEntry := <-w.lines:
+			add(logEntry)
+		case item := <-w.urgent:
+			w.flushUrgent(item)
+		case <-ready:
+			drain()
+		case <-ticker.C:
+			if w.queue != nil {
+				drain()
+			}
+			if pacer.tick(len(buffer)) {
+				ticker.Reset(pacer.interval)
+			}
+			if len(buffer) > 0 || w.pending() {
+				w.flush(&buffer)
+			}
+			w.fileMu.Lock()
+			w.syncIfDue(0)
+			w.fileMu.Unlock()
+		case req := <-w.syncs:
+			req.ack <- w.writeQueued(&buffer, req.fsync)
+		case <-w.done:
+			w.flushUrgent()
+			for w.queued() > 0 {
+				logEntry, ok := w.dequeue()
+				if !ok {
+					continue
+				}
+				buffer = append(buffer, logEntry)
+				if len(buffer) >= pacer.batch() {
+					w.flush(&buffer)
+				}
+			}
+
+			if len(buffer) > 0 || w.pending() {
+				w.flush(&buffer)
+			}
+
+			return
+		}
+	}
+}
+
+// flush writes the contents of the buffer to the log file, after the bytes of
+// a failed write. On error the bytes not written are kept to be written first
+// by the next flush, the lines are printed to the console once either way.
+func (w *fileWriter) flush(buffer *[]writeItem) error {
+	w.fileMu.Lock()
+	defer w.fileMu.Unlock()
+	w.checkDate()
+	if notice, ok := w.droppedNotice(); ok {
+		*buffer = append(*buffer, notice)
+	}
+	start := time.Now()
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.Write(w.unwritten)
+	batch := w.applyVolumeCap(*buffer)
+	for _, line := range elideFields(batch, applyFlushHook(batch)) {
+		buf.WriteString(line)
+	}
+	data := buf.Bytes()
+	n, err := w.writeData(data)
+	printConsole(*buffer)
+	countFlush(time.Since(start))
+	afterWrite(n, err)
+	lines := len(*buffer)
+	*buffer = (*buffer)[:0]
+	if err != nil {
+		w.unwritten = append(w.unwritten[:0], data[n:]...)
+		diag("flush", "writing %d lines to %s failed, %d bytes left to write: %v", lines, w.name(), len(w.unwritten), err)
+		handleError(err)
+		return err
+	}
+	w.unwritten = w.unwritten[:0]
+	diag("flush", "wrote %d lines, %d bytes to %s", lines, n, w.name())
+	w.syncIfDue(lines)
+	return nil
+}
+
+// pending reports whether bytes of a failed write wait for the next flush.
+func (w *fileWriter) pending() bool {
+	w.fileMu.Lock()
+	defer w.fileMu.Unlock()
+	return len(w.unwritten) > 0
+}
+
+// writeData writes to the file, rotating it when full, or to the collector, fileMu must be held.
+func (w *fileWriter) writeData(data []byte) (int, error) {
+	if w.socket != "" {
+		n, err := w.send(data)
+		atomic.AddUint64(&bytesWritten, uint64(n))
+		return n, err
+	}
+	if max := atomic.LoadInt64(&maxFileLines); max > 0 {
+		return w.writeLines(data, max)
+	}
+	w.rotateIfFull(len(data))
+	return w.writeFile(data)
+}
+
+// writeFile writes to the file and counts the bytes and lines written, fileMu must be held.
+func (w *fileWriter) writeFile(data []byte) (int, error) {
+	if w.file == nil {
+		if err := w.reopenFile(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.writeEncoded(data)
+	atomic.AddUint64(&bytesWritten, uint64(n))
+	w.dayBytes += int64(n)
+	if err == nil {
+		w.lineCount += int64(bytes.Count(data, newline))
+	}
+	return n, err
+}
+
+// writeEncoded writes the data in the file encoding, fileMu must be held. It
+// returns the number of bytes of the data written, which differs from the
+// number written to the file in other encodings.
+func (w *fileWriter) writeEncoded(data []byte) (int, error) {
+	empty := w.size == 0
+	n, err := w.file.Write(encodeFile(data, empty))
+	w.size += int64(n)
+	if err != nil {
+		return decodedLen(data, n, empty), err
+	}
+	return len(data), nil
+}
+
+// name returns the name of the open file, or the collector address.
+func (w *fileWriter) name() string {
+	if w.socket != "" {
+		return collectorName(w.socket)
+	}
+	if w.file == nil {
+		return w.filePath(w.date)
+	}
+	return w.file.Name()
+}
+
+// checkDate can change file over a day, fileMu must be held.
+func (w *fileWriter) checkDate() {
+	if w.socket != "" || w.file == nil {
+		return
+	}
+	day := currentDay()
+	if w.date == day {
+		return
+	}
+	file, err := openLogFile(w.filePath(day))
+	if err != nil {
+		diag("rotate", "opening the file of %s failed, keeping %s: %v", day, w.file.Name(), err)
+		handleError(fmt.Errorf("%w: %v", ErrRotateFailed, err))
+		return
+	}
+	diag("rotate", "switched from %s to %s", w.file.Name(), file.Name())
+	atomic.AddUint64(&rotationCount, 1)
+	w.writeMarker(&fileFooter, w.file.Name(), file.Name())
+	w.file.Close()
+	compressLater(w.file.Name())
+	w.file = file
+	w.date = day
+	w.size = 0
+	w.dayBytes = 0
+	w.capped = false
+	w.lineCount = 0
+	if info, err := file.Stat(); err == nil {
+		w.size = info.Size()
+		w.dayBytes = info.Size()
+		w.lineCount = countLines(file)
+	}
+	if w.size == 0 {
+		w.writeMarker(&fileHeader, w.file.Name(), "")
+	}
+	w.removeOldFiles()
+}
+
+// close flushes the queued lines and closes the log file.
+func (w *fileWriter) close() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+
+	close(w.done)
+	w.wg.Wait() // wait the run goroutine to finish
+	if w.manual {
+		w.pump()
+	}
+
+	w.fileMu.Lock()
+	defer w.fileMu.Unlock()
+	if w.socket != "" {
+		if w.conn != nil {
+			w.conn.Close()
+		}
+		diag("close", "disconnected from %s", w.name())
+		w.closed = true
+		w.conn = nil
+		return nil
+	}
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		diag("close", "closed %s", w.file.Name())
+	}
+	w.closed = true
+	w.file = nil
+	compressions.Wait()
+	return err
+}